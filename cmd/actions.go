@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"quickvm/internal/hyperv"
+)
+
+// Actions shared by the REST API and the MCP server. Errors are *apiError so
+// both can report the error code.
+
+// findVM finds a VM by index or case-insensitive name
+func findVM(ctx context.Context, m *hyperv.Manager, arg string) (hyperv.VM, error) {
+	vms, err := m.GetVMs(ctx)
+	if err != nil {
+		return hyperv.VM{}, newAPIError(http.StatusInternalServerError, "VM_LIST_FAILED", "Failed to get VMs", err)
+	}
+	index, indexErr := strconv.Atoi(arg)
+	for _, vm := range vms {
+		if (indexErr == nil && vm.Index == index) || strings.EqualFold(vm.Name, arg) {
+			return vm, nil
+		}
+	}
+	return hyperv.VM{}, newAPIError(http.StatusNotFound, "VM_NOT_FOUND", "VM not found", fmt.Errorf("no VM '%s'", arg))
+}
+
+func listVMs(ctx context.Context, m *hyperv.Manager) (VMListResponse, error) {
+	vms, err := m.GetVMs(ctx)
+	if err != nil {
+		return VMListResponse{}, newAPIError(http.StatusInternalServerError, "VM_LIST_FAILED", "Failed to get VMs", err)
+	}
+	return VMListResponse{VMs: vms, Total: len(vms)}, nil
+}
+
+// runVMAction starts, stops or restarts a VM
+func runVMAction(ctx context.Context, m *hyperv.Manager, arg, action string) (VMOperationResult, error) {
+	run := map[string]func(*hyperv.Manager, context.Context, string) error{
+		"start":   (*hyperv.Manager).StartVMByName,
+		"stop":    (*hyperv.Manager).StopVMByName,
+		"restart": (*hyperv.Manager).RestartVMByName,
+	}[action]
+	vm, err := findVM(ctx, m, arg)
+	if err != nil {
+		return VMOperationResult{}, err
+	}
+	if err := run(m, ctx, vm.Name); err != nil {
+		return VMOperationResult{}, newAPIError(http.StatusInternalServerError, strings.ToUpper(action)+"_FAILED", "Failed to "+action+" VM", err)
+	}
+	return VMOperationResult{Index: vm.Index, Name: vm.Name, Success: true, Message: "VM " + action + " completed"}, nil
+}
+
+// deleteVM removes a VM, and its virtual hard disks with deleteDisks
+func deleteVM(ctx context.Context, m *hyperv.Manager, arg string, deleteDisks bool) (VMOperationResult, error) {
+	vm, err := findVM(ctx, m, arg)
+	if err != nil {
+		return VMOperationResult{}, err
+	}
+	remove := m.DeleteVM
+	if deleteDisks {
+		remove = m.DeleteVMAndDisks
+	}
+	if err := remove(ctx, vm.Name); err != nil {
+		return VMOperationResult{}, newAPIError(http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete VM", err)
+	}
+	return VMOperationResult{Index: vm.Index, Name: vm.Name, Success: true, Message: "VM deleted"}, nil
+}
+
+func listSnapshots(ctx context.Context, m *hyperv.Manager, arg string) (SnapshotListResult, error) {
+	vm, err := findVM(ctx, m, arg)
+	if err != nil {
+		return SnapshotListResult{}, err
+	}
+	snapshots, err := m.GetSnapshotsByVMName(ctx, vm.Name)
+	if err != nil {
+		return SnapshotListResult{}, newAPIError(http.StatusInternalServerError, "SNAPSHOT_LIST_FAILED", "Failed to get snapshots", err)
+	}
+	return SnapshotListResult{VMName: vm.Name, VMIndex: vm.Index, Snapshots: snapshots, Total: len(snapshots)}, nil
+}
+
+// runSnapshotAction creates, restores or deletes a snapshot
+func runSnapshotAction(ctx context.Context, m *hyperv.Manager, arg, operation, name string) (SnapshotOpResult, error) {
+	if strings.TrimSpace(name) == "" {
+		return SnapshotOpResult{}, newAPIError(http.StatusBadRequest, "INVALID_BODY", "Snapshot name is required", nil)
+	}
+	run, verb := (*hyperv.Manager).CreateSnapshotByVMName, "created"
+	switch operation {
+	case "restore":
+		run, verb = (*hyperv.Manager).RestoreSnapshotByVMName, "restored"
+	case "delete":
+		run, verb = (*hyperv.Manager).DeleteSnapshotByVMName, "deleted"
+	}
+	vm, err := findVM(ctx, m, arg)
+	if err != nil {
+		return SnapshotOpResult{}, err
+	}
+	if err := run(m, ctx, vm.Name, name); err != nil {
+		return SnapshotOpResult{}, newAPIError(http.StatusInternalServerError, "SNAPSHOT_"+strings.ToUpper(operation)+"_FAILED",
+			"Failed to "+operation+" snapshot", err)
+	}
+	return SnapshotOpResult{Operation: operation, VMName: vm.Name, VMIndex: vm.Index, SnapshotName: name,
+		Success: true, Message: "Snapshot " + verb + " successfully"}, nil
+}
+
+// prepareExport validates an export and returns the task running it
+func prepareExport(ctx context.Context, m *hyperv.Manager, arg, path string) (apiTask, error) {
+	if path == "" {
+		return nil, newAPIError(http.StatusBadRequest, "INVALID_BODY", "Export path is required", nil)
+	}
+	vm, err := findVM(ctx, m, arg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (any, error) {
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return nil, newAPIError(http.StatusInternalServerError, "DIR_CREATE_FAILED", "Failed to create export directory", err)
+		}
+		if err := m.ExportVMByName(ctx, vm.Name, path); err != nil {
+			return nil, newAPIError(http.StatusInternalServerError, "EXPORT_FAILED", "Failed to export VM", err)
+		}
+		return ExportResult{VMName: vm.Name, VMIndex: vm.Index, ExportPath: filepath.Join(path, vm.Name),
+			Success: true, Message: "VM exported successfully"}, nil
+	}, nil
+}
+
+// prepareImport validates an import and returns the task running it. An export with a
+// quickvm manifest is verified first unless the request skips it.
+func prepareImport(m *hyperv.Manager, req ImportRequest) (apiTask, error) {
+	if req.Path == "" {
+		return nil, newAPIError(http.StatusBadRequest, "INVALID_BODY", "Import path is required", nil)
+	}
+	opts := hyperv.ImportVMOptions{Path: req.Path, Copy: req.Copy, GenerateNewID: req.GenerateNewID,
+		VHDPath: req.VHDPath, NewName: req.NewName}
+	return func(ctx context.Context) (any, error) {
+		if !req.SkipVerify && hyperv.HasExportManifest(req.Path) {
+			verification, err := hyperv.VerifyExport(req.Path)
+			if err != nil {
+				return nil, newAPIError(http.StatusInternalServerError, "VERIFY_FAILED", "Failed to verify export", err)
+			}
+			if !verification.Valid {
+				return nil, newAPIError(http.StatusBadRequest, "VERIFY_FAILED", "Export failed verification, refusing to import",
+					errors.New(strings.Join(verification.Problems(), "; ")))
+			}
+		}
+		// Unlike ImportVM, a cancelled import is rolled back
+		vmName, err := m.ImportVMWithProgress(ctx, opts, nil)
+		if err != nil {
+			return nil, newAPIError(http.StatusInternalServerError, "IMPORT_FAILED", "Failed to import VM", err)
+		}
+		return ImportResult{VMName: vmName, ImportPath: req.Path, Success: true, Message: "VM imported successfully"}, nil
+	}, nil
+}
+
+func listWorkspaces() (WorkspaceListResult, error) {
+	names, err := hyperv.ListWorkspaces()
+	if err != nil {
+		return WorkspaceListResult{}, newAPIError(http.StatusInternalServerError, "WORKSPACE_LIST_FAILED", "Failed to list workspaces", err)
+	}
+	return WorkspaceListResult{Workspaces: names, Total: len(names)}, nil
+}
+
+func loadWorkspaceArg(name string) (*hyperv.Workspace, error) {
+	ws, err := hyperv.LoadWorkspace(name)
+	if err != nil {
+		return nil, newAPIError(http.StatusNotFound, "WORKSPACE_NOT_FOUND", "Failed to load workspace", err)
+	}
+	return ws, nil
+}
+
+func workspaceStatus(ctx context.Context, m *hyperv.Manager, name string) (*hyperv.WorkspaceStatus, error) {
+	ws, err := loadWorkspaceArg(name)
+	if err != nil {
+		return nil, err
+	}
+	status, err := m.GetWorkspaceStatus(ctx, ws)
+	if err != nil {
+		return nil, newAPIError(http.StatusInternalServerError, "WORKSPACE_STATUS_FAILED", "Failed to get workspace status", err)
+	}
+	return status, nil
+}
+
+// prepareWorkspaceRun loads a workspace and returns the task starting or stopping it
+func prepareWorkspaceRun(m *hyperv.Manager, name, operation string) (apiTask, error) {
+	ws, err := loadWorkspaceArg(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (any, error) {
+		orchestrator := hyperv.NewWorkspaceOrchestrator(m)
+		run := orchestrator.Start
+		if operation == "stop" {
+			run = orchestrator.Stop
+		}
+		results, err := run(ctx, ws)
+		if err != nil {
+			return nil, newAPIError(http.StatusInternalServerError, "WORKSPACE_INVALID", "Invalid workspace startup configuration", err)
+		}
+		return summarizeWorkspaceRun(operation, ws.Name, results), nil
+	}, nil
+}
+
+func systemInfo(ctx context.Context, m *hyperv.Manager, includeDisk bool) (*hyperv.SystemInfo, error) {
+	info, err := m.GetSystemInfo(ctx, includeDisk)
+	if err != nil {
+		return nil, newAPIError(http.StatusInternalServerError, "SYSTEM_INFO_FAILED", "Error getting system info", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+)
+
+// apiPrefix is the path prefix of version 1 of the REST API
+const apiPrefix = "/api/v1"
+
+// maxFinishedAPIOperations is how many finished operations the API remembers
+const maxFinishedAPIOperations = 100
+
+// apiError is an error with the HTTP status and error code it is reported with
+type apiError struct {
+	status  int
+	code    string
+	message string
+	details string
+}
+
+func (e *apiError) Error() string {
+	if e.details != "" {
+		return e.message + ": " + e.details
+	}
+	return e.message
+}
+
+func newAPIError(status int, code, message string, err error) *apiError {
+	e := &apiError{status: status, code: code, message: message}
+	if err != nil {
+		e.details = err.Error()
+	}
+	return e
+}
+
+// apiRoute is one endpoint of the REST API; the route table drives both the
+// HTTP mux and the OpenAPI document
+type apiRoute struct {
+	method   string
+	path     string // Relative to apiPrefix, with {name} path parameters
+	summary  string
+	query    []apiParam // Query parameters
+	request  any        // Zero value of the JSON request body type, nil without a body
+	response any        // Zero value of the data type of a success response, or of the operation result
+	handle   func(s *apiServer, r *http.Request) (any, error)
+	// start validates the request of a long-running route and returns the work to run as an
+	// operation; such routes answer 202 with an APIOperation instead of calling handle
+	start func(s *apiServer, r *http.Request) (apiTask, error)
+	// stream subscribes a streaming route to its events, which are sent as NDJSON
+	// until the client disconnects; response is the type of one event
+	stream func(s *apiServer) (<-chan hyperv.Event, func())
+}
+
+// apiTask is the work of a long-running request
+type apiTask func(ctx context.Context) (any, error)
+
+// apiParam is a query parameter of a route
+type apiParam struct {
+	name        string
+	kind        string // OpenAPI type, e.g. "boolean"
+	description string
+}
+
+// APIOperation is a long-running API request, polled at /api/v1/operations/{id}
+type APIOperation struct {
+	ID       string            `json:"id"`
+	Kind     string            `json:"kind"` // Method and route, e.g. "POST /vms/{vm}/export"
+	Status   string            `json:"status"`
+	Started  time.Time         `json:"started"`
+	Finished *time.Time        `json:"finished,omitempty"`
+	Result   any               `json:"result,omitempty"`
+	Error    *output.ErrorInfo `json:"error,omitempty"`
+	cancel   context.CancelFunc
+}
+
+// Operation statuses
+const (
+	opRunning   = "running"
+	opSucceeded = "succeeded"
+	opFailed    = "failed"
+	opCanceled  = "canceled"
+)
+
+// apiOperations tracks the operations started through the API
+type apiOperations struct {
+	ctx     context.Context // Parent of every operation; cancelled when the server stops
+	running sync.WaitGroup
+
+	mu     sync.Mutex
+	nextID int
+	ops    map[string]*APIOperation
+	order  []string // IDs, oldest first
+}
+
+// start runs fn in the background and returns the operation tracking it
+func (o *apiOperations) start(kind string, fn apiTask) APIOperation {
+	ctx, cancel := context.WithCancel(o.ctx)
+	o.running.Add(1)
+	o.mu.Lock()
+	o.nextID++
+	op := &APIOperation{ID: strconv.Itoa(o.nextID), Kind: kind, Status: opRunning, Started: time.Now(), cancel: cancel}
+	if o.ops == nil {
+		o.ops = make(map[string]*APIOperation)
+	}
+	o.ops[op.ID] = op
+	o.order = append(o.order, op.ID)
+	o.prune()
+	snapshot := *op
+	o.mu.Unlock()
+
+	go func() {
+		defer o.running.Done()
+		result, err := fn(ctx)
+		o.finish(op.ID, result, err, ctx.Err() != nil)
+	}()
+	return snapshot
+}
+
+// wait blocks until every operation has finished, e.g. rolled back after the server stopped
+func (o *apiOperations) wait() {
+	o.running.Wait()
+}
+
+func (o *apiOperations) finish(id string, result any, err error, canceled bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op := o.ops[id]
+	now := time.Now()
+	op.Finished = &now
+	op.Result = result
+	op.cancel()
+	switch {
+	case canceled:
+		op.Status = opCanceled
+	case err != nil:
+		op.Status = opFailed
+	default:
+		op.Status = opSucceeded
+	}
+	if err != nil {
+		info := output.ErrorInfo{Code: "OPERATION_FAILED", Message: err.Error()}
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			info = output.ErrorInfo{Code: apiErr.code, Message: apiErr.message, Details: apiErr.details}
+		}
+		op.Error = &info
+	}
+}
+
+// prune forgets the oldest finished operations beyond maxFinishedAPIOperations; o.mu must be held
+func (o *apiOperations) prune() {
+	finished := 0
+	for _, id := range o.order {
+		if o.ops[id].Status != opRunning {
+			finished++
+		}
+	}
+	kept := o.order[:0]
+	for _, id := range o.order {
+		if finished > maxFinishedAPIOperations && o.ops[id].Status != opRunning {
+			delete(o.ops, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	o.order = kept
+}
+
+func (o *apiOperations) get(id string) (APIOperation, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op, ok := o.ops[id]
+	if !ok {
+		return APIOperation{}, false
+	}
+	return *op, true
+}
+
+func (o *apiOperations) list() []APIOperation {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ops := make([]APIOperation, 0, len(o.order))
+	for _, id := range o.order {
+		ops = append(ops, *o.ops[id])
+	}
+	return ops
+}
+
+// cancelOp cancels a running operation; it reports whether the operation exists
+func (o *apiOperations) cancelOp(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op, ok := o.ops[id]
+	if ok {
+		op.cancel()
+	}
+	return ok
+}
+
+// apiServer serves the REST API
+type apiServer struct {
+	manager *hyperv.Manager
+	token   string
+	ops     apiOperations
+	routes  []apiRoute
+	watcher *hyperv.Watcher // Shared by the clients of /events
+}
+
+// newAPIServer returns an API server whose operations are cancelled with ctx
+func newAPIServer(ctx context.Context, manager *hyperv.Manager, token string) *apiServer {
+	s := &apiServer{manager: manager, token: token}
+	s.ops.ctx = ctx
+	s.watcher = hyperv.NewWatcher(manager, hyperv.WatchOptions{Heartbeats: true, Snapshots: true})
+	s.routes = apiRoutes()
+	return s
+}
+
+// handler returns the HTTP handler of every route and of the OpenAPI document
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, route := range s.routes {
+		mux.Handle(route.method+" "+apiPrefix+route.path, s.authenticate(s.serveRoute(route)))
+	}
+	mux.HandleFunc("GET "+apiPrefix+"/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openAPIDocument(s.routes))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeAPIError(w, newAPIError(http.StatusNotFound, "NOT_FOUND", "No such endpoint", nil))
+	})
+	return mux
+}
+
+// authenticate requires the bearer token on every request
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="quickvm"`)
+			writeAPIError(w, newAPIError(http.StatusUnauthorized, "UNAUTHORIZED", "Missing or invalid API token", nil))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveRoute runs a route's handler, in the background for async routes
+func (s *apiServer) serveRoute(route apiRoute) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route.stream != nil {
+			s.serveStream(w, r, route)
+			return
+		}
+		if route.start == nil {
+			data, err := route.handle(s, r)
+			if err != nil {
+				writeAPIError(w, err)
+				return
+			}
+			writeAPIData(w, http.StatusOK, data)
+			return
+		}
+
+		// Invalid requests fail here, before an operation exists
+		task, err := route.start(s, r)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		op := s.ops.start(route.method+" "+route.path, task)
+		w.Header().Set("Location", apiPrefix+"/operations/"+op.ID)
+		writeAPIData(w, http.StatusAccepted, op)
+	})
+}
+
+// serveStream writes the events of a streaming route, one JSON object per line
+func (s *apiServer) serveStream(w http.ResponseWriter, r *http.Request, route apiRoute) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, newAPIError(http.StatusInternalServerError, "STREAMING_UNSUPPORTED", "Streaming is not supported", nil))
+		return
+	}
+	events, unsubscribe := route.stream(s)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case event := <-events:
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeAPIData(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(output.Response{Success: true, Data: data})
+}
+
+func writeAPIError(w http.ResponseWriter, err error) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = newAPIError(http.StatusInternalServerError, "INTERNAL_ERROR", "Request failed", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.status)
+	_ = json.NewEncoder(w).Encode(output.Response{
+		Success: false,
+		Error:   &output.ErrorInfo{Code: apiErr.code, Message: apiErr.message, Details: apiErr.details},
+	})
+}
+
+// decodeAPIBody decodes the JSON request body into v
+func decodeAPIBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return newAPIError(http.StatusBadRequest, "INVALID_BODY", "Invalid request body", err)
+	}
+	return nil
+}
+
+// generateAPIToken returns a random token for when none is configured
+func generateAPIToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// apiPathParam matches the {name} path parameters of a route
+var apiPathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument builds the OpenAPI 3 description of the routes; schemas are
+// derived from the Go types of the request and response values
+func openAPIDocument(routes []apiRoute) map[string]any {
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"success": map[string]any{"type": "boolean"},
+				"error":   jsonSchema(reflect.TypeOf(errorInfoSchema{}), nil),
+			},
+		},
+	}
+	paths := map[string]any{}
+	for _, route := range routes {
+		item, ok := paths[apiPrefix+route.path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[apiPrefix+route.path] = item
+		}
+		item[strings.ToLower(route.method)] = openAPIOperation(route, schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "quickvm API",
+			"version": Version,
+		},
+		"servers":  []any{map[string]any{"url": "/"}},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// errorInfoSchema mirrors output.ErrorInfo for the document
+type errorInfoSchema struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+func openAPIOperation(route apiRoute, schemas map[string]any) map[string]any {
+	var params []any
+	for _, match := range apiPathParam.FindAllStringSubmatch(route.path, -1) {
+		params = append(params, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, q := range route.query {
+		params = append(params, map[string]any{
+			"name": q.name, "in": "query", "description": q.description, "schema": map[string]any{"type": q.kind},
+		})
+	}
+
+	data := jsonSchema(reflect.TypeOf(route.response), schemas)
+	status, description := "200", "Success"
+	if route.start != nil {
+		// The result of a long-running route arrives in the operation's result field
+		data = map[string]any{"allOf": []any{
+			jsonSchema(reflect.TypeOf(APIOperation{}), schemas),
+			map[string]any{"properties": map[string]any{"result": data}},
+		}}
+		status, description = "202", "Operation started; poll the Location header"
+	}
+
+	content := map[string]any{"application/json": map[string]any{"schema": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"success": map[string]any{"type": "boolean"},
+			"data":    data,
+		},
+	}}}
+	if route.stream != nil {
+		// A stream is a sequence of bare events, one per line
+		content = map[string]any{"application/x-ndjson": map[string]any{"schema": data}}
+		description = "Stream of events, one JSON object per line"
+	}
+
+	op := map[string]any{
+		"summary":     route.summary,
+		"operationId": operationID(route),
+		"responses": map[string]any{
+			status: map[string]any{
+				"description": description,
+				"content":     content,
+			},
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{"application/json": map[string]any{
+					"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+				}},
+			},
+		},
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+	if route.request != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{"application/json": map[string]any{
+				"schema": jsonSchema(reflect.TypeOf(route.request), schemas),
+			}},
+		}
+	}
+	return op
+}
+
+// operationID names a route after its method and path, e.g. postVmsSnapshotsRestore;
+// a trailing parameter becomes a suffix such as ByVm
+func operationID(route apiRoute) string {
+	id := strings.ToLower(route.method)
+	segments := strings.Split(strings.Trim(route.path, "/"), "/")
+	for i, segment := range segments {
+		param, isParam := strings.CutPrefix(segment, "{")
+		switch {
+		case !isParam:
+			id += strings.ToUpper(segment[:1]) + segment[1:]
+		case i == len(segments)-1:
+			param = strings.TrimSuffix(param, "}")
+			id += "By" + strings.ToUpper(param[:1]) + param[1:]
+		}
+	}
+	return id
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonSchema returns the JSON schema of t as encoding/json marshals it. Named structs
+// are added to schemas and referenced; with nil schemas they are inlined. A
+// description struct tag documents a field.
+func jsonSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if schemas == nil || t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = map[string]any{} // Placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		// Interfaces such as an operation's result can hold any value
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := structSchema(field.Type, schemas)
+			for k, v := range embedded["properties"].(map[string]any) {
+				properties[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := jsonSchema(field.Type, schemas)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		properties[name] = property
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
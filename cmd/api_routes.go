@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"quickvm/internal/hyperv"
+)
+
+// SnapshotRequest is the body of a snapshot creation request
+type SnapshotRequest struct {
+	Name string `json:"name" description:"Snapshot name"`
+}
+
+// ExportRequest is the body of an export request
+type ExportRequest struct {
+	Path string `json:"path" description:"Directory on the Hyper-V host; the VM is exported into <path>/<vm name>"`
+}
+
+// ImportRequest is the body of an import request and the arguments of the import_vm tool
+type ImportRequest struct {
+	Path          string `json:"path" description:"Export directory on the Hyper-V host"`
+	Copy          bool   `json:"copy,omitempty" description:"Copy the VM files instead of registering them in place"`
+	GenerateNewID bool   `json:"generateNewId,omitempty" description:"Generate a new VM ID"`
+	VHDPath       string `json:"vhdPath,omitempty" description:"Directory for the virtual hard disks of a copy"`
+	NewName       string `json:"newName,omitempty" description:"Rename the imported VM"`
+	SkipVerify    bool   `json:"skipVerify,omitempty" description:"Import without verifying the checksums of the export manifest"`
+}
+
+// apiRoutes returns every route of the REST API
+func apiRoutes() []apiRoute {
+	routes := []apiRoute{
+		{method: "GET", path: "/vms", summary: "List virtual machines", response: VMListResponse{}, handle: listVMsHandler},
+		{method: "GET", path: "/vms/{vm}", summary: "Get a virtual machine by index or name", response: hyperv.VM{}, handle: getVMHandler},
+	}
+	for _, action := range []string{"start", "stop", "restart"} {
+		routes = append(routes, apiRoute{
+			method: "POST", path: "/vms/{vm}/" + action, summary: strings.ToUpper(action[:1]) + action[1:] + " a virtual machine",
+			response: VMOperationResult{}, handle: vmActionHandler(action),
+		})
+	}
+	return append(routes,
+		apiRoute{method: "GET", path: "/vms/{vm}/snapshots", summary: "List the snapshots of a virtual machine",
+			response: SnapshotListResult{}, handle: listSnapshotsHandler},
+		apiRoute{method: "POST", path: "/vms/{vm}/snapshots", summary: "Create a snapshot",
+			request: SnapshotRequest{}, response: SnapshotOpResult{}, handle: createSnapshotHandler},
+		apiRoute{method: "POST", path: "/vms/{vm}/snapshots/{snapshot}/restore", summary: "Restore a snapshot",
+			response: SnapshotOpResult{}, handle: snapshotHandler("restore")},
+		apiRoute{method: "DELETE", path: "/vms/{vm}/snapshots/{snapshot}", summary: "Delete a snapshot",
+			response: SnapshotOpResult{}, handle: snapshotHandler("delete")},
+		apiRoute{method: "POST", path: "/vms/{vm}/export", summary: "Export a virtual machine",
+			request: ExportRequest{}, response: ExportResult{}, start: exportHandler},
+		apiRoute{method: "POST", path: "/import", summary: "Import a virtual machine",
+			request: ImportRequest{}, response: ImportResult{}, start: importHandler},
+		apiRoute{method: "GET", path: "/workspaces", summary: "List workspaces", response: WorkspaceListResult{}, handle: listWorkspacesHandler},
+		apiRoute{method: "GET", path: "/workspaces/{name}", summary: "Get the live status of a workspace",
+			response: hyperv.WorkspaceStatus{}, handle: workspaceStatusHandler},
+		apiRoute{method: "POST", path: "/workspaces/{name}/start", summary: "Start a workspace in dependency order",
+			response: WorkspaceRunResult{}, start: workspaceRunHandler("start")},
+		apiRoute{method: "POST", path: "/workspaces/{name}/stop", summary: "Stop a workspace in reverse dependency order",
+			response: WorkspaceRunResult{}, start: workspaceRunHandler("stop")},
+		apiRoute{method: "GET", path: "/system", summary: "Get host system information",
+			query:    []apiParam{{name: "disk", kind: "boolean", description: "Include disk usage (slower)"}},
+			response: hyperv.SystemInfo{}, handle: systemInfoHandler},
+		apiRoute{method: "GET", path: "/events", summary: "Stream VM events as NDJSON until the client disconnects",
+			response: hyperv.Event{}, stream: eventsStream},
+		apiRoute{method: "GET", path: "/operations", summary: "List recent long-running operations",
+			response: []APIOperation{}, handle: listOperationsHandler},
+		apiRoute{method: "GET", path: "/operations/{id}", summary: "Get a long-running operation",
+			response: APIOperation{}, handle: getOperationHandler},
+		apiRoute{method: "DELETE", path: "/operations/{id}", summary: "Cancel a long-running operation",
+			response: APIOperation{}, handle: cancelOperationHandler},
+	)
+}
+
+func listVMsHandler(s *apiServer, r *http.Request) (any, error) {
+	return listVMs(r.Context(), s.manager)
+}
+
+func getVMHandler(s *apiServer, r *http.Request) (any, error) {
+	return findVM(r.Context(), s.manager, r.PathValue("vm"))
+}
+
+func vmActionHandler(action string) func(s *apiServer, r *http.Request) (any, error) {
+	return func(s *apiServer, r *http.Request) (any, error) {
+		return runVMAction(r.Context(), s.manager, r.PathValue("vm"), action)
+	}
+}
+
+func listSnapshotsHandler(s *apiServer, r *http.Request) (any, error) {
+	return listSnapshots(r.Context(), s.manager, r.PathValue("vm"))
+}
+
+func createSnapshotHandler(s *apiServer, r *http.Request) (any, error) {
+	var req SnapshotRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return nil, err
+	}
+	return runSnapshotAction(r.Context(), s.manager, r.PathValue("vm"), "create", req.Name)
+}
+
+func snapshotHandler(operation string) func(s *apiServer, r *http.Request) (any, error) {
+	return func(s *apiServer, r *http.Request) (any, error) {
+		return runSnapshotAction(r.Context(), s.manager, r.PathValue("vm"), operation, r.PathValue("snapshot"))
+	}
+}
+
+func exportHandler(s *apiServer, r *http.Request) (apiTask, error) {
+	var req ExportRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return nil, err
+	}
+	return prepareExport(r.Context(), s.manager, r.PathValue("vm"), req.Path)
+}
+
+func importHandler(s *apiServer, r *http.Request) (apiTask, error) {
+	var req ImportRequest
+	if err := decodeAPIBody(r, &req); err != nil {
+		return nil, err
+	}
+	return prepareImport(s.manager, req)
+}
+
+func listWorkspacesHandler(_ *apiServer, _ *http.Request) (any, error) {
+	return listWorkspaces()
+}
+
+func workspaceStatusHandler(s *apiServer, r *http.Request) (any, error) {
+	return workspaceStatus(r.Context(), s.manager, r.PathValue("name"))
+}
+
+func workspaceRunHandler(operation string) func(s *apiServer, r *http.Request) (apiTask, error) {
+	return func(s *apiServer, r *http.Request) (apiTask, error) {
+		return prepareWorkspaceRun(s.manager, r.PathValue("name"), operation)
+	}
+}
+
+func systemInfoHandler(s *apiServer, r *http.Request) (any, error) {
+	includeDisk, _ := strconv.ParseBool(r.URL.Query().Get("disk"))
+	return systemInfo(r.Context(), s.manager, includeDisk)
+}
+
+func listOperationsHandler(s *apiServer, _ *http.Request) (any, error) {
+	return s.ops.list(), nil
+}
+
+func getOperationHandler(s *apiServer, r *http.Request) (any, error) {
+	op, ok := s.ops.get(r.PathValue("id"))
+	if !ok {
+		return nil, newAPIError(http.StatusNotFound, "OPERATION_NOT_FOUND", "Operation not found", nil)
+	}
+	return op, nil
+}
+
+func cancelOperationHandler(s *apiServer, r *http.Request) (any, error) {
+	if !s.ops.cancelOp(r.PathValue("id")) {
+		return nil, newAPIError(http.StatusNotFound, "OPERATION_NOT_FOUND", "Operation not found", nil)
+	}
+	return getOperationHandler(s, r)
+}
+
+func eventsStream(s *apiServer) (<-chan hyperv.Event, func()) {
+	return s.watcher.Subscribe()
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+// apiExecutor answers every script with the VM list, fakes exports and records the cmdlets it runs
+type apiExecutor struct {
+	mu      sync.Mutex
+	cmdlets []string
+	block   chan struct{} // Export-VM waits on it when set
+}
+
+func (e *apiExecutor) RunScript(_ context.Context, _ string) ([]byte, error) {
+	return []byte(`[{"Name":"Web01","State":"Off"},{"Name":"Db01","State":"Running"}]`), nil
+}
+
+func (e *apiExecutor) RunCmdlet(ctx context.Context, cmdlet string, args ...string) ([]byte, error) {
+	e.mu.Lock()
+	e.cmdlets = append(e.cmdlets, cmdlet+" "+strings.Join(args, " "))
+	e.mu.Unlock()
+	if cmdlet == "Get-VM" {
+		return []byte(`{"ID":"1b4e28ba","Generation":2}`), nil
+	}
+	if cmdlet == "Export-VM" && e.block != nil {
+		select {
+		case <-e.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if cmdlet == "Export-VM" {
+		// -Name <vm> -Path <dir>: Hyper-V writes the export into <dir>/<vm>
+		vmDir := filepath.Join(args[3], args[1], "Virtual Machines")
+		if err := os.MkdirAll(vmDir, 0750); err != nil {
+			return nil, err
+		}
+		return nil, os.WriteFile(filepath.Join(vmDir, "1b4e28ba.vmcx"), []byte("config"), 0600)
+	}
+	return nil, nil
+}
+
+func newTestAPI(t *testing.T, exec *apiExecutor) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(newAPIServer(context.Background(), &hyperv.Manager{Exec: exec}, "secret").handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// apiResponse is the envelope with its data left raw
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func apiRequest(t *testing.T, server *httptest.Server, method, path, body string) (int, apiResponse) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+apiPrefix+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var decoded apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestAPIRequiresToken(t *testing.T) {
+	server := newTestAPI(t, &apiExecutor{})
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		req, _ := http.NewRequest("GET", server.URL+apiPrefix+"/vms", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", header, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(server.URL + apiPrefix + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("OpenAPI document: status %d, want 200 without a token", resp.StatusCode)
+	}
+}
+
+func TestAPIVMs(t *testing.T) {
+	exec := &apiExecutor{}
+	server := newTestAPI(t, exec)
+
+	status, resp := apiRequest(t, server, "GET", "/vms", "")
+	var list VMListResponse
+	if err := json.Unmarshal(resp.Data, &list); err != nil || status != http.StatusOK || list.Total != 2 {
+		t.Fatalf("GET /vms: status %d, data %s", status, resp.Data)
+	}
+
+	status, resp = apiRequest(t, server, "POST", "/vms/2/start", "")
+	var result VMOperationResult
+	if err := json.Unmarshal(resp.Data, &result); err != nil || status != http.StatusOK || result.Name != "Db01" {
+		t.Fatalf("POST /vms/2/start: status %d, data %s", status, resp.Data)
+	}
+	if got := exec.cmdlets[len(exec.cmdlets)-1]; !strings.HasPrefix(got, "Start-VM") || !strings.Contains(got, "Db01") {
+		t.Errorf("Expected Start-VM for Db01, ran %q", got)
+	}
+
+	status, resp = apiRequest(t, server, "GET", "/vms/web01", "")
+	if status != http.StatusOK || !strings.Contains(string(resp.Data), `"Web01"`) {
+		t.Errorf("GET /vms/web01: status %d, data %s", status, resp.Data)
+	}
+
+	status, resp = apiRequest(t, server, "POST", "/vms/nope/stop", "")
+	if status != http.StatusNotFound || resp.Success || resp.Error == nil || resp.Error.Code != "VM_NOT_FOUND" {
+		t.Errorf("Unknown VM: status %d, response %+v", status, resp)
+	}
+
+	status, resp = apiRequest(t, server, "POST", "/vms/1/snapshots", `{"nam":"x"}`)
+	if status != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != "INVALID_BODY" {
+		t.Errorf("Unknown body field: status %d, response %+v", status, resp)
+	}
+
+	if status, _ := apiRequest(t, server, "GET", "/nothing", ""); status != http.StatusNotFound {
+		t.Errorf("Unknown endpoint: status %d, want 404", status)
+	}
+}
+
+func TestAPIAsyncOperation(t *testing.T) {
+	exec := &apiExecutor{block: make(chan struct{})}
+	server := newTestAPI(t, exec)
+	dir := t.TempDir()
+
+	status, resp := apiRequest(t, server, "POST", "/vms/Web01/export", `{"path":`+jsonString(dir)+`}`)
+	var op APIOperation
+	if err := json.Unmarshal(resp.Data, &op); err != nil || status != http.StatusAccepted || op.Status != opRunning {
+		t.Fatalf("POST export: status %d, data %s", status, resp.Data)
+	}
+
+	close(exec.block)
+	deadline := time.Now().Add(2 * time.Second)
+	for op.Status == opRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		_, resp = apiRequest(t, server, "GET", "/operations/"+op.ID, "")
+		if err := json.Unmarshal(resp.Data, &op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if op.Status != opSucceeded || !strings.Contains(string(resp.Data), `"exportPath"`) {
+		t.Fatalf("Expected the export to succeed, got %s", resp.Data)
+	}
+
+	// Validation errors are reported before an operation starts
+	if status, _ := apiRequest(t, server, "POST", "/import", `{}`); status != http.StatusBadRequest {
+		t.Errorf("Import without a path: status %d, want 400", status)
+	}
+}
+
+func TestAPICancelOperation(t *testing.T) {
+	exec := &apiExecutor{block: make(chan struct{})}
+	server := newTestAPI(t, exec)
+
+	_, resp := apiRequest(t, server, "POST", "/vms/1/export", `{"path":`+jsonString(t.TempDir())+`}`)
+	var op APIOperation
+	if err := json.Unmarshal(resp.Data, &op); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := apiRequest(t, server, "DELETE", "/operations/"+op.ID, ""); status != http.StatusOK {
+		t.Fatalf("Cancel: status %d", status)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for op.Status == opRunning && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		_, resp = apiRequest(t, server, "GET", "/operations/"+op.ID, "")
+		_ = json.Unmarshal(resp.Data, &op)
+	}
+	if op.Status != opCanceled {
+		t.Errorf("Expected the operation to be canceled, got %q", op.Status)
+	}
+}
+
+func TestAPIShutdownCancelsOperations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newAPIServer(ctx, &hyperv.Manager{Exec: &apiExecutor{block: make(chan struct{})}}, "secret")
+	server := httptest.NewServer(s.handler())
+	t.Cleanup(server.Close)
+
+	_, resp := apiRequest(t, server, "POST", "/vms/1/export", `{"path":`+jsonString(t.TempDir())+`}`)
+	var op APIOperation
+	if err := json.Unmarshal(resp.Data, &op); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		s.ops.wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Operations did not finish after the server stopped")
+	}
+	if got, _ := s.ops.get(op.ID); got.Status != opCanceled {
+		t.Errorf("Expected the operation to be canceled, got %q", got.Status)
+	}
+}
+
+func TestAPIEventStream(t *testing.T) {
+	s := newAPIServer(context.Background(), &hyperv.Manager{Exec: &bootingExecutor{}}, "secret")
+	s.watcher = hyperv.NewWatcher(s.manager, hyperv.WatchOptions{Interval: time.Millisecond})
+	server := httptest.NewServer(s.handler())
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+apiPrefix+"/events", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var event hyperv.Event
+	if err := json.NewDecoder(resp.Body).Decode(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != hyperv.EventStateChanged || event.VMName != "Web01" || event.State != "Running" {
+		t.Errorf("Expected Web01 to start, got %+v", event)
+	}
+
+	stream := openAPIDocument(s.routes)["paths"].(map[string]any)[apiPrefix+"/events"].(map[string]any)["get"]
+	data, _ := json.Marshal(stream)
+	if !strings.Contains(string(data), `"application/x-ndjson"`) || !strings.Contains(string(data), `"#/components/schemas/Event"`) {
+		t.Errorf("Events operation: %s", data)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openAPIDocument(apiRoutes())
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := doc["paths"].(map[string]any)
+	for _, route := range apiRoutes() {
+		item, ok := paths[apiPrefix+route.path].(map[string]any)
+		if !ok || item[strings.ToLower(route.method)] == nil {
+			t.Errorf("Missing %s %s in the document", route.method, route.path)
+		}
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, name := range []string{"VMListResponse", "VM", "ExportRequest", "APIOperation", "WorkspaceRunResult"} {
+		if schemas[name] == nil {
+			t.Errorf("Missing schema %s", name)
+		}
+	}
+	if strings.Contains(string(data), `"Index"`) {
+		t.Error("Fields tagged json:\"-\" must not appear in schemas")
+	}
+}
+
+func TestOperationID(t *testing.T) {
+	tests := map[string]apiRoute{
+		"getVms":                  {method: "GET", path: "/vms"},
+		"getVmsByVm":              {method: "GET", path: "/vms/{vm}"},
+		"postVmsSnapshotsRestore": {method: "POST", path: "/vms/{vm}/snapshots/{snapshot}/restore"},
+		"deleteOperationsById":    {method: "DELETE", path: "/operations/{id}"},
+	}
+	for want, route := range tests {
+		if got := operationID(route); got != want {
+			t.Errorf("operationID(%s %s) = %s, want %s", route.method, route.path, got, want)
+		}
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func TestPrepareImport_RefusesExportFailingVerification(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"formatVersion":1,"vmName":"Web01","files":[{"path":"Virtual Hard Disks/web01.vhdx","size":1,"sha256":"00"}]}`
+	if err := os.WriteFile(filepath.Join(dir, hyperv.ExportManifestFile), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m := &hyperv.Manager{Exec: &apiExecutor{}}
+
+	task, err := prepareImport(m, ImportRequest{Path: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = task(context.Background())
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.code != "VERIFY_FAILED" || !strings.Contains(apiErr.details, "web01.vhdx") {
+		t.Fatalf("Expected the import to be refused, got %v", err)
+	}
+
+	task, _ = prepareImport(m, ImportRequest{Path: dir, SkipVerify: true})
+	if _, err := task(context.Background()); errors.As(err, &apiErr) && apiErr.code == "VERIFY_FAILED" {
+		t.Errorf("Expected skipVerify to skip the verification, got %v", err)
+	}
+}
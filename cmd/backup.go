@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"quickvm/internal/archive"
+	"quickvm/internal/backup"
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupRepo           string
+	backupKeepCheckpoint bool
+	backupListVM         string
+	backupPolicy         backup.RetentionPolicy
+	backupPruneDryRun    bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <vm> --repo <dir>",
+	Short: "Back up a VM into a deduplicating backup repository",
+	Long: `Back up a VM into a local backup repository.
+
+A checkpoint is taken so the backup is consistent while the VM keeps running,
+the checkpoint is exported into the repository and split into content-defined
+chunks. Chunks already in the repository (from earlier backups of this or any
+other VM) are not stored again, so nightly backups only add what changed.
+The checkpoint is removed afterwards unless --keep-checkpoint is set.
+
+<vm> is a VM index or name. The repository is created on first use.
+
+Subcommands:
+  list     - List backup sets
+  restore  - Reassemble a backup set into an importable export
+  prune    - Remove old backup sets according to retention rules
+
+Examples:
+  quickvm backup 1 --repo "D:\Backups\repo"
+  quickvm backup Web01 --repo "D:\Backups\repo"
+  quickvm backup list --repo "D:\Backups\repo"
+  quickvm backup restore 3fa2c1d0 "D:\Restore" --repo "D:\Backups\repo"
+  quickvm backup prune --repo "D:\Backups\repo" --keep-daily 7 --keep-weekly 4`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runBackup(cmd.Context(), args[0])
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list --repo <dir>",
+	Short: "List backup sets in a repository",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		runBackupList()
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <set> <dir> --repo <dir>",
+	Short: "Restore a backup set as an importable export",
+	Long: `Reassemble a backup set into <dir>/<vm name> as a regular VM export.
+
+Every chunk and file is verified against its SHA-256 while restoring, and an
+export manifest is written so 'quickvm import' verifies the files again.
+<set> is a set ID (or a unique prefix of one) or 'latest:<vm name>'.
+
+Examples:
+  quickvm backup restore 3fa2c1d0 "D:\Restore" --repo "D:\Backups\repo"
+  quickvm backup restore latest:Web01 "D:\Restore" --repo "D:\Backups\repo"
+  quickvm import "D:\Restore\Web01" --copy --new-id`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runBackupRestore(cmd.Context(), args[0], args[1])
+	},
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune --repo <dir>",
+	Short: "Remove old backup sets according to retention rules",
+	Long: `Remove backup sets that no retention rule keeps, then delete chunks that
+no remaining set references. Rules apply per VM; a set is kept if any rule
+selects it. At least one rule is required.
+
+Examples:
+  quickvm backup prune --repo "D:\Backups\repo" --keep-last 3
+  quickvm backup prune --repo "D:\Backups\repo" --keep-daily 7 --keep-weekly 4 --keep-monthly 12
+  quickvm backup prune --repo "D:\Backups\repo" --keep-daily 7 --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		runBackupPrune(cmd.Context())
+	},
+}
+
+// openBackupRepo opens the repository given by --repo, creating it if requested
+func openBackupRepo(create bool) (*backup.Repository, bool) {
+	if backupRepo == "" {
+		output.PrintError("INVALID_ARGS", "Backup repository required", "use --repo <dir>")
+		if !output.IsJSON() {
+			fmt.Println("❌ Backup repository required: use --repo <dir>")
+		}
+		return nil, false
+	}
+
+	root, err := filepath.Abs(backupRepo)
+	if err != nil {
+		printBackupError("PATH_ERROR", "Invalid repository path", err)
+		return nil, false
+	}
+
+	var repo *backup.Repository
+	if create {
+		repo, err = backup.Init(root)
+	} else {
+		repo, err = backup.Open(root)
+	}
+	if err != nil {
+		printBackupError("BACKUP_REPO_FAILED", "Failed to open backup repository", err)
+		return nil, false
+	}
+	return repo, true
+}
+
+func runBackup(ctx context.Context, vmArg string) {
+	manager := hyperv.NewManager()
+	vmName, err := resolveVMName(ctx, manager, vmArg)
+	if err != nil {
+		printBackupError("VM_GET_FAILED", "Failed to get VM", err)
+		return
+	}
+
+	repo, ok := openBackupRepo(true)
+	if !ok {
+		return
+	}
+
+	opts := hyperv.BackupOptions{KeepCheckpoint: backupKeepCheckpoint}
+	if !output.IsJSON() {
+		fmt.Printf("💾 Backing up VM '%s' to '%s'...\n", vmName, repo.Root)
+		opts.OnStatus = func(message string) { fmt.Printf("   %s...\n", message) }
+		printer := newArchiveProgressPrinter("Backing up")
+		opts.OnProgress = func(done, total int64) { printer(archive.Progress{Done: done, Total: total}) }
+	}
+
+	set, err := manager.BackupVM(ctx, vmName, repo, opts)
+	if err != nil {
+		if !output.IsJSON() {
+			fmt.Println()
+		}
+		printBackupError("BACKUP_FAILED", "Failed to back up VM", err)
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(newBackupSetSummary(set))
+		return
+	}
+
+	fmt.Printf("\n✅ Backup set %s created for '%s'\n", set.ID, vmName)
+	fmt.Printf("   Size: %s in %d files\n", archive.FormatSize(set.Size), len(set.Files))
+	fmt.Printf("   Added to repository: %s (%d new chunks)\n", archive.FormatSize(set.AddedBytes), set.AddedChunks)
+	if backupKeepCheckpoint {
+		fmt.Printf("   Checkpoint kept: %s\n", set.Checkpoint)
+	}
+}
+
+func runBackupList() {
+	repo, ok := openBackupRepo(false)
+	if !ok {
+		return
+	}
+
+	sets, err := repo.ListSets()
+	if err != nil {
+		printBackupError("BACKUP_LIST_FAILED", "Failed to list backup sets", err)
+		return
+	}
+
+	summaries := make([]BackupSetSummary, 0, len(sets))
+	for _, set := range sets {
+		if backupListVM == "" || strings.EqualFold(set.VMName, backupListVM) {
+			summaries = append(summaries, newBackupSetSummary(set))
+		}
+	}
+
+	if output.IsJSON() {
+		output.PrintData(BackupListResult{Repository: repo.Root, Sets: summaries, Total: len(summaries)})
+		return
+	}
+
+	if len(summaries) == 0 {
+		fmt.Println("📭 No backup sets found")
+		return
+	}
+	fmt.Printf("💾 Backup sets in '%s'\n\n", repo.Root)
+	fmt.Printf("%-10s %-20s %-20s %12s %12s\n", "ID", "VM", "CREATED", "SIZE", "ADDED")
+	for _, s := range summaries {
+		fmt.Printf("%-10s %-20s %-20s %12s %12s\n", s.ID, truncateString(s.VMName, 20),
+			s.CreatedAt.Local().Format("2006-01-02 15:04:05"), archive.FormatSize(s.Size), archive.FormatSize(s.AddedBytes))
+	}
+	fmt.Printf("\n📊 Total: %d backup sets\n", len(summaries))
+}
+
+func runBackupRestore(ctx context.Context, ref, dest string) {
+	repo, ok := openBackupRepo(false)
+	if !ok {
+		return
+	}
+
+	set, err := repo.FindSet(ref)
+	if err != nil {
+		printBackupError("BACKUP_SET_NOT_FOUND", "Backup set not found", err)
+		return
+	}
+
+	destDir, err := filepath.Abs(dest)
+	if err != nil {
+		printBackupError("PATH_ERROR", "Invalid restore path", err)
+		return
+	}
+
+	var onProgress backup.ProgressFunc
+	if !output.IsJSON() {
+		fmt.Printf("♻️  Restoring backup set %s of '%s' (%s) to '%s'...\n",
+			set.ID, set.VMName, set.CreatedAt.Local().Format("2006-01-02 15:04:05"), destDir)
+		printer := newArchiveProgressPrinter("Restoring")
+		onProgress = func(done, total int64) { printer(archive.Progress{Done: done, Total: total}) }
+	}
+
+	exportDir, err := hyperv.RestoreBackup(ctx, repo, set, destDir, onProgress)
+	if err != nil {
+		if !output.IsJSON() {
+			fmt.Println()
+		}
+		printBackupError("BACKUP_RESTORE_FAILED", "Failed to restore backup set", err)
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(BackupRestoreResult{SetID: set.ID, VMName: set.VMName, ExportPath: exportDir, Success: true})
+		return
+	}
+
+	fmt.Printf("\n✅ Backup set %s restored to '%s'\n", set.ID, exportDir)
+	fmt.Println("\n💡 Tips:")
+	fmt.Printf("   - Import it with: quickvm import \"%s\" --copy --new-id\n", exportDir)
+	fmt.Println("   - Omit --new-id to replace the original VM after deleting it")
+}
+
+func runBackupPrune(ctx context.Context) {
+	repo, ok := openBackupRepo(false)
+	if !ok {
+		return
+	}
+
+	unlock, err := repo.Lock()
+	if err != nil {
+		printBackupError("BACKUP_REPO_LOCKED", "Backup repository is locked", err)
+		return
+	}
+	defer unlock()
+
+	result, err := repo.Prune(ctx, backupPolicy, backupPruneDryRun)
+	if err != nil {
+		printBackupError("BACKUP_PRUNE_FAILED", "Failed to prune backup sets", err)
+		return
+	}
+
+	if output.IsJSON() {
+		pruneResult := BackupPruneResult{
+			Policy:        backupPolicy,
+			DryRun:        result.DryRun,
+			Kept:          make([]BackupSetSummary, 0, len(result.Kept)),
+			Removed:       make([]BackupSetSummary, 0, len(result.Removed)),
+			RemovedChunks: result.RemovedChunks,
+			FreedBytes:    result.FreedBytes,
+		}
+		for _, set := range result.Kept {
+			pruneResult.Kept = append(pruneResult.Kept, newBackupSetSummary(set))
+		}
+		for _, set := range result.Removed {
+			pruneResult.Removed = append(pruneResult.Removed, newBackupSetSummary(set))
+		}
+		output.PrintData(pruneResult)
+		return
+	}
+
+	verb := "Removed"
+	if result.DryRun {
+		verb = "Would remove"
+		fmt.Println("🔍 Dry run: nothing is deleted")
+	}
+	for _, set := range result.Removed {
+		fmt.Printf("   🗑️  %s %s (%s)\n", set.ID, set.VMName, set.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("\n✅ %s %d backup sets and %d unused chunks (%s); %d sets kept\n",
+		verb, len(result.Removed), result.RemovedChunks, archive.FormatSize(result.FreedBytes), len(result.Kept))
+}
+
+// resolveVMName accepts a VM index or name and returns the VM name
+func resolveVMName(ctx context.Context, manager *hyperv.Manager, arg string) (string, error) {
+	if index, err := strconv.Atoi(arg); err == nil {
+		return manager.GetVMNameByIndex(ctx, index)
+	}
+	exists, err := manager.VMExists(ctx, arg)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("VM '%s' not found", arg)
+	}
+	return arg, nil
+}
+
+// newBackupSetSummary describes a set without its chunk lists
+func newBackupSetSummary(set *backup.Set) BackupSetSummary {
+	return BackupSetSummary{
+		ID:          set.ID,
+		VMName:      set.VMName,
+		CreatedAt:   set.CreatedAt,
+		Checkpoint:  set.Checkpoint,
+		Files:       len(set.Files),
+		Size:        set.Size,
+		AddedBytes:  set.AddedBytes,
+		AddedChunks: set.AddedChunks,
+	}
+}
+
+func printBackupError(code, message string, err error) {
+	output.PrintError(code, message, err.Error())
+	if !output.IsJSON() {
+		fmt.Printf("❌ %s: %v\n", message, err)
+	}
+}
+
+func init() {
+	backupCmd.PersistentFlags().StringVar(&backupRepo, "repo", "", "Backup repository directory")
+	backupCmd.Flags().BoolVar(&backupKeepCheckpoint, "keep-checkpoint", false, "Keep the checkpoint taken for the backup")
+
+	backupListCmd.Flags().StringVar(&backupListVM, "vm", "", "Only list backup sets of this VM")
+
+	backupPruneCmd.Flags().IntVar(&backupPolicy.KeepLast, "keep-last", 0, "Keep the newest N sets per VM")
+	backupPruneCmd.Flags().IntVar(&backupPolicy.KeepDaily, "keep-daily", 0, "Keep the newest set of each of the last N days per VM")
+	backupPruneCmd.Flags().IntVar(&backupPolicy.KeepWeekly, "keep-weekly", 0, "Keep the newest set of each of the last N weeks per VM")
+	backupPruneCmd.Flags().IntVar(&backupPolicy.KeepMonthly, "keep-monthly", 0, "Keep the newest set of each of the last N months per VM")
+	backupPruneCmd.Flags().BoolVar(&backupPruneDryRun, "dry-run", false, "Show what would be removed without deleting anything")
+
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupPruneCmd)
+	rootCmd.AddCommand(backupCmd)
+}
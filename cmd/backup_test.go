@@ -0,0 +1,26 @@
+package cmd
+
+import "testing"
+
+func TestBackupCommandSetup(t *testing.T) {
+	expected := map[string]bool{"list": false, "restore": false, "prune": false}
+	for _, sub := range backupCmd.Commands() {
+		if _, ok := expected[sub.Name()]; ok {
+			expected[sub.Name()] = true
+		}
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("Expected subcommand '%s' on 'backup'", name)
+		}
+	}
+
+	if backupListCmd.InheritedFlags().Lookup("repo") == nil {
+		t.Error("Expected 'backup list' to inherit --repo")
+	}
+	for _, flag := range []string{"keep-last", "keep-daily", "keep-weekly", "keep-monthly", "dry-run"} {
+		if backupPruneCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag '%s' on 'backup prune'", flag)
+		}
+	}
+}
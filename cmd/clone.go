@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"quickvm/internal/hyperv"
 	"quickvm/internal/output"
@@ -11,6 +12,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	cloneNewMAC           bool
+	cloneSwitch           string
+	cloneCPU              int
+	cloneMemoryMB         int64
+	cloneStripCheckpoints bool
+	cloneHostname         string
+	cloneSysprep          bool
+	cloneCloudInitReset   bool
+	cloneGuestOS          string
+	cloneGuestUser        string
+	cloneGuestPasswordEnv string
+	cloneSSHKey           string
+	cloneGuestTimeout     time.Duration
+)
+
 var cloneCmd = &cobra.Command{
 	Use:   "clone <vm-index> <new-name>",
 	Short: "Clone a VM with a new name (full clone)",
@@ -18,16 +35,27 @@ var cloneCmd = &cobra.Command{
 
 This performs a full clone operation:
 1. Export the source VM to a temporary directory
-2. Import with Copy and GenerateNewId flags
-3. Rename to the specified new name
-4. Cleanup temporary files
+2. Import with Copy and GenerateNewId flags under the new name
+3. Cleanup temporary files
 
 The cloned VM will be completely independent from the source VM.
-This may take several minutes depending on the VM disk size.
+This may take several minutes depending on the VM disk size. Progress is
+shown as a progress bar, or as one JSON event per line with --output json.
+Press Ctrl+C to cancel; a partially imported clone is removed again.
+
+A plain clone keeps the source's MAC addresses and guest hostname, so it
+clashes with the source on the same network. Customization options fix that:
+--new-mac, --switch, --cpu, --memory and --strip-checkpoints change the clone
+while it is off. --hostname, --sysprep and --cloud-init-reset start the clone,
+run commands inside the guest (PowerShell Direct for Windows, SSH for Linux)
+and shut it down again. If customization fails the clone is removed.
 
 Examples:
   quickvm clone 1 "WebServer-Copy"            # Clone VM 1 with new name
-  quickvm clone 2 "TestVM"                    # Clone VM 2 with new name`,
+  quickvm clone 2 "TestVM"                    # Clone VM 2 with new name
+  quickvm clone 1 Web02 --new-mac --switch "Lab" --cpu 2 --memory 4096
+  quickvm clone 1 Web02 --new-mac --hostname WEB02 --guest-user Administrator --guest-password-env GUEST_PASSWORD
+  quickvm clone 3 node2 --new-mac --guest-os linux --guest-user ubuntu --ssh-key ~/.ssh/id_ed25519 --hostname node2 --cloud-init-reset`,
 	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		manager := hyperv.NewManager()
@@ -52,6 +80,15 @@ Examples:
 			return
 		}
 
+		custom := cloneCustomizationFromFlags()
+		if err := custom.Validate(); err != nil {
+			output.PrintError("INVALID_OPTIONS", "Invalid clone options", err.Error())
+			if !output.IsJSON() {
+				fmt.Printf("❌ Invalid clone options: %v\n", err)
+			}
+			return
+		}
+
 		// Get source VM name for display
 		sourceName, err := manager.GetVMNameByIndex(cmd.Context(), index)
 		if err != nil {
@@ -83,11 +120,12 @@ Examples:
 			fmt.Printf("🔄 Cloning VM '%s' to '%s'...\n", sourceName, newName)
 			fmt.Println("⏳ This may take several minutes depending on VM disk size...")
 			fmt.Println()
-			fmt.Println("Steps:")
-			fmt.Println("  1. Exporting source VM...")
 		}
 
-		if err := manager.CloneVM(cmd.Context(), index, newName); err != nil {
+		err = runWithProgress(func(events chan<- hyperv.ProgressEvent) error {
+			return manager.CloneVMWithProgress(cmd.Context(), sourceName, newName, custom, events)
+		})
+		if err != nil {
 			output.PrintError("CLONE_FAILED", "Failed to clone VM", err.Error())
 			if !output.IsJSON() {
 				fmt.Printf("\n❌ Failed to clone VM: %v\n", err)
@@ -107,19 +145,57 @@ Examples:
 			return
 		}
 
-		fmt.Println("  2. Importing with new identity...")
-		fmt.Println("  3. Renaming to target name...")
-		fmt.Println("  4. Cleaning up temporary files...")
-		fmt.Println()
 		fmt.Printf("✅ VM '%s' cloned successfully to '%s'!\n", sourceName, newName)
 		fmt.Println()
 		fmt.Println("💡 Tips:")
 		fmt.Printf("   - Start the cloned VM with: quickvm start <index>\n")
 		fmt.Printf("   - The cloned VM has a new unique ID\n")
 		fmt.Printf("   - The cloned VM is completely independent from the source\n")
+		if !cloneNewMAC {
+			fmt.Printf("   - Use --new-mac to avoid MAC address clashes with the source\n")
+		}
 	},
 }
 
+// cloneCustomizationFromFlags builds the clone customization from the command line flags
+func cloneCustomizationFromFlags() *hyperv.CloneCustomization {
+	custom := &hyperv.CloneCustomization{
+		RegenerateMAC:    cloneNewMAC,
+		SwitchName:       strings.TrimSpace(cloneSwitch),
+		ProcessorCount:   cloneCPU,
+		MemoryStartupMB:  cloneMemoryMB,
+		StripCheckpoints: cloneStripCheckpoints,
+		Hostname:         strings.TrimSpace(cloneHostname),
+		GuestOS:          strings.ToLower(cloneGuestOS),
+		GuestUser:        cloneGuestUser,
+		GuestPasswordEnv: cloneGuestPasswordEnv,
+		SSHKey:           cloneSSHKey,
+		GuestTimeout:     cloneGuestTimeout,
+	}
+	switch {
+	case cloneSysprep:
+		custom.GuestReset = hyperv.GuestResetSysprep
+	case cloneCloudInitReset:
+		custom.GuestReset = hyperv.GuestResetCloudInit
+	}
+	return custom
+}
+
 func init() {
 	rootCmd.AddCommand(cloneCmd)
+
+	cloneCmd.Flags().BoolVar(&cloneNewMAC, "new-mac", false, "Give every network adapter a new dynamically assigned MAC address")
+	cloneCmd.Flags().StringVar(&cloneSwitch, "switch", "", "Connect the clone's network adapters to this switch")
+	cloneCmd.Flags().IntVar(&cloneCPU, "cpu", 0, "Processor count for the clone")
+	cloneCmd.Flags().Int64Var(&cloneMemoryMB, "memory", 0, "Startup memory for the clone in MB")
+	cloneCmd.Flags().BoolVar(&cloneStripCheckpoints, "strip-checkpoints", false, "Remove the checkpoints copied from the source")
+	cloneCmd.Flags().StringVar(&cloneHostname, "hostname", "", "Rename the guest computer (starts the clone once)")
+	cloneCmd.Flags().BoolVar(&cloneSysprep, "sysprep", false, "Generalize a Windows guest with sysprep (starts the clone once)")
+	cloneCmd.Flags().BoolVar(&cloneCloudInitReset, "cloud-init-reset", false, "Reset cloud-init and the machine-id of a Linux guest (starts the clone once)")
+	cloneCmd.Flags().StringVar(&cloneGuestOS, "guest-os", hyperv.GuestWindows, "Guest OS for guest customization: windows (PowerShell Direct) or linux (SSH)")
+	cloneCmd.Flags().StringVar(&cloneGuestUser, "guest-user", "", "Guest account for guest customization")
+	cloneCmd.Flags().StringVar(&cloneGuestPasswordEnv, "guest-password-env", "", "Environment variable holding the Windows guest password")
+	cloneCmd.Flags().StringVar(&cloneSSHKey, "ssh-key", "", "Private key file for SSH to a Linux guest")
+	cloneCmd.Flags().DurationVar(&cloneGuestTimeout, "guest-timeout", 0, "How long to wait for the guest to boot and shut down (default 10m)")
+	cloneCmd.MarkFlagsMutuallyExclusive("sysprep", "cloud-init-reset")
 }
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"strings"
+
+	"quickvm/internal/theme"
+)
+
+// Printers for human-readable messages in the colours of the active theme; like
+// color.Red and friends they end the line
+
+func printSuccess(format string, a ...any) { printThemed(theme.Active().Success, format, a...) }
+
+func printWarning(format string, a ...any) { printThemed(theme.Active().Warning, format, a...) }
+
+func printFailure(format string, a ...any) { printThemed(theme.Active().Error, format, a...) }
+
+func printInfo(format string, a ...any) { printThemed(theme.Active().Info, format, a...) }
+
+// printPlain prints in the terminal's default colour, which stays readable on light and dark backgrounds
+func printPlain(format string, a ...any) { printThemed("", format, a...) }
+
+func printThemed(c, format string, a ...any) {
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	_, _ = theme.Color(c).Printf(format, a...)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	consoleHost     string
+	consoleEnhanced bool
+	consoleBasic    bool
+)
+
+var consoleCmd = &cobra.Command{
+	Use:   "console <vm>",
+	Short: "Open the Hyper-V console (vmconnect) of a VM",
+	Long: `Open the Virtual Machine Connection window (vmconnect.exe) of a VM, by index or name.
+
+Unlike RDP, the console works without networking in the guest, e.g. during
+setup. --enhanced or --basic turns enhanced session mode on or off for the
+Hyper-V host first (requires Administrator privileges); it applies to every VM
+on the host.
+
+The window is tracked as a session: 'quickvm sessions' lists and closes it.
+
+Examples:
+  quickvm console 1                  # Console of VM 1
+  quickvm console web01 --enhanced   # Enhanced session (clipboard, drives, resolution)
+  quickvm console web01 --host hv02  # VM on another Hyper-V host`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager := hyperv.NewManager()
+
+		opts := hyperv.ConsoleOptions{Host: consoleHost}
+		switch {
+		case consoleEnhanced && consoleBasic:
+			printConsoleError("INVALID_ARGS", "Invalid arguments", fmt.Errorf("--enhanced and --basic cannot be combined"))
+			return
+		case consoleEnhanced, consoleBasic:
+			opts.Enhanced = &consoleEnhanced
+		}
+
+		vmName := args[0]
+		if consoleHost == "" {
+			name, err := resolveVMName(cmd.Context(), manager, args[0])
+			if err != nil {
+				printConsoleError("VM_GET_FAILED", "Failed to get VM", err)
+				return
+			}
+			vmName = name
+		}
+
+		if !output.IsJSON() {
+			fmt.Printf("🖥️  Opening console of VM '%s'...\n", vmName)
+		}
+		session, err := manager.OpenConsole(cmd.Context(), vmName, opts)
+		if err != nil {
+			printConsoleError("CONSOLE_FAILED", "Failed to open console", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(SessionResult{Session: session, Success: true})
+			return
+		}
+		fmt.Printf("✅ Console opened (session %d)\n", session.ID)
+	},
+}
+
+func printConsoleError(code, message string, err error) {
+	output.PrintError(code, message, err.Error())
+	if !output.IsJSON() {
+		fmt.Printf("❌ %s: %v\n", message, err)
+	}
+}
+
+func init() {
+	consoleCmd.Flags().StringVar(&consoleHost, "host", "", "Hyper-V host running the VM (default localhost; the VM must be given by name)")
+	consoleCmd.Flags().BoolVar(&consoleEnhanced, "enhanced", false, "Turn on enhanced session mode on the host first")
+	consoleCmd.Flags().BoolVar(&consoleBasic, "basic", false, "Turn off enhanced session mode on the host first")
+	rootCmd.AddCommand(consoleCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+	"quickvm/internal/provision"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	createFromTemplate string
+	createLinked       bool
+	createVHDPath      string
+	createProvision    string
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create --from-template <template> <vm-name>",
+	Short: "Create a VM from a template",
+	Long: `Create a new VM from a template in the library (see 'quickvm template').
+
+By default the template's disks are copied into <host VHD path>\<vm-name>, so
+the new VM is independent of the template. With --linked the new VM gets
+differencing disks whose parents are the template's disks: creation takes
+seconds and uses little space, but the template must be kept (and left
+unchanged) for as long as the VM exists.
+
+Every VM gets a new ID. Press Ctrl+C to cancel; a partially created VM is
+removed again.
+
+With --provision the new VM is configured on its first boot from a
+provisioning file (see 'quickvm provision'): it is started, and the
+provisioning ISO is detached once the guest is ready.
+
+Examples:
+  quickvm create --from-template win2022-dev "Dev-Alice"
+  quickvm create --from-template win2022-dev "Dev-Bob" --linked
+  quickvm create --from-template win2022-dev "Dev-Carol" --vhd-path "D:\VMs\Dev-Carol"
+  quickvm create --from-template ubuntu-24 "web01" --linked --provision web01.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Catch mistakes in the provisioning file before anything is created
+		if createProvision != "" {
+			cfg, err := provision.Load(createProvision)
+			if err == nil {
+				_, err = cfg.Seed() // Also checks that password variables are set
+			}
+			if err != nil {
+				printTemplateError("PROVISION_INVALID", "Invalid provisioning file", err)
+				return
+			}
+		}
+
+		manager := hyperv.NewManager()
+		opts := hyperv.TemplateCreateVMOptions{
+			Template: createFromTemplate,
+			VMName:   strings.TrimSpace(args[0]),
+			Linked:   createLinked,
+			VHDPath:  createVHDPath,
+		}
+
+		if !output.IsJSON() {
+			kind := "full copy"
+			if opts.Linked {
+				kind = "linked clone"
+			}
+			fmt.Printf("🆕 Creating VM '%s' from template '%s' (%s)...\n", opts.VMName, opts.Template, kind)
+		}
+
+		var name string
+		err := runWithProgress(func(events chan<- hyperv.ProgressEvent) error {
+			var createErr error
+			name, createErr = manager.CreateVMFromTemplate(cmd.Context(), opts, events)
+			return createErr
+		})
+		if err != nil {
+			printTemplateError("CREATE_FAILED", "Failed to create VM", err)
+			return
+		}
+
+		result := CreateVMResult{Template: opts.Template, VMName: name, Linked: opts.Linked, Success: true}
+		if !output.IsJSON() {
+			fmt.Printf("✅ VM '%s' created from template '%s'\n", name, opts.Template)
+		}
+		if createProvision != "" {
+			provisioned, err := provisionVM(cmd.Context(), manager, name, createProvision)
+			if err != nil {
+				printProvisionError(name, err)
+				return
+			}
+			result.Provisioned = &provisioned
+		}
+		printCreateResult(result)
+	},
+}
+
+func printCreateResult(result CreateVMResult) {
+	if output.IsJSON() {
+		output.PrintData(result)
+		return
+	}
+	if result.Provisioned != nil {
+		printProvisionResult(*result.Provisioned)
+	}
+
+	fmt.Println("\n💡 Tips:")
+	if result.Provisioned == nil {
+		fmt.Println("   - Start it with: quickvm start <index>")
+	}
+	if result.Linked {
+		fmt.Println("   - Keep the template: its disks are the parents of this VM's disks")
+	}
+}
+
+func init() {
+	createCmd.Flags().StringVar(&createFromTemplate, "from-template", "", "Template to create the VM from")
+	_ = createCmd.MarkFlagRequired("from-template")
+	createCmd.Flags().BoolVar(&createLinked, "linked", false, "Create a linked clone with differencing disks instead of copying the disks")
+	createCmd.Flags().StringVar(&createVHDPath, "vhd-path", "", "Directory for the new VM's disks")
+	createCmd.Flags().StringVar(&createProvision, "provision", "", "Provisioning file (YAML) applied on the VM's first boot")
+	addProvisionWaitFlags(createCmd)
+	rootCmd.AddCommand(createCmd)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventsFollow     bool
+	eventsInterval   time.Duration
+	eventsHeartbeats bool
+	eventsSnapshots  bool
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream VM state changes as they happen",
+	Long: `Print VM events, one JSON object per line (NDJSON).
+
+Without --follow the VMs present now are printed as VMAdded events. With --follow
+Hyper-V is polled every --interval and changes are printed until Ctrl+C:
+
+  VMAdded, VMRemoved      A VM was created or deleted
+  StateChanged            The VM state changed, e.g. Off → Running
+  IPAssigned              The VM reported a new IPv4 address
+  HeartbeatLost           A running VM stopped answering its heartbeat
+  SnapshotCreated         A snapshot of the VM was created
+  PollFailed              Polling Hyper-V failed; the watch goes on
+
+Use -o table or -o text for one readable line per event instead.
+
+Examples:
+  quickvm events --follow
+  quickvm events --follow --interval 2s --snapshots=false
+  quickvm events --follow -o text`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		// NDJSON is the format of this command unless another one was asked for
+		readable := cmd.Flag("output").Changed && !output.IsJSON()
+		opts := hyperv.WatchOptions{
+			Interval:   eventsInterval,
+			Heartbeats: eventsHeartbeats,
+			Snapshots:  eventsSnapshots,
+			Initial:    true,
+		}
+		err := streamEvents(cmd.Context(), hyperv.NewManager(), opts, eventsFollow, func(event hyperv.Event) {
+			if readable {
+				fmt.Printf("%s  %s\n", event.Time.Format("15:04:05"), event.Describe())
+				return
+			}
+			output.PrintEvent(event)
+		})
+		if err != nil {
+			printConsoleError("EVENTS_FAILED", "Failed to get VM events", err)
+		}
+	},
+}
+
+// streamEvents passes the current VMs as VMAdded events to emit and, when following,
+// every later change until ctx is cancelled
+func streamEvents(ctx context.Context, manager *hyperv.Manager, opts hyperv.WatchOptions, follow bool, emit func(hyperv.Event)) error {
+	if !follow {
+		obs, err := manager.Observe(ctx, false, false)
+		if err != nil {
+			return err
+		}
+		for _, event := range hyperv.DiffObservations(hyperv.Observation{}, obs, time.Now()) {
+			emit(event)
+		}
+		return nil
+	}
+
+	events := make(chan hyperv.Event)
+	go manager.WatchVMs(ctx, opts, events)
+	for event := range events {
+		emit(event)
+	}
+	return nil
+}
+
+func init() {
+	eventsCmd.Flags().BoolVarP(&eventsFollow, "follow", "f", false, "Keep streaming events until Ctrl+C")
+	eventsCmd.Flags().DurationVar(&eventsInterval, "interval", hyperv.DefaultWatchInterval, "Polling interval for --follow")
+	eventsCmd.Flags().BoolVar(&eventsHeartbeats, "heartbeats", true, "Watch heartbeats for HeartbeatLost events")
+	eventsCmd.Flags().BoolVar(&eventsSnapshots, "snapshots", true, "Watch snapshots for SnapshotCreated events")
+	rootCmd.AddCommand(eventsCmd)
+}
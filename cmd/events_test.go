@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+// bootingExecutor reports Web01 as Off on the first poll and Running afterwards
+type bootingExecutor struct {
+	mu    sync.Mutex
+	polls int
+}
+
+func (e *bootingExecutor) RunScript(_ context.Context, script string) ([]byte, error) {
+	if !strings.Contains(script, "Get-VM | Select-Object @{Name='Name'") {
+		return nil, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.polls++
+	if e.polls == 1 {
+		return []byte(`{"Name":"Web01","State":"Off"}`), nil
+	}
+	return []byte(`{"Name":"Web01","State":"Running","IPAddresses":"10.0.0.7"}`), nil
+}
+
+func (e *bootingExecutor) RunCmdlet(_ context.Context, _ string, _ ...string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestStreamEvents_Once(t *testing.T) {
+	var events []hyperv.Event
+	err := streamEvents(context.Background(), &hyperv.Manager{Exec: &apiExecutor{}}, hyperv.WatchOptions{}, false,
+		func(e hyperv.Event) { events = append(events, e) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != hyperv.EventVMAdded || events[1].VMName != "Db01" || events[1].State != "Running" {
+		t.Errorf("Expected a VMAdded event per VM, got %+v", events)
+	}
+}
+
+func TestStreamEvents_Follow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := hyperv.WatchOptions{Interval: time.Millisecond, Heartbeats: true, Snapshots: true, Initial: true}
+
+	var types []string
+	done := make(chan error, 1)
+	go func() {
+		done <- streamEvents(ctx, &hyperv.Manager{Exec: &bootingExecutor{}}, opts, true, func(e hyperv.Event) {
+			types = append(types, e.Type)
+			if e.Type == hyperv.EventIPAssigned {
+				cancel()
+			}
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamEvents did not stop after cancellation")
+	}
+
+	want := []string{hyperv.EventVMAdded, hyperv.EventStateChanged, hyperv.EventIPAssigned}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("Events = %v, want %v", types, want)
+	}
+}
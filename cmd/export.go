@@ -1,17 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
+	"quickvm/internal/archive"
 	"quickvm/internal/hyperv"
 	"quickvm/internal/output"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	exportArchive string
+	exportSplit   string
+	exportStaging string
+)
+
 var exportCmd = &cobra.Command{
 	Use:   "export <vm-index> <path>",
 	Short: "Export a VM to a directory",
@@ -24,8 +33,18 @@ Examples:
   quickvm export 1 "D:\Backups\VMs"            # Export VM 1 to D:\Backups\VMs
   quickvm export 2 "C:\Export\MyVM"            # Export VM 2 to C:\Export\MyVM
   quickvm export 1 .                            # Export VM 1 to current directory
+  quickvm export 1 "E:\" --archive zip --split fat32   # Zip split for a FAT32 USB drive
+  quickvm export 1 "D:\Backups" --archive tar.zst      # Single zstd-compressed archive
+
+The exported VM will be placed in a subdirectory named after the VM, together
+with a manifest (quickvm-manifest.json) listing every file with its SHA-256.
+Check an export later with 'quickvm export verify <path>'.
+
+With --archive the export is staged in the temp directory (or --staging-dir)
+and only the archive or its chunks are written to <path>.
 
-The exported VM will be placed in a subdirectory named after the VM.`,
+Progress is shown as a progress bar, or as one JSON event per line with
+--output json. Press Ctrl+C to cancel; the partial export is removed.`,
 	Args: cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
 		manager := hyperv.NewManager()
@@ -40,6 +59,24 @@ The exported VM will be placed in a subdirectory named after the VM.`,
 			return
 		}
 
+		var archiveOpts *hyperv.ExportArchiveOptions
+		if exportArchive != "" {
+			archiveOpts, err = parseArchiveFlags(exportArchive, exportSplit)
+			if err != nil {
+				output.PrintError("INVALID_ARCHIVE_OPTIONS", "Invalid archive options", err.Error())
+				if !output.IsJSON() {
+					fmt.Printf("❌ Invalid archive options: %v\n", err)
+				}
+				return
+			}
+		} else if exportSplit != "" {
+			output.PrintError("INVALID_ARCHIVE_OPTIONS", "Invalid archive options", "--split requires --archive")
+			if !output.IsJSON() {
+				fmt.Println("❌ --split requires --archive")
+			}
+			return
+		}
+
 		// Get export path
 		exportPath := args[1]
 
@@ -86,7 +123,15 @@ The exported VM will be placed in a subdirectory named after the VM.`,
 			fmt.Println("⏳ This may take a while depending on VM size...")
 		}
 
-		if err := manager.ExportVM(cmd.Context(), index, exportPath); err != nil {
+		if archiveOpts != nil {
+			runExportArchive(cmd.Context(), manager, index, vmName, exportPath, archiveOpts)
+			return
+		}
+
+		err = runWithProgress(func(events chan<- hyperv.ProgressEvent) error {
+			return manager.ExportVMWithProgress(cmd.Context(), vmName, exportPath, events)
+		})
+		if err != nil {
 			output.PrintError("EXPORT_FAILED", "Failed to export VM", err.Error())
 			if !output.IsJSON() {
 				fmt.Printf("❌ Failed to export VM: %v\n", err)
@@ -114,9 +159,160 @@ The exported VM will be placed in a subdirectory named after the VM.`,
 		fmt.Println("\n💡 Tips:")
 		fmt.Printf("   - Import this VM with: quickvm import \"%s\"\n", exportedPath)
 		fmt.Println("   - The export contains: VM config, checkpoints, and virtual hard disks")
+		fmt.Printf("   - Check its integrity with: quickvm export verify \"%s\"\n", exportedPath)
+	},
+}
+
+var exportVerifyCmd = &cobra.Command{
+	Use:   "verify <path>",
+	Short: "Verify an export or workspace bundle against its manifest",
+	Long: `Recompute the SHA-256 of every file listed in an export manifest
+(quickvm-manifest.json) or workspace bundle manifest (quickvm-bundle.json)
+and report missing or modified files. Exits with status 1 on mismatch.
+
+Examples:
+  quickvm export verify "D:\Backups\VMs\MyVM"
+  quickvm export verify "D:\Bundles\lab"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if !runExportVerify(args[0]) {
+			os.Exit(1)
+		}
 	},
 }
 
+// parseArchiveFlags validates the --archive and --split flags
+func parseArchiveFlags(format, split string) (*hyperv.ExportArchiveOptions, error) {
+	archiveFormat, err := archive.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	opts := &hyperv.ExportArchiveOptions{Format: archiveFormat, StagingDir: exportStaging}
+	if split != "" {
+		if opts.ChunkSize, err = archive.ParseSize(split); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// runExportArchive exports a VM into a (possibly split) archive and reports the result
+func runExportArchive(ctx context.Context, manager *hyperv.Manager, index int, vmName, exportPath string, opts *hyperv.ExportArchiveOptions) {
+	opts.OnProgress = newArchiveProgress("export", vmName, hyperv.ProgressArchiving, "Archiving")
+
+	files, err := manager.ExportVMArchive(ctx, vmName, exportPath, *opts)
+	if err != nil {
+		output.PrintError("EXPORT_FAILED", "Failed to export VM", err.Error())
+		if !output.IsJSON() {
+			fmt.Printf("\n❌ Failed to export VM: %v\n", err)
+		}
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(ExportResult{
+			VMName:     vmName,
+			VMIndex:    index,
+			ExportPath: files[0],
+			Files:      files,
+			Success:    true,
+			Message:    "VM exported to archive successfully",
+		})
+		return
+	}
+
+	var total int64
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			total += info.Size()
+		}
+	}
+
+	fmt.Printf("\n\n✅ VM '%s' exported to archive (%s)!\n", vmName, archive.FormatSize(total))
+	for _, file := range files {
+		fmt.Printf("📁 %s\n", file)
+	}
+	fmt.Println("\n💡 Tips:")
+	fmt.Printf("   - Import this VM with: quickvm import \"%s\"\n", files[0])
+	if len(files) > 1 {
+		fmt.Println("   - Keep all chunks together in the same directory")
+	}
+}
+
+// newArchiveProgress returns the progress callback of an archive step: a status line, or
+// progress events as NDJSON in JSON mode like the other export and import phases
+func newArchiveProgress(operation, vmName string, phase hyperv.ProgressPhase, action string) func(archive.Progress) {
+	if !output.IsJSON() {
+		return newArchiveProgressPrinter(action)
+	}
+	lastPercent := -1
+	return func(p archive.Progress) {
+		if p.Total <= 0 {
+			return
+		}
+		percent := int(p.Done * 100 / p.Total)
+		if percent == lastPercent {
+			return
+		}
+		lastPercent = percent
+		output.PrintEvent(hyperv.ProgressEvent{Operation: operation, VMName: vmName, Phase: phase,
+			BytesDone: p.Done, BytesTotal: p.Total, Percent: float64(p.Done) * 100 / float64(p.Total),
+			Message: p.File, Time: time.Now()})
+	}
+}
+
+// newArchiveProgressPrinter returns a progress callback that redraws a single status line
+func newArchiveProgressPrinter(action string) func(archive.Progress) {
+	lastPercent := -1
+	return func(p archive.Progress) {
+		if p.Total <= 0 {
+			return
+		}
+		percent := int(p.Done * 100 / p.Total)
+		if percent == lastPercent {
+			return
+		}
+		lastPercent = percent
+		fmt.Printf("\r⏳ %s: %3d%% (%s of %s)   ", action, percent, archive.FormatSize(p.Done), archive.FormatSize(p.Total))
+	}
+}
+
+// runExportVerify verifies an export or bundle and reports whether it is intact
+func runExportVerify(path string) bool {
+	if !output.IsJSON() {
+		fmt.Printf("🔍 Verifying '%s'...\n", path)
+	}
+
+	verification, err := hyperv.VerifyExport(path)
+	if err != nil {
+		output.PrintError("VERIFY_FAILED", "Failed to verify export", err.Error())
+		if !output.IsJSON() {
+			fmt.Printf("❌ Failed to verify export: %v\n", err)
+		}
+		return false
+	}
+
+	if output.IsJSON() {
+		output.PrintData(verification)
+		return verification.Valid
+	}
+
+	if verification.Valid {
+		fmt.Printf("✅ %s '%s' is intact (%d files verified).\n", verification.Kind, verification.Name, verification.Checked)
+		return true
+	}
+
+	fmt.Printf("❌ %s '%s' failed verification:\n", verification.Kind, verification.Name)
+	for _, problem := range verification.Problems() {
+		fmt.Printf("  - %s\n", problem)
+	}
+	return false
+}
+
 func init() {
+	exportCmd.Flags().StringVarP(&exportArchive, "archive", "a", "", "Pack the export into an archive: zip or tar.zst")
+	exportCmd.Flags().StringVar(&exportSplit, "split", "", "Split the archive into chunks of this size (e.g. 4000M, 2G, fat32)")
+	exportCmd.Flags().StringVar(&exportStaging, "staging-dir", "", "Where to stage the export before archiving (default: the temp directory)")
+	exportCmd.AddCommand(exportVerifyCmd)
 	rootCmd.AddCommand(exportCmd)
 }
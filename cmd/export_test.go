@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quickvm/internal/archive"
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+)
+
+func TestParseArchiveFlags(t *testing.T) {
+	opts, err := parseArchiveFlags("tar.zst", "fat32")
+	if err != nil {
+		t.Fatalf("Expected valid flags, got %v", err)
+	}
+	if opts.Format != archive.FormatTarZst || opts.ChunkSize != archive.FAT32ChunkSize {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	if _, err := parseArchiveFlags("rar", ""); err == nil {
+		t.Error("Expected unsupported format to be rejected")
+	}
+	if _, err := parseArchiveFlags("zip", "huge"); err == nil {
+		t.Error("Expected invalid split size to be rejected")
+	}
+}
+
+func TestExportCommandSetup(t *testing.T) {
+	for _, flag := range []string{"archive", "split", "staging-dir"} {
+		if exportCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag '%s' on 'export'", flag)
+		}
+	}
+	if importCmd.Flags().Lookup("staging-dir") == nil {
+		t.Error("Expected flag 'staging-dir' on 'import'")
+	}
+}
+
+func TestImportFixFlag(t *testing.T) {
+	flag := importCmd.Flags().Lookup("fix")
+	t.Cleanup(func() {
+		importFix = nil
+		flag.Changed = false
+	})
+
+	// --fix takes an optional value, so kinds must be attached with '='
+	args := []string{`D:\Exports\VM`, "--fix=media,resources"}
+	if err := importCmd.ParseFlags(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := importCmd.ValidateArgs(importCmd.Flags().Args()); err != nil {
+		t.Errorf("Expected one argument, got %v", err)
+	}
+	if strings.Join(importFix, ",") != "media,resources" {
+		t.Errorf("Expected --fix=media,resources to select two fixes, got %v", importFix)
+	}
+
+	importFix = nil
+	if err := importCmd.ParseFlags([]string{`D:\Exports\VM`, "--fix"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(importFix, ",") != "all" {
+		t.Errorf("Expected --fix alone to select all fixes, got %v", importFix)
+	}
+}
+
+func TestParseImportFixes(t *testing.T) {
+	if fixes, err := parseImportFixes(nil, ""); err != nil || fixes != nil {
+		t.Errorf("Expected no fixes without flags, got %+v, %v", fixes, err)
+	}
+
+	fixes, err := parseImportFixes([]string{"all"}, "")
+	if err != nil || !fixes.Switches || !fixes.Media || !fixes.Resources {
+		t.Errorf("Expected 'all' to enable every fix, got %+v, %v", fixes, err)
+	}
+
+	fixes, err = parseImportFixes(nil, "Default Switch")
+	if err != nil || !fixes.Switches || fixes.SwitchName != "Default Switch" || fixes.Media {
+		t.Errorf("Expected --switch alone to enable the switch fix, got %+v, %v", fixes, err)
+	}
+
+	fixes, err = parseImportFixes([]string{"media", "Resources"}, "")
+	if err != nil || fixes.Switches || !fixes.Media || !fixes.Resources {
+		t.Errorf("Unexpected fixes %+v, %v", fixes, err)
+	}
+	if got := describeImportFixes(fixes); got != "media,resources" {
+		t.Errorf("describeImportFixes = %q", got)
+	}
+
+	if _, err := parseImportFixes([]string{"network"}, ""); err == nil {
+		t.Error("Expected error for unknown fix")
+	}
+}
+
+func TestVerifyBeforeImport_JSON(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"formatVersion":1,"vmName":"Web01","files":[{"path":"web01.vhdx","size":1,"sha256":"00"}]}`
+	if err := os.WriteFile(filepath.Join(dir, hyperv.ExportManifestFile), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var ok bool
+	printed := captureJSONOutput(t, func() { ok = verifyBeforeImport(dir) })
+	if ok {
+		t.Error("Expected the import to be refused")
+	}
+	// Only the error envelope is printed
+	var resp struct {
+		Success bool `json:"success"`
+		Error   struct {
+			Code    string `json:"code"`
+			Details string `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(printed, &resp); err != nil || resp.Success || resp.Error.Code != "VERIFY_FAILED" ||
+		!strings.Contains(resp.Error.Details, "web01.vhdx") {
+		t.Errorf("Expected a VERIFY_FAILED envelope, got %q (%v)", printed, err)
+	}
+}
+
+func TestNewArchiveProgress_JSON(t *testing.T) {
+	printed := captureJSONOutput(t, func() {
+		progress := newArchiveProgress("export", "Web01", hyperv.ProgressArchiving, "Archiving")
+		for _, done := range []int64{0, 1, 100, 200} {
+			progress(archive.Progress{File: "web01.vhdx", Done: done, Total: 200})
+		}
+	})
+
+	lines := strings.Split(strings.TrimSpace(string(printed)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected one event per percent, got:\n%s", printed)
+	}
+	var event hyperv.ProgressEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Operation != "export" || event.Phase != hyperv.ProgressArchiving || event.Percent != 50 || event.Message != "web01.vhdx" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+// captureJSONOutput runs fn with JSON output and returns what it printed
+func captureJSONOutput(t *testing.T, fn func()) []byte {
+	t.Helper()
+	output.CurrentFormat = output.FormatJSON
+	defer func() { output.CurrentFormat = output.FormatTable }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	_ = w.Close()
+	printed, _ := io.ReadAll(r)
+	return printed
+}
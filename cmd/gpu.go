@@ -7,7 +7,6 @@ import (
 
 	"quickvm/internal/hyperv"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -32,34 +31,34 @@ var gpuStatusCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		manager := hyperv.NewManager()
 
-		color.Cyan("🔍 Checking GPU partitioning support...")
+		printInfo("🔍 Checking GPU partitioning support...")
 		fmt.Println()
 
 		gpus, err := manager.CheckGPUPartitionable(cmd.Context())
 		if err != nil {
-			color.Red("❌ Error checking GPU support: %v", err)
+			printFailure("❌ Error checking GPU support: %v", err)
 			os.Exit(1)
 		}
 
 		if len(gpus) == 0 {
-			color.Yellow("⚠️  No GPUs with partitioning support found.")
+			printWarning("⚠️  No GPUs with partitioning support found.")
 			fmt.Println()
-			color.White("   Possible reasons:")
-			color.White("   • GPU does not support GPU-P")
-			color.White("   • GPU drivers are outdated")
-			color.White("   • Hyper-V is not enabled")
+			printPlain("   Possible reasons:")
+			printPlain("   • GPU does not support GPU-P")
+			printPlain("   • GPU drivers are outdated")
+			printPlain("   • Hyper-V is not enabled")
 			return
 		}
 
-		color.Green("✅ Found %d GPU(s) with partitioning support:", len(gpus))
+		printSuccess("✅ Found %d GPU(s) with partitioning support:", len(gpus))
 		fmt.Println()
 
 		for i, gpu := range gpus {
-			color.Cyan("  GPU #%d:", i+1)
-			color.White("    Name: %s", gpu.Name)
-			color.White("    Partition Count: %d", gpu.PartitionCount)
+			printInfo("  GPU #%d:", i+1)
+			printPlain("    Name: %s", gpu.Name)
+			printPlain("    Partition Count: %d", gpu.PartitionCount)
 			if gpu.MaxPartitionVRAM > 0 {
-				color.White("    Max VRAM: %d", gpu.MaxPartitionVRAM)
+				printPlain("    Max VRAM: %d", gpu.MaxPartitionVRAM)
 			}
 			fmt.Println()
 		}
@@ -79,7 +78,7 @@ Example: quickvm gpu add 1`,
 	Run: func(cmd *cobra.Command, args []string) {
 		index, err := strconv.Atoi(args[0])
 		if err != nil {
-			color.Red("❌ Invalid VM index: %s", args[0])
+			printFailure("❌ Invalid VM index: %s", args[0])
 			return
 		}
 
@@ -87,81 +86,81 @@ Example: quickvm gpu add 1`,
 
 		// Check admin privileges
 		if !hyperv.IsRunningAsAdmin(cmd.Context()) {
-			color.Red("❌ This command requires Administrator privileges.")
+			printFailure("❌ This command requires Administrator privileges.")
 			fmt.Println()
-			color.Yellow("💡 Please run this command in an elevated PowerShell or Command Prompt.")
+			printWarning("💡 Please run this command in an elevated PowerShell or Command Prompt.")
 			os.Exit(1)
 		}
 
 		// Check GPU support first
-		color.Cyan("🔍 Checking GPU partitioning support...")
+		printInfo("🔍 Checking GPU partitioning support...")
 		gpus, err := manager.CheckGPUPartitionable(cmd.Context())
 		if err != nil {
-			color.Red("❌ Error checking GPU support: %v", err)
+			printFailure("❌ Error checking GPU support: %v", err)
 			os.Exit(1)
 		}
 
 		if len(gpus) == 0 {
-			color.Red("❌ No GPUs with partitioning support found.")
-			color.Yellow("💡 Your GPU may not support GPU-P or drivers need updating.")
+			printFailure("❌ No GPUs with partitioning support found.")
+			printWarning("💡 Your GPU may not support GPU-P or drivers need updating.")
 			os.Exit(1)
 		}
 
 		// Get VMs to validate index
 		vms, err := manager.GetVMs(cmd.Context())
 		if err != nil {
-			color.Red("❌ Failed to get VMs: %v", err)
+			printFailure("❌ Failed to get VMs: %v", err)
 			os.Exit(1)
 		}
 
 		if index < 1 || index > len(vms) {
-			color.Red("❌ Invalid VM index: %d (valid range: 1-%d)", index, len(vms))
+			printFailure("❌ Invalid VM index: %d (valid range: 1-%d)", index, len(vms))
 			return
 		}
 
 		vm := vms[index-1]
-		color.Cyan("🔧 Adding GPU partition to VM: %s", vm.Name)
+		printInfo("🔧 Adding GPU partition to VM: %s", vm.Name)
 		fmt.Println()
 
 		// Check if VM is running
 		if vm.State == "Running" {
-			color.Red("❌ VM '%s' is currently running.", vm.Name)
-			color.Yellow("💡 Please stop the VM first: quickvm stop %d", index)
+			printFailure("❌ VM '%s' is currently running.", vm.Name)
+			printWarning("💡 Please stop the VM first: quickvm stop %d", index)
 			os.Exit(1)
 		}
 
 		// Add GPU partition with default config
 		config := hyperv.DefaultGPUPartitionConfig()
 		if err := manager.AddGPUPartition(cmd.Context(), vm.Name, config); err != nil {
-			color.Red("❌ Failed to add GPU partition: %v", err)
+			printFailure("❌ Failed to add GPU partition: %v", err)
 			os.Exit(1)
 		}
 
-		color.Green("✅ GPU partition added successfully to '%s'!", vm.Name)
+		printSuccess("✅ GPU partition added successfully to '%s'!", vm.Name)
 		fmt.Println()
 
 		// Show driver copy instructions
-		color.Yellow("⚠️  Important: You need to copy GPU drivers to the guest VM.")
+		printWarning("⚠️  Important: You need to copy GPU drivers to the guest VM.")
 		fmt.Println()
-		color.Cyan("📋 Driver Copy Instructions:")
+		printInfo("📋 Driver Copy Instructions:")
 		fmt.Println()
 
 		driverPaths, _ := manager.GetGPUDriverPaths(cmd.Context())
 		if len(driverPaths) > 0 {
-			color.White("   1. Copy driver folder from Host to Guest:")
+			printPlain("   1. Copy driver folder from Host to Guest:")
 			for _, path := range driverPaths {
-				color.White("      FROM: %s", path)
+				printPlain("      FROM: %s", path)
 			}
-			color.White("      TO:   C:\\Windows\\System32\\HostDriverStore\\FileRepository\\")
+			printPlain("      TO:   C:\\Windows\\System32\\HostDriverStore\\FileRepository\\")
 			fmt.Println()
 		}
 
-		color.White("   2. Copy system files from Host to Guest:")
-		color.White("      FROM: C:\\Windows\\System32\\nv*.*")
-		color.White("      TO:   C:\\Windows\\System32\\")
+		printPlain("   2. Copy system files from Host to Guest:")
+		printPlain("      FROM: C:\\Windows\\System32\\nv*.*")
+		printPlain("      TO:   C:\\Windows\\System32\\")
 		fmt.Println()
 
-		color.Cyan("ℹ️  For detailed instructions, see: docs/GPU_PASSTHROUGH.md")
+		printInfo("ℹ️  For detailed instructions, see: docs/GPU_PASSTHROUGH.md")
 	},
 }
 
@@ -177,7 +176,7 @@ Example: quickvm gpu remove 1`,
 	Run: func(cmd *cobra.Command, args []string) {
 		index, err := strconv.Atoi(args[0])
 		if err != nil {
-			color.Red("❌ Invalid VM index: %s", args[0])
+			printFailure("❌ Invalid VM index: %s", args[0])
 			return
 		}
 
@@ -185,42 +184,42 @@ Example: quickvm gpu remove 1`,
 
 		// Check admin privileges
 		if !hyperv.IsRunningAsAdmin(cmd.Context()) {
-			color.Red("❌ This command requires Administrator privileges.")
+			printFailure("❌ This command requires Administrator privileges.")
 			fmt.Println()
-			color.Yellow("💡 Please run this command in an elevated PowerShell or Command Prompt.")
+			printWarning("💡 Please run this command in an elevated PowerShell or Command Prompt.")
 			os.Exit(1)
 		}
 
 		// Get VMs to validate index
 		vms, err := manager.GetVMs(cmd.Context())
 		if err != nil {
-			color.Red("❌ Failed to get VMs: %v", err)
+			printFailure("❌ Failed to get VMs: %v", err)
 			os.Exit(1)
 		}
 
 		if index < 1 || index > len(vms) {
-			color.Red("❌ Invalid VM index: %d (valid range: 1-%d)", index, len(vms))
+			printFailure("❌ Invalid VM index: %d (valid range: 1-%d)", index, len(vms))
 			return
 		}
 
 		vm := vms[index-1]
-		color.Cyan("🔧 Removing GPU partition from VM: %s", vm.Name)
+		printInfo("🔧 Removing GPU partition from VM: %s", vm.Name)
 		fmt.Println()
 
 		// Check if VM is running
 		if vm.State == "Running" {
-			color.Red("❌ VM '%s' is currently running.", vm.Name)
-			color.Yellow("💡 Please stop the VM first: quickvm stop %d", index)
+			printFailure("❌ VM '%s' is currently running.", vm.Name)
+			printWarning("💡 Please stop the VM first: quickvm stop %d", index)
 			os.Exit(1)
 		}
 
 		// Remove GPU partition
 		if err := manager.RemoveGPUPartition(cmd.Context(), vm.Name); err != nil {
-			color.Red("❌ Failed to remove GPU partition: %v", err)
+			printFailure("❌ Failed to remove GPU partition: %v", err)
 			os.Exit(1)
 		}
 
-		color.Green("✅ GPU partition removed successfully from '%s'!", vm.Name)
+		printSuccess("✅ GPU partition removed successfully from '%s'!", vm.Name)
 	},
 }
 
@@ -231,37 +230,37 @@ var gpuDriversCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, _ []string) {
 		manager := hyperv.NewManager()
 
-		color.Cyan("🔍 Searching for GPU driver files...")
+		printInfo("🔍 Searching for GPU driver files...")
 		fmt.Println()
 
 		paths, err := manager.GetGPUDriverPaths(cmd.Context())
 		if err != nil {
-			color.Red("❌ Error getting driver paths: %v", err)
+			printFailure("❌ Error getting driver paths: %v", err)
 			os.Exit(1)
 		}
 
 		if len(paths) == 0 {
-			color.Yellow("⚠️  No GPU driver folders found.")
-			color.White("   Looking for NVIDIA (nv_dispi.inf_*) or AMD (u0*) drivers.")
+			printWarning("⚠️  No GPU driver folders found.")
+			printPlain("   Looking for NVIDIA (nv_dispi.inf_*) or AMD (u0*) drivers.")
 			return
 		}
 
-		color.Green("✅ Found GPU driver folder(s):")
+		printSuccess("✅ Found GPU driver folder(s):")
 		fmt.Println()
 
 		for _, path := range paths {
-			color.White("   📁 %s", path)
+			printPlain("   📁 %s", path)
 		}
 
 		fmt.Println()
-		color.Cyan("📋 Copy Instructions:")
+		printInfo("📋 Copy Instructions:")
 		fmt.Println()
-		color.White("   1. Copy the driver folder(s) above to the guest VM:")
-		color.White("      C:\\Windows\\System32\\HostDriverStore\\FileRepository\\")
+		printPlain("   1. Copy the driver folder(s) above to the guest VM:")
+		printPlain("      C:\\Windows\\System32\\HostDriverStore\\FileRepository\\")
 		fmt.Println()
-		color.White("   2. Copy all nv*.* files from Host to Guest:")
-		color.White("      FROM: C:\\Windows\\System32\\nv*.*")
-		color.White("      TO:   C:\\Windows\\System32\\")
+		printPlain("   2. Copy all nv*.* files from Host to Guest:")
+		printPlain("      FROM: C:\\Windows\\System32\\nv*.*")
+		printPlain("      TO:   C:\\Windows\\System32\\")
 	},
 }
 
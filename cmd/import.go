@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"quickvm/internal/archive"
 	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +18,11 @@ var (
 	importCopy          bool
 	importGenerateNewID bool
 	importVHDPath       string
+	importSkipVerify    bool
+	importStagingDir    string
+	importCheck         bool
+	importFix           []string
+	importSwitch        string
 )
 
 var importCmd = &cobra.Command{
@@ -29,11 +38,36 @@ Examples:
   quickvm import "D:\Backups\VMs\MyVM" --copy       # Copy VM files to default location
   quickvm import "D:\Backups\VMs\MyVM" --new-id     # Generate new VM ID
   quickvm import "D:\Exports\VM" --vhd-path "E:\VHDs"  # Specify VHD destination
+  quickvm import "E:\MyVM.zip"                      # Import from an archive
+  quickvm import "E:\MyVM.zip.001"                  # Import from a split archive
+  quickvm import "D:\Exports\VM" --check             # Show incompatibilities only
+  quickvm import "D:\Exports\VM" --fix --switch "Default Switch"  # Fix and import
+  quickvm import "D:\Exports\VM" --fix=media,resources             # Only some fixes
 
 Flags:
   --copy       Copy the VM files instead of registering in place
   --new-id     Generate a new unique ID for the imported VM
-  --vhd-path   Specify a custom path for virtual hard disk files`,
+  --vhd-path   Specify a custom path for virtual hard disk files
+
+Exports created by quickvm include a manifest with SHA-256 checksums. The
+checksums are verified before importing and the import is refused if any
+file is missing or modified (use --skip-verify to bypass).
+
+Archives (.zip, .tar.zst and their .001, .002, ... chunks) are extracted to a
+staging directory in the temp directory (or --staging-dir) and always imported
+with --copy; the staging directory is removed afterwards.
+
+Copy imports show a progress bar. Press Ctrl+C to cancel; a partially
+imported VM is removed again, together with its copied disks.
+
+Compatibility:
+  --check            Show what would prevent the import on this host (Compare-VM)
+                     with a suggested fix for each issue, without importing
+  --fix[=kinds]      Fix issues before the import is committed; kinds are
+                     switch, media, resources or all (default: all). Give kinds
+                     with '=', e.g. --fix=media,resources
+  --switch <name>    Connect adapters whose switch is missing to this switch
+                     (without it, --fix switch disconnects them)`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		manager := hyperv.NewManager()
@@ -45,37 +79,47 @@ Flags:
 		if !filepath.IsAbs(importPath) {
 			cwd, err := os.Getwd()
 			if err != nil {
-				fmt.Printf("❌ Failed to get current directory: %v\n", err)
+				output.PrintError("PATH_ERROR", "Failed to get current directory", err.Error())
+				if !output.IsJSON() {
+					fmt.Printf("❌ Failed to get current directory: %v\n", err)
+				}
 				return
 			}
 			importPath = filepath.Join(cwd, importPath)
 		}
 
-		// Verify path exists
+		// Verify path exists (a split archive may be given by its base name)
 		if _, err := os.Stat(importPath); os.IsNotExist(err) {
-			fmt.Printf("❌ Import path does not exist: %s\n", importPath)
-			return
-		}
-
-		fmt.Printf("📦 Importing VM from '%s'...\n", importPath)
-
-		// Show options being used
-		if importCopy {
-			fmt.Println("   📋 Mode: Copy (will copy VM files to default location)")
-		} else {
-			fmt.Println("   📋 Mode: Register in place")
+			if _, chunkErr := os.Stat(importPath + ".001"); chunkErr != nil {
+				output.PrintError("PATH_NOT_FOUND", "Import path does not exist", importPath)
+				if !output.IsJSON() {
+					fmt.Printf("❌ Import path does not exist: %s\n", importPath)
+				}
+				return
+			}
 		}
 
-		if importGenerateNewID {
-			fmt.Println("   🔄 Generating new VM ID")
+		cleanup := func() {}
+		if _, isArchive := archive.DetectFormat(importPath); isArchive {
+			exportDir, removeStaging, ok := extractImportArchive(cmd.Context(), importPath)
+			if !ok {
+				return
+			}
+			cleanup = removeStaging
+			defer cleanup()
+			importPath = exportDir
+			if !importCopy {
+				if !output.IsJSON() {
+					fmt.Println("   📋 Archive imports copy the VM files out of the staging directory")
+				}
+				importCopy = true
+			}
 		}
 
-		if importVHDPath != "" {
-			fmt.Printf("   💾 VHD destination: %s\n", importVHDPath)
+		if !importSkipVerify && !verifyBeforeImport(importPath) {
+			return
 		}
 
-		fmt.Println("⏳ This may take a while depending on VM size...")
-
 		// Build import options
 		opts := hyperv.ImportVMOptions{
 			Path:          importPath,
@@ -84,9 +128,48 @@ Flags:
 			VHDPath:       importVHDPath,
 		}
 
-		vmName, err := manager.ImportVM(cmd.Context(), opts)
+		if importCheck {
+			if !runImportCheck(cmd.Context(), manager, opts) {
+				// os.Exit skips the deferred calls, so the staging directory is removed first
+				cleanup()
+				os.Exit(1)
+			}
+			return
+		}
+
+		fixes, err := parseImportFixes(importFix, importSwitch)
+		if err != nil {
+			output.PrintError("INVALID_FIX_OPTIONS", "Invalid --fix option", err.Error())
+			if !output.IsJSON() {
+				fmt.Printf("❌ Invalid --fix option: %v\n", err)
+			}
+			return
+		}
+		opts.Fixes = fixes
+
+		if !output.IsJSON() {
+			printImportPlan(importPath, fixes)
+		}
+
+		var vmName string
+		err = runWithProgress(func(events chan<- hyperv.ProgressEvent) error {
+			var importErr error
+			vmName, importErr = manager.ImportVMWithProgress(cmd.Context(), opts, events)
+			return importErr
+		})
 		if err != nil {
-			fmt.Printf("❌ Failed to import VM: %v\n", err)
+			output.PrintError("IMPORT_FAILED", "Failed to import VM", err.Error())
+			if !output.IsJSON() {
+				fmt.Printf("❌ Failed to import VM: %v\n", err)
+				if fixes == nil {
+					fmt.Println("💡 Run with --check to see incompatibilities, and --fix to resolve common ones.")
+				}
+			}
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(ImportResult{VMName: vmName, ImportPath: importPath, Success: true, Message: "VM imported successfully"})
 			return
 		}
 
@@ -97,11 +180,189 @@ Flags:
 	},
 }
 
+// printImportPlan describes the import about to run
+func printImportPlan(importPath string, fixes *hyperv.ImportFixes) {
+	fmt.Printf("📦 Importing VM from '%s'...\n", importPath)
+
+	// Show options being used
+	if importCopy {
+		fmt.Println("   📋 Mode: Copy (will copy VM files to default location)")
+	} else {
+		fmt.Println("   📋 Mode: Register in place")
+	}
+
+	if importGenerateNewID {
+		fmt.Println("   🔄 Generating new VM ID")
+	}
+
+	if importVHDPath != "" {
+		fmt.Printf("   💾 VHD destination: %s\n", importVHDPath)
+	}
+
+	if fixes != nil {
+		fmt.Printf("   🔧 Fixing incompatibilities: %s\n", describeImportFixes(fixes))
+	}
+
+	fmt.Println("⏳ This may take a while depending on VM size...")
+}
+
+// extractImportArchive extracts an archive to a staging directory and returns the export directory inside it
+func extractImportArchive(ctx context.Context, archivePath string) (string, func(), bool) {
+	stagingRoot := importStagingDir
+	if stagingRoot == "" {
+		// Not next to the archive: a FAT32 drive cannot hold the extracted disks
+		stagingRoot = os.TempDir()
+	}
+
+	if !output.IsJSON() {
+		fmt.Printf("🗜️  Extracting '%s'...\n", filepath.Base(archivePath))
+	}
+	progress := newArchiveProgress("import", archive.TrimExt(archivePath), hyperv.ProgressExtracting, "Extracting")
+	exportDir, cleanup, err := hyperv.ExtractExportArchive(ctx, archivePath, stagingRoot, progress)
+	if !output.IsJSON() {
+		fmt.Println()
+	}
+	if err != nil {
+		output.PrintError("ARCHIVE_EXTRACT_FAILED", "Failed to extract archive", err.Error())
+		if !output.IsJSON() {
+			fmt.Printf("❌ Failed to extract archive: %v\n", err)
+		}
+		return "", nil, false
+	}
+	return exportDir, cleanup, true
+}
+
+// verifyBeforeImport checks an export against its manifest and reports whether the import may proceed
+func verifyBeforeImport(importPath string) bool {
+	if info, err := os.Stat(importPath); err != nil || !info.IsDir() || !hyperv.HasExportManifest(importPath) {
+		if !output.IsJSON() {
+			fmt.Println("⚠️  No quickvm manifest found; file integrity cannot be verified.")
+		}
+		return true
+	}
+
+	if !output.IsJSON() {
+		fmt.Println("🔍 Verifying checksums...")
+	}
+	verification, err := hyperv.VerifyExport(importPath)
+	if err != nil {
+		output.PrintError("VERIFY_FAILED", "Failed to verify export", err.Error())
+		if !output.IsJSON() {
+			fmt.Printf("❌ Failed to verify export: %v\n", err)
+		}
+		return false
+	}
+
+	if !verification.Valid {
+		output.PrintError("VERIFY_FAILED", "Export failed verification, refusing to import",
+			strings.Join(verification.Problems(), "; "))
+		if output.IsJSON() {
+			return false
+		}
+		fmt.Println("❌ Export failed verification, refusing to import:")
+		for _, problem := range verification.Problems() {
+			fmt.Printf("  - %s\n", problem)
+		}
+		fmt.Println("💡 Use --skip-verify to import anyway.")
+		return false
+	}
+
+	if !output.IsJSON() {
+		fmt.Printf("✅ %d files verified.\n", verification.Checked)
+	}
+	return true
+}
+
+// runImportCheck prints the compatibility report of an export and reports whether it can be imported
+func runImportCheck(ctx context.Context, manager *hyperv.Manager, opts hyperv.ImportVMOptions) bool {
+	report, err := manager.CheckImportCompatibility(ctx, opts)
+	if err != nil {
+		output.PrintError("IMPORT_CHECK_FAILED", "Failed to check compatibility", err.Error())
+		if !output.IsJSON() {
+			fmt.Printf("❌ Failed to check compatibility: %v\n", err)
+		}
+		return false
+	}
+
+	if output.IsJSON() {
+		output.PrintData(report)
+		return report.Compatible
+	}
+
+	fmt.Printf("🔍 Compatibility of '%s' (Gen %d, %d vCPU, %d MB)\n\n",
+		report.VMName, report.Generation, report.ProcessorCount, report.MemoryStartupMB)
+	if report.Compatible {
+		fmt.Println("✅ No incompatibilities found; the VM can be imported on this host.")
+		return true
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Printf("❌ [%s] %s (message %d)\n", issue.Kind, issue.Message, issue.MessageID)
+		if issue.Detail != "" {
+			fmt.Printf("   %s: %s\n", issue.Source, issue.Detail)
+		}
+		if issue.Fix != "" {
+			fmt.Printf("   💡 %s\n", issue.Fix)
+		}
+	}
+
+	fixes := report.Fixes()
+	if kinds := describeImportFixes(&fixes); kinds != "" {
+		fmt.Printf("\n💡 Import with --fix=%s to apply the automatic fixes.\n", kinds)
+	}
+	return false
+}
+
+// parseImportFixes turns --fix and --switch into import fix-ups; nil means no fixes
+func parseImportFixes(kinds []string, switchName string) (*hyperv.ImportFixes, error) {
+	if len(kinds) == 0 && switchName == "" {
+		return nil, nil
+	}
+
+	fixes := &hyperv.ImportFixes{SwitchName: switchName, Switches: switchName != ""}
+	for _, kind := range kinds {
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "all":
+			fixes.Switches, fixes.Media, fixes.Resources = true, true, true
+		case "switch":
+			fixes.Switches = true
+		case "media":
+			fixes.Media = true
+		case "resources":
+			fixes.Resources = true
+		default:
+			return nil, fmt.Errorf("unknown fix '%s' (valid: switch, media, resources, all)", kind)
+		}
+	}
+	return fixes, nil
+}
+
+// describeImportFixes lists the enabled fixes as --fix would accept them
+func describeImportFixes(fixes *hyperv.ImportFixes) string {
+	var kinds []string
+	if fixes.Switches {
+		kinds = append(kinds, "switch")
+	}
+	if fixes.Media {
+		kinds = append(kinds, "media")
+	}
+	if fixes.Resources {
+		kinds = append(kinds, "resources")
+	}
+	return strings.Join(kinds, ",")
+}
+
 func init() {
 	// Add flags
 	importCmd.Flags().BoolVarP(&importCopy, "copy", "c", false, "Copy VM files instead of registering in place")
 	importCmd.Flags().BoolVarP(&importGenerateNewID, "new-id", "n", false, "Generate a new unique ID for the VM")
 	importCmd.Flags().StringVarP(&importVHDPath, "vhd-path", "v", "", "Custom destination path for VHD files")
+	importCmd.Flags().StringVar(&importStagingDir, "staging-dir", "", "Where to extract archives before importing (default: the temp directory)")
+	importCmd.Flags().BoolVar(&importSkipVerify, "skip-verify", false, "Import even if the export fails checksum verification")
+	importCmd.Flags().BoolVar(&importCheck, "check", false, "Show a compatibility report instead of importing")
+	importCmd.Flags().StringSliceVar(&importFix, "fix", nil, "Fix incompatibilities before importing: switch, media, resources, all")
+	importCmd.Flags().Lookup("fix").NoOptDefVal = "all"
+	importCmd.Flags().StringVar(&importSwitch, "switch", "", "Connect adapters whose switch is missing to this switch")
 
 	rootCmd.AddCommand(importCmd)
 }
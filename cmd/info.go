@@ -6,6 +6,7 @@ import (
 
 	"quickvm/internal/hyperv"
 	"quickvm/internal/output"
+	"quickvm/internal/theme"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -28,7 +29,7 @@ var infoCmd = &cobra.Command{
 		if err != nil {
 			output.PrintError("SYSTEM_INFO_FAILED", "Error getting system info", err.Error())
 			if !output.IsJSON() {
-				color.Red("❌ Error getting system info: %v", err)
+				printFailure("❌ Error getting system info: %v", err)
 			}
 			return
 		}
@@ -51,11 +52,12 @@ func init() {
 //nolint:funlen // UI function
 func printSystemInfo(info *hyperv.SystemInfo) {
 	// Header
-	headerColor := color.New(color.FgCyan, color.Bold)
-	labelColor := color.New(color.FgYellow)
-	valueColor := color.New(color.FgWhite)
-	successColor := color.New(color.FgGreen)
-	errorColor := color.New(color.FgRed)
+	t := theme.Active()
+	headerColor := theme.Color(t.Accent).Add(color.Bold)
+	labelColor := theme.Color(t.Info)
+	valueColor := theme.Color("")
+	successColor := theme.Color(t.Success)
+	errorColor := theme.Color(t.Error)
 
 	fmt.Println()
 	_, _ = headerColor.Println("╔══════════════════════════════════════════════════════════════╗")
@@ -70,6 +72,8 @@ func printSystemInfo(info *hyperv.SystemInfo) {
 	_, _ = valueColor.Println(info.CPU.Name)
 	_, _ = labelColor.Print("   Cores: ")
 	_, _ = valueColor.Printf("%d cores\n", info.CPU.Cores)
+	_, _ = labelColor.Print("   Load:  ")
+	_, _ = valueColor.Printf("%d%%\n", info.CPU.LoadPercent)
 	fmt.Println()
 
 	// Memory Section
@@ -121,15 +125,12 @@ func printProgressBar(label string, percent float64, width int) {
 	empty := width - filled
 
 	// Choose color based on usage
-	// Choose color based on usage
-	var barColor *color.Color
+	barColor := theme.Color(theme.Active().Success)
 	switch {
 	case percent >= 90:
-		barColor = color.New(color.FgRed)
+		barColor = theme.Color(theme.Active().Error)
 	case percent >= 70:
-		barColor = color.New(color.FgYellow)
-	default:
-		barColor = color.New(color.FgGreen)
+		barColor = theme.Color(theme.Active().Warning)
 	}
 
 	fmt.Printf("%s: [", label)
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/lab"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	labFile             string
+	labNoStart          bool
+	labReplaceWorkspace bool
+	labYes              bool
+)
+
+var labCmd = &cobra.Command{
+	Use:   "lab",
+	Short: "Build and tear down a set of VMs from one manifest",
+	Long: `Build a complete lab of VMs from a single manifest file and tear it down again.
+
+Lab manifest:
+  name: training                 # also the name of the lab's workspace
+  description: Quarterly training lab
+  networks:
+    - name: LabNet
+      type: private              # private (default), internal or external
+  vms:
+    - name: DC01
+      template: win2022          # create from a template ...
+      linked: true
+      switch: LabNet
+      cpu: 4
+      memoryMB: 8192
+      provision: dc01.yaml       # see 'quickvm provision'; relative to the manifest
+    - name: WS01
+      clone: Win11-Gold          # ... or clone an existing VM (gets new MACs)
+      switch: LabNet
+      gpu: true                  # add a GPU partition
+      dependsOn: [DC01]          # startup policy, as in workspaces
+      readiness: {type: heartbeat}
+
+Subcommands:
+  up    - Create networks and VMs, save the workspace and start it
+  down  - Delete the VMs (with their disks) and networks the lab created, and its workspace
+
+Examples:
+  quickvm lab up -f lab.yaml
+  quickvm lab down -f lab.yaml`,
+}
+
+var labUpCmd = &cobra.Command{
+	Use:   "up -f <lab.yaml>",
+	Short: "Build and start a lab",
+	Long: `Create the lab's networks, create or clone each VM that does not exist yet,
+configure its switch, CPU and memory, attach its provisioning media, add GPU
+partitions, save a workspace for the lab and start it in dependency order.
+
+VMs that already exist are left unchanged, so 'lab up' can be re-run after a
+failure. Provisioning media is detached once a VM is ready. A workspace with the
+lab's name that the lab did not save is not overwritten unless --replace-workspace
+is given.
+
+Examples:
+  quickvm lab up -f lab.yaml
+  quickvm lab up -f lab.yaml --no-start`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := lab.Load(labFile)
+		if err != nil {
+			printWorkspaceError("LAB_INVALID", "Invalid lab manifest", err)
+			return
+		}
+
+		manager := hyperv.NewManager()
+		l := lab.New(manager)
+		l.ReplaceWorkspace = labReplaceWorkspace
+		orchestrator := hyperv.NewWorkspaceOrchestrator(manager)
+		l.Starter = orchestrator
+		if !output.IsJSON() {
+			l.OnEvent = printLabEvent
+			orchestrator.OnEvent = printWorkspaceEvent
+			fmt.Printf("🧪 Building lab '%s' (%d VMs)...\n", manifest.Name, len(manifest.VMs))
+		}
+
+		result, err := l.Up(cmd.Context(), manifest, !labNoStart)
+		if err != nil {
+			printWorkspaceError("LAB_UP_FAILED", "Failed to bring up lab", err)
+			if !output.IsJSON() {
+				fmt.Println("\n💡 Fix the problem and re-run 'quickvm lab up': existing VMs are kept")
+			}
+			return
+		}
+		printLabUpResult(result)
+	},
+}
+
+var labDownCmd = &cobra.Command{
+	Use:   "down -f <lab.yaml>",
+	Short: "Tear down a lab",
+	Long: `Delete the VMs 'lab up' created together with their virtual hard disks, the
+switches it created and the lab's workspace. The VMs are turned off first. You are
+asked to confirm unless --yes is given.
+
+VMs, switches and a workspace that already existed when the lab was brought up
+are kept, and so is a lab switch that VMs outside the lab are still connected to. What 'lab up'
+created is recorded in ~/.quickvm/labs/<name>.json.
+
+Examples:
+  quickvm lab down -f lab.yaml
+  quickvm lab down -f lab.yaml --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := lab.Load(labFile)
+		if err != nil {
+			printWorkspaceError("LAB_INVALID", "Invalid lab manifest", err)
+			return
+		}
+
+		l := lab.New(hyperv.NewManager())
+		plan, err := l.PlanDown(cmd.Context(), manifest)
+		if err != nil {
+			printWorkspaceError("LAB_DOWN_FAILED", "Failed to plan lab teardown", err)
+			return
+		}
+
+		if !labYes {
+			if output.IsJSON() {
+				printWorkspaceError("CONFIRMATION_REQUIRED", "Lab teardown needs confirmation", fmt.Errorf("use --yes with JSON output"))
+				return
+			}
+			printLabDownPlan(plan)
+			if !confirmLabDown(cmd.Context(), plan.Lab) {
+				fmt.Println("Cancelled.")
+				return
+			}
+		}
+
+		if !output.IsJSON() {
+			l.OnEvent = printLabEvent
+		}
+		if err := l.Down(cmd.Context(), plan); err != nil {
+			printWorkspaceError("LAB_DOWN_FAILED", "Failed to tear down lab", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(LabDownResult{DownPlan: plan, Success: true})
+			return
+		}
+		fmt.Printf("✅ Lab '%s' torn down\n", plan.Lab)
+	},
+}
+
+// confirmLabDown asks the user to type the lab name. It is a variable so tests can replace it.
+var confirmLabDown = func(ctx context.Context, name string) bool {
+	fmt.Printf("❓ Type the lab name (%s) to confirm: ", name)
+	response, err := readResponse(ctx)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(response) == name
+}
+
+func printLabDownPlan(plan *lab.DownPlan) {
+	fmt.Printf("⚠️  Tearing down lab '%s' deletes:\n", plan.Lab)
+	for _, vm := range plan.VMs {
+		fmt.Printf("   - VM %s and its virtual hard disks\n", vm)
+	}
+	for _, network := range plan.Networks {
+		fmt.Printf("   - Switch %s\n", network)
+	}
+	if plan.Workspace != "" {
+		fmt.Printf("   - Workspace %s\n", plan.Workspace)
+	}
+	if len(plan.KeptVMs) > 0 || len(plan.KeptNetworks) > 0 || plan.KeptWorkspace != "" {
+		fmt.Println("   Not created by the lab, kept:")
+		for _, vm := range plan.KeptVMs {
+			fmt.Printf("   - VM %s\n", vm)
+		}
+		for _, network := range plan.KeptNetworks {
+			fmt.Printf("   - Switch %s\n", network)
+		}
+		if plan.KeptWorkspace != "" {
+			fmt.Printf("   - Workspace %s\n", plan.KeptWorkspace)
+		}
+	}
+}
+
+func printLabUpResult(result *lab.UpResult) {
+	if output.IsJSON() {
+		labResult := LabUpResult{UpResult: result, Success: true}
+		if result.Start != nil {
+			summary := summarizeWorkspaceRun("start", result.Lab, result.Start)
+			labResult.Start = &summary
+			labResult.Success = summary.FailCount == 0 && summary.SkippedCount == 0
+		}
+		output.PrintData(labResult)
+		return
+	}
+
+	fmt.Printf("\n📊 Lab '%s': %d VMs created, %d already existed\n", result.Lab, len(result.CreatedVMs), len(result.ExistingVMs))
+	if result.Start != nil {
+		summary := summarizeWorkspaceRun("start", result.Lab, result.Start)
+		fmt.Printf("📊 Start: %d started, %d failed, %d skipped\n", summary.SuccessCount, summary.FailCount, summary.SkippedCount)
+		return
+	}
+	fmt.Printf("\n💡 Start the lab with: quickvm ws start \"%s\"\n", result.Lab)
+}
+
+// printLabEvent prints build and teardown progress for one lab network or VM
+func printLabEvent(event lab.Event) {
+	switch event.Step {
+	case lab.StepNetwork:
+		fmt.Printf("🔌 Created %s switch: %s\n", event.Message, event.Target)
+	case lab.StepExists:
+		fmt.Printf("⏭️  VM '%s' already exists, leaving it unchanged\n", event.Target)
+	case lab.StepCreate:
+		fmt.Printf("🆕 Creating VM '%s' from template '%s'...\n", event.Target, event.Message)
+	case lab.StepClone:
+		fmt.Printf("🧬 Cloning VM '%s' from '%s'...\n", event.Target, event.Message)
+	case lab.StepConfigure:
+		fmt.Printf("⚙️  Configuring VM '%s'...\n", event.Target)
+	case lab.StepProvision:
+		fmt.Printf("💿 Attaching provisioning ISO (%s) to VM '%s'\n", event.Message, event.Target)
+	case lab.StepGPU:
+		fmt.Printf("🎮 Adding GPU partition to VM '%s'\n", event.Target)
+	case lab.StepWorkspace:
+		fmt.Printf("📁 Saved workspace '%s'\n", event.Target)
+	case lab.StepDetach:
+		fmt.Printf("⏏️  Detached provisioning ISO from VM '%s'\n", event.Target)
+	case lab.StepDelete:
+		fmt.Printf("🗑️  Deleting %s...\n", event.Target)
+	case lab.StepKeep:
+		fmt.Printf("⏭️  Keeping switch '%s': still used by %s\n", event.Target, event.Message)
+	}
+}
+
+func init() {
+	labCmd.PersistentFlags().StringVarP(&labFile, "file", "f", "", "Lab manifest (YAML)")
+	_ = labCmd.MarkPersistentFlagRequired("file")
+	labUpCmd.Flags().BoolVar(&labNoStart, "no-start", false, "Build the lab and save its workspace without starting it")
+	labUpCmd.Flags().BoolVar(&labReplaceWorkspace, "replace-workspace", false, "Overwrite a workspace of the lab's name that the lab did not save")
+	labDownCmd.Flags().BoolVarP(&labYes, "yes", "y", false, "Do not ask for confirmation")
+
+	labCmd.AddCommand(labUpCmd)
+	labCmd.AddCommand(labDownCmd)
+	rootCmd.AddCommand(labCmd)
+}
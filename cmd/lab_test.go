@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/lab"
+)
+
+func TestLabCommandSetup(t *testing.T) {
+	names := map[string]bool{}
+	for _, sub := range labCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	if !names["up"] || !names["down"] {
+		t.Errorf("Expected 'up' and 'down' subcommands on 'lab', got %v", names)
+	}
+	if labCmd.PersistentFlags().Lookup("file") == nil || labDownCmd.Flags().Lookup("yes") == nil {
+		t.Error("Expected --file on 'lab' and --yes on 'lab down'")
+	}
+}
+
+func TestLabUpResult_JSONUsesStartSummary(t *testing.T) {
+	result := &lab.UpResult{Lab: "training", Start: []hyperv.WorkspaceVMResult{{Name: "DC01", Success: true}, {Name: "WS01", Skipped: true}}}
+	summary := summarizeWorkspaceRun("start", result.Lab, result.Start)
+	data, err := json.Marshal(LabUpResult{UpResult: result, Start: &summary})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"skippedCount":1`) || !strings.Contains(string(data), `"lab":"training"`) {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"quickvm/internal/hyperv"
 	"quickvm/internal/output"
+	"quickvm/internal/theme"
 
 	"github.com/spf13/cobra"
 )
@@ -16,13 +19,33 @@ type VMListResponse struct {
 	Total int         `json:"total"`
 }
 
+// VMListEvent is one refresh of 'list --watch' in JSON mode
+type VMListEvent struct {
+	Time    time.Time   `json:"time"`
+	VMs     []hyperv.VM `json:"vms"`
+	Changed []string    `json:"changed,omitempty"` // VMs whose state changed since the previous refresh
+}
+
+var (
+	listWatch    bool
+	listInterval time.Duration
+)
+
 var listCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List all Hyper-V virtual machines",
-	Long:    `Display a list of all Hyper-V virtual machines with their status.`,
+	Use:   "list",
+	Short: "List all Hyper-V virtual machines",
+	Long: `Display a list of all Hyper-V virtual machines with their status.
+
+With --watch the list is redrawn every --interval until Ctrl+C; VMs whose
+state changed since the previous refresh are marked with ⚡. In JSON mode each
+refresh is printed as one JSON object per line.`,
 	Aliases: []string{"ls"},
 	Run: func(cmd *cobra.Command, _ []string) {
 		manager := hyperv.NewManager()
+		if listWatch {
+			watchVMList(cmd.Context(), manager, listInterval)
+			return
+		}
 
 		if !output.IsJSON() {
 			fmt.Println("📋 Fetching Hyper-V virtual machines...")
@@ -30,10 +53,7 @@ var listCmd = &cobra.Command{
 
 		vms, err := manager.GetVMs(cmd.Context())
 		if err != nil {
-			output.PrintError("VM_LIST_FAILED", "Failed to get VMs", err.Error())
-			if !output.IsJSON() {
-				fmt.Printf("❌ Failed to get VMs: %v\n", err)
-			}
+			printListError(err)
 			return
 		}
 
@@ -51,43 +71,105 @@ var listCmd = &cobra.Command{
 			fmt.Println("ℹ️  No virtual machines found.")
 			return
 		}
+		printVMTable(vms, nil)
+		fmt.Println("\n💡 Tip: Use 'quickvm start <index>' to start a VM")
+	},
+}
 
-		// Print header
-		fmt.Println("\n" + strings.Repeat("=", 110))
-		fmt.Printf("%-7s %-30s %-12s %-8s %-12s %-20s %-15s\n",
-			"Index", "Name", "State", "CPU%", "Memory(MB)", "Uptime", "Status")
-		fmt.Println(strings.Repeat("=", 110))
-
-		// Print VMs
-		for _, vm := range vms {
-			stateIcon := "⚪"
-			switch strings.ToLower(vm.State) {
-			case "running":
-				stateIcon = "🟢"
-			case "off":
-				stateIcon = "🔴"
-			case "paused":
-				stateIcon = "🟡"
+func printListError(err error) {
+	output.PrintError("VM_LIST_FAILED", "Failed to get VMs", err.Error())
+	if !output.IsJSON() {
+		fmt.Printf("❌ Failed to get VMs: %v\n", err)
+	}
+}
+
+// printVMTable prints the human-readable VM table; VMs in changed are marked
+func printVMTable(vms []hyperv.VM, changed map[string]bool) {
+	// Print header
+	fmt.Println("\n" + strings.Repeat("=", 110))
+	fmt.Printf("%-7s %-30s %-12s %-8s %-12s %-20s %-15s\n",
+		"Index", "Name", "State", "CPU%", "Memory(MB)", "Uptime", "Status")
+	fmt.Println(strings.Repeat("=", 110))
+
+	// Print VMs
+	for _, vm := range vms {
+		marker := "  "
+		if changed[vm.Name] {
+			marker = "⚡"
+		}
+
+		fmt.Printf("%-7d %-30s %s%s %-8d %-12d %-20s %-15s\n",
+			vm.Index,
+			vm.Name,
+			marker,
+			theme.Active().State(vm.State, 10),
+			vm.CPUUsage,
+			vm.MemoryMB,
+			vm.Uptime,
+			vm.Status,
+		)
+	}
+
+	fmt.Println(strings.Repeat("=", 110))
+	fmt.Printf("\nTotal VMs: %d\n", len(vms))
+}
+
+// watchVMList reloads and prints the VM list every interval until ctx is cancelled
+func watchVMList(ctx context.Context, manager hyperv.VMManager, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	states := make(map[string]string)
+	for {
+		vms, err := manager.GetVMs(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			printListError(err)
+		} else {
+			changed := stateChanges(states, vms)
+			if output.IsJSON() {
+				event := VMListEvent{Time: time.Now(), VMs: vms}
+				for _, vm := range vms {
+					if changed[vm.Name] {
+						event.Changed = append(event.Changed, vm.Name)
+					}
+				}
+				output.PrintEvent(event)
+			} else {
+				fmt.Print("\033[H\033[2J") // Clear the screen
+				fmt.Printf("👀 Watching Hyper-V VMs every %s (Ctrl+C to stop) - %s\n", interval, time.Now().Format("15:04:05"))
+				printVMTable(vms, changed)
 			}
+		}
 
-			fmt.Printf("%-7d %-30s %s %-10s %-8d %-12d %-20s %-15s\n",
-				vm.Index,
-				vm.Name,
-				stateIcon,
-				vm.State,
-				vm.CPUUsage,
-				vm.MemoryMB,
-				vm.Uptime,
-				vm.Status,
-			)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
 		}
+	}
+}
 
-		fmt.Println(strings.Repeat("=", 110))
-		fmt.Printf("\nTotal VMs: %d\n", len(vms))
-		fmt.Println("\n💡 Tip: Use 'quickvm start <index>' to start a VM")
-	},
+// stateChanges returns the VMs whose state differs from states and updates states.
+// VMs seen for the first time are not reported as changed.
+func stateChanges(states map[string]string, vms []hyperv.VM) map[string]bool {
+	changed := make(map[string]bool)
+	for _, vm := range vms {
+		if previous, ok := states[vm.Name]; ok && previous != vm.State {
+			changed[vm.Name] = true
+		}
+		states[vm.Name] = vm.State
+	}
+	return changed
 }
 
 func init() {
+	listCmd.Flags().BoolVarP(&listWatch, "watch", "w", false, "Redraw the list periodically until Ctrl+C")
+	listCmd.Flags().DurationVar(&listInterval, "interval", defaultWatchInterval, "Refresh interval for --watch")
 	rootCmd.AddCommand(listCmd)
 }
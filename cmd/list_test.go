@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestStateChanges(t *testing.T) {
+	states := map[string]string{}
+	if changed := stateChanges(states, []hyperv.VM{{Name: "Web01", State: "Off"}}); len(changed) != 0 {
+		t.Errorf("Expected VMs seen for the first time not to be changed, got %v", changed)
+	}
+	changed := stateChanges(states, []hyperv.VM{{Name: "Web01", State: "Running"}, {Name: "DB01", State: "Off"}})
+	if !changed["Web01"] || changed["DB01"] {
+		t.Errorf("Expected only Web01 to have changed, got %v", changed)
+	}
+}
+
+func TestWatchVMList_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	manager := &MockManager{GetVMsFn: func(context.Context) ([]hyperv.VM, error) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+		return []hyperv.VM{{Name: "Web01", State: "Running"}}, nil
+	}}
+
+	done := make(chan struct{})
+	go func() {
+		watchVMList(ctx, manager, time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchVMList did not stop after cancellation")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 refreshes, got %d", calls)
+	}
+}
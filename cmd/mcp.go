@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+// mcpProtocolVersions are the MCP revisions the server speaks, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// maxMCPMessage is the largest JSON-RPC message the server reads
+const maxMCPMessage = 4 << 20
+
+var (
+	mcpReadOnly bool
+	mcpAllow    []string
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve VM management tools to AI agents over MCP (stdio)",
+	Long: `Run a Model Context Protocol server on stdin/stdout, offering quickvm
+operations as tools with JSON schemas for their arguments and results.
+
+Tools:
+  read         list_vms, get_vm, list_snapshots, list_workspaces,
+               workspace_status, get_system_info
+  write        start_vm, stop_vm, restart_vm, create_snapshot, export_vm,
+               import_vm, workspace_start, workspace_stop
+  destructive  delete_vm, delete_snapshot, restore_snapshot (discards the
+               current state of the VM)
+
+Read and write tools are offered by default, read tools only with --read-only.
+Destructive tools are never offered unless named in --allow; --allow also
+re-enables single write tools in read-only mode.
+
+Example client configuration:
+  {"mcpServers": {"quickvm": {"command": "quickvm", "args": ["mcp", "--read-only"]}}}
+
+Examples:
+  quickvm mcp
+  quickvm mcp --read-only
+  quickvm mcp --read-only --allow start_vm,stop_vm
+  quickvm mcp --allow delete_snapshot`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		tools, err := selectMCPTools(mcpTools(), mcpReadOnly, mcpAllow)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		// stdout carries the protocol; anything else goes to stderr
+		server := newMCPServer(hyperv.NewManager(), tools, os.Stdout)
+		if err := server.serve(cmd.Context(), os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ MCP server failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// selectMCPTools returns the tools offered for the given mode and allowlist
+func selectMCPTools(all []mcpTool, readOnly bool, allow []string) ([]mcpTool, error) {
+	for _, name := range allow {
+		if !slices.ContainsFunc(all, func(t mcpTool) bool { return t.name == name }) {
+			return nil, fmt.Errorf("unknown tool in --allow: %s", name)
+		}
+	}
+	var tools []mcpTool
+	for _, t := range all {
+		offered := t.class == mcpRead || (t.class == mcpWrite && !readOnly)
+		if offered || slices.Contains(allow, t.name) {
+			tools = append(tools, t)
+		}
+	}
+	return tools, nil
+}
+
+// rpcRequest is a JSON-RPC request or notification; notifications have no ID
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpToolInfo describes a tool in tools/list
+type mcpToolInfo struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	InputSchema  map[string]any `json:"inputSchema"`
+	OutputSchema map[string]any `json:"outputSchema,omitempty"`
+	Annotations  map[string]any `json:"annotations,omitempty"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content           []mcpContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// mcpServer answers MCP requests read line by line; tool calls run concurrently
+type mcpServer struct {
+	manager *hyperv.Manager
+	tools   []mcpTool
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	callsMu sync.Mutex
+	calls   map[string]context.CancelFunc // Running tool calls by request ID
+	wg      sync.WaitGroup
+}
+
+func newMCPServer(manager *hyperv.Manager, tools []mcpTool, out io.Writer) *mcpServer {
+	return &mcpServer{manager: manager, tools: tools, out: out, calls: make(map[string]context.CancelFunc)}
+}
+
+// serve handles messages until in is exhausted or ctx is done, then waits for running calls
+func (s *mcpServer) serve(ctx context.Context, in io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxMCPMessage)
+		for scanner.Scan() {
+			select {
+			case lines <- slices.Clone(scanner.Bytes()):
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	defer s.wg.Wait()
+	for {
+		select {
+		case line := <-lines:
+			s.handle(ctx, line)
+		case err := <-scanErr:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *mcpServer) handle(ctx context.Context, line []byte) {
+	if len(strings.TrimSpace(string(line))) == 0 {
+		return
+	}
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.writeError(json.RawMessage("null"), rpcParseError, "Parse error: "+err.Error())
+		return
+	}
+	if req.Method == "" {
+		// A response to a server request; the server sends none
+		return
+	}
+	if req.ID == nil {
+		s.handleNotification(req)
+		return
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := mcpProtocolVersions[0]
+		if slices.Contains(mcpProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		s.writeResult(req.ID, map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": "quickvm", "version": Version},
+			"instructions": "Manage Hyper-V virtual machines on this host. VMs are selected by the index " +
+				"shown by list_vms or by name; names are stable, indexes change when VMs are added or removed.",
+		})
+	case "ping":
+		s.writeResult(req.ID, map[string]any{})
+	case "tools/list":
+		infos := make([]mcpToolInfo, 0, len(s.tools))
+		for _, t := range s.tools {
+			infos = append(infos, t.info())
+		}
+		s.writeResult(req.ID, map[string]any{"tools": infos})
+	case "tools/call":
+		s.startCall(ctx, req)
+	default:
+		s.writeError(req.ID, rpcMethodNotFound, "Method not found: "+req.Method)
+	}
+}
+
+func (s *mcpServer) handleNotification(req rpcRequest) {
+	if req.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+	s.callsMu.Lock()
+	defer s.callsMu.Unlock()
+	if cancel, ok := s.calls[string(params.RequestID)]; ok {
+		cancel()
+	}
+}
+
+// startCall validates a tools/call request and runs the tool in the background
+func (s *mcpServer) startCall(ctx context.Context, req rpcRequest) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.writeError(req.ID, rpcInvalidRequest, "Invalid tools/call parameters: "+err.Error())
+		return
+	}
+	i := slices.IndexFunc(s.tools, func(t mcpTool) bool { return t.name == params.Name })
+	if i < 0 {
+		s.writeError(req.ID, rpcInvalidParams, "Unknown or disallowed tool: "+params.Name)
+		return
+	}
+	tool := s.tools[i]
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.callsMu.Lock()
+	s.calls[string(req.ID)] = cancel
+	s.callsMu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		result := tool.run(ctx, s.manager, params.Arguments)
+		s.callsMu.Lock()
+		delete(s.calls, string(req.ID))
+		s.callsMu.Unlock()
+		// A cancelled request gets no response
+		if ctx.Err() == nil {
+			s.writeResult(req.ID, result)
+		}
+		cancel()
+	}()
+}
+
+// info describes the tool for tools/list
+func (t mcpTool) info() mcpToolInfo {
+	return mcpToolInfo{
+		Name:         t.name,
+		Description:  t.description,
+		InputSchema:  jsonSchema(reflect.TypeOf(t.args), nil),
+		OutputSchema: nullableCollections(jsonSchema(reflect.TypeOf(t.result), nil)),
+		Annotations: map[string]any{
+			"readOnlyHint":    t.class == mcpRead,
+			"destructiveHint": t.class == mcpDestructive,
+		},
+	}
+}
+
+// run decodes the arguments and calls the tool; failures are reported in the result
+func (t mcpTool) run(ctx context.Context, m *hyperv.Manager, arguments json.RawMessage) mcpToolResult {
+	args := reflect.New(reflect.TypeOf(t.args)).Interface()
+	if len(arguments) > 0 && string(arguments) != "null" {
+		decoder := json.NewDecoder(strings.NewReader(string(arguments)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(args); err != nil {
+			return mcpErrorResult(output.ErrorInfo{Code: "INVALID_ARGUMENTS", Message: "Invalid arguments", Details: err.Error()})
+		}
+	}
+
+	result, err := t.call(ctx, m, args)
+	if err != nil {
+		info := output.ErrorInfo{Code: "TOOL_FAILED", Message: err.Error()}
+		var apiErr *apiError
+		if errors.As(err, &apiErr) {
+			info = output.ErrorInfo{Code: apiErr.code, Message: apiErr.message, Details: apiErr.details}
+		}
+		return mcpErrorResult(info)
+	}
+	text, err := json.Marshal(result)
+	if err != nil {
+		return mcpErrorResult(output.ErrorInfo{Code: "ENCODE_FAILED", Message: "Failed to encode result", Details: err.Error()})
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: string(text)}}, StructuredContent: result}
+}
+
+func mcpErrorResult(info output.ErrorInfo) mcpToolResult {
+	text, _ := json.Marshal(info)
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: string(text)}}, IsError: true}
+}
+
+// nullableCollections allows null wherever the schema has an array or a map, as
+// encoding/json writes nil slices and maps as null
+func nullableCollections(schema map[string]any) map[string]any {
+	for key, value := range schema {
+		switch v := value.(type) {
+		case map[string]any:
+			nullableCollections(v)
+		case []any:
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					nullableCollections(m)
+				}
+			}
+		}
+		if key == "type" && value == "array" {
+			schema[key] = []any{"array", "null"}
+		}
+		if key == "additionalProperties" {
+			schema["type"] = []any{"object", "null"}
+		}
+	}
+	return schema
+}
+
+func (s *mcpServer) writeResult(id json.RawMessage, result any) {
+	s.write(rpcResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *mcpServer) writeError(id json.RawMessage, code int, message string) {
+	s.write(rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}})
+}
+
+func (s *mcpServer) write(resp rpcResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(rpcResponse{JSONRPC: "2.0", ID: resp.ID,
+			Error: &rpcError{Code: rpcInternalError, Message: "Failed to encode response: " + err.Error()}})
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, _ = s.out.Write(append(data, '\n'))
+}
+
+func init() {
+	mcpCmd.Flags().BoolVar(&mcpReadOnly, "read-only", false, "Only offer tools that do not change anything")
+	mcpCmd.Flags().StringSliceVar(&mcpAllow, "allow", nil, "Tools to offer in addition to the mode's, required for destructive tools")
+	rootCmd.AddCommand(mcpCmd)
+}
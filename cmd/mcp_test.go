@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+// runMCP sends the messages to a server offering tools and returns its responses by ID
+func runMCP(t *testing.T, tools []mcpTool, messages ...string) map[string]map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	server := newMCPServer(&hyperv.Manager{Exec: &apiExecutor{}}, tools, &out)
+	if err := server.serve(context.Background(), strings.NewReader(strings.Join(messages, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+	responses := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("Invalid response %q: %v", line, err)
+		}
+		id, _ := json.Marshal(resp["id"])
+		responses[string(id)] = resp
+	}
+	return responses
+}
+
+func TestSelectMCPTools(t *testing.T) {
+	names := func(tools []mcpTool) string {
+		var n []string
+		for _, tool := range tools {
+			n = append(n, tool.name)
+		}
+		return "," + strings.Join(n, ",") + ","
+	}
+
+	tools, _ := selectMCPTools(mcpTools(), false, nil)
+	if got := names(tools); !strings.Contains(got, ",start_vm,") || strings.Contains(got, ",delete_vm,") ||
+		strings.Contains(got, ",restore_snapshot,") {
+		t.Errorf("Default tools: %s", got)
+	}
+	tools, _ = selectMCPTools(mcpTools(), true, []string{"stop_vm"})
+	if got := names(tools); strings.Contains(got, ",start_vm,") || !strings.Contains(got, ",stop_vm,") ||
+		!strings.Contains(got, ",list_vms,") {
+		t.Errorf("Read-only tools with stop_vm allowed: %s", got)
+	}
+	tools, _ = selectMCPTools(mcpTools(), false, []string{"delete_snapshot", "restore_snapshot"})
+	if got := names(tools); !strings.Contains(got, ",delete_snapshot,") || !strings.Contains(got, ",restore_snapshot,") ||
+		strings.Contains(got, ",delete_vm,") {
+		t.Errorf("Tools with delete_snapshot allowed: %s", got)
+	}
+	if _, err := selectMCPTools(mcpTools(), false, []string{"format_disk"}); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+}
+
+func TestMCPSession(t *testing.T) {
+	tools, _ := selectMCPTools(mcpTools(), true, nil)
+	responses := runMCP(t, tools,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list_vms","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":"4","method":"tools/call","params":{"name":"get_vm","arguments":{"vm":"nope"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"start_vm","arguments":{"vm":"1"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"get_vm","arguments":{"name":"1"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 8 {
+		t.Fatalf("Expected 8 responses, got %d: %v", len(responses), responses)
+	}
+
+	if v := responses["1"]["result"].(map[string]any)["protocolVersion"]; v != "2025-03-26" {
+		t.Errorf("Expected the client's protocol version, got %v", v)
+	}
+
+	listed := responses["2"]["result"].(map[string]any)["tools"].([]any)
+	for _, item := range listed {
+		tool := item.(map[string]any)
+		if tool["name"] == "start_vm" {
+			t.Error("Write tools must not be listed in read-only mode")
+		}
+		if tool["name"] == "get_vm" {
+			schema := tool["inputSchema"].(map[string]any)
+			if req := schema["required"].([]any); len(req) != 1 || req[0] != "vm" {
+				t.Errorf("get_vm input schema: %v", schema)
+			}
+		}
+	}
+
+	result := responses["3"]["result"].(map[string]any)
+	if result["isError"] == true || result["structuredContent"].(map[string]any)["total"] != float64(2) {
+		t.Errorf("list_vms result: %v", result)
+	}
+
+	result = responses[`"4"`]["result"].(map[string]any)
+	if result["isError"] != true || !strings.Contains(result["content"].([]any)[0].(map[string]any)["text"].(string), "VM_NOT_FOUND") {
+		t.Errorf("Expected a VM_NOT_FOUND tool error, got %v", result)
+	}
+
+	if code := responses["5"]["error"].(map[string]any)["code"]; code != float64(rpcInvalidParams) {
+		t.Errorf("Expected a disallowed tool to be rejected, got %v", responses["5"])
+	}
+	if result := responses["6"]["result"].(map[string]any); result["isError"] != true {
+		t.Errorf("Expected unknown arguments to fail, got %v", result)
+	}
+	if code := responses["7"]["error"].(map[string]any)["code"]; code != float64(rpcMethodNotFound) {
+		t.Errorf("Expected method not found, got %v", responses["7"])
+	}
+	if code := responses["null"]["error"].(map[string]any)["code"]; code != float64(rpcParseError) {
+		t.Errorf("Expected a parse error, got %v", responses["null"])
+	}
+}
+
+func TestNullableCollections(t *testing.T) {
+	schema := nullableCollections(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"vms":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"tags": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	})
+	properties := schema["properties"].(map[string]any)
+	if got, _ := json.Marshal(properties["vms"].(map[string]any)["type"]); string(got) != `["array","null"]` {
+		t.Errorf("Array type: %s", got)
+	}
+	if got, _ := json.Marshal(properties["tags"].(map[string]any)["type"]); string(got) != `["object","null"]` {
+		t.Errorf("Map type: %s", got)
+	}
+	if schema["type"] != "object" {
+		t.Errorf("Struct type changed to %v", schema["type"])
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+
+	"quickvm/internal/hyperv"
+)
+
+// Tool classes: read-only tools are always offered, write tools unless --read-only,
+// destructive tools only when named in --allow
+const (
+	mcpRead        = "read"
+	mcpWrite       = "write"
+	mcpDestructive = "destructive"
+)
+
+// mcpTool is a tool of the MCP server; its input and output schemas are derived
+// from the Go types of args and result
+type mcpTool struct {
+	name        string
+	description string
+	class       string
+	args        any // Zero value of the arguments struct
+	result      any // Zero value of the result type
+	call        func(ctx context.Context, m *hyperv.Manager, args any) (any, error)
+}
+
+// VMArgs selects a VM
+type VMArgs struct {
+	VM string `json:"vm" description:"VM index (from list_vms) or name"`
+}
+
+// DeleteVMArgs are the arguments of delete_vm
+type DeleteVMArgs struct {
+	VM          string `json:"vm" description:"VM index (from list_vms) or name"`
+	DeleteDisks bool   `json:"deleteDisks,omitempty" description:"Also delete the VM's virtual hard disks"`
+}
+
+// SnapshotArgs selects a snapshot of a VM
+type SnapshotArgs struct {
+	VM       string `json:"vm" description:"VM index (from list_vms) or name"`
+	Snapshot string `json:"snapshot" description:"Snapshot name"`
+}
+
+// ExportArgs are the arguments of export_vm
+type ExportArgs struct {
+	VM   string `json:"vm" description:"VM index (from list_vms) or name"`
+	Path string `json:"path" description:"Directory on the Hyper-V host; the VM is exported into <path>/<vm name>"`
+}
+
+// WorkspaceArgs selects a workspace
+type WorkspaceArgs struct {
+	Name string `json:"name" description:"Workspace name (from list_workspaces)"`
+}
+
+// SystemInfoArgs are the arguments of get_system_info
+type SystemInfoArgs struct {
+	Disk bool `json:"disk,omitempty" description:"Include disk usage (slower)"`
+}
+
+// NoArgs is the arguments struct of tools without arguments
+type NoArgs struct{}
+
+// mcpTools returns every tool of the MCP server
+func mcpTools() []mcpTool {
+	return []mcpTool{
+		{name: "list_vms", class: mcpRead, description: "List all Hyper-V virtual machines with state, CPU, memory, uptime and IP addresses",
+			args: NoArgs{}, result: VMListResponse{},
+			call: func(ctx context.Context, m *hyperv.Manager, _ any) (any, error) { return listVMs(ctx, m) }},
+		{name: "get_vm", class: mcpRead, description: "Get one virtual machine by index or name",
+			args: VMArgs{}, result: hyperv.VM{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				return findVM(ctx, m, args.(*VMArgs).VM)
+			}},
+		vmActionTool("start_vm", "start", "Start a virtual machine"),
+		vmActionTool("stop_vm", "stop", "Shut down a virtual machine"),
+		vmActionTool("restart_vm", "restart", "Restart a virtual machine"),
+		{name: "delete_vm", class: mcpDestructive, description: "Remove a virtual machine from Hyper-V, optionally deleting its disks",
+			args: DeleteVMArgs{}, result: VMOperationResult{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				a := args.(*DeleteVMArgs)
+				return deleteVM(ctx, m, a.VM, a.DeleteDisks)
+			}},
+		{name: "list_snapshots", class: mcpRead, description: "List the snapshots (checkpoints) of a virtual machine",
+			args: VMArgs{}, result: SnapshotListResult{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				return listSnapshots(ctx, m, args.(*VMArgs).VM)
+			}},
+		snapshotTool("create_snapshot", "create", mcpWrite, "Create a snapshot (checkpoint) of a virtual machine"),
+		snapshotTool("restore_snapshot", "restore", mcpDestructive, "Restore a virtual machine to a snapshot, discarding its current state"),
+		snapshotTool("delete_snapshot", "delete", mcpDestructive, "Delete a snapshot of a virtual machine"),
+		{name: "export_vm", class: mcpWrite, description: "Export a virtual machine to a directory; waits until the export is complete",
+			args: ExportArgs{}, result: ExportResult{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				a := args.(*ExportArgs)
+				return runPrepared(ctx)(prepareExport(ctx, m, a.VM, a.Path))
+			}},
+		{name: "import_vm", class: mcpWrite, description: "Import a virtual machine from an export directory",
+			args: ImportRequest{}, result: ImportResult{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				return runPrepared(ctx)(prepareImport(m, *args.(*ImportRequest)))
+			}},
+		{name: "list_workspaces", class: mcpRead, description: "List workspaces (named groups of VMs)",
+			args: NoArgs{}, result: WorkspaceListResult{},
+			call: func(_ context.Context, _ *hyperv.Manager, _ any) (any, error) { return listWorkspaces() }},
+		{name: "workspace_status", class: mcpRead, description: "Get the live state, CPU, memory and IPs of every VM in a workspace",
+			args: WorkspaceArgs{}, result: hyperv.WorkspaceStatus{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				return workspaceStatus(ctx, m, args.(*WorkspaceArgs).Name)
+			}},
+		workspaceRunTool("workspace_start", "start", "Start every VM of a workspace in dependency order, waiting for readiness checks"),
+		workspaceRunTool("workspace_stop", "stop", "Stop every VM of a workspace in reverse dependency order"),
+		{name: "get_system_info", class: mcpRead, description: "Get host CPU, memory, disk and Hyper-V information",
+			args: SystemInfoArgs{}, result: hyperv.SystemInfo{},
+			call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+				return systemInfo(ctx, m, args.(*SystemInfoArgs).Disk)
+			}},
+	}
+}
+
+func vmActionTool(name, action, description string) mcpTool {
+	return mcpTool{name: name, class: mcpWrite, description: description, args: VMArgs{}, result: VMOperationResult{},
+		call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+			return runVMAction(ctx, m, args.(*VMArgs).VM, action)
+		}}
+}
+
+func snapshotTool(name, operation, class, description string) mcpTool {
+	return mcpTool{name: name, class: class, description: description, args: SnapshotArgs{}, result: SnapshotOpResult{},
+		call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+			a := args.(*SnapshotArgs)
+			return runSnapshotAction(ctx, m, a.VM, operation, a.Snapshot)
+		}}
+}
+
+func workspaceRunTool(name, operation, description string) mcpTool {
+	return mcpTool{name: name, class: mcpWrite, description: description, args: WorkspaceArgs{}, result: WorkspaceRunResult{},
+		call: func(ctx context.Context, m *hyperv.Manager, args any) (any, error) {
+			return runPrepared(ctx)(prepareWorkspaceRun(m, args.(*WorkspaceArgs).Name, operation))
+		}}
+}
+
+// runPrepared runs a prepared task to completion; the MCP client waits for the result
+func runPrepared(ctx context.Context) func(apiTask, error) (any, error) {
+	return func(task apiTask, err error) (any, error) {
+		if err != nil {
+			return nil, err
+		}
+		return task(ctx)
+	}
+}
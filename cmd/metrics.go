@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/metrics"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsPort    int
+	metricsAddress string
+	metricsCache   time.Duration
+	metricsDisk    bool
+)
+
+// MetricsServeResult is printed when the metrics server starts
+type MetricsServeResult struct {
+	Listen   string `json:"listen"`
+	URL      string `json:"url"`
+	CacheTTL string `json:"cacheTtl"`
+}
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Export host and VM metrics for Prometheus",
+	Long: `Export host and VM statistics in the Prometheus text format.
+
+Metrics:
+  quickvm_vm_state, quickvm_vm_running, quickvm_vm_cpu_usage_percent,
+  quickvm_vm_memory_assigned_bytes, quickvm_vm_uptime_seconds, quickvm_vm_snapshots
+  quickvm_host_cpu_cores, quickvm_host_cpu_load_percent, quickvm_host_memory_*_bytes,
+  quickvm_host_disk_*_bytes (--disk), quickvm_hyperv_enabled
+  quickvm_exports_total, quickvm_export_last_duration_seconds (from exports run by quickvm)
+  quickvm_scrape_error, quickvm_scrape_duration_seconds`,
+}
+
+var metricsServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve metrics over HTTP for Prometheus to scrape",
+	Long: `Serve metrics at /metrics for Prometheus to scrape.
+
+Collections are cached for --cache and concurrent scrapes share the collection in
+progress, so PowerShell runs at most once per cache period.
+
+Examples:
+  quickvm metrics serve                       # http://:9090/metrics
+  quickvm metrics serve --port 9182 --cache 30s
+  quickvm metrics serve --address 127.0.0.1 --disk`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		collector := newMetricsCollector()
+		listener, err := net.Listen("tcp", net.JoinHostPort(metricsAddress, strconv.Itoa(metricsPort)))
+		if err != nil {
+			printConsoleError("LISTEN_FAILED", "Failed to listen", err)
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler(collector))
+		mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, `<html><head><title>quickvm exporter</title></head><body><a href="/metrics">Metrics</a></body></html>`)
+		})
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+		result := MetricsServeResult{
+			Listen:   listener.Addr().String(),
+			URL:      "http://" + listener.Addr().String() + "/metrics",
+			CacheTTL: metricsCache.String(),
+		}
+		if output.IsJSON() {
+			output.PrintData(result)
+		} else {
+			fmt.Printf("📈 Serving metrics on %s (cache %s)\n", result.URL, result.CacheTTL)
+			fmt.Println("Press Ctrl+C to stop")
+		}
+
+		go func() {
+			<-cmd.Context().Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			printConsoleError("SERVE_FAILED", "Metrics server failed", err)
+		}
+	},
+}
+
+var metricsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the metrics once in the Prometheus text format",
+	Long: `Print the metrics once, e.g. for the node_exporter textfile collector.
+
+Examples:
+  quickvm metrics export
+  quickvm metrics export > C:\node_exporter\textfile\quickvm.prom`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := metrics.WriteText(os.Stdout, newMetricsCollector().Collect()); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write metrics: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func newMetricsCollector() *metrics.Collector {
+	collector := metrics.NewCollector(hyperv.NewManager(), metricsCache)
+	collector.IncludeDisk = metricsDisk
+	return collector
+}
+
+func init() {
+	metricsServeCmd.Flags().IntVar(&metricsPort, "port", 9090, "Port to listen on")
+	metricsServeCmd.Flags().StringVar(&metricsAddress, "address", "", "Address to listen on (default all interfaces)")
+	metricsServeCmd.Flags().DurationVar(&metricsCache, "cache", metrics.DefaultCacheTTL, "How long a collection is reused for later scrapes")
+	metricsCmd.PersistentFlags().BoolVar(&metricsDisk, "disk", false, "Include host disk metrics (slower)")
+	metricsCmd.AddCommand(metricsServeCmd)
+	metricsCmd.AddCommand(metricsExportCmd)
+	rootCmd.AddCommand(metricsCmd)
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"quickvm/internal/backup"
 	"quickvm/internal/hyperv"
+	"quickvm/internal/lab"
 	"quickvm/internal/output"
 )
 
@@ -47,12 +50,13 @@ type SnapshotOpResult struct {
 
 // ExportResult represents the result of an export operation
 type ExportResult struct {
-	VMName     string `json:"vmName"`
-	VMIndex    int    `json:"vmIndex"`
-	ExportPath string `json:"exportPath"`
-	Success    bool   `json:"success"`
-	Message    string `json:"message,omitempty"`
-	Error      string `json:"error,omitempty"`
+	VMName     string   `json:"vmName"`
+	VMIndex    int      `json:"vmIndex"`
+	ExportPath string   `json:"exportPath"`
+	Files      []string `json:"files,omitempty"` // Archive files (chunks) when exporting to an archive
+	Success    bool     `json:"success"`
+	Message    string   `json:"message,omitempty"`
+	Error      string   `json:"error,omitempty"`
 }
 
 // CloneResult represents the result of a clone operation
@@ -70,6 +74,7 @@ type RDPResult struct {
 	VMName    string `json:"vmName"`
 	VMIndex   int    `json:"vmIndex"`
 	IPAddress string `json:"ipAddress"`
+	SessionID int    `json:"sessionId,omitempty"`
 	Success   bool   `json:"success"`
 	Message   string `json:"message,omitempty"`
 	Error     string `json:"error,omitempty"`
@@ -180,3 +185,131 @@ func runVMBatchOperation(
 		fmt.Printf("\n📊 Summary: %d %s, %d failed\n", successCount, config.SuccessVerb, failCount)
 	}
 }
+
+// WorkspaceListResult represents the result of listing workspaces
+type WorkspaceListResult struct {
+	Workspaces []string `json:"workspaces"`
+	Total      int      `json:"total"`
+}
+
+// WorkspaceShowResult represents a workspace definition with its resolved start order
+type WorkspaceShowResult struct {
+	Workspace  *hyperv.Workspace `json:"workspace"`
+	StartOrder [][]string        `json:"startOrder,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// WorkspaceOpResult represents the result of a workspace create/edit/delete operation
+type WorkspaceOpResult struct {
+	Operation string   `json:"operation"`
+	Workspace string   `json:"workspace"`
+	VMs       []string `json:"vms,omitempty"`
+	Success   bool     `json:"success"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// WorkspaceRunResult represents the result of starting or stopping a workspace
+type WorkspaceRunResult struct {
+	Operation    string                     `json:"operation"`
+	Workspace    string                     `json:"workspace"`
+	Results      []hyperv.WorkspaceVMResult `json:"results"`
+	SuccessCount int                        `json:"successCount"`
+	FailCount    int                        `json:"failCount"`
+	SkippedCount int                        `json:"skippedCount"`
+	TotalCount   int                        `json:"totalCount"`
+}
+
+// BackupSetSummary describes a backup set without its chunk lists
+type BackupSetSummary struct {
+	ID          string    `json:"id"`
+	VMName      string    `json:"vmName"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Checkpoint  string    `json:"checkpoint,omitempty"`
+	Files       int       `json:"files"`
+	Size        int64     `json:"size"`
+	AddedBytes  int64     `json:"addedBytes"`
+	AddedChunks int       `json:"addedChunks"`
+}
+
+// BackupListResult represents the result of listing backup sets
+type BackupListResult struct {
+	Repository string             `json:"repository"`
+	Sets       []BackupSetSummary `json:"sets"`
+	Total      int                `json:"total"`
+}
+
+// BackupRestoreResult represents the result of restoring a backup set
+type BackupRestoreResult struct {
+	SetID      string `json:"setId"`
+	VMName     string `json:"vmName"`
+	ExportPath string `json:"exportPath"`
+	Success    bool   `json:"success"`
+}
+
+// BackupPruneResult represents the result of pruning a backup repository
+type BackupPruneResult struct {
+	Policy        backup.RetentionPolicy `json:"policy"`
+	DryRun        bool                   `json:"dryRun"`
+	Kept          []BackupSetSummary     `json:"kept"`
+	Removed       []BackupSetSummary     `json:"removed"`
+	RemovedChunks int                    `json:"removedChunks"`
+	FreedBytes    int64                  `json:"freedBytes"`
+}
+
+// TemplateListResult represents the result of listing templates
+type TemplateListResult struct {
+	Templates []*hyperv.Template `json:"templates"`
+	Total     int                `json:"total"`
+}
+
+// TemplateOpResult represents the result of a template operation
+type TemplateOpResult struct {
+	Operation string `json:"operation"`
+	Template  string `json:"template"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+}
+
+// CreateVMResult represents the result of creating a VM from a template
+type CreateVMResult struct {
+	Template    string           `json:"template"`
+	VMName      string           `json:"vmName"`
+	Linked      bool             `json:"linked"`
+	Provisioned *ProvisionResult `json:"provisioned,omitempty"`
+	Success     bool             `json:"success"`
+}
+
+// ProvisionResult represents the result of provisioning a VM
+type ProvisionResult struct {
+	VMName   string `json:"vmName"`
+	GuestOS  string `json:"guestOs,omitempty"`
+	ISOPath  string `json:"isoPath,omitempty"`
+	Started  bool   `json:"started"`
+	Detached bool   `json:"detached"`
+	Success  bool   `json:"success"`
+}
+
+// LabUpResult represents the result of building and starting a lab
+type LabUpResult struct {
+	*lab.UpResult
+	Start   *WorkspaceRunResult `json:"start,omitempty"`
+	Success bool                `json:"success"`
+}
+
+// LabDownResult represents the result of tearing down a lab
+type LabDownResult struct {
+	*lab.DownPlan
+	Success bool `json:"success"`
+}
+
+// SessionResult represents a console or RDP window that was opened
+type SessionResult struct {
+	Session *hyperv.Session `json:"session"`
+	Success bool            `json:"success"`
+}
+
+// SessionListResult represents open sessions, or the sessions that were closed
+type SessionListResult struct {
+	Sessions []hyperv.Session `json:"sessions"`
+	Total    int              `json:"total"`
+}
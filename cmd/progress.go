@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"quickvm/internal/archive"
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+)
+
+const progressBarWidth = 24
+
+// runWithProgress runs op with a progress channel and renders its events until op closes
+// the channel: a progress bar in table mode, one JSON object per line in JSON mode
+func runWithProgress(op func(events chan<- hyperv.ProgressEvent) error) error {
+	events := make(chan hyperv.ProgressEvent, 16)
+	errCh := make(chan error, 1)
+	go func() { errCh <- op(events) }()
+
+	bar := &progressBar{}
+	for event := range events {
+		if output.IsJSON() {
+			output.PrintEvent(event)
+			continue
+		}
+		bar.render(event)
+	}
+	bar.end()
+	return <-errCh
+}
+
+// progressBar redraws one terminal line per phase
+type progressBar struct {
+	phase  hyperv.ProgressPhase
+	active bool
+	width  int // Length of the last line, to blank out leftovers
+}
+
+func (b *progressBar) render(e hyperv.ProgressEvent) {
+	if e.Finished() {
+		b.end()
+		return
+	}
+	if e.Phase != b.phase {
+		b.end()
+		b.phase = e.Phase
+	}
+
+	line := formatProgressLine(e)
+	padding := ""
+	if n := len([]rune(line)); n < b.width {
+		padding = strings.Repeat(" ", b.width-n)
+	}
+	fmt.Printf("\r%s%s", line, padding)
+	b.width = len([]rune(line))
+	b.active = true
+}
+
+// end finishes the current line
+func (b *progressBar) end() {
+	if b.active {
+		fmt.Println()
+	}
+	b.active = false
+	b.width = 0
+}
+
+// formatProgressLine renders an event as "[████░░░░]  45.0%  1.2 GiB / 2.6 GiB  ETA 3m10s  exporting"
+func formatProgressLine(e hyperv.ProgressEvent) string {
+	if e.BytesTotal <= 0 {
+		if e.BytesDone > 0 {
+			return fmt.Sprintf("⏳ %s: %s", e.Phase, archive.FormatSize(e.BytesDone))
+		}
+		if e.Message != "" {
+			return fmt.Sprintf("⏳ %s: %s", e.Phase, e.Message)
+		}
+		return fmt.Sprintf("⏳ %s...", e.Phase)
+	}
+
+	filled := int(e.Percent / 100 * progressBarWidth)
+	if filled > progressBarWidth {
+		filled = progressBarWidth
+	}
+	line := fmt.Sprintf("[%s%s] %5.1f%%  %s / %s",
+		strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled),
+		e.Percent, archive.FormatSize(e.BytesDone), archive.FormatSize(e.BytesTotal))
+	if e.ETASeconds > 0 {
+		line += fmt.Sprintf("  ETA %s", time.Duration(e.ETASeconds)*time.Second)
+	}
+	return fmt.Sprintf("%s  %s", line, e.Phase)
+}
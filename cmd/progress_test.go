@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestFormatProgressLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		event hyperv.ProgressEvent
+		want  []string
+	}{
+		{
+			name: "known total",
+			event: hyperv.ProgressEvent{
+				Phase: hyperv.ProgressExporting, BytesDone: 512 << 20, BytesTotal: 2 << 30, Percent: 25, ETASeconds: 190,
+			},
+			want: []string{"[██████░░░░░░░░░░░░░░░░░░]", " 25.0%", "512.0 MiB / 2.0 GiB", "ETA 3m10s", "exporting"},
+		},
+		{
+			name:  "unknown total",
+			event: hyperv.ProgressEvent{Phase: hyperv.ProgressImporting, BytesDone: 4096},
+			want:  []string{"importing: 4.0 KiB"},
+		},
+		{
+			name:  "message only",
+			event: hyperv.ProgressEvent{Phase: hyperv.ProgressPreparing, Message: "reading VM configuration"},
+			want:  []string{"preparing: reading VM configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := formatProgressLine(tt.event)
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("Expected %q in %q", want, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+	"quickvm/internal/provision"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	provisionFile    string
+	provisionNoStart bool
+	provisionReady   string
+	provisionTimeout time.Duration
+)
+
+var provisionCmd = &cobra.Command{
+	Use:   "provision <vm> -f <provision.yaml>",
+	Short: "Configure a VM's first boot with a cloud-init or unattend ISO",
+	Long: `Generate first-boot configuration media from a provisioning file, attach it
+to the VM's DVD drive and start the VM.
+
+Linux guests get a cloud-init NoCloud seed ISO (label "cidata") with user-data,
+meta-data and network-config. Windows guests get an ISO with autounattend.xml.
+The VM must be off. Once the guest is ready the ISO is detached and deleted.
+
+Provisioning file:
+  os: linux                      # linux (default) or windows
+  hostname: web01
+  timezone: Europe/Berlin
+  users:
+    - name: alice
+      admin: true
+      sshKeys: ["ssh-ed25519 AAAA... alice@laptop"]
+      passwordEnv: ALICE_PASSWORD  # password is read from this variable
+  packages: [nginx]              # linux only
+  network:                       # omit for DHCP
+    address: 192.168.10.20/24
+    gateway: 192.168.10.1
+    dns: [192.168.10.1]
+  runCommands:
+    - systemctl enable --now nginx
+
+Subcommands:
+  detach  - Eject and delete the provisioning ISO
+
+Examples:
+  quickvm provision Web01 -f web.yaml
+  quickvm provision 3 -f dc.yaml --ready heartbeat --timeout 30m
+  quickvm provision Web01 -f web.yaml --no-start`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager := hyperv.NewManager()
+		vmName, err := resolveVMName(cmd.Context(), manager, args[0])
+		if err != nil {
+			printTemplateError("VM_GET_FAILED", "Failed to get VM", err)
+			return
+		}
+
+		result, err := provisionVM(cmd.Context(), manager, vmName, provisionFile)
+		if err != nil {
+			printProvisionError(vmName, err)
+			return
+		}
+		printProvisionResult(result)
+	},
+}
+
+var provisionDetachCmd = &cobra.Command{
+	Use:   "detach <vm>",
+	Short: "Eject and delete a VM's provisioning ISO",
+	Long: `Eject the provisioning ISO from the VM's DVD drive and delete it.
+
+Use this after 'quickvm provision --no-start', or when the guest did not become
+ready in time and the ISO was left attached.
+
+Examples:
+  quickvm provision detach Web01`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager := hyperv.NewManager()
+		vmName, err := resolveVMName(cmd.Context(), manager, args[0])
+		if err != nil {
+			printTemplateError("VM_GET_FAILED", "Failed to get VM", err)
+			return
+		}
+
+		detached, err := manager.DetachProvisioningISO(cmd.Context(), vmName)
+		if err != nil {
+			printTemplateError("PROVISION_DETACH_FAILED", "Failed to detach provisioning ISO", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(ProvisionResult{VMName: vmName, Detached: detached, Success: true})
+			return
+		}
+		if detached {
+			fmt.Printf("✅ Provisioning ISO detached from VM '%s'\n", vmName)
+		} else {
+			fmt.Printf("ℹ️  VM '%s' has no provisioning ISO attached\n", vmName)
+		}
+	},
+}
+
+// provisionVM builds the seed ISO from file and provisions vmName with it
+func provisionVM(ctx context.Context, manager *hyperv.Manager, vmName, file string) (ProvisionResult, error) {
+	result := ProvisionResult{VMName: vmName}
+	cfg, err := provision.Load(file)
+	if err != nil {
+		return result, err
+	}
+	img, err := cfg.Seed()
+	if err != nil {
+		return result, err
+	}
+
+	ready := provisionReady
+	if ready == "" {
+		ready = hyperv.ReadinessIP
+		if cfg.GuestOS() == provision.OSWindows {
+			ready = hyperv.ReadinessHeartbeat // Windows reboots during specialize; wait for it to settle
+		}
+	}
+	opts := hyperv.ProvisionOptions{
+		NoStart: provisionNoStart,
+		Ready:   &hyperv.ReadinessCheck{Type: ready, Timeout: hyperv.Duration(provisionTimeout)},
+	}
+
+	result.GuestOS = cfg.GuestOS()
+	if !output.IsJSON() {
+		fmt.Printf("💿 Provisioning VM '%s' (%s, %s)...\n", vmName, result.GuestOS, img.VolumeID)
+	}
+	result.ISOPath, err = manager.ProvisionVM(ctx, vmName, img, opts)
+	if err != nil {
+		return result, err
+	}
+	result.Started = !provisionNoStart
+	result.Detached = !provisionNoStart
+	result.Success = true
+	return result, nil
+}
+
+func printProvisionResult(result ProvisionResult) {
+	if output.IsJSON() {
+		output.PrintData(result)
+		return
+	}
+	if !result.Started {
+		fmt.Printf("✅ Provisioning ISO attached to VM '%s': %s\n", result.VMName, result.ISOPath)
+		fmt.Println("\n💡 Tips:")
+		fmt.Println("   - Start the VM to apply the configuration on its first boot")
+		fmt.Printf("   - Detach the ISO afterwards with: quickvm provision detach \"%s\"\n", result.VMName)
+		return
+	}
+	fmt.Printf("✅ VM '%s' provisioned and ready; provisioning ISO detached\n", result.VMName)
+}
+
+func printProvisionError(vmName string, err error) {
+	printTemplateError("PROVISION_FAILED", "Failed to provision VM", err)
+	if !output.IsJSON() {
+		fmt.Printf("\n💡 If the ISO is still attached, detach it with: quickvm provision detach \"%s\"\n", vmName)
+	}
+}
+
+func addProvisionWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&provisionNoStart, "no-start", false, "Only attach the ISO; do not start the VM")
+	cmd.Flags().StringVar(&provisionReady, "ready", "", "Readiness check before detaching: ip or heartbeat (default: ip for linux, heartbeat for windows)")
+	cmd.Flags().DurationVar(&provisionTimeout, "timeout", 0, "How long to wait for the guest (default 5m)")
+}
+
+func init() {
+	provisionCmd.Flags().StringVarP(&provisionFile, "file", "f", "", "Provisioning file (YAML)")
+	_ = provisionCmd.MarkFlagRequired("file")
+	addProvisionWaitFlags(provisionCmd)
+
+	provisionCmd.AddCommand(provisionDetachCmd)
+	rootCmd.AddCommand(provisionCmd)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import "testing"
+
+func TestProvisionCommandSetup(t *testing.T) {
+	if len(provisionCmd.Commands()) != 1 || provisionCmd.Commands()[0].Name() != "detach" {
+		t.Error("Expected a 'detach' subcommand on 'provision'")
+	}
+	for _, flag := range []string{"file", "no-start", "ready", "timeout"} {
+		if provisionCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag '%s' on 'provision'", flag)
+		}
+	}
+	if createCmd.Flags().Lookup("provision") == nil {
+		t.Error("Expected flag 'provision' on 'create'")
+	}
+}
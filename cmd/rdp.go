@@ -33,6 +33,8 @@ Credentials format:
 When password is provided, credentials are saved to Windows Credential Manager
 for seamless login.
 
+The RDP window is tracked as a session: 'quickvm sessions' lists and closes it.
+
 Examples:
   quickvm rdp 1                               # RDP into VM 1
   quickvm rdp 2 -u admin                      # RDP with username
@@ -81,7 +83,8 @@ Examples:
 			fmt.Println("🔐 Saving credentials to Windows Credential Manager...")
 		}
 
-		if err := manager.ConnectRDPByIP(cmd.Context(), ip, rdpCredentials); err != nil {
+		session, err := manager.OpenRDP(cmd.Context(), vmName, ip, rdpCredentials)
+		if err != nil {
 			output.PrintError("RDP_FAILED", "Failed to open RDP", err.Error())
 			if !output.IsJSON() {
 				fmt.Printf("❌ Failed to open RDP: %v\n", err)
@@ -95,13 +98,14 @@ Examples:
 				VMName:    vmName,
 				VMIndex:   index,
 				IPAddress: ip,
+				SessionID: session.ID,
 				Success:   true,
 				Message:   "RDP client opened successfully",
 			})
 			return
 		}
 
-		fmt.Println("✅ RDP client opened successfully!")
+		fmt.Printf("✅ RDP client opened successfully! (session %d)\n", session.ID)
 		fmt.Println()
 		fmt.Println("💡 Tips:")
 		fmt.Printf("   - IP address: %s\n", ip)
@@ -2,20 +2,27 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
 	"quickvm/internal/output"
+	"quickvm/internal/theme"
 	"quickvm/ui"
 	"quickvm/updater"
 )
 
 var (
-	autoUpdate   bool
-	outputFormat string
+	autoUpdate      bool
+	outputFormat    string
+	refreshInterval time.Duration
+	themeName       string
 )
 
 var rootCmd = &cobra.Command{
@@ -28,16 +35,20 @@ It provides a fast and intuitive interface for starting, stopping, and managing
 		if err := output.SetFormat(outputFormat); err != nil {
 			return fmt.Errorf("invalid output format: %w", err)
 		}
+		if err := theme.Select(themeName); err != nil {
+			return err
+		}
 
 		// Check for updates if --update flag is set
 		if autoUpdate && cmd.Name() != "update" {
-			checkAndUpdate()
+			checkAndUpdate(cmd.Context())
 		}
 		return nil
 	},
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		// Launch TUI
-		p := tea.NewProgram(ui.NewModel(), tea.WithAltScreen())
+		model := ui.NewModel().WithRefreshInterval(refreshInterval).WithCommands(paletteCommands(cmd.Root()))
+		p := tea.NewProgram(model, tea.WithAltScreen())
 		if _, err := p.Run(); err != nil {
 			fmt.Printf("Error running TUI: %v\n", err)
 			os.Exit(1)
@@ -45,9 +56,49 @@ It provides a fast and intuitive interface for starting, stopping, and managing
 	},
 }
 
+// paletteExcluded lists commands the TUI command palette does not offer because they
+// prompt on the terminal or take it over
+var paletteExcluded = map[string]bool{
+	"watch":          true,
+	"events":         true,
+	"update":         true,
+	"enable":         true,
+	"workspace edit": true,
+	"serve":          true,
+	"mcp":            true,
+	"metrics serve":  true,
+	"help":           true,
+	"completion":     true,
+}
+
+// paletteCommands returns every runnable subcommand of parent for the TUI command palette
+func paletteCommands(parent *cobra.Command, path ...string) []ui.Command {
+	var commands []ui.Command
+	for _, c := range parent.Commands() {
+		cmdPath := append(append([]string{}, path...), c.Name())
+		if c.Hidden || paletteExcluded[strings.Join(cmdPath, " ")] {
+			continue
+		}
+		if c.Runnable() {
+			commands = append(commands, ui.NewCommand(cmdPath, c.Short, c.Use))
+		}
+		commands = append(commands, paletteCommands(c, cmdPath...)...)
+	}
+	return commands
+}
+
 // Execute runs the root command.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl+C cancels the command context so long-running operations can roll back.
+	// The handler is removed after the first Ctrl+C, so a second one ends quickvm.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -56,10 +107,39 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&autoUpdate, "update", false, "Check for updates before running")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: json, table, text (json is AI-agent friendly)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "",
+		fmt.Sprintf("Colour theme: %s or a file in ~/.quickvm/themes (default $%s, then %s)",
+			strings.Join(theme.Names(), ", "), theme.EnvVar, theme.DefaultName))
+	rootCmd.Flags().DurationVar(&refreshInterval, "interval", ui.DefaultRefreshInterval, "TUI refresh interval (0 disables automatic refresh)")
+}
+
+// readResponse reads the answer to a prompt. Ctrl+C cancels ctx and with it the prompt,
+// which returns ctx's error without waiting for Enter.
+func readResponse(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	type answer struct {
+		response string
+		err      error
+	}
+	answers := make(chan answer, 1)
+	go func() {
+		var response string
+		_, err := fmt.Scanln(&response)
+		answers <- answer{response, err}
+	}()
+	select {
+	case a := <-answers:
+		return a.response, a.err
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
+	}
 }
 
 // checkAndUpdate checks for updates and prompts to install if available
-func checkAndUpdate() {
+func checkAndUpdate(ctx context.Context) {
 	u := updater.NewUpdater(Version)
 
 	release, hasUpdate, err := u.CheckForUpdates()
@@ -77,13 +157,13 @@ func checkAndUpdate() {
 	fmt.Printf("🎉 New version available: %s (current: %s)\n", release.TagName, Version)
 	fmt.Print("❓ Do you want to update now? [Y/n]: ")
 
-	var response string
-	if _, err := fmt.Scanln(&response); err != nil {
-		// Default to 'yes' if can't read input
+	response, err := readResponse(ctx)
+	if err != nil {
+		// Default to 'yes' if can't read input, but not after Ctrl+C
 		response = ""
 	}
 
-	if response == "n" || response == "N" {
+	if response == "n" || response == "N" || ctx.Err() != nil {
 		fmt.Println("⏭️  Continuing with current version...")
 		fmt.Println()
 		return
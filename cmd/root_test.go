@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestPaletteCommands(t *testing.T) {
+	byName := make(map[string]int)
+	for _, c := range paletteCommands(rootCmd) {
+		byName[c.Name()] = len(c.Args)
+	}
+
+	for name, args := range map[string]int{"snapshot create": 2, "rdp": 1, "list": 0, "workspace add": 2} {
+		if got, ok := byName[name]; !ok || got != args {
+			t.Errorf("Expected %q with %d arguments, got %d (present: %v)", name, args, got, ok)
+		}
+	}
+	for _, name := range []string{"watch", "events", "workspace edit", "workspace"} {
+		if _, ok := byName[name]; ok {
+			t.Errorf("Expected %q not to be offered", name)
+		}
+	}
+}
+
+func TestReadResponse(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+
+	if _, err := w.WriteString("n\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	if response, err := readResponse(context.Background()); err != nil || response != "n" {
+		t.Errorf("readResponse() = %q, %v, want n", response, err)
+	}
+
+	// Ctrl+C at a prompt cancels it
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := readResponse(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled prompt, got %v", err)
+	}
+}
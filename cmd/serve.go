@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+// apiTokenEnvVar is the environment variable holding the API token when --token is not given
+const apiTokenEnvVar = "QUICKVM_API_TOKEN"
+
+var (
+	serveListen string
+	serveToken  string
+)
+
+// ServeResult is printed when the API server starts
+type ServeResult struct {
+	Listen  string `json:"listen"`
+	BaseURL string `json:"baseUrl"`
+	Token   string `json:"token,omitempty"` // Only when generated
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a JSON REST API for managing VMs",
+	Long: `Run an HTTP server exposing VM, snapshot, export/import, workspace and system
+operations as a versioned JSON API under /api/v1.
+
+Every request except GET /api/v1/openapi.json needs the header
+'Authorization: Bearer <token>'. The token comes from --token or
+$QUICKVM_API_TOKEN; without either a random token is generated and printed.
+
+Responses use the same envelope as --json output. Exports, imports and workspace
+start/stop answer 202 Accepted with an operation to poll at
+/api/v1/operations/{id}; DELETE on an operation cancels it. GET /api/v1/events
+streams VM events as NDJSON, like 'quickvm events --follow'. Ctrl+C cancels the
+running operations and waits for them to roll back before the server exits.
+
+Examples:
+  quickvm serve                                # Listen on 127.0.0.1:7070
+  quickvm serve --listen 0.0.0.0:7070 --token s3cret
+  curl -H "Authorization: Bearer s3cret" http://127.0.0.1:7070/api/v1/vms`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		token := serveToken
+		if token == "" {
+			token = os.Getenv(apiTokenEnvVar)
+		}
+		generated := ""
+		if token == "" {
+			t, err := generateAPIToken()
+			if err != nil {
+				printConsoleError("TOKEN_FAILED", "Failed to generate API token", err)
+				return
+			}
+			token, generated = t, t
+		}
+
+		listener, err := net.Listen("tcp", serveListen)
+		if err != nil {
+			printConsoleError("LISTEN_FAILED", "Failed to listen", err)
+			return
+		}
+		api := newAPIServer(cmd.Context(), hyperv.NewManager(), token)
+		server := &http.Server{
+			Handler:           api.handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			// Ends event streams on Ctrl+C, which Shutdown would otherwise wait for
+			BaseContext: func(net.Listener) context.Context { return cmd.Context() },
+		}
+
+		result := ServeResult{
+			Listen:  listener.Addr().String(),
+			BaseURL: "http://" + listener.Addr().String() + apiPrefix,
+			Token:   generated,
+		}
+		if output.IsJSON() {
+			output.PrintData(result)
+		} else {
+			fmt.Printf("🌐 quickvm API listening on %s\n", result.BaseURL)
+			if generated != "" {
+				fmt.Printf("🔑 API token: %s\n", generated)
+			}
+			fmt.Printf("📄 OpenAPI document: %s/openapi.json\n", result.BaseURL)
+			fmt.Println("Press Ctrl+C to stop")
+		}
+
+		go func() {
+			<-cmd.Context().Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			printConsoleError("SERVE_FAILED", "API server failed", err)
+		}
+		// Running operations were cancelled with the command context; let them roll back
+		api.ops.wait()
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:7070", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "API token (default $"+apiTokenEnvVar+", or a generated one)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var sessionsCloseAll bool
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List console and RDP windows opened by quickvm",
+	Long: `List the console (vmconnect) and RDP (mstsc) windows opened by 'quickvm console'
+and 'quickvm rdp' that are still open. Closed windows are forgotten.
+
+State is kept in ~/.quickvm/sessions.json.`,
+	Aliases: []string{"session"},
+	Run: func(cmd *cobra.Command, _ []string) {
+		sessions, err := hyperv.NewManager().ListSessions(cmd.Context())
+		if err != nil {
+			printConsoleError("SESSION_LIST_FAILED", "Failed to list sessions", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(SessionListResult{Sessions: sessions, Total: len(sessions)})
+			return
+		}
+		if len(sessions) == 0 {
+			fmt.Println("ℹ️  No open sessions.")
+			return
+		}
+		fmt.Printf("%-4s %-8s %-25s %-18s %-8s %s\n", "ID", "Kind", "VM", "Target", "PID", "Started")
+		fmt.Println(strings.Repeat("=", 85))
+		for _, s := range sessions {
+			vmName := s.VMName
+			if vmName == "" {
+				vmName = "-"
+			}
+			fmt.Printf("%-4d %-8s %-25s %-18s %-8d %s\n", s.ID, s.Kind, vmName, s.Target, s.PID,
+				s.Started.Local().Format(time.DateTime))
+		}
+	},
+}
+
+var sessionsCloseCmd = &cobra.Command{
+	Use:   "close [session-id|vm-name...]",
+	Short: "Close console and RDP windows",
+	Long: `Close sessions by session ID or VM name, or every session with --all.
+
+Examples:
+  quickvm sessions close 3        # Close session 3
+  quickvm sessions close web01    # Close every window of web01
+  quickvm sessions close --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !sessionsCloseAll {
+			printConsoleError("INVALID_ARGS", "Invalid arguments", fmt.Errorf("give session IDs or VM names, or --all"))
+			return
+		}
+
+		closed, err := hyperv.NewManager().CloseSessions(cmd.Context(), func(s hyperv.Session) bool {
+			return sessionsCloseAll || sessionMatches(s, args)
+		})
+		if err != nil {
+			printConsoleError("SESSION_CLOSE_FAILED", "Failed to close sessions", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(SessionListResult{Sessions: closed, Total: len(closed)})
+			return
+		}
+		for _, s := range closed {
+			fmt.Printf("✅ Closed %s session %d (%s)\n", s.Kind, s.ID, s.Target)
+		}
+		if len(closed) == 0 {
+			fmt.Println("ℹ️  No matching open sessions.")
+		}
+	},
+}
+
+// sessionMatches reports whether a session has one of the IDs or VM names in args
+func sessionMatches(s hyperv.Session, args []string) bool {
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil && id == s.ID {
+			return true
+		}
+		if s.VMName != "" && strings.EqualFold(arg, s.VMName) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	sessionsCloseCmd.Flags().BoolVar(&sessionsCloseAll, "all", false, "Close every session")
+	sessionsCmd.AddCommand(sessionsCloseCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
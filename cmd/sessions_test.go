@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestSessionMatches(t *testing.T) {
+	session := hyperv.Session{ID: 3, VMName: "Web01"}
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"3"}, true},
+		{[]string{"web01"}, true},
+		{[]string{"1", "Db01"}, false},
+		{nil, false},
+	}
+	for _, tc := range tests {
+		if got := sessionMatches(session, tc.args); got != tc.want {
+			t.Errorf("sessionMatches(%v) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+	if sessionMatches(hyperv.Session{ID: 4}, []string{""}) {
+		t.Error("Expected a session without a VM name not to match an empty name")
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"quickvm/internal/archive"
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	templateDescription string
+	templateDeleteForce bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage the VM template library",
+	Long: `Manage VM templates stored in ~/.quickvm/templates.
+
+A template is an export of a prepared VM (e.g. a patched Windows Server dev box)
+with generalized metadata. Create new VMs from it with
+'quickvm create --from-template <name> <vm-name>', either as full copies or as
+linked clones that use the template's disks as differencing disk parents.
+
+Subcommands:
+  create  - Create a template from a VM
+  list    - List templates
+  show    - Show details of a template
+  delete  - Delete a template
+
+Examples:
+  quickvm template create 1 "win2022-dev" --description "Windows Server 2022 + VS Build Tools"
+  quickvm template list
+  quickvm create --from-template win2022-dev "Dev-Alice"`,
+}
+
+var templateCreateCmd = &cobra.Command{
+	Use:   "create <vm> <name>",
+	Short: "Create a template from a VM",
+	Long: `Export a VM into the template library.
+
+<vm> is a VM index or name. The VM must not have checkpoints. Shut the VM down
+(and generalize the guest, e.g. with sysprep, if VMs created from the template
+join a domain) before creating the template.
+
+Examples:
+  quickvm template create 1 "win2022-dev"
+  quickvm template create Web01 "web-base" --description "IIS + .NET 8"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager := hyperv.NewManager()
+		vmName, err := resolveVMName(cmd.Context(), manager, args[0])
+		if err != nil {
+			printTemplateError("VM_GET_FAILED", "Failed to get VM", err)
+			return
+		}
+
+		name := strings.TrimSpace(args[1])
+		if !output.IsJSON() {
+			fmt.Printf("📐 Creating template '%s' from VM '%s'...\n", name, vmName)
+		}
+
+		var t *hyperv.Template
+		err = runWithProgress(func(events chan<- hyperv.ProgressEvent) error {
+			var createErr error
+			t, createErr = manager.CreateTemplate(cmd.Context(), vmName, name, templateDescription, events)
+			return createErr
+		})
+		if err != nil {
+			printTemplateError("TEMPLATE_CREATE_FAILED", "Failed to create template", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(t)
+			return
+		}
+		fmt.Printf("✅ Template '%s' created (%s)\n", t.Name, archive.FormatSize(t.Size))
+		fmt.Printf("\n💡 Create a VM from it with: quickvm create --from-template \"%s\" <vm-name>\n", t.Name)
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		templates, err := hyperv.ListTemplates()
+		if err != nil {
+			printTemplateError("TEMPLATE_LIST_FAILED", "Failed to list templates", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(TemplateListResult{Templates: templates, Total: len(templates)})
+			return
+		}
+
+		if len(templates) == 0 {
+			fmt.Println("📭 No templates found")
+			fmt.Println("💡 Create one with: quickvm template create <vm> <name>")
+			return
+		}
+		fmt.Printf("%-20s %-4s %-5s %10s %10s %-20s %s\n", "NAME", "GEN", "CPU", "MEMORY", "SIZE", "CREATED", "DESCRIPTION")
+		for _, t := range templates {
+			fmt.Printf("%-20s %-4d %-5d %10s %10s %-20s %s\n", truncateString(t.Name, 20), t.Generation, t.ProcessorCount,
+				fmt.Sprintf("%d MB", t.MemoryStartupMB), archive.FormatSize(t.Size),
+				t.CreatedAt.Local().Format("2006-01-02 15:04:05"), t.Description)
+		}
+		fmt.Printf("\n📊 Total: %d templates\n", len(templates))
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show details of a template",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		t, err := hyperv.LoadTemplate(args[0])
+		if err != nil {
+			printTemplateError("TEMPLATE_NOT_FOUND", "Template not found", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(t)
+			return
+		}
+
+		fmt.Printf("📐 Template: %s\n", t.Name)
+		if t.Description != "" {
+			fmt.Printf("   Description: %s\n", t.Description)
+		}
+		fmt.Printf("   Created:     %s from '%s' on %s\n", t.CreatedAt.Local().Format("2006-01-02 15:04:05"), t.SourceVM, t.SourceHost)
+		fmt.Printf("   Generation:  %d\n", t.Generation)
+		fmt.Printf("   Processors:  %d\n", t.ProcessorCount)
+		fmt.Printf("   Memory:      %d MB\n", t.MemoryStartupMB)
+		fmt.Printf("   Size:        %s\n", archive.FormatSize(t.Size))
+		fmt.Printf("   Export:      %s\n", t.ExportPath())
+		if len(t.LinkedClones) > 0 {
+			fmt.Printf("   Linked VMs:  %s\n", strings.Join(t.LinkedClones, ", "))
+		}
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a template",
+	Long: `Delete a template from the library.
+
+Templates that still hold the parent disks of existing linked clones are only
+deleted with --force; those VMs cannot start afterwards.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager := hyperv.NewManager()
+		if err := manager.DeleteTemplate(cmd.Context(), args[0], templateDeleteForce); err != nil {
+			printTemplateError("TEMPLATE_DELETE_FAILED", "Failed to delete template", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(TemplateOpResult{Operation: "delete", Template: args[0], Success: true, Message: "Template deleted"})
+			return
+		}
+		fmt.Printf("🗑️  Template '%s' deleted\n", args[0])
+	},
+}
+
+func printTemplateError(code, message string, err error) {
+	output.PrintError(code, message, err.Error())
+	if !output.IsJSON() {
+		fmt.Printf("❌ %s: %v\n", message, err)
+	}
+}
+
+func init() {
+	templateCreateCmd.Flags().StringVarP(&templateDescription, "description", "d", "", "Template description")
+	templateDeleteCmd.Flags().BoolVarP(&templateDeleteForce, "force", "f", false, "Delete even if linked clones still use the template's disks")
+
+	templateCmd.AddCommand(templateCreateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateDeleteCmd)
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import "testing"
+
+func TestTemplateCommandSetup(t *testing.T) {
+	expected := map[string]bool{"create": false, "list": false, "show": false, "delete": false}
+	for _, sub := range templateCmd.Commands() {
+		if _, ok := expected[sub.Name()]; ok {
+			expected[sub.Name()] = true
+		}
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("Expected subcommand '%s' on 'template'", name)
+		}
+	}
+
+	for _, flag := range []string{"from-template", "linked", "vhd-path"} {
+		if createCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected flag '%s' on 'create'", flag)
+		}
+	}
+}
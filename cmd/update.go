@@ -19,7 +19,7 @@ var updateCmd = &cobra.Command{
 	Short: "Check for updates and install the latest version",
 	Long: `Check for new versions of QuickVM from GitHub releases.
 If a new version is available, download and install it automatically.`,
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		fmt.Println("🔍 Checking for updates...")
 
 		u := updater.NewUpdater(Version)
@@ -51,8 +51,8 @@ If a new version is available, download and install it automatically.`,
 
 		if !autoInstall {
 			fmt.Print("❓ Do you want to install this update? [y/N]: ")
-			var response string
-			if _, err := fmt.Scanln(&response); err != nil {
+			response, err := readResponse(cmd.Context())
+			if err != nil {
 				// Default to 'no' if can't read input
 				response = "n"
 			}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"quickvm/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchInterval is the refresh interval of 'watch' and 'list --watch'
+const defaultWatchInterval = 2 * time.Second
+
+var watchInterval time.Duration
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Monitor VMs in real time",
+	Long: `Open the TUI with a short refresh interval to monitor VMs in real time.
+
+State changes are highlighted for a few seconds and the CPU and memory columns
+show a sparkline of the recent history of each VM. For a plain-text view that
+works in any terminal, use 'quickvm list --watch'.
+
+Examples:
+  quickvm watch
+  quickvm watch --interval 5s`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if watchInterval <= 0 {
+			watchInterval = defaultWatchInterval
+		}
+		model := ui.NewModel().WithRefreshInterval(watchInterval).WithCommands(paletteCommands(cmd.Root()))
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Printf("Error running TUI: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "n", defaultWatchInterval, "Refresh interval")
+	rootCmd.AddCommand(watchCmd)
+}
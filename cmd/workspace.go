@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"time"
 
 	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+	"quickvm/internal/theme"
+	"quickvm/ui"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +32,12 @@ var wsListCmd = &cobra.Command{
 	Run: func(_ *cobra.Command, _ []string) {
 		names, err := hyperv.ListWorkspaces()
 		if err != nil {
-			fmt.Printf("❌ Failed to list workspaces: %v\n", err)
+			printWorkspaceError("WORKSPACE_LIST_FAILED", "Failed to list workspaces", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(WorkspaceListResult{Workspaces: names, Total: len(names)})
 			return
 		}
 
@@ -39,30 +54,106 @@ var wsListCmd = &cobra.Command{
 	},
 }
 
-var wsVms string
+var (
+	wsVms       string
+	wsForce     bool
+	wsStatusTUI bool
+)
+
 var wsCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new workspace",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new workspace from a comma-separated list of VM names.
+
+Every VM must exist in Hyper-V unless --force is given.
+
+Examples:
+  quickvm ws create Dev --vms "Proxy,WebApp,DB"
+  quickvm ws create Dev --vms "NotYetCreated" --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWsCreate(cmd.Context(), hyperv.NewManager(), args[0], splitVMList(wsVms), wsForce)
+	},
+}
+
+var wsAddCmd = &cobra.Command{
+	Use:   "add <name> <vm-name>...",
+	Short: "Add VMs to a workspace",
+	Long: `Add one or more VMs to an existing workspace.
+
+Every VM must exist in Hyper-V unless --force is given.
+
+Example:
+  quickvm ws add Dev "Cache" "Worker"`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWsAdd(cmd.Context(), hyperv.NewManager(), args[0], args[1:], wsForce)
+	},
+}
+
+var wsRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <vm-name>...",
+	Short: "Remove VMs from a workspace",
+	Long: `Remove one or more VMs from a workspace.
+
+Startup settings for the removed VMs, and dependencies on them, are removed too.
+
+Example:
+  quickvm ws remove Dev "Cache"`,
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(2),
 	Run: func(_ *cobra.Command, args []string) {
-		name := args[0]
-		vms := strings.Split(wsVms, ",")
-		for i, v := range vms {
-			vms[i] = strings.TrimSpace(v)
-		}
+		runWsRemove(args[0], args[1:])
+	},
+}
 
-		ws := &hyperv.Workspace{
-			Name:        name,
-			Description: "Created via CLI",
-			VMs:         vms,
+var wsRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new-name>",
+	Short: "Rename a workspace",
+	Args:  cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := hyperv.RenameWorkspace(args[0], args[1]); err != nil {
+			printWorkspaceError("WORKSPACE_RENAME_FAILED", "Failed to rename workspace", err)
+			return
 		}
 
-		if err := hyperv.SaveWorkspace(ws); err != nil {
-			fmt.Printf("❌ Failed to save workspace: %v\n", err)
+		if output.IsJSON() {
+			output.PrintData(WorkspaceOpResult{
+				Operation: "rename",
+				Workspace: args[1],
+				Success:   true,
+				Message:   fmt.Sprintf("Workspace renamed from '%s'", args[0]),
+			})
 			return
 		}
+		fmt.Printf("✅ Workspace '%s' renamed to '%s'.\n", args[0], args[1])
+	},
+}
+
+var wsEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a workspace YAML file in your editor",
+	Long: `Open a workspace YAML file in $VISUAL or $EDITOR (notepad on Windows, vi elsewhere).
+
+The file is validated when the editor closes: unknown keys, duplicate VMs,
+invalid startup settings and dependency cycles are rejected, and you can
+re-open the editor to fix them. The workspace is only saved if it is valid.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWsEdit(cmd.Context(), args[0])
+	},
+}
 
-		fmt.Printf("✅ Workspace '%s' created successfully!\n", name)
+var wsValidateCmd = &cobra.Command{
+	Use:   "validate <name>",
+	Short: "Check a workspace against the VMs in Hyper-V",
+	Long: `Check that every VM in a workspace exists in Hyper-V, that no VM is
+listed twice and that the startup configuration is valid.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !runWsValidate(cmd.Context(), hyperv.NewManager(), args[0]) {
+			os.Exit(1)
+		}
 	},
 }
 
@@ -73,7 +164,18 @@ var wsShowCmd = &cobra.Command{
 	Run: func(_ *cobra.Command, args []string) {
 		ws, err := hyperv.LoadWorkspace(args[0])
 		if err != nil {
-			fmt.Printf("❌ Failed to load workspace: %v\n", err)
+			printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+			return
+		}
+
+		layers, orderErr := ws.StartupOrder()
+
+		if output.IsJSON() {
+			result := WorkspaceShowResult{Workspace: ws, StartOrder: layers}
+			if orderErr != nil {
+				result.Error = orderErr.Error()
+			}
+			output.PrintData(result)
 			return
 		}
 
@@ -81,18 +183,131 @@ var wsShowCmd = &cobra.Command{
 		fmt.Printf("📝 Description: %s\n", ws.Description)
 		fmt.Println("🖥️  Virtual Machines:")
 		for _, vm := range ws.VMs {
-			fmt.Printf("  - %s\n", vm)
+			fmt.Printf("  - %s%s\n", vm, describeStartupPolicy(ws.Policy(vm)))
 		}
+
+		if orderErr != nil {
+			fmt.Printf("⚠️  Startup configuration problem: %v\n", orderErr)
+		} else if len(ws.Startup) > 0 {
+			fmt.Println("🔢 Start order:")
+			for i, layer := range layers {
+				fmt.Printf("  %d. %s\n", i+1, strings.Join(layer, ", "))
+			}
+		}
+	},
+}
+
+var wsStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show a live status dashboard for a workspace",
+	Long: `Join workspace members with live Hyper-V data (state, CPU, memory, uptime, IPs)
+and show totals, including memory committed to the workspace versus host memory.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ws, err := hyperv.LoadWorkspace(args[0])
+		if err != nil {
+			printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+			return
+		}
+
+		if wsStatusTUI {
+			p := tea.NewProgram(ui.NewWorkspaceModel(ws).WithCommands(paletteCommands(cmd.Root())), tea.WithAltScreen())
+			if _, err := p.Run(); err != nil {
+				fmt.Printf("Error running TUI: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		status, err := hyperv.NewManager().GetWorkspaceStatus(cmd.Context(), ws)
+		if err != nil {
+			printWorkspaceError("WORKSPACE_STATUS_FAILED", "Failed to get workspace status", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(status)
+			return
+		}
+		printWorkspaceStatus(status)
 	},
 }
 
+// printWorkspaceStatus renders a workspace status as a table followed by totals
+func printWorkspaceStatus(status *hyperv.WorkspaceStatus) {
+	fmt.Printf("📂 Workspace: %s\n", status.Workspace)
+	fmt.Println(strings.Repeat("=", 100))
+	fmt.Printf("%-7s %-25s %-12s %-8s %-12s %-18s %-15s\n",
+		"Index", "Name", "State", "CPU%", "Memory(MB)", "Uptime", "IP")
+	fmt.Println(strings.Repeat("=", 100))
+
+	for _, member := range status.Members {
+		if !member.Found {
+			fmt.Printf("%-7s %-25s %s %-8s %-12s %-18s %-15s\n",
+				"-", member.Name, theme.Active().State(member.State, 10), "-", "-", "-", "-")
+			continue
+		}
+
+		ip := "-"
+		if len(member.IPAddresses) > 0 {
+			ip = strings.Join(member.IPAddresses, ", ")
+		}
+
+		fmt.Printf("%-7d %-25s %s %-8d %-12d %-18s %-15s\n",
+			member.Index, member.Name, theme.Active().State(member.State, 10),
+			member.CPUUsage, member.MemoryMB, member.Uptime, ip)
+	}
+	fmt.Println(strings.Repeat("=", 100))
+
+	fmt.Printf("\n🟢 Running: %d/%d\n", status.Running, status.Total)
+	if status.Missing > 0 {
+		fmt.Printf("❓ Missing: %d (run 'quickvm ws validate %s')\n", status.Missing, status.Workspace)
+	}
+	fmt.Printf("⚙️  CPU: %d%%\n", status.TotalCPUUsage)
+	if status.HostError != "" {
+		fmt.Printf("💾 Memory: %d MB\n", status.MemoryMB)
+		fmt.Printf("⚠️  Could not read host memory: %s\n", status.HostError)
+		return
+	}
+	fmt.Printf("💾 Memory: %d MB of %d MB host (%.1f%%), %d MB free on host\n",
+		status.MemoryMB, status.HostMemoryMB, status.MemoryPercent, status.HostFreeMB)
+}
+
+// describeStartupPolicy renders a startup policy as a short suffix for `ws show`
+func describeStartupPolicy(policy hyperv.StartupPolicy) string {
+	var parts []string
+	if len(policy.DependsOn) > 0 {
+		parts = append(parts, "after: "+strings.Join(policy.DependsOn, ", "))
+	}
+	if policy.StartDelay > 0 {
+		parts = append(parts, "delay: "+time.Duration(policy.StartDelay).String())
+	}
+	if policy.Readiness != nil {
+		parts = append(parts, "ready: "+policy.Readiness.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
+
 var wsDeleteCmd = &cobra.Command{
 	Use:   "delete <name>",
 	Short: "Delete a workspace",
 	Args:  cobra.ExactArgs(1),
 	Run: func(_ *cobra.Command, args []string) {
 		if err := hyperv.DeleteWorkspace(args[0]); err != nil {
-			fmt.Printf("❌ Failed to delete workspace: %v\n", err)
+			printWorkspaceError("WORKSPACE_DELETE_FAILED", "Failed to delete workspace", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(WorkspaceOpResult{
+				Operation: "delete",
+				Workspace: args[0],
+				Success:   true,
+				Message:   "Workspace deleted",
+			})
 			return
 		}
 		fmt.Printf("✅ Workspace '%s' deleted.\n", args[0])
@@ -102,61 +317,457 @@ var wsDeleteCmd = &cobra.Command{
 var wsStartCmd = &cobra.Command{
 	Use:   "start <name>",
 	Short: "Start all VMs in a workspace",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		ws, err := hyperv.LoadWorkspace(args[0])
-		if err != nil {
-			fmt.Printf("❌ Failed to load workspace: %v\n", err)
-			return
-		}
+	Long: `Start all VMs in a workspace in dependency order.
 
-		manager := hyperv.NewManager()
-		fmt.Printf("🚀 Starting workspace '%s' (%d VMs)...\n", ws.Name, len(ws.VMs))
+VMs listed under "startup" in the workspace YAML can declare dependsOn,
+startDelay and a readiness check (heartbeat, ip, tcp or command). A VM is
+started only after its dependencies are ready; independent VMs start in parallel.
 
-		for _, vmName := range ws.VMs {
-			fmt.Printf("🚀 Starting VM: %s...\n", vmName)
-			if err := manager.StartVMByName(cmd.Context(), vmName); err != nil {
-				fmt.Printf("❌ Failed to start VM '%s': %v\n", vmName, err)
-			} else {
-				fmt.Printf("✅ VM '%s' started.\n", vmName)
-			}
-		}
+Example workspace file (~/.quickvm/workspaces/lab.yaml):
+  name: lab
+  vms: [DC01, SQL01, APP01]
+  startup:
+    SQL01:
+      dependsOn: [DC01]
+      readiness: {type: tcp, port: 1433, timeout: 5m}
+    APP01:
+      dependsOn: [SQL01]
+      startDelay: 30s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runWorkspaceOperation(cmd.Context(), args[0], "start")
 	},
 }
 
 var wsStopCmd = &cobra.Command{
 	Use:   "stop <name>",
 	Short: "Stop all VMs in a workspace",
-	Args:  cobra.ExactArgs(1),
+	Long: `Stop all VMs in a workspace in reverse dependency order.
+
+A VM is stopped only after every VM that depends on it has stopped.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		ws, err := hyperv.LoadWorkspace(args[0])
+		runWorkspaceOperation(cmd.Context(), args[0], "stop")
+	},
+}
+
+// runWorkspaceOperation starts or stops every member of a workspace in dependency order
+func runWorkspaceOperation(ctx context.Context, name, operation string) {
+	ws, err := hyperv.LoadWorkspace(name)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+		return
+	}
+
+	orchestrator := hyperv.NewWorkspaceOrchestrator(hyperv.NewManager())
+	if !output.IsJSON() {
+		orchestrator.OnEvent = printWorkspaceEvent
+	}
+
+	var results []hyperv.WorkspaceVMResult
+	successVerb := "started"
+	if operation == "stop" {
+		successVerb = "stopped"
+		if !output.IsJSON() {
+			fmt.Printf("🛑 Stopping workspace '%s' (%d VMs)...\n", ws.Name, len(ws.VMs))
+		}
+		results, err = orchestrator.Stop(ctx, ws)
+	} else {
+		if !output.IsJSON() {
+			fmt.Printf("🚀 Starting workspace '%s' (%d VMs)...\n", ws.Name, len(ws.VMs))
+		}
+		results, err = orchestrator.Start(ctx, ws)
+	}
+	if err != nil {
+		printWorkspaceError("WORKSPACE_INVALID", "Invalid workspace startup configuration", err)
+		return
+	}
+
+	summary := summarizeWorkspaceRun(operation, ws.Name, results)
+	if output.IsJSON() {
+		output.PrintData(summary)
+		return
+	}
+	fmt.Printf("\n📊 Summary: %d %s, %d failed, %d skipped\n",
+		summary.SuccessCount, successVerb, summary.FailCount, summary.SkippedCount)
+}
+
+// summarizeWorkspaceRun counts the outcomes of a workspace start or stop
+func summarizeWorkspaceRun(operation, name string, results []hyperv.WorkspaceVMResult) WorkspaceRunResult {
+	summary := WorkspaceRunResult{
+		Operation:  operation,
+		Workspace:  name,
+		Results:    results,
+		TotalCount: len(results),
+	}
+	for _, result := range results {
+		switch {
+		case result.Success:
+			summary.SuccessCount++
+		case result.Skipped:
+			summary.SkippedCount++
+		default:
+			summary.FailCount++
+		}
+	}
+	return summary
+}
+
+// printWorkspaceEvent prints orchestration progress for one workspace member
+func printWorkspaceEvent(event hyperv.WorkspaceEvent) {
+	switch event.Phase {
+	case hyperv.PhaseDelaying:
+		fmt.Printf("⏱️  Waiting %s before starting VM: %s\n", event.Message, event.VMName)
+	case hyperv.PhaseStarting:
+		fmt.Printf("🚀 Starting VM: %s...\n", event.VMName)
+	case hyperv.PhaseWaiting:
+		fmt.Printf("⏳ Waiting for VM '%s' to become ready (%s)...\n", event.VMName, event.Message)
+	case hyperv.PhaseReady:
+		fmt.Printf("✅ VM '%s' started.\n", event.VMName)
+	case hyperv.PhaseStopping:
+		fmt.Printf("🛑 Stopping VM: %s...\n", event.VMName)
+	case hyperv.PhaseStopped:
+		fmt.Printf("✅ VM '%s' stopped.\n", event.VMName)
+	case hyperv.PhaseFailed:
+		fmt.Printf("❌ VM '%s' failed: %s\n", event.VMName, event.Message)
+	case hyperv.PhaseSkipped:
+		fmt.Printf("⏭️  VM '%s' %s\n", event.VMName, event.Message)
+	}
+}
+
+// printWorkspaceError reports a workspace command failure in the active output format
+func printWorkspaceError(code, message string, err error) {
+	output.PrintError(code, message, err.Error())
+	if !output.IsJSON() {
+		fmt.Printf("❌ %s: %v\n", message, err)
+	}
+}
+
+// splitVMList splits a comma-separated VM list, dropping empty entries
+func splitVMList(list string) []string {
+	var vms []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vms = append(vms, v)
+		}
+	}
+	return vms
+}
+
+// checkVMsExist returns an error naming any VMs that are not registered in Hyper-V
+func checkVMsExist(ctx context.Context, manager hyperv.VMManager, names []string) error {
+	vms, err := manager.GetVMs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get VMs: %w", err)
+	}
+
+	existing := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		existing[vm.Name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !existing[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("VM(s) not found in Hyper-V: %s (use --force to add anyway)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func runWsCreate(ctx context.Context, manager hyperv.VMManager, name string, vms []string, force bool) {
+	if hyperv.WorkspaceExists(name) {
+		printWorkspaceError("WORKSPACE_EXISTS", "Failed to create workspace",
+			fmt.Errorf("workspace '%s' already exists (use 'quickvm ws add' to add VMs)", name))
+		return
+	}
+
+	ws := &hyperv.Workspace{
+		Name:        name,
+		Description: "Created via CLI",
+		VMs:         vms,
+	}
+	if err := ws.Validate(); err != nil {
+		printWorkspaceError("WORKSPACE_INVALID", "Invalid workspace", err)
+		return
+	}
+	if !force {
+		if err := checkVMsExist(ctx, manager, vms); err != nil {
+			printWorkspaceError("VM_NOT_FOUND", "Failed to create workspace", err)
+			return
+		}
+	}
+
+	if err := hyperv.SaveWorkspace(ws); err != nil {
+		printWorkspaceError("WORKSPACE_SAVE_FAILED", "Failed to save workspace", err)
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(WorkspaceOpResult{
+			Operation: "create",
+			Workspace: name,
+			VMs:       ws.VMs,
+			Success:   true,
+			Message:   "Workspace created",
+		})
+		return
+	}
+	fmt.Printf("✅ Workspace '%s' created successfully!\n", name)
+}
+
+func runWsAdd(ctx context.Context, manager hyperv.VMManager, name string, vms []string, force bool) {
+	ws, err := hyperv.LoadWorkspace(name)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+		return
+	}
+
+	if err := ws.AddVMs(vms...); err != nil {
+		printWorkspaceError("WORKSPACE_ADD_FAILED", "Failed to add VMs", err)
+		return
+	}
+	if !force {
+		if err := checkVMsExist(ctx, manager, vms); err != nil {
+			printWorkspaceError("VM_NOT_FOUND", "Failed to add VMs", err)
+			return
+		}
+	}
+
+	if err := hyperv.SaveWorkspace(ws); err != nil {
+		printWorkspaceError("WORKSPACE_SAVE_FAILED", "Failed to save workspace", err)
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(WorkspaceOpResult{
+			Operation: "add",
+			Workspace: ws.Name,
+			VMs:       ws.VMs,
+			Success:   true,
+			Message:   fmt.Sprintf("Added %d VM(s)", len(vms)),
+		})
+		return
+	}
+	fmt.Printf("✅ Added %s to workspace '%s' (%d VMs).\n", strings.Join(vms, ", "), ws.Name, len(ws.VMs))
+}
+
+func runWsRemove(name string, vms []string) {
+	ws, err := hyperv.LoadWorkspace(name)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+		return
+	}
+
+	if err := ws.RemoveVMs(vms...); err != nil {
+		printWorkspaceError("WORKSPACE_REMOVE_FAILED", "Failed to remove VMs", err)
+		return
+	}
+
+	if err := hyperv.SaveWorkspace(ws); err != nil {
+		printWorkspaceError("WORKSPACE_SAVE_FAILED", "Failed to save workspace", err)
+		return
+	}
+
+	if output.IsJSON() {
+		output.PrintData(WorkspaceOpResult{
+			Operation: "remove",
+			Workspace: ws.Name,
+			VMs:       ws.VMs,
+			Success:   true,
+			Message:   fmt.Sprintf("Removed %d VM(s)", len(vms)),
+		})
+		return
+	}
+	fmt.Printf("✅ Removed %s from workspace '%s' (%d VMs left).\n", strings.Join(vms, ", "), ws.Name, len(ws.VMs))
+}
+
+// runWsValidate checks a workspace against the live VM inventory and reports whether it is valid
+func runWsValidate(ctx context.Context, manager hyperv.VMManager, name string) bool {
+	ws, err := hyperv.LoadWorkspace(name)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+		return false
+	}
+
+	vms, err := manager.GetVMs(ctx)
+	if err != nil {
+		printWorkspaceError("VM_GET_FAILED", "Failed to get VMs", err)
+		return false
+	}
+
+	result := ws.CheckMembers(vms)
+
+	if output.IsJSON() {
+		output.PrintData(result)
+		return result.Valid
+	}
+
+	if result.Valid {
+		fmt.Printf("✅ Workspace '%s' is valid (%d VMs).\n", ws.Name, len(ws.VMs))
+		return true
+	}
+
+	fmt.Printf("❌ Workspace '%s' has problems:\n", ws.Name)
+	for _, vm := range result.Missing {
+		fmt.Printf("  - Missing VM: %s (not found in Hyper-V)\n", vm)
+	}
+	for _, vm := range result.Duplicates {
+		fmt.Printf("  - Duplicate VM: %s (listed more than once)\n", vm)
+	}
+	for _, problem := range result.Errors {
+		fmt.Printf("  - %s\n", problem)
+	}
+	fmt.Printf("\n💡 Fix with: quickvm ws remove %s <vm> or quickvm ws edit %s\n", ws.Name, ws.Name)
+	return false
+}
+
+// launchEditor opens path in the user's editor and waits for it to exit.
+// It is a variable so tests can replace it.
+var launchEditor = func(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// EDITOR may include arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
+
+// confirmReedit asks whether to re-open the editor after a validation error.
+// It is a variable so tests can replace it.
+var confirmReedit = func(ctx context.Context) bool {
+	fmt.Print("❓ Re-open the editor to fix it? [Y/n]: ")
+	response, err := readResponse(ctx)
+	if err != nil {
+		response = ""
+	}
+	return response != "n" && response != "N" && ctx.Err() == nil
+}
+
+//nolint:funlen // Edit/validate loop with dual output mode
+func runWsEdit(ctx context.Context, name string) {
+	path, err := hyperv.GetWorkspacePath(name)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+		return
+	}
+
+	//nolint:gosec // G304: Path comes from the workspace directory
+	original, err := os.ReadFile(path)
+	if err != nil {
+		printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to read workspace", err)
+		return
+	}
+
+	// Edit a temporary copy so an invalid file never replaces the saved workspace
+	tmp, err := os.CreateTemp("", "quickvm-ws-*.yaml")
+	if err != nil {
+		printWorkspaceError("WORKSPACE_EDIT_FAILED", "Failed to create temporary file", err)
+		return
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		printWorkspaceError("WORKSPACE_EDIT_FAILED", "Failed to write temporary file", err)
+		return
+	}
+
+	for {
+		if err := launchEditor(tmpPath); err != nil {
+			printWorkspaceError("WORKSPACE_EDIT_FAILED", "Failed to open editor", err)
+			return
+		}
+
+		//nolint:gosec // G304: Path is our own temporary file
+		edited, err := os.ReadFile(tmpPath)
 		if err != nil {
-			fmt.Printf("❌ Failed to load workspace: %v\n", err)
+			printWorkspaceError("WORKSPACE_EDIT_FAILED", "Failed to read edited file", err)
 			return
 		}
 
-		manager := hyperv.NewManager()
-		fmt.Printf("🛑 Stopping workspace '%s' (%d VMs)...\n", ws.Name, len(ws.VMs))
+		if bytes.Equal(edited, original) {
+			if output.IsJSON() {
+				output.PrintData(WorkspaceOpResult{Operation: "edit", Workspace: name, Success: true, Message: "No changes made"})
+				return
+			}
+			fmt.Println("ℹ️  No changes made.")
+			return
+		}
 
-		for _, vmName := range ws.VMs {
-			fmt.Printf("🛑 Stopping VM: %s...\n", vmName)
-			// Need StopVMByName
-			if err := manager.StopVMByName(cmd.Context(), vmName); err != nil {
-				fmt.Printf("❌ Failed to stop VM '%s': %v\n", vmName, err)
-			} else {
-				fmt.Printf("✅ VM '%s' stopped.\n", vmName)
+		ws, err := hyperv.ParseWorkspace(edited)
+		if err == nil && ws.Name != name {
+			err = fmt.Errorf("workspace name cannot be changed in the editor (found '%s'); use 'quickvm ws rename'", ws.Name)
+		}
+		if err != nil {
+			if !output.IsJSON() {
+				fmt.Printf("❌ Invalid workspace: %v\n", err)
+			}
+			if output.IsJSON() || !confirmReedit(ctx) {
+				printWorkspaceError("WORKSPACE_INVALID", "Changes discarded", err)
+				return
 			}
+			continue
 		}
-	},
+
+		// Save the edited text as-is so the user's comments and formatting are kept
+		if err := os.WriteFile(path, edited, 0600); err != nil {
+			printWorkspaceError("WORKSPACE_SAVE_FAILED", "Failed to save workspace", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(WorkspaceOpResult{
+				Operation: "edit",
+				Workspace: ws.Name,
+				VMs:       ws.VMs,
+				Success:   true,
+				Message:   "Workspace saved",
+			})
+			return
+		}
+		fmt.Printf("✅ Workspace '%s' saved.\n", ws.Name)
+		return
+	}
 }
 
 func init() {
 	wsCreateCmd.Flags().StringVarP(&wsVms, "vms", "v", "", "Comma-separated list of VM names")
 	_ = wsCreateCmd.MarkFlagRequired("vms")
+	wsCreateCmd.Flags().BoolVarP(&wsForce, "force", "f", false, "Skip checking that the VMs exist in Hyper-V")
+	wsAddCmd.Flags().BoolVarP(&wsForce, "force", "f", false, "Skip checking that the VMs exist in Hyper-V")
+	wsStatusCmd.Flags().BoolVar(&wsStatusTUI, "tui", false, "Open the interactive TUI filtered to the workspace")
 
 	workspaceCmd.AddCommand(wsListCmd)
 	workspaceCmd.AddCommand(wsCreateCmd)
 	workspaceCmd.AddCommand(wsShowCmd)
+	workspaceCmd.AddCommand(wsStatusCmd)
+	workspaceCmd.AddCommand(wsAddCmd)
+	workspaceCmd.AddCommand(wsRemoveCmd)
+	workspaceCmd.AddCommand(wsRenameCmd)
+	workspaceCmd.AddCommand(wsEditCmd)
+	workspaceCmd.AddCommand(wsValidateCmd)
 	workspaceCmd.AddCommand(wsDeleteCmd)
 	workspaceCmd.AddCommand(wsStartCmd)
 	workspaceCmd.AddCommand(wsStopCmd)
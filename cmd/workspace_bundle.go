@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	wsImportPrefix     string
+	wsImportName       string
+	wsImportVHDPath    string
+	wsImportSkipVerify bool
+	wsImportForce      bool
+)
+
+var wsExportCmd = &cobra.Command{
+	Use:   "export <name> <dir>",
+	Short: "Export a workspace and all its VMs as a shareable bundle",
+	Long: `Export every VM in a workspace into a bundle directory.
+
+The bundle contains each member's Export-VM output, the workspace definition
+and a manifest (quickvm-bundle.json) with VM specs, SHA-256 checksums of every
+file and the quickvm version. Copy the directory to another host and run
+'quickvm ws import <dir>' to recreate the environment. If the export fails,
+what it wrote into the directory is removed again.
+
+Example:
+  quickvm ws export lab "D:\Bundles\lab"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ws, err := hyperv.LoadWorkspace(args[0])
+		if err != nil {
+			printWorkspaceError("WORKSPACE_LOAD_FAILED", "Failed to load workspace", err)
+			return
+		}
+
+		dir, err := filepath.Abs(args[1])
+		if err != nil {
+			printWorkspaceError("PATH_ERROR", "Invalid bundle path", err)
+			return
+		}
+
+		opts := hyperv.BundleExportOptions{QuickVMVersion: Version}
+		if !output.IsJSON() {
+			fmt.Printf("📦 Exporting workspace '%s' (%d VMs) to '%s'...\n", ws.Name, len(ws.VMs), dir)
+			fmt.Println("⏳ This may take a while depending on VM size...")
+			opts.OnProgress = printBundleProgress
+		}
+
+		manifest, err := hyperv.NewManager().ExportWorkspaceBundle(cmd.Context(), ws, dir, opts)
+		if err != nil {
+			printWorkspaceError("WORKSPACE_EXPORT_FAILED", "Failed to export workspace", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(manifest)
+			return
+		}
+
+		fileCount := 0
+		for _, vm := range manifest.VMs {
+			fileCount += len(vm.Files)
+		}
+		fmt.Printf("\n✅ Workspace '%s' exported: %d VMs, %d files\n", ws.Name, len(manifest.VMs), fileCount)
+		fmt.Printf("📁 Bundle location: %s\n", dir)
+		fmt.Printf("\n💡 Import on another host with: quickvm ws import \"%s\"\n", dir)
+	},
+}
+
+var wsImportCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Import a workspace bundle",
+	Long: `Import every VM in a workspace bundle and recreate the workspace.
+
+Checksums are verified before anything is imported. VMs are copied into
+Hyper-V with new IDs; use --prefix to avoid name conflicts with existing VMs.
+If any import fails, the VMs imported so far are removed.
+
+Examples:
+  quickvm ws import "D:\Bundles\lab"
+  quickvm ws import "D:\Bundles\lab" --prefix "alice-" --name alice-lab`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			printWorkspaceError("PATH_ERROR", "Invalid bundle path", err)
+			return
+		}
+
+		opts := hyperv.BundleImportOptions{
+			Prefix:        wsImportPrefix,
+			WorkspaceName: wsImportName,
+			VHDPath:       wsImportVHDPath,
+			SkipVerify:    wsImportSkipVerify,
+			Force:         wsImportForce,
+		}
+		if !output.IsJSON() {
+			fmt.Printf("📦 Importing workspace bundle from '%s'...\n", dir)
+			if !wsImportSkipVerify {
+				fmt.Println("🔍 Verifying checksums...")
+			}
+			opts.OnProgress = printBundleProgress
+		}
+
+		result, err := hyperv.NewManager().ImportWorkspaceBundle(cmd.Context(), dir, opts)
+		if err != nil {
+			printWorkspaceError("WORKSPACE_IMPORT_FAILED", "Failed to import workspace", err)
+			return
+		}
+
+		if output.IsJSON() {
+			output.PrintData(result)
+			return
+		}
+
+		originals := make([]string, 0, len(result.VMs))
+		for name := range result.VMs {
+			originals = append(originals, name)
+		}
+		sort.Strings(originals)
+
+		fmt.Printf("\n✅ Workspace '%s' imported with %d VMs:\n", result.Workspace, len(result.VMs))
+		for _, name := range originals {
+			if result.VMs[name] == name {
+				fmt.Printf("  - %s\n", name)
+			} else {
+				fmt.Printf("  - %s (from %s)\n", result.VMs[name], name)
+			}
+		}
+		fmt.Printf("\n💡 Start it with: quickvm ws start %s\n", result.Workspace)
+	},
+}
+
+// printBundleProgress prints a progress line for a bundle export/import step
+func printBundleProgress(vmName, message string) {
+	fmt.Printf("  ⏳ %s: %s...\n", vmName, message)
+}
+
+func init() {
+	wsImportCmd.Flags().StringVar(&wsImportPrefix, "prefix", "", "Prefix added to every imported VM name")
+	wsImportCmd.Flags().StringVar(&wsImportName, "name", "", "Name for the recreated workspace (defaults to the bundle's)")
+	wsImportCmd.Flags().StringVarP(&wsImportVHDPath, "vhd-path", "v", "", "Custom destination path for VHD files")
+	wsImportCmd.Flags().BoolVar(&wsImportSkipVerify, "skip-verify", false, "Skip checksum verification")
+	wsImportCmd.Flags().BoolVarP(&wsImportForce, "force", "f", false, "Overwrite an existing workspace with the same name")
+
+	workspaceCmd.AddCommand(wsExportCmd)
+	workspaceCmd.AddCommand(wsImportCmd)
+}
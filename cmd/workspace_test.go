@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+// setTestHome points the user home directory at a fresh temp dir for the duration of a test
+func setTestHome(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+}
+
+func newInventoryManager(names ...string) *MockManager {
+	return &MockManager{
+		GetVMsFn: func(_ context.Context) ([]hyperv.VM, error) {
+			vms := make([]hyperv.VM, 0, len(names))
+			for i, name := range names {
+				vms = append(vms, hyperv.VM{Index: i + 1, Name: name})
+			}
+			return vms, nil
+		},
+	}
+}
+
+func TestRunWsCreate(t *testing.T) {
+	setTestHome(t)
+	manager := newInventoryManager("DC01", "SQL01")
+
+	runWsCreate(context.Background(), manager, "missing", []string{"DC01", "Ghost"}, false)
+	if hyperv.WorkspaceExists("missing") {
+		t.Error("Workspace with unknown VMs should not be created without --force")
+	}
+
+	runWsCreate(context.Background(), manager, "forced", []string{"Ghost"}, true)
+	if !hyperv.WorkspaceExists("forced") {
+		t.Error("Workspace should be created with --force")
+	}
+
+	runWsCreate(context.Background(), manager, "lab", []string{"DC01", "SQL01"}, false)
+	ws, err := hyperv.LoadWorkspace("lab")
+	if err != nil {
+		t.Fatalf("Expected workspace to be created: %v", err)
+	}
+	if len(ws.VMs) != 2 {
+		t.Errorf("Expected 2 VMs, got %v", ws.VMs)
+	}
+
+	// Creating again must not overwrite the existing workspace
+	runWsCreate(context.Background(), manager, "lab", []string{"DC01"}, false)
+	ws, _ = hyperv.LoadWorkspace("lab")
+	if len(ws.VMs) != 2 {
+		t.Errorf("Existing workspace was overwritten: %v", ws.VMs)
+	}
+}
+
+func TestRunWsAddRemove(t *testing.T) {
+	setTestHome(t)
+	manager := newInventoryManager("DC01", "SQL01", "APP01")
+
+	if err := hyperv.SaveWorkspace(&hyperv.Workspace{Name: "lab", VMs: []string{"DC01"}}); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+
+	runWsAdd(context.Background(), manager, "lab", []string{"Ghost"}, false)
+	runWsAdd(context.Background(), manager, "lab", []string{"SQL01", "APP01"}, false)
+
+	ws, _ := hyperv.LoadWorkspace("lab")
+	if len(ws.VMs) != 3 || ws.HasVM("Ghost") {
+		t.Errorf("Expected DC01, SQL01, APP01 after add, got %v", ws.VMs)
+	}
+
+	runWsRemove("lab", []string{"SQL01"})
+	ws, _ = hyperv.LoadWorkspace("lab")
+	if len(ws.VMs) != 2 || ws.HasVM("SQL01") {
+		t.Errorf("Expected SQL01 to be removed, got %v", ws.VMs)
+	}
+}
+
+func TestRunWsValidate(t *testing.T) {
+	setTestHome(t)
+	manager := newInventoryManager("DC01")
+
+	_ = hyperv.SaveWorkspace(&hyperv.Workspace{Name: "good", VMs: []string{"DC01"}})
+	_ = hyperv.SaveWorkspace(&hyperv.Workspace{Name: "bad", VMs: []string{"DC01", "Ghost"}})
+
+	if !runWsValidate(context.Background(), manager, "good") {
+		t.Error("Expected 'good' workspace to validate")
+	}
+	if runWsValidate(context.Background(), manager, "bad") {
+		t.Error("Expected 'bad' workspace to fail validation")
+	}
+	if runWsValidate(context.Background(), manager, "nope") {
+		t.Error("Expected missing workspace to fail validation")
+	}
+
+	failing := &MockManager{GetVMsFn: func(_ context.Context) ([]hyperv.VM, error) {
+		return nil, fmt.Errorf("hyper-v error")
+	}}
+	if runWsValidate(context.Background(), failing, "good") {
+		t.Error("Expected validation to fail when VMs cannot be listed")
+	}
+}
+
+func TestRunWsEdit(t *testing.T) {
+	setTestHome(t)
+	_ = hyperv.SaveWorkspace(&hyperv.Workspace{Name: "lab", VMs: []string{"DC01"}})
+
+	origLaunch, origConfirm := launchEditor, confirmReedit
+	defer func() { launchEditor, confirmReedit = origLaunch, origConfirm }()
+
+	// First edit is invalid (cycle), the user re-opens the editor and fixes it
+	edits := []string{
+		"name: lab\nvms: [DC01, SQL01]\nstartup:\n  DC01: {dependsOn: [SQL01]}\n  SQL01: {dependsOn: [DC01]}\n",
+		"# lab environment\nname: lab\nvms: [DC01, SQL01]\nstartup:\n  SQL01: {dependsOn: [DC01]}\n",
+	}
+	calls := 0
+	launchEditor = func(path string) error {
+		content := edits[calls]
+		calls++
+		return os.WriteFile(path, []byte(content), 0600)
+	}
+	confirmReedit = func(context.Context) bool { return true }
+
+	runWsEdit(context.Background(), "lab")
+
+	if calls != 2 {
+		t.Fatalf("Expected editor to open twice, opened %d times", calls)
+	}
+	ws, err := hyperv.LoadWorkspace("lab")
+	if err != nil {
+		t.Fatalf("LoadWorkspace failed: %v", err)
+	}
+	if len(ws.VMs) != 2 || len(ws.Policy("SQL01").DependsOn) != 1 {
+		t.Errorf("Edited workspace not saved: %+v", ws)
+	}
+
+	path, _ := hyperv.GetWorkspacePath("lab")
+	data, _ := os.ReadFile(path)
+	if string(data) != edits[1] {
+		t.Errorf("Expected edited text to be saved verbatim, got:\n%s", data)
+	}
+}
+
+func TestRunWsEdit_InvalidDiscarded(t *testing.T) {
+	setTestHome(t)
+	_ = hyperv.SaveWorkspace(&hyperv.Workspace{Name: "lab", VMs: []string{"DC01"}})
+
+	origLaunch, origConfirm := launchEditor, confirmReedit
+	defer func() { launchEditor, confirmReedit = origLaunch, origConfirm }()
+
+	launchEditor = func(path string) error {
+		return os.WriteFile(path, []byte("name: renamed\nvms: [DC01]\n"), 0600)
+	}
+	confirmReedit = func(context.Context) bool { return false }
+
+	runWsEdit(context.Background(), "lab")
+
+	ws, err := hyperv.LoadWorkspace("lab")
+	if err != nil || ws.Name != "lab" {
+		t.Errorf("Workspace should be unchanged after discarded edit: %+v, %v", ws, err)
+	}
+}
+
+func TestWorkspaceCommandSetup(t *testing.T) {
+	expected := []string{"list", "create", "show", "status", "add", "remove", "rename", "edit", "validate", "delete", "start", "stop", "export", "import"}
+	for _, name := range expected {
+		found := false
+		for _, sub := range workspaceCmd.Commands() {
+			if sub.Name() == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected workspace subcommand '%s' to be registered", name)
+		}
+	}
+
+	if wsStatusCmd.Flags().Lookup("tui") == nil {
+		t.Error("Expected flag 'tui' on 'ws status'")
+	}
+	if wsAddCmd.Flags().Lookup("force") == nil {
+		t.Error("Expected flag 'force' on 'ws add'")
+	}
+}
@@ -0,0 +1,521 @@
+// Package archive packs directories into zip or tar.zst archives and extracts them safely.
+// Archives can be split into fixed-size chunks (e.g. for FAT32 drives).
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format is an archive format
+type Format string
+
+const (
+	// FormatZip is a zip archive (deflate)
+	FormatZip Format = "zip"
+	// FormatTarZst is a zstandard-compressed tar archive
+	FormatTarZst Format = "tar.zst"
+)
+
+// FAT32ChunkSize is the largest chunk that fits on a FAT32 file system (4 GiB - 1)
+const FAT32ChunkSize = 4<<30 - 1
+
+// ParseFormat parses an archive format name
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "zip":
+		return FormatZip, nil
+	case "tar.zst", "tzst", "zst":
+		return FormatTarZst, nil
+	default:
+		return "", fmt.Errorf("invalid archive format: %s (valid: zip, tar.zst)", s)
+	}
+}
+
+// Ext returns the file extension for the format, including the leading dot
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// DetectFormat determines the format of an archive (or its first chunk) from its file name.
+// It returns false if path does not look like an archive.
+func DetectFormat(path string) (Format, bool) {
+	name := strings.ToLower(archiveBaseName(path))
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, true
+	case strings.HasSuffix(name, ".tar.zst"), strings.HasSuffix(name, ".tzst"):
+		return FormatTarZst, true
+	default:
+		return "", false
+	}
+}
+
+// TrimExt removes the archive extension (and chunk suffix) from a file name
+func TrimExt(path string) string {
+	name := archiveBaseName(path)
+	for _, ext := range []string{".tar.zst", ".tzst", ".zip"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// ParseSize parses a chunk size such as "4000M", "2G", "650MB" or "fat32"
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	if value == "FAT32" {
+		return FAT32ChunkSize, nil
+	}
+
+	value = strings.TrimSuffix(value, "B")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size: %s (examples: 4000M, 2G, fat32)", s)
+	}
+	return n * multiplier, nil
+}
+
+// FormatSize renders a byte count using binary units
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Progress reports how far an archive operation has got
+type Progress struct {
+	File  string `json:"file"`  // File currently being processed
+	Done  int64  `json:"done"`  // Bytes processed so far
+	Total int64  `json:"total"` // Total bytes to process
+}
+
+// Options contains options for creating an archive
+type Options struct {
+	Format     Format
+	ChunkSize  int64          // Split the archive into chunks of this size; 0 writes a single file
+	OnProgress func(Progress) // Optional: called as data is written
+}
+
+// EstimateSize returns the total size of the regular files under dir
+func EstimateSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat '%s': %w", d.Name(), err)
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
+
+// Create packs srcDir into an archive at dest. Entries are stored under the base name
+// of srcDir so extraction recreates the directory. It returns the files written,
+// which are dest itself or its chunks (dest.001, dest.002, ...).
+// Partially written files are removed if an error occurs or ctx is cancelled.
+func Create(ctx context.Context, srcDir, dest string, opts Options) ([]string, error) {
+	total, err := EstimateSize(srcDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", srcDir, err)
+	}
+
+	out := newChunkWriter(dest, opts.ChunkSize)
+	var entries entryWriter
+	switch opts.Format {
+	case FormatZip:
+		entries = newZipEntryWriter(out)
+	case FormatTarZst:
+		entries, err = newTarZstEntryWriter(out)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid archive format: %s", opts.Format)
+	}
+
+	progress := &progressCounter{ctx: ctx, total: total, onProgress: opts.OnProgress}
+	if err := writeEntries(srcDir, entries, progress); err != nil {
+		_ = entries.Close()
+		out.remove()
+		return nil, err
+	}
+	if err := entries.Close(); err != nil {
+		out.remove()
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		out.remove()
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return out.files, nil
+}
+
+// writeEntries adds every directory and regular file under srcDir to the archive
+func writeEntries(srcDir string, entries entryWriter, progress *progressCounter) error {
+	root := filepath.Dir(srcDir)
+	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil // Symlinks and special files are not archived
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve '%s': %w", path, err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat '%s': %w", path, err)
+		}
+
+		name := filepath.ToSlash(rel)
+		if d.IsDir() {
+			return entries.WriteDir(name, info)
+		}
+
+		w, err := entries.WriteFile(name, info)
+		if err != nil {
+			return err
+		}
+		//nolint:gosec // G304: Archiving files under a user-provided directory is intended
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open '%s': %w", path, err)
+		}
+		defer func() { _ = f.Close() }()
+
+		progress.file = name
+		if _, err := io.Copy(w, progress.reader(f)); err != nil {
+			return fmt.Errorf("failed to archive '%s': %w", name, err)
+		}
+		return nil
+	})
+}
+
+// entryWriter abstracts the zip and tar writers
+type entryWriter interface {
+	WriteDir(name string, info fs.FileInfo) error
+	WriteFile(name string, info fs.FileInfo) (io.Writer, error)
+	Close() error
+}
+
+type zipEntryWriter struct {
+	zw *zip.Writer
+}
+
+func newZipEntryWriter(w io.Writer) *zipEntryWriter {
+	return &zipEntryWriter{zw: zip.NewWriter(w)}
+}
+
+func (z *zipEntryWriter) WriteDir(name string, info fs.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return fmt.Errorf("failed to create zip header for '%s': %w", name, err)
+	}
+	header.Name = name + "/"
+	if _, err := z.zw.CreateHeader(header); err != nil {
+		return fmt.Errorf("failed to add '%s': %w", name, err)
+	}
+	return nil
+}
+
+func (z *zipEntryWriter) WriteFile(name string, info fs.FileInfo) (io.Writer, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zip header for '%s': %w", name, err)
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add '%s': %w", name, err)
+	}
+	return w, nil
+}
+
+func (z *zipEntryWriter) Close() error {
+	if err := z.zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zip archive: %w", err)
+	}
+	return nil
+}
+
+type tarZstEntryWriter struct {
+	zw *zstd.Encoder
+	tw *tar.Writer
+}
+
+func newTarZstEntryWriter(w io.Writer) (*tarZstEntryWriter, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	return &tarZstEntryWriter{zw: zw, tw: tar.NewWriter(zw)}, nil
+}
+
+func (t *tarZstEntryWriter) WriteDir(name string, info fs.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("failed to create tar header for '%s': %w", name, err)
+	}
+	header.Name = name + "/"
+	if err := t.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add '%s': %w", name, err)
+	}
+	return nil
+}
+
+func (t *tarZstEntryWriter) WriteFile(name string, info fs.FileInfo) (io.Writer, error) {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tar header for '%s': %w", name, err)
+	}
+	header.Name = name
+	if err := t.tw.WriteHeader(header); err != nil {
+		return nil, fmt.Errorf("failed to add '%s': %w", name, err)
+	}
+	return t.tw, nil
+}
+
+func (t *tarZstEntryWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		_ = t.zw.Close()
+		return fmt.Errorf("failed to close tar archive: %w", err)
+	}
+	if err := t.zw.Close(); err != nil {
+		return fmt.Errorf("failed to close zstd stream: %w", err)
+	}
+	return nil
+}
+
+// Extract unpacks an archive (or the chunked archive starting at path) into destDir.
+// Entries that would land outside destDir, symlinks and other special entries are rejected.
+func Extract(ctx context.Context, path, destDir string, onProgress func(Progress)) error {
+	format, ok := DetectFormat(path)
+	if !ok {
+		return fmt.Errorf("'%s' is not a supported archive (expected .zip or .tar.zst)", path)
+	}
+
+	r, err := openChunks(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	// Progress is measured on compressed bytes read, which is known up front for both formats
+	progress := &progressCounter{ctx: ctx, total: r.size, onProgress: onProgress}
+
+	switch format {
+	case FormatZip:
+		return extractZip(r, destDir, progress)
+	default:
+		return extractTarZst(progress.reader(r), destDir, progress)
+	}
+}
+
+func extractZip(r *chunkReader, destDir string, progress *progressCounter) error {
+	zr, err := zip.NewReader(progress.readerAt(r), r.size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	for _, file := range zr.File {
+		target, err := safeJoin(destDir, file.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return fmt.Errorf("failed to create '%s': %w", target, err)
+			}
+		case mode.IsRegular():
+			progress.file = file.Name
+			rc, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("failed to read '%s': %w", file.Name, err)
+			}
+			err = writeFile(target, rc)
+			_ = rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry '%s' has unsupported type", file.Name)
+		}
+	}
+	return progress.err()
+}
+
+func extractTarZst(r io.Reader, destDir string, progress *progressCounter) error {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read zstd stream: %w", err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return progress.err()
+		}
+		if err != nil {
+			if ctxErr := progress.err(); ctxErr != nil {
+				return ctxErr
+			}
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return fmt.Errorf("failed to create '%s': %w", target, err)
+			}
+		case tar.TypeReg:
+			progress.file = header.Name
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry '%s' has unsupported type", header.Name)
+		}
+	}
+}
+
+// safeJoin resolves an archive entry name inside destDir, rejecting absolute
+// paths and any entry that would escape destDir (zip-slip)
+func safeJoin(destDir, name string) (string, error) {
+	clean := strings.TrimSuffix(name, "/")
+	local := filepath.FromSlash(clean)
+	if clean == "" || strings.Contains(clean, "\\") || !filepath.IsLocal(local) {
+		return "", fmt.Errorf("archive entry '%s' has an unsafe path", name)
+	}
+	return filepath.Join(destDir, local), nil
+}
+
+// writeFile creates target (and its parent directories) with the contents of r
+func writeFile(target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return fmt.Errorf("failed to create '%s': %w", filepath.Dir(target), err)
+	}
+	//nolint:gosec // G304: target has been validated by safeJoin
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", target, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to extract '%s': %w", target, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", target, err)
+	}
+	return nil
+}
+
+// progressCounter counts bytes flowing through readers, reports progress and stops on cancellation
+type progressCounter struct {
+	ctx        context.Context
+	total      int64
+	done       int64
+	file       string
+	onProgress func(Progress)
+}
+
+func (p *progressCounter) err() error {
+	if p.ctx == nil || p.ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("archive operation cancelled: %w", p.ctx.Err())
+}
+
+func (p *progressCounter) add(n int) error {
+	p.done += int64(n)
+	if p.total > 0 && p.done > p.total {
+		p.done = p.total // Zip readers revisit the central directory
+	}
+	if p.onProgress != nil && n > 0 {
+		p.onProgress(Progress{File: p.file, Done: p.done, Total: p.total})
+	}
+	return p.err()
+}
+
+func (p *progressCounter) reader(r io.Reader) io.Reader {
+	return &countingReader{r: r, progress: p}
+}
+
+func (p *progressCounter) readerAt(r io.ReaderAt) io.ReaderAt {
+	return &countingReaderAt{r: r, progress: p}
+}
+
+type countingReader struct {
+	r        io.Reader
+	progress *progressCounter
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	if progressErr := c.progress.add(n); progressErr != nil {
+		return n, progressErr
+	}
+	return n, err //nolint:wrapcheck // io.Reader must pass io.EOF through unchanged
+}
+
+type countingReaderAt struct {
+	r        io.ReaderAt
+	progress *progressCounter
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(b, off)
+	if progressErr := c.progress.add(n); progressErr != nil {
+		return n, progressErr
+	}
+	return n, err //nolint:wrapcheck // io.ReaderAt must pass io.EOF through unchanged
+}
@@ -0,0 +1,168 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// diskData is incompressible content so that archives are large enough to be split
+var diskData = func() string {
+	data := make([]byte, 20000)
+	_, _ = rand.New(rand.NewSource(1)).Read(data)
+	return string(data)
+}()
+
+// makeExport creates a small directory tree resembling a VM export
+func makeExport(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "MyVM")
+	files := map[string]string{
+		"Virtual Machines/1234.vmcx":   "config",
+		"Virtual Hard Disks/disk.vhdx": diskData,
+		"quickvm-manifest.json":        "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src
+}
+
+func TestCreateExtract_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatZip, FormatTarZst} {
+		for _, chunkSize := range []int64{0, 1000} {
+			src := makeExport(t)
+			dest := filepath.Join(t.TempDir(), "MyVM"+format.Ext())
+
+			var last Progress
+			files, err := Create(context.Background(), src, dest, Options{
+				Format:     format,
+				ChunkSize:  chunkSize,
+				OnProgress: func(p Progress) { last = p },
+			})
+			if err != nil {
+				t.Fatalf("%s/%d: Create failed: %v", format, chunkSize, err)
+			}
+			if chunkSize == 0 && (len(files) != 1 || files[0] != dest) {
+				t.Errorf("%s: expected a single archive file, got %v", format, files)
+			}
+			if chunkSize > 0 {
+				if len(files) < 2 || files[0] != dest+".001" {
+					t.Errorf("%s: expected chunked archive, got %v", format, files)
+				}
+				for _, f := range files {
+					if info, _ := os.Stat(f); info.Size() > chunkSize {
+						t.Errorf("%s: chunk %s exceeds %d bytes", format, f, chunkSize)
+					}
+				}
+			}
+			if last.Total == 0 || last.Done != last.Total {
+				t.Errorf("%s: expected final progress to reach total, got %+v", format, last)
+			}
+
+			out := t.TempDir()
+			if err := Extract(context.Background(), files[0], out, nil); err != nil {
+				t.Fatalf("%s/%d: Extract failed: %v", format, chunkSize, err)
+			}
+			got, err := os.ReadFile(filepath.Join(out, "MyVM", "Virtual Hard Disks", "disk.vhdx"))
+			if err != nil || string(got) != diskData {
+				t.Errorf("%s/%d: extracted content mismatch (%v)", format, chunkSize, err)
+			}
+		}
+	}
+}
+
+func TestExtract_RejectsPathTraversal(t *testing.T) {
+	for _, name := range []string{"../evil.txt", "MyVM/../../evil.txt", "/abs/evil.txt", "MyVM\\..\\..\\evil.txt"} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte("pwned"))
+		_ = zw.Close()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "evil.zip")
+		if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		out := filepath.Join(dir, "out")
+		if err := Extract(context.Background(), path, out, nil); err == nil {
+			t.Errorf("Expected entry %q to be rejected", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+			t.Errorf("Entry %q escaped the destination directory", name)
+		}
+	}
+}
+
+func TestCreate_CancelledRemovesOutput(t *testing.T) {
+	src := makeExport(t)
+	dest := filepath.Join(t.TempDir(), "MyVM.zip")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Create(ctx, src, dest, Options{Format: FormatZip}); err == nil {
+		t.Fatal("Expected cancelled archive to fail")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Expected partial archive to be removed")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"4000M": 4000 << 20,
+		"2G":    2 << 30,
+		"650MB": 650 << 20,
+		"512":   512,
+		"fat32": FAT32ChunkSize,
+	}
+	for input, expected := range tests {
+		got, err := ParseSize(input)
+		if err != nil || got != expected {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", input, got, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "-1G", "0"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Errorf("Expected ParseSize(%q) to fail", input)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]Format{
+		"vm.zip":         FormatZip,
+		"vm.ZIP.001":     FormatZip,
+		"vm.tar.zst":     FormatTarZst,
+		"vm.tar.zst.001": FormatTarZst,
+		"vm.zip.002":     FormatZip,
+		"vm.tar.zst.012": FormatTarZst,
+	}
+	for path, expected := range tests {
+		if got, ok := DetectFormat(path); !ok || got != expected {
+			t.Errorf("DetectFormat(%q) = %q, %v; want %q", path, got, ok, expected)
+		}
+	}
+	if _, ok := DetectFormat(`D:\Exports\MyVM`); ok {
+		t.Error("Expected directory path not to be detected as an archive")
+	}
+	for _, path := range []string{"MyVM.tar.zst.001", "MyVM.tar.zst.017", "MyVM.zip"} {
+		if TrimExt(path) != "MyVM" {
+			t.Errorf("Unexpected TrimExt(%q) result %q", path, TrimExt(path))
+		}
+	}
+}
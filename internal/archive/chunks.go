@@ -0,0 +1,217 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// chunkWriter writes a stream to path, or to path.001, path.002, ... when chunkSize > 0
+type chunkWriter struct {
+	path      string
+	chunkSize int64
+	files     []string
+	current   *os.File
+	written   int64 // Bytes written to the current chunk
+}
+
+func newChunkWriter(path string, chunkSize int64) *chunkWriter {
+	return &chunkWriter{path: path, chunkSize: chunkSize}
+}
+
+// Write implements io.Writer, starting a new chunk whenever the current one is full
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	total := 0
+	for len(p) > 0 {
+		if w.current == nil || (w.chunkSize > 0 && w.written >= w.chunkSize) {
+			if err := w.next(); err != nil {
+				return total, err
+			}
+		}
+
+		n := len(p)
+		if w.chunkSize > 0 && int64(n) > w.chunkSize-w.written {
+			n = int(w.chunkSize - w.written)
+		}
+		written, err := w.current.Write(p[:n])
+		total += written
+		w.written += int64(written)
+		if err != nil {
+			return total, fmt.Errorf("failed to write archive: %w", err)
+		}
+		p = p[n:]
+	}
+	return total, nil
+}
+
+// next closes the current chunk and opens the next one
+func (w *chunkWriter) next() error {
+	if err := w.closeCurrent(); err != nil {
+		return err
+	}
+
+	name := w.path
+	if w.chunkSize > 0 {
+		name = chunkName(w.path, len(w.files)+1)
+	}
+	//nolint:gosec // G304: Writing to a user-chosen archive path is intended
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+	w.current = f
+	w.written = 0
+	w.files = append(w.files, name)
+	return nil
+}
+
+func (w *chunkWriter) closeCurrent() error {
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.Close()
+	w.current = nil
+	if err != nil {
+		return fmt.Errorf("failed to close archive file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the last chunk; an empty stream still produces one file
+func (w *chunkWriter) Close() error {
+	if w.current == nil && len(w.files) == 0 {
+		if err := w.next(); err != nil {
+			return err
+		}
+	}
+	return w.closeCurrent()
+}
+
+// remove deletes every file written so far
+func (w *chunkWriter) remove() {
+	_ = w.closeCurrent()
+	for _, name := range w.files {
+		_ = os.Remove(name)
+	}
+}
+
+// chunkName returns the name of the n-th chunk of path
+func chunkName(path string, n int) string {
+	return fmt.Sprintf("%s.%03d", path, n)
+}
+
+// chunkReader presents a single file or a sequence of chunks as one contiguous stream
+type chunkReader struct {
+	files   []*os.File
+	offsets []int64 // Starting offset of each chunk
+	size    int64
+	pos     int64
+}
+
+// openChunks opens path or, if path is a chunked archive, all of its chunks in order.
+// path may name either the archive ("vm.zip") or any of its chunks ("vm.zip.001").
+func openChunks(path string) (*chunkReader, error) {
+	base := trimChunkSuffix(path)
+	names := []string{path}
+	if _, err := os.Stat(chunkName(base, 1)); err == nil {
+		names = nil
+		for n := 1; ; n++ {
+			name := chunkName(base, n)
+			if _, err := os.Stat(name); err != nil {
+				break
+			}
+			names = append(names, name)
+		}
+	}
+
+	r := &chunkReader{}
+	for _, name := range names {
+		//nolint:gosec // G304: Reading a user-provided archive is intended
+		f, err := os.Open(name)
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			_ = r.Close()
+			return nil, fmt.Errorf("failed to open archive: %w", err)
+		}
+		r.files = append(r.files, f)
+		r.offsets = append(r.offsets, r.size)
+		r.size += info.Size()
+	}
+	return r, nil
+}
+
+// ReadAt implements io.ReaderAt across chunk boundaries
+func (r *chunkReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	total := 0
+	for len(p) > 0 && off < r.size {
+		i := len(r.offsets) - 1
+		for r.offsets[i] > off {
+			i--
+		}
+		n, err := r.files[i].ReadAt(p, off-r.offsets[i])
+		total += n
+		off += int64(n)
+		p = p[n:]
+		if err != nil && err != io.EOF {
+			return total, err //nolint:wrapcheck // io.ReaderAt errors are passed through unchanged
+		}
+		if n == 0 {
+			break
+		}
+	}
+
+	if len(p) > 0 {
+		return total, io.EOF
+	}
+	return total, nil
+}
+
+// Read implements io.Reader
+func (r *chunkReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// Close closes every chunk
+func (r *chunkReader) Close() error {
+	var firstErr error
+	for _, f := range r.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close archive file: %w", err)
+		}
+	}
+	return firstErr
+}
+
+// archiveBaseName returns the file name of an archive path without its chunk suffix
+func archiveBaseName(path string) string {
+	return trimChunkSuffix(filepath.Base(path))
+}
+
+// trimChunkSuffix strips the numeric suffix of any chunk, e.g. ".001" or ".012"
+func trimChunkSuffix(path string) string {
+	dot := strings.LastIndex(path, ".")
+	if dot < 0 {
+		return path
+	}
+	suffix := path[dot+1:]
+	if len(suffix) < 3 || strings.Trim(suffix, "0123456789") != "" {
+		return path
+	}
+	return path[:dot]
+}
@@ -0,0 +1,256 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ProgressFunc is called as data is backed up or restored
+type ProgressFunc func(done, total int64)
+
+// Backup stores every file under dir in the repository and saves set, filling in its
+// ID, files and statistics. Chunks already in the repository are not stored again.
+// The caller should hold the repository lock.
+func (r *Repository) Backup(ctx context.Context, dir string, set *Set, onProgress ProgressFunc) error {
+	paths, total, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	b := &ingest{repo: r, set: set, seen: make(map[string]bool), total: total, onProgress: onProgress}
+	set.Files = make([]File, 0, len(paths))
+	set.Size = 0
+	for _, rel := range paths {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("backup cancelled: %w", err)
+		}
+		file, err := b.addFile(ctx, dir, rel)
+		if err != nil {
+			return err
+		}
+		set.Files = append(set.Files, *file)
+		set.Size += file.Size
+	}
+
+	if set.CreatedAt.IsZero() {
+		return fmt.Errorf("backup set has no creation time")
+	}
+	return r.saveSet(set)
+}
+
+// ingest tracks the state of one backup
+type ingest struct {
+	repo       *Repository
+	set        *Set
+	seen       map[string]bool // Chunks known to be stored
+	done       int64
+	total      int64
+	onProgress ProgressFunc
+}
+
+// addFile chunks dir/rel and stores chunks that aren't in the repository yet
+func (b *ingest) addFile(ctx context.Context, dir, rel string) (*File, error) {
+	//nolint:gosec // G304: Backing up files of a user-chosen export is intended
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", rel, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	file := &File{Path: rel}
+	fileHash := sha256.New()
+	c := newChunker(f, b.repo.Config.Chunker)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("backup cancelled: %w", err)
+		}
+		data, err := c.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s': %w", rel, err)
+		}
+
+		fileHash.Write(data)
+		sum := sha256.Sum256(data)
+		id := hex.EncodeToString(sum[:])
+		if err := b.store(id, data); err != nil {
+			return nil, err
+		}
+		file.Chunks = append(file.Chunks, id)
+		file.Size += int64(len(data))
+
+		b.done += int64(len(data))
+		if b.onProgress != nil {
+			b.onProgress(b.done, b.total)
+		}
+	}
+	file.SHA256 = hex.EncodeToString(fileHash.Sum(nil))
+	return file, nil
+}
+
+// store writes a chunk unless the repository already has it
+func (b *ingest) store(id string, data []byte) error {
+	if b.seen[id] {
+		return nil
+	}
+	b.seen[id] = true
+
+	path := b.repo.chunkPath(id)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	compressed := b.repo.encoder.EncodeAll(data, nil)
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to store chunk: %w", err)
+	}
+	if err := writeFileAtomic(path, compressed); err != nil {
+		return err
+	}
+	b.set.AddedBytes += int64(len(compressed))
+	b.set.AddedChunks++
+	return nil
+}
+
+// Restore reassembles the files of set into dest, which must not exist yet. Every chunk
+// and file is verified against its SHA-256. On failure or cancellation dest is removed.
+func (r *Repository) Restore(ctx context.Context, set *Set, dest string, onProgress ProgressFunc) (err error) {
+	if _, statErr := os.Stat(dest); statErr == nil {
+		return fmt.Errorf("restore destination '%s' already exists", dest)
+	}
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(dest, 0750); err != nil {
+		return fmt.Errorf("failed to create restore destination: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(dest)
+		}
+	}()
+
+	var done int64
+	for _, file := range set.Files {
+		if err := r.restoreFile(ctx, file, dest, func(n int64) {
+			done += n
+			if onProgress != nil {
+				onProgress(done, set.Size)
+			}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreFile writes one file of a set below dest
+func (r *Repository) restoreFile(ctx context.Context, file File, dest string, written func(int64)) error {
+	path := filepath.Join(dest, filepath.FromSlash(file.Path))
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory for '%s': %w", file.Path, err)
+	}
+	//nolint:gosec // G304: Restoring into a user-chosen destination is intended
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", file.Path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	fileHash := sha256.New()
+	var size int64
+	for _, id := range file.Chunks {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("restore cancelled: %w", err)
+		}
+		data, err := r.readChunk(id)
+		if err != nil {
+			return fmt.Errorf("failed to restore '%s': %w", file.Path, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("failed to write '%s': %w", file.Path, err)
+		}
+		fileHash.Write(data)
+		size += int64(len(data))
+		written(int64(len(data)))
+	}
+
+	if size != file.Size || hex.EncodeToString(fileHash.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("restored '%s' does not match its checksum", file.Path)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", file.Path, err)
+	}
+	return nil
+}
+
+// readChunk reads, decompresses and verifies a chunk
+func (r *Repository) readChunk(id string) ([]byte, error) {
+	compressed, err := os.ReadFile(r.chunkPath(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("chunk %s is missing from the repository", id)
+		}
+		return nil, fmt.Errorf("failed to read chunk %s: %w", id, err)
+	}
+	data, err := r.decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %s is corrupt: %w", id, err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != id {
+		return nil, fmt.Errorf("chunk %s does not match its checksum", id)
+	}
+	return data, nil
+}
+
+// isChunkID reports whether id is a hex-encoded SHA-256
+func isChunkID(id string) bool {
+	if len(id) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// chunkPath returns the path of a chunk, fanned out by the first two hex digits
+func (r *Repository) chunkPath(id string) string {
+	return filepath.Join(r.Root, chunksDir, id[:2], id)
+}
+
+// listFiles returns the slash-separated paths of all regular files under dir and their total size
+func listFiles(dir string) ([]string, int64, error) {
+	var paths []string
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat '%s': %w", path, err)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve '%s': %w", path, err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list files in '%s': %w", dir, err)
+	}
+	sort.Strings(paths)
+	return paths, total, nil
+}
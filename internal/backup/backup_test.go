@@ -0,0 +1,283 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testParams = ChunkerParams{MinSize: 256, AvgSize: 1024, MaxSize: 4096}
+
+func randomData(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+func chunkAll(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var chunks [][]byte
+	c := newChunker(bytes.NewReader(data), testParams)
+	for {
+		chunk, err := c.next()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("next failed: %v", err)
+		}
+		chunks = append(chunks, append([]byte(nil), chunk...))
+	}
+}
+
+func TestChunker_BoundariesFollowContent(t *testing.T) {
+	data := randomData(1, 64<<10)
+	chunks := chunkAll(t, data)
+
+	if got := bytes.Join(chunks, nil); !bytes.Equal(got, data) {
+		t.Fatal("Chunks do not reassemble the input")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > testParams.MaxSize || (len(chunk) < testParams.MinSize && i != len(chunks)-1) {
+			t.Errorf("Chunk %d has size %d outside [%d, %d]", i, len(chunk), testParams.MinSize, testParams.MaxSize)
+		}
+	}
+
+	// Inserting bytes at the start must only change the chunks around the insertion
+	shifted := chunkAll(t, append([]byte("inserted bytes"), data...))
+	original := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		original[string(chunk)] = true
+	}
+	shared := 0
+	for _, chunk := range shifted {
+		if original[string(chunk)] {
+			shared++
+		}
+	}
+	if shared < len(chunks)-2 {
+		t.Errorf("Expected nearly all %d chunks to survive a shift, only %d did", len(chunks), shared)
+	}
+}
+
+func TestChunkerParams_Validate(t *testing.T) {
+	if err := DefaultChunkerParams.validate(); err != nil {
+		t.Errorf("Default params invalid: %v", err)
+	}
+	if err := (ChunkerParams{MinSize: 256, AvgSize: 1000, MaxSize: 4096}).validate(); err == nil {
+		t.Error("Expected error for an average size that is not a power of two")
+	}
+	if err := (ChunkerParams{MinSize: 2048, AvgSize: 1024, MaxSize: 4096}).validate(); err == nil {
+		t.Error("Expected error for min > avg")
+	}
+}
+
+func writeTree(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestRepo(t *testing.T) *Repository {
+	t.Helper()
+	repo, err := initWithParams(filepath.Join(t.TempDir(), "repo"), testParams)
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	return repo
+}
+
+func backupTree(t *testing.T, repo *Repository, vmName string, createdAt time.Time, files map[string][]byte) *Set {
+	t.Helper()
+	dir := t.TempDir()
+	writeTree(t, dir, files)
+	set := &Set{VMName: vmName, CreatedAt: createdAt}
+	if err := repo.Backup(context.Background(), dir, set, nil); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	return set
+}
+
+func TestBackup_DeduplicatesAndRestores(t *testing.T) {
+	repo := newTestRepo(t)
+	disk := randomData(2, 128<<10)
+	now := time.Now().UTC()
+
+	first := backupTree(t, repo, "Web01", now.Add(-time.Hour), map[string][]byte{
+		"Virtual Machines/1234.vmcx":     []byte("config"),
+		"Virtual Hard Disks/disk.vhdx":   disk,
+		"Virtual Hard Disks/zeroes.vhdx": make([]byte, 32<<10),
+	})
+	if first.ID == "" || first.Size != int64(len(disk)+6+32<<10) {
+		t.Fatalf("Unexpected first set: id %q, size %d", first.ID, first.Size)
+	}
+
+	// Change a few bytes in the middle of the disk: only nearby chunks are new
+	changed := append([]byte(nil), disk...)
+	copy(changed[64<<10:], "changed block")
+	second := backupTree(t, repo, "Web01", now, map[string][]byte{
+		"Virtual Machines/1234.vmcx":     []byte("config"),
+		"Virtual Hard Disks/disk.vhdx":   changed,
+		"Virtual Hard Disks/zeroes.vhdx": make([]byte, 32<<10),
+	})
+	if second.AddedChunks == 0 || second.AddedChunks > 3 {
+		t.Errorf("Expected 1-3 new chunks for a small change, got %d", second.AddedChunks)
+	}
+	if second.AddedBytes >= first.AddedBytes/4 {
+		t.Errorf("Expected the second backup to add far less data: %d vs %d", second.AddedBytes, first.AddedBytes)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	if err := repo.Restore(context.Background(), second, dest, nil); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "Virtual Hard Disks", "disk.vhdx"))
+	if err != nil || !bytes.Equal(got, changed) {
+		t.Errorf("Restored disk does not match the backed up data (err %v)", err)
+	}
+
+	if err := repo.Restore(context.Background(), second, dest, nil); err == nil {
+		t.Error("Expected restore into an existing directory to fail")
+	}
+}
+
+func TestRestore_CorruptChunkRemovesDestination(t *testing.T) {
+	repo := newTestRepo(t)
+	set := backupTree(t, repo, "Web01", time.Now(), map[string][]byte{"disk.vhdx": randomData(3, 16<<10)})
+
+	chunk := repo.chunkPath(set.Files[0].Chunks[0])
+	if err := os.WriteFile(chunk, repo.encoder.EncodeAll([]byte("tampered"), nil), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	err := repo.Restore(context.Background(), set, dest, nil)
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("Expected checksum error, got %v", err)
+	}
+	if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+		t.Error("Expected partial restore to be removed")
+	}
+}
+
+func TestRepository_FindSetAndLock(t *testing.T) {
+	repo := newTestRepo(t)
+	older := backupTree(t, repo, "Web01", time.Now().Add(-time.Hour), map[string][]byte{"a": []byte("a")})
+	newer := backupTree(t, repo, "Web01", time.Now(), map[string][]byte{"a": []byte("b")})
+
+	if set, err := repo.FindSet(older.ID[:6]); err != nil || set.ID != older.ID {
+		t.Errorf("FindSet by prefix = %v, %v", set, err)
+	}
+	if set, err := repo.FindSet("latest:web01"); err != nil || set.ID != newer.ID {
+		t.Errorf("FindSet latest = %v, %v", set, err)
+	}
+	if _, err := repo.FindSet("zzzz"); err == nil {
+		t.Error("Expected error for unknown set")
+	}
+
+	reopened, err := Init(repo.Root)
+	if err != nil || reopened.Config.Chunker != testParams {
+		t.Fatalf("Init on an existing repository should keep its config: %+v, %v", reopened, err)
+	}
+
+	unlock, err := repo.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if _, err := reopened.Lock(); err == nil {
+		t.Error("Expected second lock to fail")
+	}
+	unlock()
+	if unlock2, err := reopened.Lock(); err != nil {
+		t.Errorf("Expected lock after release to succeed: %v", err)
+	} else {
+		unlock2()
+	}
+}
+
+func TestRetentionPolicy_Apply(t *testing.T) {
+	base := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	var sets []*Set
+	// Two backups a day for 10 days for Web01, one backup for DB01
+	for day := 0; day < 10; day++ {
+		for _, hour := range []int{1, 13} {
+			sets = append(sets, &Set{ID: "w", VMName: "Web01", CreatedAt: base.AddDate(0, 0, -day).Add(time.Duration(hour-12) * time.Hour)})
+		}
+	}
+	sets = append(sets, &Set{ID: "d", VMName: "DB01", CreatedAt: base.AddDate(0, -3, 0)})
+
+	keep, remove := RetentionPolicy{KeepLast: 1}.Apply(sets)
+	if len(keep) != 2 || len(remove) != len(sets)-2 {
+		t.Errorf("KeepLast 1 should keep one set per VM, kept %d", len(keep))
+	}
+
+	keep, _ = RetentionPolicy{KeepDaily: 3}.Apply(sets)
+	if len(keep) != 4 { // 3 days of Web01 + DB01
+		t.Errorf("KeepDaily 3 kept %d sets, want 4", len(keep))
+	}
+	for _, set := range keep {
+		if set.VMName == "Web01" && set.CreatedAt.Hour() != 13 {
+			t.Errorf("KeepDaily should keep the newest set of each day, kept %v", set.CreatedAt)
+		}
+	}
+
+	keep, _ = RetentionPolicy{KeepLast: 2, KeepDaily: 2}.Apply(sets)
+	if len(keep) != 4 { // Rules overlap: last 2 are today; daily adds yesterday's newest
+		t.Errorf("Combined rules kept %d sets, want 4", len(keep))
+	}
+
+	if !(RetentionPolicy{}).IsEmpty() {
+		t.Error("Expected zero policy to be empty")
+	}
+}
+
+func TestPrune_RemovesSetsAndUnusedChunks(t *testing.T) {
+	repo := newTestRepo(t)
+	old := backupTree(t, repo, "Web01", time.Now().Add(-48*time.Hour), map[string][]byte{"disk.vhdx": randomData(4, 16<<10)})
+	current := backupTree(t, repo, "Web01", time.Now(), map[string][]byte{"disk.vhdx": randomData(5, 16<<10)})
+
+	if _, err := repo.Prune(context.Background(), RetentionPolicy{}, false); err == nil {
+		t.Error("Expected empty policy to be refused")
+	}
+
+	dry, err := repo.Prune(context.Background(), RetentionPolicy{KeepLast: 1}, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(dry.Removed) != 1 || dry.Removed[0].ID != old.ID || dry.RemovedChunks != old.AddedChunks {
+		t.Errorf("Unexpected dry run result: %+v", dry)
+	}
+	if _, err := repo.LoadSet(old.ID); err != nil {
+		t.Error("Dry run must not delete sets")
+	}
+
+	result, err := repo.Prune(context.Background(), RetentionPolicy{KeepLast: 1}, false)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.RemovedChunks != old.AddedChunks || result.FreedBytes != old.AddedBytes {
+		t.Errorf("Expected %d chunks (%d bytes) freed, got %+v", old.AddedChunks, old.AddedBytes, result)
+	}
+	sets, _ := repo.ListSets()
+	if len(sets) != 1 || sets[0].ID != current.ID {
+		t.Errorf("Expected only the current set to remain, got %v", sets)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	if err := repo.Restore(context.Background(), current, dest, nil); err != nil {
+		t.Errorf("Remaining set must still restore: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChunkerParams controls content-defined chunking. Chunk boundaries depend only on
+// the data and these parameters, so they are fixed when a repository is created.
+type ChunkerParams struct {
+	MinSize int `json:"minSize"`
+	AvgSize int `json:"avgSize"` // Must be a power of two
+	MaxSize int `json:"maxSize"`
+}
+
+// DefaultChunkerParams suit multi-gigabyte virtual hard disks
+var DefaultChunkerParams = ChunkerParams{
+	MinSize: 512 << 10,
+	AvgSize: 2 << 20,
+	MaxSize: 8 << 20,
+}
+
+// validate checks that the parameters describe a usable chunker
+func (p ChunkerParams) validate() error {
+	if p.MinSize <= 0 || p.AvgSize < p.MinSize || p.MaxSize < p.AvgSize {
+		return fmt.Errorf("invalid chunker sizes: min %d, avg %d, max %d", p.MinSize, p.AvgSize, p.MaxSize)
+	}
+	if p.AvgSize&(p.AvgSize-1) != 0 {
+		return fmt.Errorf("average chunk size %d is not a power of two", p.AvgSize)
+	}
+	return nil
+}
+
+// gearTable maps each byte to a pseudo-random value for the rolling gear hash.
+// It is generated from a fixed seed; changing it would change every chunk boundary.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x5175_6963_6b56_4d31) // "QuickVM1"
+	for i := range table {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// chunker splits a stream into content-defined chunks, so that an insertion or change
+// in a disk image only affects the chunks around it instead of shifting every block
+type chunker struct {
+	r      io.Reader
+	params ChunkerParams
+	mask   uint64
+	buf    []byte
+	start  int
+	end    int
+	eof    bool
+}
+
+func newChunker(r io.Reader, params ChunkerParams) *chunker {
+	return &chunker{
+		r:      r,
+		params: params,
+		mask:   uint64(params.AvgSize - 1),
+		buf:    make([]byte, params.MaxSize),
+	}
+}
+
+// next returns the next chunk, or io.EOF at the end of the stream.
+// The returned slice is only valid until the next call.
+func (c *chunker) next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+
+	n := c.cutPoint(c.buf[c.start:c.end])
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
+
+// fill tops up the buffer so that a full-size chunk is available unless the stream ended
+func (c *chunker) fill() error {
+	if c.eof || c.end-c.start >= c.params.MaxSize {
+		return nil
+	}
+
+	copy(c.buf, c.buf[c.start:c.end])
+	c.end -= c.start
+	c.start = 0
+	for c.end < len(c.buf) {
+		n, err := c.r.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read data: %w", err)
+		}
+	}
+	return nil
+}
+
+// cutPoint returns the length of the first chunk in data
+func (c *chunker) cutPoint(data []byte) int {
+	n := len(data)
+	if n <= c.params.MinSize {
+		return n
+	}
+	if n > c.params.MaxSize {
+		n = c.params.MaxSize
+	}
+
+	var hash uint64
+	for i := c.params.MinSize; i < n; i++ {
+		hash = (hash << 1) + gearTable[data[i]]
+		if hash&c.mask == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,160 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RetentionPolicy decides which backup sets to keep. Rules are applied per VM and a set
+// is kept if any rule selects it.
+type RetentionPolicy struct {
+	KeepLast    int `json:"keepLast,omitempty"`    // Keep the newest N sets
+	KeepDaily   int `json:"keepDaily,omitempty"`   // Keep the newest set of each of the last N days with a backup
+	KeepWeekly  int `json:"keepWeekly,omitempty"`  // Keep the newest set of each of the last N ISO weeks with a backup
+	KeepMonthly int `json:"keepMonthly,omitempty"` // Keep the newest set of each of the last N months with a backup
+}
+
+// IsEmpty reports whether the policy has no rules, which would remove every set
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0
+}
+
+// Apply splits sets into those to keep and those to remove, both oldest first
+func (p RetentionPolicy) Apply(sets []*Set) (keep, remove []*Set) {
+	byVM := make(map[string][]*Set)
+	for _, set := range sets {
+		key := strings.ToLower(set.VMName)
+		byVM[key] = append(byVM[key], set)
+	}
+
+	kept := make(map[*Set]bool)
+	for _, vmSets := range byVM {
+		newestFirst := append([]*Set(nil), vmSets...)
+		sort.Slice(newestFirst, func(i, j int) bool { return newestFirst[i].CreatedAt.After(newestFirst[j].CreatedAt) })
+
+		for i := 0; i < p.KeepLast && i < len(newestFirst); i++ {
+			kept[newestFirst[i]] = true
+		}
+		keepBuckets(newestFirst, p.KeepDaily, "2006-01-02", kept)
+		keepBuckets(newestFirst, p.KeepWeekly, "", kept)
+		keepBuckets(newestFirst, p.KeepMonthly, "2006-01", kept)
+	}
+
+	for _, set := range sets {
+		if kept[set] {
+			keep = append(keep, set)
+		} else {
+			remove = append(remove, set)
+		}
+	}
+	sort.Slice(keep, func(i, j int) bool { return keep[i].CreatedAt.Before(keep[j].CreatedAt) })
+	sort.Slice(remove, func(i, j int) bool { return remove[i].CreatedAt.Before(remove[j].CreatedAt) })
+	return keep, remove
+}
+
+// keepBuckets keeps the newest set of each of the first n time buckets. An empty layout
+// buckets by ISO week. Sets are bucketed in local time, as users think of days.
+func keepBuckets(newestFirst []*Set, n int, layout string, kept map[*Set]bool) {
+	seen := make(map[string]bool)
+	for _, set := range newestFirst {
+		if len(seen) >= n {
+			return
+		}
+		t := set.CreatedAt.Local()
+		bucket := t.Format(layout)
+		if layout == "" {
+			year, week := t.ISOWeek()
+			bucket = fmt.Sprintf("%d-W%02d", year, week)
+		}
+		if !seen[bucket] {
+			seen[bucket] = true
+			kept[set] = true
+		}
+	}
+}
+
+// PruneResult describes what a prune removed, or would remove in a dry run
+type PruneResult struct {
+	Kept          []*Set `json:"-"`
+	Removed       []*Set `json:"-"`
+	RemovedChunks int    `json:"removedChunks"`
+	FreedBytes    int64  `json:"freedBytes"`
+	DryRun        bool   `json:"dryRun"`
+}
+
+// Prune removes the sets that policy does not keep and deletes chunks no remaining set
+// references, including chunks left behind by interrupted backups. With dryRun nothing
+// is deleted. The caller should hold the repository lock.
+func (r *Repository) Prune(ctx context.Context, policy RetentionPolicy, dryRun bool) (*PruneResult, error) {
+	if policy.IsEmpty() {
+		return nil, fmt.Errorf("retention policy is empty; refusing to remove every backup set")
+	}
+
+	sets, err := r.ListSets()
+	if err != nil {
+		return nil, err
+	}
+	keep, remove := policy.Apply(sets)
+	result := &PruneResult{Kept: keep, Removed: remove, DryRun: dryRun}
+
+	if !dryRun {
+		for _, set := range remove {
+			if err := r.deleteSet(set.ID); err != nil {
+				return nil, err
+			}
+		}
+		// Leftovers of interrupted backups
+		_ = os.RemoveAll(filepath.Join(r.Root, stagingDir))
+	}
+
+	referenced := make(map[string]bool)
+	for _, set := range keep {
+		for _, file := range set.Files {
+			for _, id := range file.Chunks {
+				referenced[id] = true
+			}
+		}
+	}
+
+	if err := r.removeUnusedChunks(ctx, referenced, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// removeUnusedChunks deletes every chunk not in referenced, recording the totals in result
+func (r *Repository) removeUnusedChunks(ctx context.Context, referenced map[string]bool, result *PruneResult) error {
+	err := filepath.WalkDir(filepath.Join(r.Root, chunksDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("prune cancelled: %w", ctxErr)
+		}
+		if referenced[d.Name()] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat chunk: %w", err)
+		}
+		result.RemovedChunks++
+		result.FreedBytes += info.Size()
+		if result.DryRun {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove chunk: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect unused chunks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Package backup implements a local, deduplicating backup repository for VM exports.
+// Files are split into content-defined chunks that are stored once, compressed and
+// addressed by their SHA-256; a backup set lists the chunks of every file it contains.
+package backup
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	configFile  = "config.json"
+	chunksDir   = "chunks"
+	setsDir     = "sets"
+	stagingDir  = "tmp"
+	lockFile    = "lock"
+	repoVersion = 1
+)
+
+// Config is stored in config.json at the root of a repository
+type Config struct {
+	Version   int           `json:"version"`
+	Chunker   ChunkerParams `json:"chunker"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// File is a file of a backup set and the chunks it is made of, in order
+type File struct {
+	Path   string   `json:"path"` // Slash-separated path relative to the backed up directory
+	Size   int64    `json:"size"`
+	SHA256 string   `json:"sha256"`
+	Chunks []string `json:"chunks"`
+}
+
+// Set is a point-in-time backup of one VM
+type Set struct {
+	ID          string          `json:"id"`
+	VMName      string          `json:"vmName"`
+	VMID        string          `json:"vmId,omitempty"`
+	Checkpoint  string          `json:"checkpoint,omitempty"` // Checkpoint the backup was taken from
+	CreatedAt   time.Time       `json:"createdAt"`
+	SourceHost  string          `json:"sourceHost,omitempty"`
+	Spec        json.RawMessage `json:"spec,omitempty"` // VM configuration recorded by the caller
+	Size        int64           `json:"size"`           // Total size of all files
+	AddedBytes  int64           `json:"addedBytes"`     // Compressed bytes of chunks this set added to the repository
+	AddedChunks int             `json:"addedChunks"`
+	Files       []File          `json:"files"`
+}
+
+// ChunkCount returns the number of chunk references in the set
+func (s *Set) ChunkCount() int {
+	count := 0
+	for _, file := range s.Files {
+		count += len(file.Chunks)
+	}
+	return count
+}
+
+// Repository is a backup repository on the local file system
+type Repository struct {
+	Root   string
+	Config Config
+
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// Init creates a repository at root, or opens it if one already exists
+func Init(root string) (*Repository, error) {
+	if _, err := os.Stat(filepath.Join(root, configFile)); err == nil {
+		return Open(root)
+	}
+	return initWithParams(root, DefaultChunkerParams)
+}
+
+func initWithParams(root string, params ChunkerParams) (*Repository, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{chunksDir, setsDir} {
+		// gosec G301: Expect directory permissions to be 0750 or less
+		if err := os.MkdirAll(filepath.Join(root, dir), 0750); err != nil {
+			return nil, fmt.Errorf("failed to create backup repository: %w", err)
+		}
+	}
+
+	config := Config{Version: repoVersion, Chunker: params, CreatedAt: time.Now().UTC()}
+	if err := writeJSON(filepath.Join(root, configFile), config); err != nil {
+		return nil, err
+	}
+	return newRepository(root, config)
+}
+
+// Open opens an existing repository
+func Open(root string) (*Repository, error) {
+	//nolint:gosec // G304: Reading the config of a user-provided repository is intended
+	data, err := os.ReadFile(filepath.Join(root, configFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("'%s' is not a backup repository", root)
+		}
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse repository config: %w", err)
+	}
+	if config.Version > repoVersion {
+		return nil, fmt.Errorf("repository version %d is newer than supported version %d", config.Version, repoVersion)
+	}
+	if err := config.Chunker.validate(); err != nil {
+		return nil, fmt.Errorf("repository config: %w", err)
+	}
+	return newRepository(root, config)
+}
+
+func newRepository(root string, config Config) (*Repository, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	return &Repository{Root: root, Config: config, encoder: encoder, decoder: decoder}, nil
+}
+
+// Lock takes the repository lock so that backups and prunes don't run concurrently.
+// The returned function releases it.
+func (r *Repository) Lock() (func(), error) {
+	path := filepath.Join(r.Root, lockFile)
+	//nolint:gosec // G304: The lock file lives inside the repository
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("repository is locked by another operation (remove '%s' if none is running)", path)
+		}
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	_, _ = fmt.Fprintf(f, "pid %d at %s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	_ = f.Close()
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// StagingDir creates a temporary directory inside the repository, on the same volume
+func (r *Repository) StagingDir() (string, error) {
+	root := filepath.Join(r.Root, stagingDir)
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(root, 0750); err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(root, "backup-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return dir, nil
+}
+
+// ListSets returns all backup sets, oldest first
+func (r *Repository) ListSets() ([]*Set, error) {
+	entries, err := os.ReadDir(filepath.Join(r.Root, setsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup sets: %w", err)
+	}
+
+	sets := make([]*Set, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		set, err := r.LoadSet(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].CreatedAt.Before(sets[j].CreatedAt) })
+	return sets, nil
+}
+
+// LoadSet reads the backup set with the given ID
+func (r *Repository) LoadSet(id string) (*Set, error) {
+	//nolint:gosec // G304: Reading a set file inside the repository
+	data, err := os.ReadFile(filepath.Join(r.Root, setsDir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup set '%s': %w", id, err)
+	}
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse backup set '%s': %w", id, err)
+	}
+	for _, file := range set.Files {
+		if file.Path == "" || !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return nil, fmt.Errorf("backup set '%s' contains invalid path '%s'", id, file.Path)
+		}
+		for _, chunk := range file.Chunks {
+			if !isChunkID(chunk) {
+				return nil, fmt.Errorf("backup set '%s' contains invalid chunk ID '%s'", id, chunk)
+			}
+		}
+	}
+	return &set, nil
+}
+
+// FindSet returns the set whose ID starts with ref, or the newest set of VM ref when ref is
+// "latest:<vm>"
+func (r *Repository) FindSet(ref string) (*Set, error) {
+	sets, err := r.ListSets()
+	if err != nil {
+		return nil, err
+	}
+
+	if vmName, ok := strings.CutPrefix(ref, "latest:"); ok {
+		for i := len(sets) - 1; i >= 0; i-- {
+			if strings.EqualFold(sets[i].VMName, vmName) {
+				return sets[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no backup sets found for VM '%s'", vmName)
+	}
+
+	var matches []*Set
+	for _, set := range sets {
+		if strings.HasPrefix(set.ID, strings.ToLower(ref)) {
+			matches = append(matches, set)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("backup set '%s' not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("backup set '%s' is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+// saveSet assigns an ID to set and writes it. Sets are written after all of their
+// chunks, so an interrupted backup never leaves a set that references missing data.
+func (r *Repository) saveSet(set *Set) error {
+	for {
+		id, err := newSetID()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(r.setPath(id)); errors.Is(err, fs.ErrNotExist) {
+			set.ID = id
+			break
+		}
+	}
+	return writeJSON(r.setPath(set.ID), set)
+}
+
+func (r *Repository) deleteSet(id string) error {
+	if err := os.Remove(r.setPath(id)); err != nil {
+		return fmt.Errorf("failed to delete backup set '%s': %w", id, err)
+	}
+	return nil
+}
+
+func (r *Repository) setPath(id string) string {
+	return filepath.Join(r.Root, setsDir, id+".json")
+}
+
+// newSetID returns a random 8-character hex ID
+func newSetID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate set ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes v as indented JSON via a temporary file, so readers never see a partial file
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to path via a temporary file in the same directory
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
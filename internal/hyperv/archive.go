@@ -0,0 +1,86 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"quickvm/internal/archive"
+)
+
+// ExportArchiveOptions contains options for exporting a VM into an archive
+type ExportArchiveOptions struct {
+	Format     archive.Format
+	ChunkSize  int64                  // Split the archive into chunks of this size; 0 writes a single file
+	StagingDir string                 // Where the raw export is staged before packing; default the OS temp directory
+	OnProgress func(archive.Progress) // Optional: called while the archive is written
+}
+
+// ExportVMArchive exports a VM (with its manifest) and packs it into destDir/<vmName>.<format>.
+// The raw export is staged in opts.StagingDir and removed afterwards, so only the archive
+// reaches destDir, which may be a FAT32 drive that cannot hold a large VHDX. It returns
+// the archive files written, which are several chunks when opts.ChunkSize is set.
+func (m *Manager) ExportVMArchive(ctx context.Context, vmName, destDir string, opts ExportArchiveOptions) ([]string, error) {
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(destDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	stagingRoot := opts.StagingDir
+	if stagingRoot == "" {
+		stagingRoot = os.TempDir()
+	}
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(stagingRoot, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(stagingRoot, ".quickvm-export-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	if _, err := m.exportVM(ctx, vmName, staging, true); err != nil {
+		return nil, err
+	}
+
+	dest := filepath.Join(destDir, vmName+opts.Format.Ext())
+	files, err := archive.Create(ctx, filepath.Join(staging, vmName), dest, archive.Options{
+		Format:     opts.Format,
+		ChunkSize:  opts.ChunkSize,
+		OnProgress: opts.OnProgress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive export of '%s': %w", vmName, err)
+	}
+	return files, nil
+}
+
+// ExtractExportArchive extracts an export archive (or the first chunk of a split archive)
+// into a new staging directory under stagingRoot. It returns the extracted export directory
+// and a cleanup function that removes the staging directory.
+func ExtractExportArchive(ctx context.Context, archivePath, stagingRoot string, onProgress func(archive.Progress)) (string, func(), error) {
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(stagingRoot, 0750); err != nil {
+		return "", nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(stagingRoot, ".quickvm-import-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(staging) }
+
+	if err := archive.Extract(ctx, archivePath, staging, onProgress); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract '%s': %w", archivePath, err)
+	}
+
+	// Archives created by quickvm contain a single directory named after the VM
+	exportDir := staging
+	entries, err := os.ReadDir(staging)
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		exportDir = filepath.Join(staging, entries[0].Name())
+	}
+	return exportDir, cleanup, nil
+}
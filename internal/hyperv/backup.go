@@ -0,0 +1,139 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"quickvm/internal/backup"
+)
+
+// backupCheckpointPrefix names the checkpoints taken for backups
+const backupCheckpointPrefix = "quickvm-backup-"
+
+// BackupOptions contains options for backing up a VM
+type BackupOptions struct {
+	KeepCheckpoint bool                 // Keep the checkpoint instead of removing it after the backup
+	OnStatus       func(message string) // Optional: called when a step starts
+	OnProgress     backup.ProgressFunc  // Optional: called while data is added to the repository
+}
+
+func (o BackupOptions) status(format string, args ...interface{}) {
+	if o.OnStatus != nil {
+		o.OnStatus(fmt.Sprintf(format, args...))
+	}
+}
+
+// BackupVM takes a checkpoint of a VM for a consistent point in time, exports the
+// checkpoint into the repository's staging area and adds it to the repository as a new
+// backup set. Only chunks the repository doesn't have yet are stored.
+func (m *Manager) BackupVM(ctx context.Context, vmName string, repo *backup.Repository, opts BackupOptions) (*backup.Set, error) {
+	unlock, err := repo.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	spec, err := m.getVMSpec(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode VM configuration: %w", err)
+	}
+
+	createdAt := time.Now().UTC()
+	checkpoint := backupCheckpointPrefix + createdAt.Format("20060102-150405")
+	opts.status("Creating checkpoint '%s'", checkpoint)
+	if err := m.CreateSnapshotByVMName(ctx, vmName, checkpoint); err != nil {
+		return nil, err
+	}
+	if !opts.KeepCheckpoint {
+		defer m.removeBackupCheckpoint(vmName, checkpoint)
+	}
+
+	staging, err := repo.StagingDir()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	opts.status("Exporting checkpoint")
+	output, err := m.Exec.RunCmdlet(ctx, "Export-VMSnapshot", "-VMName", vmName, "-Name", checkpoint, "-Path", staging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export checkpoint '%s' of VM '%s': %v\nOutput: %s", checkpoint, vmName, err, string(output))
+	}
+
+	hostname, _ := os.Hostname()
+	set := &backup.Set{
+		VMName:     vmName,
+		VMID:       spec.ID,
+		Checkpoint: checkpoint,
+		CreatedAt:  createdAt,
+		SourceHost: hostname,
+		Spec:       specJSON,
+	}
+	opts.status("Adding to repository")
+	if err := repo.Backup(ctx, filepath.Join(staging, vmName), set, opts.OnProgress); err != nil {
+		return nil, fmt.Errorf("failed to back up VM '%s': %w", vmName, err)
+	}
+	return set, nil
+}
+
+// removeBackupCheckpoint deletes the checkpoint taken for a backup, merging it back into the
+// VM's disks. It runs with its own deadline so cleanup happens after a cancellation too.
+func (m *Manager) removeBackupCheckpoint(vmName, checkpoint string) {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+	_ = m.DeleteSnapshotByVMName(ctx, vmName, checkpoint)
+}
+
+// RestoreBackup reassembles a backup set into destDir/<vm name> as a regular export with a
+// manifest, ready for ImportVM. The files are verified against their checksums while restoring.
+func RestoreBackup(ctx context.Context, repo *backup.Repository, set *backup.Set, destDir string, onProgress backup.ProgressFunc) (string, error) {
+	exportDir := filepath.Join(destDir, set.VMName)
+	if err := repo.Restore(ctx, set, exportDir, onProgress); err != nil {
+		return "", fmt.Errorf("failed to restore backup set '%s': %w", set.ID, err)
+	}
+
+	var spec VMSpec
+	if len(set.Spec) > 0 {
+		if err := json.Unmarshal(set.Spec, &spec); err != nil {
+			_ = os.RemoveAll(exportDir)
+			return "", fmt.Errorf("failed to parse VM configuration of backup set '%s': %w", set.ID, err)
+		}
+	}
+	if spec.ID == "" {
+		spec.ID = set.VMID
+	}
+
+	files := make([]FileChecksum, 0, len(set.Files))
+	for _, file := range set.Files {
+		files = append(files, FileChecksum{Path: file.Path, Size: file.Size, SHA256: file.SHA256})
+	}
+	configFile, err := selectConfigFile(files, spec.ID)
+	if err != nil {
+		_ = os.RemoveAll(exportDir)
+		return "", fmt.Errorf("backup set '%s': %w", set.ID, err)
+	}
+
+	manifest := &ExportManifest{
+		FormatVersion: exportManifestFormatVersion,
+		VMName:        set.VMName,
+		VMID:          spec.ID,
+		ConfigFile:    configFile,
+		CreatedAt:     set.CreatedAt,
+		SourceHost:    set.SourceHost,
+		Spec:          &spec,
+		Files:         files,
+	}
+	if err := writeJSONFile(filepath.Join(exportDir, ExportManifestFile), manifest); err != nil {
+		_ = os.RemoveAll(exportDir)
+		return "", err
+	}
+	return exportDir, nil
+}
@@ -0,0 +1,136 @@
+package hyperv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quickvm/internal/backup"
+)
+
+// backupRunner simulates checkpoints and Export-VMSnapshot
+type backupRunner struct {
+	checkpoints []string
+	removed     []string
+	exportErr   error
+	disk        []byte
+}
+
+func (r *backupRunner) RunScript(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+func (r *backupRunner) RunCmdlet(_ context.Context, cmdlet string, args ...string) ([]byte, error) {
+	switch cmdlet {
+	case "Get-VM":
+		return []byte(`{"ID": "1234", "Generation": 2, "ProcessorCount": 2, "MemoryStartupMB": 2048}`), nil
+	case "Checkpoint-VM":
+		r.checkpoints = append(r.checkpoints, args[3])
+	case "Remove-VMSnapshot":
+		r.removed = append(r.removed, args[3])
+	case "Export-VMSnapshot":
+		if r.exportErr != nil {
+			return nil, r.exportErr
+		}
+		exportDir := filepath.Join(args[5], args[1])
+		for name, data := range map[string][]byte{
+			"Virtual Machines/1234.vmcx":   []byte("config"),
+			"Virtual Hard Disks/disk.vhdx": r.disk,
+		} {
+			path := filepath.Join(exportDir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+func TestBackupVM_RestoresVerifiableExport(t *testing.T) {
+	repo, err := backup.Init(filepath.Join(t.TempDir(), "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &backupRunner{disk: make([]byte, 3<<20)}
+	copy(runner.disk[1<<20:], "boot sector")
+	manager := &Manager{Exec: runner}
+
+	set, err := manager.BackupVM(context.Background(), "Web01", repo, BackupOptions{})
+	if err != nil {
+		t.Fatalf("BackupVM failed: %v", err)
+	}
+	if len(runner.checkpoints) != 1 || set.Checkpoint != runner.checkpoints[0] {
+		t.Errorf("Expected set to reference the checkpoint taken, got %q vs %v", set.Checkpoint, runner.checkpoints)
+	}
+	if len(runner.removed) != 1 || runner.removed[0] != set.Checkpoint {
+		t.Errorf("Expected checkpoint to be removed after the backup, got %v", runner.removed)
+	}
+	if set.VMID != "1234" || len(set.Files) != 2 {
+		t.Errorf("Unexpected set: %+v", set)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(repo.Root, "tmp")); len(entries) != 0 {
+		t.Errorf("Expected staging directory to be cleaned up, found %d entries", len(entries))
+	}
+
+	exportDir, err := RestoreBackup(context.Background(), repo, set, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	manifest, err := ReadExportManifest(exportDir)
+	if err != nil {
+		t.Fatalf("Expected restored export to have a manifest: %v", err)
+	}
+	if manifest.ConfigFile != "Virtual Machines/1234.vmcx" || manifest.Spec.ProcessorCount != 2 {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	verification, err := VerifyExport(exportDir)
+	if err != nil || !verification.Valid {
+		t.Errorf("Expected restored export to verify, got %+v, %v", verification, err)
+	}
+}
+
+func TestBackupVM_FailureRemovesCheckpointAndLock(t *testing.T) {
+	repo, err := backup.Init(filepath.Join(t.TempDir(), "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &backupRunner{exportErr: errors.New("export failed")}
+	manager := &Manager{Exec: runner}
+
+	if _, err := manager.BackupVM(context.Background(), "Web01", repo, BackupOptions{}); err == nil {
+		t.Fatal("Expected backup to fail")
+	}
+	if len(runner.removed) != 1 {
+		t.Errorf("Expected checkpoint to be removed after a failure, got %v", runner.removed)
+	}
+	if sets, _ := repo.ListSets(); len(sets) != 0 {
+		t.Errorf("Expected no backup set after a failure, got %d", len(sets))
+	}
+
+	unlock, err := repo.Lock()
+	if err != nil {
+		t.Fatalf("Expected repository lock to be released: %v", err)
+	}
+	unlock()
+}
+
+func TestBackupVM_KeepCheckpoint(t *testing.T) {
+	repo, err := backup.Init(filepath.Join(t.TempDir(), "repo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &backupRunner{disk: []byte("disk")}
+	manager := &Manager{Exec: runner}
+
+	if _, err := manager.BackupVM(context.Background(), "Web01", repo, BackupOptions{KeepCheckpoint: true}); err != nil {
+		t.Fatalf("BackupVM failed: %v", err)
+	}
+	if len(runner.removed) != 0 {
+		t.Errorf("Expected checkpoint to be kept, removed %v", runner.removed)
+	}
+}
@@ -0,0 +1,301 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BundleManifestFile is the name of the manifest written at the root of a workspace bundle
+	BundleManifestFile = "quickvm-bundle.json"
+	// BundleWorkspaceFile is the name of the workspace definition stored in a bundle
+	BundleWorkspaceFile = "workspace.yaml"
+
+	bundleFormatVersion = 1
+)
+
+// BundleVM describes one exported workspace member
+type BundleVM struct {
+	Name      string         `json:"name"`
+	Directory string         `json:"directory"` // Export-VM output directory, relative to the bundle root
+	Spec      *VMSpec        `json:"spec,omitempty"`
+	Files     []FileChecksum `json:"files"`
+}
+
+// BundleManifest describes the contents of a workspace bundle
+type BundleManifest struct {
+	FormatVersion  int        `json:"formatVersion"`
+	QuickVMVersion string     `json:"quickvmVersion"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	SourceHost     string     `json:"sourceHost"`
+	Workspace      string     `json:"workspace"`
+	WorkspaceFile  string     `json:"workspaceFile"`
+	VMs            []BundleVM `json:"vms"`
+}
+
+// BundleExportOptions contains options for exporting a workspace bundle
+type BundleExportOptions struct {
+	QuickVMVersion string                       // Recorded in the manifest
+	OnProgress     func(vmName, message string) // Optional: called as each VM is processed
+}
+
+// BundleImportOptions contains options for importing a workspace bundle
+type BundleImportOptions struct {
+	Prefix        string                       // Optional: prepended to every imported VM name
+	WorkspaceName string                       // Optional: name for the recreated workspace (defaults to the bundle's)
+	VHDPath       string                       // Optional: custom destination for VHD files
+	SkipVerify    bool                         // Skip checksum verification before importing
+	Force         bool                         // Overwrite an existing workspace with the same name
+	OnProgress    func(vmName, message string) // Optional: called as each VM is processed
+}
+
+// BundleImportResult describes the outcome of a bundle import
+type BundleImportResult struct {
+	Workspace string            `json:"workspace"`
+	VMs       map[string]string `json:"vms"` // Original name -> imported name
+}
+
+// ExportWorkspaceBundle exports every workspace member into dir and writes a bundle manifest
+// that can be used by ImportWorkspaceBundle to recreate the environment on another host.
+// If the export fails, what it wrote into dir is removed again so that it can be retried.
+func (m *Manager) ExportWorkspaceBundle(ctx context.Context, ws *Workspace, dir string, opts BundleExportOptions) (_ *BundleManifest, err error) {
+	if len(ws.VMs) == 0 {
+		return nil, fmt.Errorf("workspace '%s' has no VMs to export", ws.Name)
+	}
+
+	manifestPath := filepath.Join(dir, BundleManifestFile)
+	if _, err := os.Stat(manifestPath); err == nil {
+		return nil, fmt.Errorf("'%s' already contains a bundle", dir)
+	}
+
+	// Paths this export created, removed in reverse order if it fails
+	var created []string
+	defer func() {
+		if err != nil {
+			for i := len(created) - 1; i >= 0; i-- {
+				_ = os.RemoveAll(created[i])
+			}
+		}
+	}()
+	createdPath := func(path string) {
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			created = append(created, path)
+		}
+	}
+
+	createdPath(dir)
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workspace: %w", err)
+	}
+	createdPath(filepath.Join(dir, BundleWorkspaceFile))
+	// gosec G306: Expect WriteFile permissions to be 0600 or less
+	if err := os.WriteFile(filepath.Join(dir, BundleWorkspaceFile), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write workspace file: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	manifest := &BundleManifest{
+		FormatVersion:  bundleFormatVersion,
+		QuickVMVersion: opts.QuickVMVersion,
+		CreatedAt:      time.Now().UTC(),
+		SourceHost:     hostname,
+		Workspace:      ws.Name,
+		WorkspaceFile:  BundleWorkspaceFile,
+		VMs:            make([]BundleVM, 0, len(ws.VMs)),
+	}
+
+	progress := opts.OnProgress
+	if progress == nil {
+		progress = func(string, string) {}
+	}
+
+	for _, vmName := range ws.VMs {
+		progress(vmName, "exporting")
+		createdPath(filepath.Join(dir, vmName))
+		exported, err := m.exportVM(ctx, vmName, dir, true)
+		if err != nil {
+			return nil, err
+		}
+
+		// Bundle paths are relative to the bundle root rather than the VM's export directory
+		files := make([]FileChecksum, 0, len(exported.Files))
+		for _, file := range exported.Files {
+			file.Path = vmName + "/" + file.Path
+			files = append(files, file)
+		}
+
+		manifest.VMs = append(manifest.VMs, BundleVM{
+			Name:      vmName,
+			Directory: vmName,
+			Spec:      exported.Spec,
+			Files:     files,
+		})
+	}
+
+	if err := writeJSONFile(manifestPath, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ReadBundleManifest reads the manifest of the bundle in dir
+func ReadBundleManifest(dir string) (*BundleManifest, error) {
+	//nolint:gosec // G304: Reading the manifest of a user-provided bundle is intended
+	data, err := os.ReadFile(filepath.Join(dir, BundleManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("'%s' is not a workspace bundle (missing %s)", dir, BundleManifestFile)
+		}
+		return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.FormatVersion > bundleFormatVersion {
+		return nil, fmt.Errorf("bundle format version %d is newer than supported version %d", manifest.FormatVersion, bundleFormatVersion)
+	}
+
+	for _, vm := range manifest.VMs {
+		if !isLocalPath(vm.Directory) {
+			return nil, fmt.Errorf("bundle VM '%s' has invalid directory '%s'", vm.Name, vm.Directory)
+		}
+		for _, file := range vm.Files {
+			if !isLocalPath(file.Path) {
+				return nil, fmt.Errorf("bundle file '%s' is outside the bundle", file.Path)
+			}
+		}
+	}
+	return &manifest, nil
+}
+
+// VerifyBundle recomputes checksums for every file listed in a bundle manifest
+func VerifyBundle(dir string, manifest *BundleManifest) (*ExportVerification, error) {
+	verification := &ExportVerification{Path: dir, Kind: "bundle", Name: manifest.Workspace}
+	for _, vm := range manifest.VMs {
+		if err := verification.check(dir, vm.Files); err != nil {
+			return nil, err
+		}
+	}
+	verification.Valid = len(verification.Missing) == 0 && len(verification.Mismatched) == 0
+	return verification, nil
+}
+
+// ImportWorkspaceBundle imports every VM in a bundle with new IDs and recreates the workspace.
+// If any import fails, the VMs imported so far are removed.
+func (m *Manager) ImportWorkspaceBundle(ctx context.Context, dir string, opts BundleImportOptions) (*BundleImportResult, error) {
+	manifest, err := ReadBundleManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:gosec // G304: Reading the workspace of a user-provided bundle is intended
+	data, err := os.ReadFile(filepath.Join(dir, BundleWorkspaceFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle workspace: %w", err)
+	}
+	ws, err := ParseWorkspace(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle workspace: %w", err)
+	}
+
+	if opts.WorkspaceName != "" {
+		ws.Name = opts.WorkspaceName
+	}
+	if err := validateWorkspaceName(ws.Name); err != nil {
+		return nil, err
+	}
+	if WorkspaceExists(ws.Name) && !opts.Force {
+		return nil, fmt.Errorf("workspace '%s' already exists (use --force to overwrite)", ws.Name)
+	}
+
+	if !opts.SkipVerify {
+		verification, err := VerifyBundle(dir, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if !verification.Valid {
+			return nil, fmt.Errorf("bundle failed verification:\n  %s", strings.Join(verification.Problems(), "\n  "))
+		}
+	}
+
+	names := make(map[string]string, len(manifest.VMs))
+	for _, vm := range manifest.VMs {
+		newName := opts.Prefix + vm.Name
+		if exists, _ := m.VMExists(ctx, newName); exists {
+			return nil, fmt.Errorf("a VM named '%s' already exists (use a prefix to avoid conflicts)", newName)
+		}
+		names[vm.Name] = newName
+	}
+
+	progress := opts.OnProgress
+	if progress == nil {
+		progress = func(string, string) {}
+	}
+
+	imported := make([]string, 0, len(manifest.VMs))
+	for _, vm := range manifest.VMs {
+		progress(vm.Name, "importing")
+		importOpts := ImportVMOptions{
+			Path:          filepath.Join(dir, filepath.FromSlash(vm.Directory)),
+			Copy:          true,
+			GenerateNewID: true,
+			VHDPath:       opts.VHDPath,
+			NewName:       names[vm.Name],
+		}
+		if _, err := m.ImportVM(ctx, importOpts); err != nil {
+			for _, name := range imported {
+				_ = m.DeleteVM(ctx, name)
+			}
+			return nil, fmt.Errorf("failed to import '%s': %w", vm.Name, err)
+		}
+		imported = append(imported, names[vm.Name])
+	}
+
+	ws.renameMembers(names)
+	if err := SaveWorkspace(ws); err != nil {
+		return nil, err
+	}
+
+	return &BundleImportResult{Workspace: ws.Name, VMs: names}, nil
+}
+
+// renameMembers renames workspace members and their startup policies using names (old -> new)
+func (ws *Workspace) renameMembers(names map[string]string) {
+	rename := func(name string) string {
+		if newName, ok := names[name]; ok {
+			return newName
+		}
+		return name
+	}
+
+	for i, vm := range ws.VMs {
+		ws.VMs[i] = rename(vm)
+	}
+
+	if len(ws.Startup) == 0 {
+		return
+	}
+	startup := make(map[string]StartupPolicy, len(ws.Startup))
+	for vm, policy := range ws.Startup {
+		for i, dep := range policy.DependsOn {
+			policy.DependsOn[i] = rename(dep)
+		}
+		startup[rename(vm)] = policy
+	}
+	ws.Startup = startup
+}
@@ -0,0 +1,189 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bundleRunner simulates Export-VM by writing files and answers other cmdlets by name
+type bundleRunner struct {
+	existing map[string]bool
+	failOn   string
+	imports  []string
+	deleted  []string
+}
+
+func (r *bundleRunner) RunScript(_ context.Context, _ string) ([]byte, error) {
+	return nil, nil
+}
+
+func (r *bundleRunner) RunCmdlet(_ context.Context, cmdlet string, args ...string) ([]byte, error) {
+	switch cmdlet {
+	case "Get-VM":
+		if len(args) > 2 && args[2] == "-ErrorAction" {
+			if r.existing[args[1]] {
+				return []byte(args[1]), nil
+			}
+			return nil, nil
+		}
+		return []byte(`{"ID": "1234", "Generation": 2, "ProcessorCount": 4, "MemoryStartupMB": 4096}`), nil
+	case "Export-VM":
+		vmDir := filepath.Join(args[3], args[1], "Virtual Machines")
+		if err := os.MkdirAll(vmDir, 0750); err != nil {
+			return nil, err
+		}
+		if args[1] == r.failOn {
+			return nil, fmt.Errorf("export failed")
+		}
+		return nil, os.WriteFile(filepath.Join(vmDir, "1234.vmcx"), []byte(args[1]), 0600)
+	case "Import-VM":
+		newName := ""
+		for i, arg := range args {
+			if arg == "-NewName" {
+				newName = args[i+1]
+			}
+		}
+		if newName == r.failOn {
+			return nil, fmt.Errorf("import failed")
+		}
+		r.imports = append(r.imports, newName)
+		return []byte(newName), nil
+	case "Remove-VM":
+		r.deleted = append(r.deleted, args[1])
+	}
+	return nil, nil
+}
+
+func bundleWorkspace() *Workspace {
+	return &Workspace{
+		Name: "lab",
+		VMs:  []string{"DC01", "SQL01"},
+		Startup: map[string]StartupPolicy{
+			"SQL01": {DependsOn: []string{"DC01"}},
+		},
+	}
+}
+
+func TestWorkspaceBundle_RoundTrip(t *testing.T) {
+	setTestHome(t)
+	dir := filepath.Join(t.TempDir(), "bundle")
+	runner := &bundleRunner{}
+	manager := &Manager{Exec: runner}
+
+	manifest, err := manager.ExportWorkspaceBundle(context.Background(), bundleWorkspace(), dir, BundleExportOptions{QuickVMVersion: "1.2.3"})
+	if err != nil {
+		t.Fatalf("ExportWorkspaceBundle failed: %v", err)
+	}
+	if manifest.QuickVMVersion != "1.2.3" || len(manifest.VMs) != 2 {
+		t.Fatalf("Unexpected manifest: %+v", manifest)
+	}
+	if manifest.VMs[0].Spec.ProcessorCount != 4 || len(manifest.VMs[0].Files) != 1 {
+		t.Errorf("Expected spec and checksums for DC01, got %+v", manifest.VMs[0])
+	}
+	if manifest.VMs[0].Files[0].Path != "DC01/Virtual Machines/1234.vmcx" {
+		t.Errorf("Unexpected file path %q", manifest.VMs[0].Files[0].Path)
+	}
+
+	if _, err := manager.ExportWorkspaceBundle(context.Background(), bundleWorkspace(), dir, BundleExportOptions{}); err == nil {
+		t.Error("Expected export into an existing bundle to fail")
+	}
+
+	result, err := manager.ImportWorkspaceBundle(context.Background(), dir, BundleImportOptions{Prefix: "new-", WorkspaceName: "lab2"})
+	if err != nil {
+		t.Fatalf("ImportWorkspaceBundle failed: %v", err)
+	}
+	if result.VMs["SQL01"] != "new-SQL01" || len(runner.imports) != 2 {
+		t.Errorf("Unexpected import result %+v, imports %v", result, runner.imports)
+	}
+
+	ws, err := LoadWorkspace("lab2")
+	if err != nil {
+		t.Fatalf("Expected workspace to be recreated: %v", err)
+	}
+	if ws.VMs[0] != "new-DC01" || ws.Policy("new-SQL01").DependsOn[0] != "new-DC01" {
+		t.Errorf("Workspace members not renamed: %+v", ws)
+	}
+}
+
+func TestExportWorkspaceBundle_RemovesPartialBundle(t *testing.T) {
+	setTestHome(t)
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("mine"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manager := &Manager{Exec: &bundleRunner{failOn: "SQL01"}}
+
+	if _, err := manager.ExportWorkspaceBundle(context.Background(), bundleWorkspace(), dir, BundleExportOptions{}); err == nil {
+		t.Fatal("Expected the export of SQL01 to fail")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "notes.txt" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Expected only the files that were there before to remain, got %v", names)
+	}
+
+	// The failed export can be retried
+	manager.Exec = &bundleRunner{}
+	if _, err := manager.ExportWorkspaceBundle(context.Background(), bundleWorkspace(), dir, BundleExportOptions{}); err != nil {
+		t.Errorf("Expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestImportWorkspaceBundle_Failures(t *testing.T) {
+	setTestHome(t)
+	dir := filepath.Join(t.TempDir(), "bundle")
+	manager := &Manager{Exec: &bundleRunner{}}
+	if _, err := manager.ExportWorkspaceBundle(context.Background(), bundleWorkspace(), dir, BundleExportOptions{}); err != nil {
+		t.Fatalf("ExportWorkspaceBundle failed: %v", err)
+	}
+
+	// Name conflicts are detected before anything is imported
+	conflict := &bundleRunner{existing: map[string]bool{"SQL01": true}}
+	_, err := (&Manager{Exec: conflict}).ImportWorkspaceBundle(context.Background(), dir, BundleImportOptions{})
+	if err == nil || len(conflict.imports) != 0 {
+		t.Errorf("Expected name conflict error before import, got %v (imports %v)", err, conflict.imports)
+	}
+
+	// A failed import removes the VMs imported so far
+	failing := &bundleRunner{failOn: "SQL01"}
+	_, err = (&Manager{Exec: failing}).ImportWorkspaceBundle(context.Background(), dir, BundleImportOptions{})
+	if err == nil || len(failing.deleted) != 1 || failing.deleted[0] != "DC01" {
+		t.Errorf("Expected rollback of DC01, got err=%v deleted=%v", err, failing.deleted)
+	}
+	if WorkspaceExists("lab") {
+		t.Error("Workspace should not be created when an import fails")
+	}
+
+	// Corrupted files fail verification
+	vmcx := filepath.Join(dir, "DC01", "Virtual Machines", "1234.vmcx")
+	if err := os.WriteFile(vmcx, []byte("corrupt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = manager.ImportWorkspaceBundle(context.Background(), dir, BundleImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestReadBundleManifest_RejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"formatVersion": 1, "vms": [{"name": "DC01", "directory": "../outside", "files": []}]}`
+	if err := os.WriteFile(filepath.Join(dir, BundleManifestFile), []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadBundleManifest(dir); err == nil {
+		t.Error("Expected manifest with '..' directory to be rejected")
+	}
+	if _, err := ReadBundleManifest(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without a manifest")
+	}
+}
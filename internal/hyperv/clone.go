@@ -15,7 +15,7 @@ type CloneVMOptions struct {
 }
 
 // CloneVM clones a VM by index with a new name (full clone)
-// This performs: Export -> Import with Copy and GenerateNewId -> Rename -> Cleanup
+// This performs: Export -> Import with Copy, GenerateNewId and the new name -> Cleanup
 func (m *Manager) CloneVM(ctx context.Context, vmIndex int, newName string) error {
 	// Validate new name
 	if strings.TrimSpace(newName) == "" {
@@ -28,6 +28,20 @@ func (m *Manager) CloneVM(ctx context.Context, vmIndex int, newName string) erro
 		return err
 	}
 
+	return m.cloneVM(ctx, vmName, newName, nil, newProgressReporter(nil, "clone", vmName))
+}
+
+// cloneVM performs a full clone, applies custom if it is not nil and reports progress to r
+func (m *Manager) cloneVM(ctx context.Context, vmName, newName string, custom *CloneCustomization, r *progressReporter) error {
+	if strings.TrimSpace(newName) == "" {
+		return fmt.Errorf("new VM name cannot be empty")
+	}
+	if custom != nil {
+		if err := custom.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Check if new name already exists
 	exists, err := m.VMExists(ctx, newName)
 	if err != nil {
@@ -37,40 +51,67 @@ func (m *Manager) CloneVM(ctx context.Context, vmIndex int, newName string) erro
 		return fmt.Errorf("a VM with name '%s' already exists", newName)
 	}
 
+	r.phase(ProgressPreparing, "measuring source disks")
+	total, _ := m.getVMDiskSize(ctx, vmName) // Progress is indeterminate if the size is unknown
+
 	// Create temp directory for export
 	tempDir, err := os.MkdirTemp("", "quickvm-clone-*")
 	if err != nil {
 		return fmt.Errorf("failed to create temp directory: %v", err)
 	}
-	defer func() { _ = os.RemoveAll(tempDir) }() // Cleanup on exit
+	defer func() { _ = os.RemoveAll(tempDir) }() // Cleanup on exit, including cancellation
 
-	// Step 1: Export the source VM
-	if err := m.ExportVMByName(ctx, vmName, tempDir); err != nil {
+	// Step 1: Export the source VM (no manifest; the export is only kept until import)
+	exportedPath := filepath.Join(tempDir, vmName)
+	stop := r.watch(ctx, ProgressExporting, exportedPath, total)
+	err = m.runExportVM(ctx, vmName, tempDir)
+	stop()
+	if err != nil {
 		return fmt.Errorf("failed to export source VM: %v", err)
 	}
 
-	// Step 2: Import with Copy and GenerateNewId
-	exportedPath := filepath.Join(tempDir, vmName)
+	before, err := m.listVMIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: Import with Copy and GenerateNewId, renaming in the same pipeline, then customize
+	if err := m.importClone(ctx, exportedPath, newName, total, custom, r); err != nil {
+		// Remove the clone if Hyper-V registered it before failing or being cancelled
+		r.phase(ProgressRollingBack, "removing partially created clone")
+		if rollbackErr := m.rollbackImport(before, newName, true); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// importClone imports the exported source as a new VM and applies custom to it.
+// A clone that kept the source's identity would clash with it, so customization
+// failures are returned like import failures.
+func (m *Manager) importClone(ctx context.Context, exportedPath, newName string, total int64, custom *CloneCustomization, r *progressReporter) error {
 	importOpts := ImportVMOptions{
 		Path:          exportedPath,
 		Copy:          true, // Full clone - copy files
 		GenerateNewID: true, // Generate new VM ID
+		NewName:       newName,
 	}
 
-	importedName, err := m.ImportVM(ctx, importOpts)
+	stop := r.watch(ctx, ProgressImporting, m.importDestination(ctx, importOpts), total)
+	_, err := m.ImportVM(ctx, importOpts)
+	stop()
 	if err != nil {
 		return fmt.Errorf("failed to import cloned VM: %v", err)
 	}
 
-	// Step 3: Rename to the new name if different
-	if importedName != newName {
-		if err := m.RenameVM(ctx, importedName, newName); err != nil {
-			// Try to cleanup the imported VM if rename fails
-			_ = m.DeleteVM(ctx, importedName)
-			return fmt.Errorf("failed to rename cloned VM: %v", err)
-		}
+	if custom.IsEmpty() {
+		return nil
+	}
+	if err := m.customizeClone(ctx, newName, custom, r); err != nil {
+		return fmt.Errorf("failed to customize cloned VM: %w", err)
 	}
-
 	return nil
 }
 
@@ -140,3 +181,21 @@ func (m *Manager) DeleteVM(ctx context.Context, name string) error {
 	}
 	return nil
 }
+
+// DeleteVMAndDisks turns a VM off, removes it and deletes its virtual hard disks.
+// Differencing disk parents are not touched.
+func (m *Manager) DeleteVMAndDisks(ctx context.Context, name string) error {
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$vm = Get-VM -Name "%s"
+		$disks = @($vm | Get-VMHardDiskDrive | Select-Object -ExpandProperty Path)
+		if ($vm.State -ne "Off") { Stop-VM -VM $vm -TurnOff -Force }
+		Remove-VM -VM $vm -Force
+		$disks | Where-Object { $_ } | Remove-Item -Force -ErrorAction SilentlyContinue
+	`, escapePSString(name))
+
+	if output, err := m.Exec.RunScript(ctx, script); err != nil {
+		return fmt.Errorf("failed to delete VM '%s': %v\nOutput: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
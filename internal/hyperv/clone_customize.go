@@ -0,0 +1,309 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Guest operating systems for clone guest customization
+const (
+	GuestWindows = "windows" // Commands run via PowerShell Direct
+	GuestLinux   = "linux"   // Commands run via SSH to the guest's IPv4 address
+)
+
+// Guest identity resets run after the hostname change
+const (
+	GuestResetSysprep   = "sysprep"    // Windows: sysprep /generalize /oobe
+	GuestResetCloudInit = "cloud-init" // Linux: cloud-init clean and a fresh machine-id
+)
+
+// defaultGuestTimeout bounds waiting for a clone's guest to boot and to shut down again
+const defaultGuestTimeout = 10 * time.Minute
+
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?$`)
+
+// CloneCustomization changes a clone so it can run next to its source.
+// Hardware settings are applied while the clone is off. Guest steps start the clone,
+// run commands inside it and shut it down again.
+type CloneCustomization struct {
+	RegenerateMAC    bool   // Give every adapter a fresh dynamically assigned MAC address
+	SwitchName       string // Connect every adapter to this switch
+	ProcessorCount   int    // Override the virtual processor count
+	MemoryStartupMB  int64  // Override startup memory
+	StripCheckpoints bool   // Remove all checkpoints copied from the source
+
+	Hostname         string        // Rename the guest computer
+	GuestReset       string        // sysprep or cloud-init
+	GuestOS          string        // windows (default) or linux
+	GuestUser        string        // Guest account for PowerShell Direct or SSH
+	GuestPasswordEnv string        // windows: environment variable holding the guest password
+	SSHKey           string        // linux: private key file for SSH
+	GuestTimeout     time.Duration // Give up waiting for the guest after this long (default 10m)
+}
+
+// IsEmpty reports whether the customization changes nothing
+func (c *CloneCustomization) IsEmpty() bool {
+	return c == nil || (!c.hasHardwareSteps() && !c.hasGuestSteps())
+}
+
+func (c *CloneCustomization) hasHardwareSteps() bool {
+	return c.RegenerateMAC || c.SwitchName != "" || c.ProcessorCount > 0 || c.MemoryStartupMB > 0 || c.StripCheckpoints
+}
+
+func (c *CloneCustomization) hasGuestSteps() bool {
+	return c.Hostname != "" || c.GuestReset != ""
+}
+
+func (c *CloneCustomization) guestOS() string {
+	if c.GuestOS == "" {
+		return GuestWindows
+	}
+	return c.GuestOS
+}
+
+// Validate checks that the customization is consistent before anything is cloned
+func (c *CloneCustomization) Validate() error {
+	if c.ProcessorCount < 0 {
+		return fmt.Errorf("processor count must be positive")
+	}
+	if c.MemoryStartupMB < 0 || c.MemoryStartupMB%2 != 0 || (c.MemoryStartupMB > 0 && c.MemoryStartupMB < 32) {
+		return fmt.Errorf("memory must be an even number of MB and at least 32 MB")
+	}
+	if !c.hasGuestSteps() {
+		return nil
+	}
+	if err := c.validateGuest(); err != nil {
+		return err
+	}
+	if c.GuestUser == "" {
+		return fmt.Errorf("guest customization requires a guest user")
+	}
+	if c.GuestPasswordEnv != "" && !envNamePattern.MatchString(c.GuestPasswordEnv) {
+		return fmt.Errorf("invalid password variable '%s': must be an environment variable name", c.GuestPasswordEnv)
+	}
+	return nil
+}
+
+// validateGuest checks that the hostname and reset suit the guest OS
+func (c *CloneCustomization) validateGuest() error {
+	target := c.guestOS()
+	if target != GuestWindows && target != GuestLinux {
+		return fmt.Errorf("unknown guest OS '%s' (valid: windows, linux)", c.GuestOS)
+	}
+	if c.Hostname != "" {
+		maxLen := 63
+		if target == GuestWindows {
+			maxLen = 15 // NetBIOS limit
+		}
+		if len(c.Hostname) > maxLen || !hostnamePattern.MatchString(c.Hostname) {
+			return fmt.Errorf("invalid hostname '%s': use letters, digits and hyphens, at most %d characters", c.Hostname, maxLen)
+		}
+	}
+	switch c.GuestReset {
+	case "":
+	case GuestResetSysprep:
+		if target != GuestWindows {
+			return fmt.Errorf("sysprep requires a Windows guest")
+		}
+		if c.Hostname != "" {
+			return fmt.Errorf("sysprep assigns a new computer name during setup; do not combine it with a hostname")
+		}
+	case GuestResetCloudInit:
+		if target != GuestLinux {
+			return fmt.Errorf("cloud-init reset requires a Linux guest")
+		}
+	default:
+		return fmt.Errorf("unknown guest reset '%s' (valid: sysprep, cloud-init)", c.GuestReset)
+	}
+	return nil
+}
+
+// CustomizeVM applies a customization to an existing VM, e.g. one created from a template.
+// The VM must be off.
+func (m *Manager) CustomizeVM(ctx context.Context, vmName string, c *CloneCustomization) error {
+	if c.IsEmpty() {
+		return nil
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return m.customizeClone(ctx, vmName, c, newProgressReporter(nil, "customize", vmName))
+}
+
+// customizeClone applies c to the freshly imported clone vmName, which is off
+func (m *Manager) customizeClone(ctx context.Context, vmName string, c *CloneCustomization, r *progressReporter) error {
+	if c.hasHardwareSteps() {
+		r.phase(ProgressCustomizing, "configuring hardware")
+		if output, err := m.Exec.RunScript(ctx, buildCloneHardwareScript(vmName, c)); err != nil {
+			return fmt.Errorf("failed to configure clone: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	if !c.hasGuestSteps() {
+		return nil
+	}
+
+	timeout := c.GuestTimeout
+	if timeout <= 0 {
+		timeout = defaultGuestTimeout
+	}
+
+	r.phase(ProgressCustomizing, "starting guest")
+	if err := m.StartVMByName(ctx, vmName); err != nil {
+		return err
+	}
+	check := &ReadinessCheck{Type: ReadinessCommand, Command: "$true", User: c.GuestUser, PasswordEnv: c.GuestPasswordEnv, Timeout: Duration(timeout)}
+	if c.guestOS() == GuestLinux {
+		check = &ReadinessCheck{Type: ReadinessTCP, Port: 22, Timeout: Duration(timeout)}
+	}
+	if err := m.WaitReady(ctx, vmName, check); err != nil {
+		return err
+	}
+
+	for _, step := range guestSteps(c) {
+		r.phase(ProgressCustomizing, step.message)
+		if err := m.runGuestStep(ctx, vmName, c, step.command); err != nil {
+			return fmt.Errorf("failed to %s: %w", step.message, err)
+		}
+	}
+
+	r.phase(ProgressCustomizing, "shutting down guest")
+	if c.GuestReset != GuestResetSysprep { // sysprep shuts the guest down itself
+		if err := m.StopVMByName(ctx, vmName); err != nil {
+			return err
+		}
+	}
+	return m.waitForVMOff(ctx, vmName, timeout)
+}
+
+// buildCloneHardwareScript builds the script that changes the clone's settings while it is off.
+// Checkpoints go first so the remaining changes apply to the merged configuration.
+func buildCloneHardwareScript(vmName string, c *CloneCustomization) string {
+	var steps []string
+	if c.StripCheckpoints {
+		steps = append(steps, `
+			Get-VMSnapshot -VM $vm | Sort-Object CreationTime -Descending | Remove-VMSnapshot
+			while ((Get-VM -Id $vm.Id).Status -like "*Merging*") { Start-Sleep -Seconds 1 }`)
+	}
+	if c.SwitchName != "" {
+		steps = append(steps, fmt.Sprintf(`
+			if (@(Get-VMNetworkAdapter -VM $vm).Count -eq 0) {
+				Add-VMNetworkAdapter -VM $vm -SwitchName "%[1]s"
+			} else {
+				Connect-VMNetworkAdapter -VMName $vm.Name -SwitchName "%[1]s"
+			}`, escapePSString(c.SwitchName)))
+	}
+	if c.RegenerateMAC {
+		// Switching from a static to a dynamic address clears it, so Hyper-V assigns a new
+		// one from its pool on the next start instead of keeping the source's address
+		steps = append(steps, `
+			foreach ($adapter in Get-VMNetworkAdapter -VM $vm) {
+				Set-VMNetworkAdapter -VMNetworkAdapter $adapter -StaticMacAddress "020000000001"
+				Set-VMNetworkAdapter -VMNetworkAdapter $adapter -DynamicMacAddress
+			}`)
+	}
+	if c.ProcessorCount > 0 {
+		steps = append(steps, fmt.Sprintf(`
+			Set-VMProcessor -VM $vm -Count %d`, c.ProcessorCount))
+	}
+	if c.MemoryStartupMB > 0 {
+		steps = append(steps, fmt.Sprintf(`
+			$bytes = [int64]%d * 1MB
+			$memory = Get-VMMemory -VM $vm
+			if ($memory.DynamicMemoryEnabled) {
+				Set-VMMemory -VM $vm -MinimumBytes ([math]::Min($memory.Minimum, $bytes)) -StartupBytes $bytes -MaximumBytes ([math]::Max($memory.Maximum, $bytes))
+			} else {
+				Set-VMMemory -VM $vm -StartupBytes $bytes
+			}`, c.MemoryStartupMB))
+	}
+
+	return fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$vm = Get-VM -Name "%s"%s
+	`, escapePSString(vmName), strings.Join(steps, ""))
+}
+
+// guestStep is one command run inside the clone's guest
+type guestStep struct {
+	message string
+	command string
+}
+
+// guestSteps returns the commands that give the guest a new identity. Hostnames are
+// validated, so they are safe to embed in the commands.
+func guestSteps(c *CloneCustomization) []guestStep {
+	var steps []guestStep
+	if c.guestOS() == GuestLinux {
+		if c.Hostname != "" {
+			steps = append(steps, guestStep{"rename guest", "sudo hostnamectl set-hostname " + c.Hostname})
+		}
+		if c.GuestReset == GuestResetCloudInit {
+			// An empty machine-id is regenerated on boot, which also changes the DHCP client ID
+			steps = append(steps, guestStep{"reset cloud-init", "sudo cloud-init clean --logs && sudo truncate -s 0 /etc/machine-id"})
+		}
+		return steps
+	}
+
+	if c.Hostname != "" {
+		steps = append(steps, guestStep{"rename guest", fmt.Sprintf("Rename-Computer -NewName '%s' -Force", c.Hostname)})
+	}
+	if c.GuestReset == GuestResetSysprep {
+		steps = append(steps, guestStep{"run sysprep", `Start-Process -FilePath "$env:SystemRoot\System32\Sysprep\sysprep.exe" -ArgumentList '/generalize', '/oobe', '/shutdown', '/quiet'`})
+	}
+	return steps
+}
+
+// runGuestStep runs command inside the guest via PowerShell Direct or SSH
+func (m *Manager) runGuestStep(ctx context.Context, vmName string, c *CloneCustomization, command string) error {
+	script := buildGuestCommandScript(vmName, command, c.GuestUser, c.GuestPasswordEnv)
+	if c.guestOS() == GuestLinux {
+		ip, err := m.getVMIPv4(ctx, vmName)
+		if ip == "" {
+			return err
+		}
+		script = buildSSHCommandScript(ip, c.GuestUser, c.SSHKey, command)
+	}
+
+	if output, err := m.Exec.RunScript(ctx, script); err != nil {
+		return fmt.Errorf("%v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// buildSSHCommandScript runs command on host over SSH with key authentication only, so a
+// missing key fails instead of prompting for a password
+func buildSSHCommandScript(host, user, keyFile, command string) string {
+	identity := ""
+	if keyFile != "" {
+		identity = fmt.Sprintf(` -i "%s"`, escapePSString(keyFile))
+	}
+	return fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		& ssh.exe -o BatchMode=yes -o StrictHostKeyChecking=accept-new%s "%s@%s" "%s"
+		if ($LASTEXITCODE -ne 0) { throw "ssh exited with code $LASTEXITCODE" }
+	`, identity, escapePSString(user), escapePSString(host), escapePSString(command))
+}
+
+// waitForVMOff polls until vmName is off, the timeout expires or ctx is cancelled
+func (m *Manager) waitForVMOff(ctx context.Context, vmName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := m.Exec.RunCmdlet(ctx, "Get-VM", "-Name", vmName, "|", "Select-Object", "-ExpandProperty", "State")
+		if err == nil && strings.TrimSpace(string(output)) == "Off" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("VM '%s' did not shut down within %s", vmName, timeout)
+		}
+		select {
+		case <-time.After(guestPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for VM '%s' to shut down cancelled: %w", vmName, ctx.Err())
+		}
+	}
+}
+
+// guestPollInterval is how often waitForVMOff checks the VM state
+var guestPollInterval = 2 * time.Second
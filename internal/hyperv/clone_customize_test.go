@@ -0,0 +1,132 @@
+package hyperv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCloneCustomization_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		custom  CloneCustomization
+		wantErr bool
+	}{
+		{"hardware only", CloneCustomization{RegenerateMAC: true, ProcessorCount: 2, MemoryStartupMB: 4096}, false},
+		{"odd memory", CloneCustomization{MemoryStartupMB: 1025}, true},
+		{"windows hostname", CloneCustomization{Hostname: "WEB02", GuestUser: "Administrator"}, false},
+		{"windows hostname too long", CloneCustomization{Hostname: "WEBSERVER-CLONE-02", GuestUser: "Administrator"}, true},
+		{"hostname with quote", CloneCustomization{Hostname: "web'02", GuestUser: "Administrator"}, true},
+		{"missing user", CloneCustomization{Hostname: "WEB02"}, true},
+		{"sysprep on linux", CloneCustomization{GuestReset: GuestResetSysprep, GuestOS: GuestLinux, GuestUser: "ubuntu"}, true},
+		{"sysprep with hostname", CloneCustomization{GuestReset: GuestResetSysprep, Hostname: "WEB02", GuestUser: "Administrator"}, true},
+		{"cloud-init on windows", CloneCustomization{GuestReset: GuestResetCloudInit, GuestUser: "Administrator"}, true},
+		{"linux reset", CloneCustomization{GuestReset: GuestResetCloudInit, Hostname: "node-2", GuestOS: GuestLinux, GuestUser: "ubuntu"}, false},
+		{"bad password variable", CloneCustomization{Hostname: "WEB02", GuestUser: "Administrator", GuestPasswordEnv: "$(calc)"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.custom.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildCloneHardwareScript(t *testing.T) {
+	script := buildCloneHardwareScript(`Web"02`, &CloneCustomization{
+		RegenerateMAC:    true,
+		SwitchName:       "Lab",
+		ProcessorCount:   4,
+		MemoryStartupMB:  2048,
+		StripCheckpoints: true,
+	})
+
+	for _, want := range []string{`Get-VM -Name "Web` + "`" + `"02"`, "Remove-VMSnapshot", `-SwitchName "Lab"`, "-DynamicMacAddress", "-Count 4", "[int64]2048 * 1MB"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q:\n%s", want, script)
+		}
+	}
+	if strings.Index(script, "Remove-VMSnapshot") > strings.Index(script, "-DynamicMacAddress") {
+		t.Error("Expected checkpoints to be removed before other settings change")
+	}
+}
+
+// customizeRunner records scripts run against a clone and can fail the one containing failOn
+type customizeRunner struct {
+	progressRunner
+	scripts []string
+	failOn  string
+}
+
+func (r *customizeRunner) RunScript(ctx context.Context, script string) ([]byte, error) {
+	r.scripts = append(r.scripts, script)
+	if r.failOn != "" && strings.Contains(script, r.failOn) {
+		return []byte("Access is denied"), errors.New("exit status 1")
+	}
+	return r.progressRunner.RunScript(ctx, script)
+}
+
+func (r *customizeRunner) RunCmdlet(ctx context.Context, cmdlet string, args ...string) ([]byte, error) {
+	if cmdlet == "Get-VM" && len(args) == 6 && args[5] == "State" {
+		return []byte("Off"), nil
+	}
+	return r.progressRunner.RunCmdlet(ctx, cmdlet, args...)
+}
+
+func (r *customizeRunner) ran(fragment string) bool {
+	for _, script := range r.scripts {
+		if strings.Contains(script, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCloneVMWithProgress_Customizes(t *testing.T) {
+	runner := &customizeRunner{progressRunner: progressRunner{importedID: "clone-id", exportBytes: 64}}
+	manager := &Manager{Exec: runner}
+	custom := &CloneCustomization{RegenerateMAC: true, Hostname: "WEB02", GuestUser: "Administrator", GuestPasswordEnv: "GUEST_PASSWORD"}
+
+	events := make(chan ProgressEvent, 100)
+	if err := manager.CloneVMWithProgress(context.Background(), "Web01", "Web02", custom, events); err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if !runner.ran("-DynamicMacAddress") || !runner.ran("Rename-Computer -NewName 'WEB02'") {
+		t.Errorf("Expected MAC regeneration and guest rename, ran %v", runner.scripts)
+	}
+	if !runner.ran("$env:GUEST_PASSWORD") {
+		t.Error("Expected guest credentials to be read from the environment")
+	}
+	if len(runner.removedIDs) != 0 {
+		t.Errorf("Expected the clone to be kept, removed %v", runner.removedIDs)
+	}
+
+	sawCustomizing := false
+	for event := range events {
+		sawCustomizing = sawCustomizing || event.Phase == ProgressCustomizing
+	}
+	if !sawCustomizing {
+		t.Error("Expected customizing progress events")
+	}
+}
+
+func TestCloneVMWithProgress_RemovesCloneWhenCustomizationFails(t *testing.T) {
+	runner := &customizeRunner{
+		progressRunner: progressRunner{importedID: "clone-id", exportBytes: 64},
+		failOn:         "Rename-Computer",
+	}
+	runner.vms = []vmIdentity{{ID: "source-id", Name: "Web01"}}
+	manager := &Manager{Exec: runner}
+	custom := &CloneCustomization{StripCheckpoints: true, Hostname: "WEB02", GuestUser: "Administrator"}
+
+	err := manager.CloneVMWithProgress(context.Background(), "Web01", "Web02", custom, nil)
+	if err == nil || !strings.Contains(err.Error(), "rename guest") {
+		t.Fatalf("Expected rename failure, got %v", err)
+	}
+	if len(runner.removedIDs) != 1 || runner.removedIDs[0] != "clone-id" {
+		t.Errorf("Expected only the clone to be removed, got %v", runner.removedIDs)
+	}
+}
@@ -0,0 +1,269 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IssueKind classifies an import incompatibility reported by Compare-VM
+type IssueKind string
+
+// Incompatibility kinds
+const (
+	IssueMissingSwitch   IssueKind = "missing-switch"
+	IssueMissingMedia    IssueKind = "missing-media"
+	IssuePassthroughDisk IssueKind = "passthrough-disk"
+	IssueMissingDisk     IssueKind = "missing-disk"
+	IssueProcessor       IssueKind = "processor"
+	IssueMemory          IssueKind = "memory"
+	IssueOther           IssueKind = "other"
+)
+
+// CompatibilityIssue is one incompatibility between an exported VM and this host
+type CompatibilityIssue struct {
+	Kind      IssueKind `json:"kind"`
+	MessageID int64     `json:"messageId"`
+	Message   string    `json:"message"`
+	Source    string    `json:"source,omitempty"` // Type of the affected device, e.g. VMNetworkAdapter
+	Detail    string    `json:"detail,omitempty"` // Switch name or file path of the affected device
+	Fix       string    `json:"fix,omitempty"`    // Suggested fix
+	Fixable   bool      `json:"fixable"`          // Whether an ImportFixes option resolves it
+}
+
+// CompatibilityReport describes whether an export can be imported on this host
+type CompatibilityReport struct {
+	VMName          string               `json:"vmName"`
+	Generation      int                  `json:"generation"`
+	ProcessorCount  int                  `json:"processorCount"`
+	MemoryStartupMB int64                `json:"memoryStartupMB"`
+	Compatible      bool                 `json:"compatible"`
+	Issues          []CompatibilityIssue `json:"issues"`
+}
+
+// ImportFixes selects automatic fix-ups applied to the compatibility report before
+// Import-VM commits the import
+type ImportFixes struct {
+	Switches   bool   // Connect adapters whose switch is missing to SwitchName, or disconnect them
+	SwitchName string // Switch to connect to; empty disconnects the adapters
+	Media      bool   // Eject missing ISO images and remove pass-through disks
+	Resources  bool   // Lower processor count and memory to what the host provides
+}
+
+// classifyIssuePS defines a PowerShell function that maps an incompatibility to an IssueKind
+const classifyIssuePS = `
+	function Get-QuickVMIssueKind($issue) {
+		$source = $issue.Source
+		$type = if ($source) { $source.GetType().Name } else { "" }
+		if ($type -like "*NetworkAdapter*") { return "missing-switch" }
+		if ($type -like "*DvdDrive*") { return "missing-media" }
+		if ($type -like "*HardDiskDrive*") {
+			if ($null -ne $source.DiskNumber) { return "passthrough-disk" }
+			return "missing-disk"
+		}
+		if ($type -like "*Processor*" -or $issue.Message -match "processor") { return "processor" }
+		if ($type -like "*Memory*" -or $issue.Message -match "memory") { return "memory" }
+		return "other"
+	}
+`
+
+// CheckImportCompatibility runs Compare-VM with the same options Import-VM would use and
+// returns a structured report of every incompatibility with a suggested fix
+func (m *Manager) CheckImportCompatibility(ctx context.Context, opts ImportVMOptions) (*CompatibilityReport, error) {
+	vmcxPath, err := m.findVMCXFile(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		%s
+		$report = Compare-VM %s
+		[PSCustomObject]@{
+			VMName = $report.VM.Name
+			Generation = $report.VM.Generation
+			ProcessorCount = $report.VM.ProcessorCount
+			MemoryStartupMB = [int64]($report.VM.MemoryStartup/1MB)
+			Issues = @($report.Incompatibilities | ForEach-Object {
+				$source = $_.Source
+				[PSCustomObject]@{
+					Kind = Get-QuickVMIssueKind $_
+					MessageId = [int64]$_.MessageId
+					Message = $_.Message
+					Source = if ($source) { $source.GetType().Name } else { "" }
+					Detail = if ($source.SwitchName) { $source.SwitchName } elseif ($source.Path) { $source.Path } else { "" }
+				}
+			})
+		} | ConvertTo-Json -Depth 4
+	`, classifyIssuePS, compareVMArgs(vmcxPath, opts))
+
+	output, err := m.Exec.RunScript(ctx, script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check compatibility of '%s': %v\nOutput: %s", opts.Path, err, string(output))
+	}
+	return parseCompatibilityReport(output)
+}
+
+// parseCompatibilityReport parses the JSON written by the Compare-VM script and fills in fixes
+func parseCompatibilityReport(output []byte) (*CompatibilityReport, error) {
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty output from PowerShell")
+	}
+
+	var report CompatibilityReport
+	if err := json.Unmarshal([]byte(trimmed), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse compatibility report: %v", err)
+	}
+	if report.Issues == nil {
+		report.Issues = []CompatibilityIssue{}
+	}
+	for i := range report.Issues {
+		issue := &report.Issues[i]
+		issue.Fix, issue.Fixable = suggestFix(issue.Kind)
+	}
+	report.Compatible = len(report.Issues) == 0
+	return &report, nil
+}
+
+// suggestFix describes how to resolve an incompatibility and whether --fix handles it
+func suggestFix(kind IssueKind) (string, bool) {
+	switch kind {
+	case IssueMissingSwitch:
+		return "Connect the adapter to an existing switch (--fix switch --switch <name>) or disconnect it (--fix switch)", true
+	case IssueMissingMedia:
+		return "Eject the missing ISO image (--fix media)", true
+	case IssuePassthroughDisk:
+		return "Remove the pass-through disk (--fix media)", true
+	case IssueMissingDisk:
+		return "Copy the missing virtual hard disk next to the export or re-export the VM", false
+	case IssueProcessor:
+		return "Lower the processor count to what this host provides (--fix resources)", true
+	case IssueMemory:
+		return "Lower the VM's memory to fit this host (--fix resources)", true
+	default:
+		return "", false
+	}
+}
+
+// Fixes returns the ImportFixes needed to resolve every fixable issue in the report
+func (r *CompatibilityReport) Fixes() ImportFixes {
+	var fixes ImportFixes
+	for _, issue := range r.Issues {
+		switch issue.Kind {
+		case IssueMissingSwitch:
+			fixes.Switches = true
+		case IssueMissingMedia, IssuePassthroughDisk:
+			fixes.Media = true
+		case IssueProcessor, IssueMemory:
+			fixes.Resources = true
+		}
+	}
+	return fixes
+}
+
+// compareVMArgs returns the Compare-VM/Import-VM parameters for importing vmcxPath with opts
+func compareVMArgs(vmcxPath string, opts ImportVMOptions) string {
+	args := fmt.Sprintf(`-Path "%s"`, escapePSString(vmcxPath))
+	if opts.Copy {
+		args += " -Copy"
+	}
+	if opts.GenerateNewID {
+		args += " -GenerateNewId"
+	}
+	if opts.VHDPath != "" {
+		args += fmt.Sprintf(` -VhdDestinationPath "%s"`, escapePSString(opts.VHDPath))
+	}
+	return args
+}
+
+// importWithFixes imports through a compatibility report, applying fixes to it first.
+// Hyper-V only commits the import when no incompatibility remains.
+func (m *Manager) importWithFixes(ctx context.Context, vmcxPath string, opts ImportVMOptions) (string, error) {
+	rename := ""
+	if opts.NewName != "" {
+		rename = fmt.Sprintf(`$vm = $vm | Rename-VM -NewName "%s" -Passthru`, escapePSString(opts.NewName))
+	}
+
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		%s
+		$report = Compare-VM %s
+		foreach ($issue in @($report.Incompatibilities)) {
+			$source = $issue.Source
+			switch (Get-QuickVMIssueKind $issue) {
+				"missing-switch" { %s }
+				"missing-media" { %s }
+				"passthrough-disk" { %s }
+				"processor" { %s }
+				"memory" { %s }
+			}
+		}
+		$report = Compare-VM -CompatibilityReport $report
+		if (@($report.Incompatibilities).Count -gt 0) {
+			throw ("Unresolved incompatibilities: " + ((@($report.Incompatibilities) | ForEach-Object { $_.Message }) -join "; "))
+		}
+		$vm = Import-VM -CompatibilityReport $report
+		%s
+		$vm.Name
+	`, classifyIssuePS, compareVMArgs(vmcxPath, opts),
+		switchFixPS(opts.Fixes), mediaFixPS(opts.Fixes), passthroughFixPS(opts.Fixes),
+		processorFixPS(opts.Fixes), memoryFixPS(opts.Fixes), rename)
+
+	output, err := m.Exec.RunScript(ctx, script)
+	if err != nil {
+		return "", fmt.Errorf("failed to import VM from '%s': %v\nOutput: %s", opts.Path, err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func switchFixPS(fixes *ImportFixes) string {
+	switch {
+	case !fixes.Switches:
+		return ""
+	case fixes.SwitchName != "":
+		return fmt.Sprintf(`Connect-VMNetworkAdapter -VMNetworkAdapter $source -SwitchName "%s"`, escapePSString(fixes.SwitchName))
+	default:
+		return `Disconnect-VMNetworkAdapter -VMNetworkAdapter $source`
+	}
+}
+
+func mediaFixPS(fixes *ImportFixes) string {
+	if !fixes.Media {
+		return ""
+	}
+	return `Set-VMDvdDrive -VMDvdDrive $source -Path $null`
+}
+
+func passthroughFixPS(fixes *ImportFixes) string {
+	if !fixes.Media {
+		return ""
+	}
+	return `Remove-VMHardDiskDrive -VMHardDiskDrive $source`
+}
+
+func processorFixPS(fixes *ImportFixes) string {
+	if !fixes.Resources {
+		return ""
+	}
+	return `
+		$max = (Get-VMHost).LogicalProcessorCount
+		if ($report.VM.ProcessorCount -gt $max) { Set-VMProcessor -VM $report.VM -Count $max }`
+}
+
+// memoryFixPS caps memory at half of the host's physical memory, keeping dynamic memory
+// minimum <= startup <= maximum
+func memoryFixPS(fixes *ImportFixes) string {
+	if !fixes.Resources {
+		return ""
+	}
+	return `
+		$limit = [int64]([math]::Floor((Get-VMHost).MemoryCapacity / 2 / 2MB) * 2MB)
+		$memory = Get-VMMemory -VM $report.VM
+		if ($memory.DynamicMemoryEnabled) {
+			Set-VMMemory -VM $report.VM -MinimumBytes ([math]::Min($memory.Minimum, $limit)) -StartupBytes ([math]::Min($memory.Startup, $limit)) -MaximumBytes ([math]::Min($memory.Maximum, $limit))
+		} elseif ($memory.Startup -gt $limit) {
+			Set-VMMemory -VM $report.VM -StartupBytes $limit
+		}`
+}
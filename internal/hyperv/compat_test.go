@@ -0,0 +1,145 @@
+package hyperv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const compatReportJSON = `{
+	"VMName": "Web01",
+	"Generation": 2,
+	"ProcessorCount": 64,
+	"MemoryStartupMB": 4096,
+	"Issues": [
+		{"Kind": "missing-switch", "MessageId": 33012, "Message": "Could not find Ethernet switch 'LabNet'.", "Source": "VMNetworkAdapter", "Detail": "LabNet"},
+		{"Kind": "missing-media", "MessageId": 40010, "Message": "The file could not be found.", "Source": "DvdDrive", "Detail": "C:\\ISO\\setup.iso"},
+		{"Kind": "processor", "MessageId": 14420, "Message": "Too many processors.", "Source": "VMProcessor"},
+		{"Kind": "missing-disk", "MessageId": 40010, "Message": "The disk could not be found.", "Source": "HardDiskDrive"}
+	]
+}`
+
+func writeTestExport(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "Web01")
+	if err := os.MkdirAll(filepath.Join(dir, "Virtual Machines"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Virtual Machines", "1234.vmcx"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCheckImportCompatibility(t *testing.T) {
+	dir := writeTestExport(t)
+	manager, mock := newMockManager(compatReportJSON, nil)
+
+	report, err := manager.CheckImportCompatibility(context.Background(), ImportVMOptions{Path: dir, Copy: true, GenerateNewID: true})
+	if err != nil {
+		t.Fatalf("CheckImportCompatibility failed: %v", err)
+	}
+	if !strings.Contains(mock.LastScript, "Compare-VM -Path") || !strings.Contains(mock.LastScript, "-Copy -GenerateNewId") {
+		t.Errorf("Expected Compare-VM with the import options, got script:\n%s", mock.LastScript)
+	}
+
+	if report.Compatible || len(report.Issues) != 4 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if issue := report.Issues[0]; issue.Kind != IssueMissingSwitch || issue.MessageID != 33012 || !issue.Fixable || issue.Fix == "" {
+		t.Errorf("Unexpected switch issue: %+v", issue)
+	}
+	if issue := report.Issues[3]; issue.Fixable {
+		t.Errorf("Missing disks cannot be fixed automatically: %+v", issue)
+	}
+
+	fixes := report.Fixes()
+	if !fixes.Switches || !fixes.Media || !fixes.Resources {
+		t.Errorf("Expected all fix kinds to be suggested, got %+v", fixes)
+	}
+}
+
+func TestCheckImportCompatibility_NoIssues(t *testing.T) {
+	dir := writeTestExport(t)
+	manager, _ := newMockManager(`{"VMName": "Web01", "Generation": 2, "ProcessorCount": 2, "MemoryStartupMB": 2048, "Issues": []}`, nil)
+
+	report, err := manager.CheckImportCompatibility(context.Background(), ImportVMOptions{Path: dir})
+	if err != nil {
+		t.Fatalf("CheckImportCompatibility failed: %v", err)
+	}
+	if !report.Compatible || report.Issues == nil {
+		t.Errorf("Expected a compatible report with an empty issue list, got %+v", report)
+	}
+}
+
+func TestImportVM_WithFixes(t *testing.T) {
+	dir := writeTestExport(t)
+
+	tests := []struct {
+		name    string
+		fixes   ImportFixes
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "connect switch",
+			fixes:   ImportFixes{Switches: true, SwitchName: "Default Switch"},
+			want:    []string{`Connect-VMNetworkAdapter -VMNetworkAdapter $source -SwitchName "Default Switch"`},
+			notWant: []string{"Set-VMDvdDrive", "Set-VMProcessor"},
+		},
+		{
+			name:  "disconnect switch",
+			fixes: ImportFixes{Switches: true},
+			want:  []string{"Disconnect-VMNetworkAdapter"},
+		},
+		{
+			name:    "media and resources",
+			fixes:   ImportFixes{Media: true, Resources: true},
+			want:    []string{"Set-VMDvdDrive -VMDvdDrive $source -Path $null", "Remove-VMHardDiskDrive", "Set-VMProcessor", "Set-VMMemory"},
+			notWant: []string{"Connect-VMNetworkAdapter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, mock := newMockManager("Web01-Copy\n", nil)
+			fixes := tt.fixes
+			name, err := manager.ImportVM(context.Background(), ImportVMOptions{Path: dir, Copy: true, NewName: "Web01-Copy", Fixes: &fixes})
+			if err != nil {
+				t.Fatalf("ImportVM failed: %v", err)
+			}
+			if name != "Web01-Copy" {
+				t.Errorf("Expected imported name 'Web01-Copy', got %q", name)
+			}
+
+			for _, want := range append(tt.want, "Import-VM -CompatibilityReport $report", `Rename-VM -NewName "Web01-Copy"`) {
+				if !strings.Contains(mock.LastScript, want) {
+					t.Errorf("Expected %q in script", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(mock.LastScript, notWant) {
+					t.Errorf("Did not expect %q in script", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestGetExportedVMInfo(t *testing.T) {
+	dir := writeTestExport(t)
+	manager, _ := newMockManager(compatReportJSON, nil)
+
+	info, err := manager.GetExportedVMInfo(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("GetExportedVMInfo failed: %v", err)
+	}
+	if info["VMName"] != "Web01" || info["ProcessorCount"] != "64" {
+		t.Errorf("Unexpected info: %v", info)
+	}
+	if !strings.Contains(info["Incompatibilities"], "Could not find Ethernet switch 'LabNet'.; The file") {
+		t.Errorf("Expected joined incompatibility messages, got %q", info["Incompatibilities"])
+	}
+}
@@ -0,0 +1,201 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Session kinds
+const (
+	SessionConsole = "console"
+	SessionRDP     = "rdp"
+)
+
+// Programs launched for each session kind
+const (
+	vmconnectProgram = "vmconnect.exe"
+	rdpProgram       = "mstsc.exe"
+)
+
+// Session is a console or RDP window opened by quickvm
+type Session struct {
+	ID      int       `json:"id"`
+	Kind    string    `json:"kind"` // SessionConsole or SessionRDP
+	VMName  string    `json:"vmName,omitempty"`
+	Target  string    `json:"target"` // Hyper-V host of a console, address of an RDP session
+	PID     int       `json:"pid"`
+	Program string    `json:"program"`
+	Started time.Time `json:"started"`
+}
+
+// ConsoleOptions configures a vmconnect window
+type ConsoleOptions struct {
+	Host string // Hyper-V host; "localhost" when empty
+	// Enhanced turns enhanced session mode on or off on the host before connecting;
+	// nil leaves the host setting alone
+	Enhanced *bool
+}
+
+// GetSessionsPath returns the path of the session state file, ~/.quickvm/sessions.json
+func GetSessionsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".quickvm", "sessions.json"), nil
+}
+
+// OpenConsole opens vmconnect for a VM and records the session
+func (m *Manager) OpenConsole(ctx context.Context, vmName string, opts ConsoleOptions) (*Session, error) {
+	host := opts.Host
+	if host == "" {
+		host = "localhost"
+	}
+	if host == "localhost" {
+		exists, err := m.VMExists(ctx, vmName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("VM '%s' not found", vmName)
+		}
+	}
+	if opts.Enhanced != nil {
+		if err := m.SetEnhancedSessionMode(ctx, host, *opts.Enhanced); err != nil {
+			return nil, err
+		}
+	}
+
+	pid, err := m.launcher().Start(vmconnectProgram, host, vmName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open console: %w", err)
+	}
+	return recordSession(Session{Kind: SessionConsole, VMName: vmName, Target: host, PID: pid, Program: vmconnectProgram})
+}
+
+// SetEnhancedSessionMode allows or disallows enhanced session connections on a Hyper-V host
+func (m *Manager) SetEnhancedSessionMode(ctx context.Context, host string, enabled bool) error {
+	args := []string{"-EnableEnhancedSessionMode", fmt.Sprintf("$%t", enabled)}
+	if host != "" && host != "localhost" {
+		args = append([]string{"-ComputerName", host}, args...)
+	}
+	output, err := m.Exec.RunCmdlet(ctx, "Set-VMHost", args...)
+	if err != nil {
+		return fmt.Errorf("failed to set enhanced session mode: %v\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// ListSessions returns the recorded sessions whose window is still open and forgets the others
+func (m *Manager) ListSessions(ctx context.Context) ([]Session, error) {
+	sessions, err := loadSessions()
+	if err != nil {
+		return nil, err
+	}
+	open := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		name, err := m.launcher().ProcessName(ctx, s.PID)
+		if err != nil {
+			return nil, err
+		}
+		// A recycled process ID belongs to another program
+		if strings.EqualFold(name, strings.TrimSuffix(s.Program, ".exe")) {
+			open = append(open, s)
+		}
+	}
+	if len(open) != len(sessions) {
+		if err := saveSessions(open); err != nil {
+			return nil, err
+		}
+	}
+	return open, nil
+}
+
+// CloseSessions closes the open sessions selected by match and returns them
+func (m *Manager) CloseSessions(ctx context.Context, match func(Session) bool) ([]Session, error) {
+	sessions, err := m.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var closed, kept []Session
+	var errs []error
+	for _, s := range sessions {
+		if !match(s) {
+			kept = append(kept, s)
+			continue
+		}
+		if err := m.launcher().Stop(ctx, s.PID); err != nil {
+			errs = append(errs, fmt.Errorf("session %d: %w", s.ID, err))
+			kept = append(kept, s)
+			continue
+		}
+		closed = append(closed, s)
+	}
+	if err := saveSessions(kept); err != nil {
+		errs = append(errs, err)
+	}
+	return closed, errors.Join(errs...)
+}
+
+// recordSession assigns the next session ID and adds the session to the state file
+func recordSession(s Session) (*Session, error) {
+	sessions, err := loadSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range sessions {
+		s.ID = max(s.ID, existing.ID)
+	}
+	s.ID++
+	s.Started = time.Now()
+	if err := saveSessions(append(sessions, s)); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func loadSessions() ([]Session, error) {
+	path, err := GetSessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the user's home directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+	var sessions []Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func saveSessions(sessions []Session) error {
+	path, err := GetSessionsPath()
+	if err != nil {
+		return err
+	}
+	if sessions == nil {
+		sessions = []Session{}
+	}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeLauncher records started programs and reports them running until stopped
+type fakeLauncher struct {
+	nextPID int
+	started map[int][]string // Program and arguments by process ID
+	stopped []int
+}
+
+func (f *fakeLauncher) Start(program string, args ...string) (int, error) {
+	if f.started == nil {
+		f.started = make(map[int][]string)
+	}
+	f.nextPID += 100
+	f.started[f.nextPID] = append([]string{program}, args...)
+	return f.nextPID, nil
+}
+
+func (f *fakeLauncher) ProcessName(_ context.Context, pid int) (string, error) {
+	if started, ok := f.started[pid]; ok {
+		return strings.TrimSuffix(started[0], ".exe"), nil
+	}
+	return "", nil
+}
+
+func (f *fakeLauncher) Stop(_ context.Context, pid int) error {
+	if _, ok := f.started[pid]; !ok {
+		return fmt.Errorf("no process %d", pid)
+	}
+	delete(f.started, pid)
+	f.stopped = append(f.stopped, pid)
+	return nil
+}
+
+func newConsoleManager(t *testing.T) (*Manager, *MockRunner, *fakeLauncher) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	manager, mock := newMockManager("Web01", nil)
+	launcher := &fakeLauncher{}
+	manager.Launcher = launcher
+	return manager, mock, launcher
+}
+
+func TestOpenConsole_LaunchesVMConnectAndRecordsSession(t *testing.T) {
+	manager, mock, launcher := newConsoleManager(t)
+	enhanced := true
+
+	session, err := manager.OpenConsole(context.Background(), "Web01", ConsoleOptions{Enhanced: &enhanced})
+	if err != nil {
+		t.Fatalf("OpenConsole failed: %v", err)
+	}
+	if mock.LastCmdlet != "Set-VMHost" || strings.Join(mock.LastArgs, " ") != "-EnableEnhancedSessionMode $true" {
+		t.Errorf("Expected enhanced session mode to be turned on, got %s %v", mock.LastCmdlet, mock.LastArgs)
+	}
+	if got := strings.Join(launcher.started[session.PID], " "); got != "vmconnect.exe localhost Web01" {
+		t.Errorf("Unexpected launch: %s", got)
+	}
+	if session.ID != 1 || session.Kind != SessionConsole || session.VMName != "Web01" {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	rdp, err := manager.OpenRDP(context.Background(), "Web01", "10.0.0.5", "")
+	if err != nil {
+		t.Fatalf("OpenRDP failed: %v", err)
+	}
+	if rdp.ID != 2 || strings.Join(launcher.started[rdp.PID], " ") != "mstsc.exe /v:10.0.0.5" {
+		t.Errorf("Unexpected RDP session: %+v", rdp)
+	}
+}
+
+func TestOpenConsole_MissingVM(t *testing.T) {
+	manager, mock, launcher := newConsoleManager(t)
+	mock.MockOutput = ""
+
+	if _, err := manager.OpenConsole(context.Background(), "Ghost", ConsoleOptions{}); err == nil {
+		t.Fatal("Expected an error for a missing VM")
+	}
+	if len(launcher.started) != 0 {
+		t.Error("Expected no console to be launched")
+	}
+}
+
+func TestSessions_ListForgetsClosedWindowsAndCloseStopsThem(t *testing.T) {
+	manager, _, launcher := newConsoleManager(t)
+	ctx := context.Background()
+	first, _ := manager.OpenConsole(ctx, "Web01", ConsoleOptions{})
+	second, _ := manager.OpenConsole(ctx, "Web01", ConsoleOptions{})
+	third, _ := manager.OpenRDP(ctx, "Db01", "10.0.0.6", "")
+
+	// The user closed the first window; its PID now belongs to another program
+	launcher.started[first.PID] = []string{"notepad.exe"}
+	sessions, err := manager.ListSessions(ctx)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != second.ID || sessions[1].ID != third.ID {
+		t.Fatalf("Expected the two open sessions, got %+v", sessions)
+	}
+
+	closed, err := manager.CloseSessions(ctx, func(s Session) bool { return s.VMName == "Db01" })
+	if err != nil {
+		t.Fatalf("CloseSessions failed: %v", err)
+	}
+	if len(closed) != 1 || closed[0].ID != third.ID || len(launcher.stopped) != 1 || launcher.stopped[0] != third.PID {
+		t.Errorf("Expected only the RDP session to be closed, got %+v", closed)
+	}
+	if sessions, _ := manager.ListSessions(ctx); len(sessions) != 1 || sessions[0].ID != second.ID {
+		t.Errorf("Expected one session left, got %+v", sessions)
+	}
+
+	// IDs continue from the highest recorded session
+	next, _ := manager.OpenConsole(ctx, "Web01", ConsoleOptions{})
+	if next.ID != second.ID+1 {
+		t.Errorf("Expected session ID %d, got %d", second.ID+1, next.ID)
+	}
+}
+
+func TestProcessLauncher_ProcessName(t *testing.T) {
+	mock := &MockRunner{MockOutput: "vmconnect\r\n"}
+	launcher := &ProcessLauncher{Exec: mock}
+	if name, err := launcher.ProcessName(context.Background(), 42); err != nil || name != "vmconnect" {
+		t.Errorf("ProcessName() = %q, %v, want vmconnect", name, err)
+	}
+	if mock.LastCmdlet != "Get-Process" || mock.LastArgs[1] != "42" {
+		t.Errorf("Unexpected lookup %s %v", mock.LastCmdlet, mock.LastArgs)
+	}
+
+	// PowerShell exits 1 without output once the process is gone
+	mock.MockError = fmt.Errorf("exit status 1")
+	if name, err := launcher.ProcessName(context.Background(), 42); err != nil || name != "" {
+		t.Errorf("Expected an exited process to have no name, got %q, %v", name, err)
+	}
+}
@@ -0,0 +1,93 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// VMDisk is a virtual hard disk attached to a VM
+type VMDisk struct {
+	Path           string `json:"path"`
+	ControllerType string `json:"controllerType"` // IDE or SCSI
+	ControllerSlot string `json:"controllerSlot"` // Controller number and location, e.g. 0:1
+	VhdType        string `json:"vhdType"`        // Fixed, Dynamic or Differencing
+	FileSize       int64  `json:"fileSize"`       // Bytes used on the host
+	Size           int64  `json:"size"`           // Virtual size in bytes
+	ParentPath     string `json:"parentPath,omitempty"`
+}
+
+// VMNetworkAdapter is a network adapter of a VM
+type VMNetworkAdapter struct {
+	Name        string   `json:"name"`
+	SwitchName  string   `json:"switchName"`
+	MacAddress  string   `json:"macAddress"`
+	Status      string   `json:"status"`
+	IPAddresses []string `json:"ipAddresses"`
+}
+
+// GetVMDisks returns the virtual hard disks attached to a VM
+func (m *Manager) GetVMDisks(ctx context.Context, vmName string) ([]VMDisk, error) {
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$disks = foreach ($drive in Get-VMHardDiskDrive -VMName "%s") {
+			$vhd = Get-VHD -Path $drive.Path -ErrorAction SilentlyContinue
+			[PSCustomObject]@{
+				Path           = $drive.Path
+				ControllerType = $drive.ControllerType.ToString()
+				ControllerSlot = "$($drive.ControllerNumber):$($drive.ControllerLocation)"
+				VhdType        = if ($vhd) { $vhd.VhdType.ToString() } else { "" }
+				FileSize       = if ($vhd) { [int64]$vhd.FileSize } else { [int64]0 }
+				Size           = if ($vhd) { [int64]$vhd.Size } else { [int64]0 }
+				ParentPath     = if ($vhd) { $vhd.ParentPath } else { "" }
+			}
+		}
+		ConvertTo-Json -InputObject @($disks) -Compress
+	`, escapePSString(vmName))
+
+	var disks []VMDisk
+	if err := m.runJSONList(ctx, script, &disks); err != nil {
+		return nil, fmt.Errorf("failed to get disks of VM '%s': %w", vmName, err)
+	}
+	return disks, nil
+}
+
+// GetVMNetworkAdapters returns the network adapters of a VM
+func (m *Manager) GetVMNetworkAdapters(ctx context.Context, vmName string) ([]VMNetworkAdapter, error) {
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$adapters = foreach ($adapter in Get-VMNetworkAdapter -VMName "%s") {
+			[PSCustomObject]@{
+				Name        = $adapter.Name
+				SwitchName  = "$($adapter.SwitchName)"
+				MacAddress  = $adapter.MacAddress
+				Status      = "$($adapter.Status)"
+				IPAddresses = @($adapter.IPAddresses)
+			}
+		}
+		ConvertTo-Json -InputObject @($adapters) -Compress
+	`, escapePSString(vmName))
+
+	var adapters []VMNetworkAdapter
+	if err := m.runJSONList(ctx, script, &adapters); err != nil {
+		return nil, fmt.Errorf("failed to get network adapters of VM '%s': %w", vmName, err)
+	}
+	return adapters, nil
+}
+
+// runJSONList runs a script that prints a JSON array and decodes it into list
+func (m *Manager) runJSONList(ctx context.Context, script string, list interface{}) error {
+	output, err := m.Exec.RunScript(ctx, script)
+	if err != nil {
+		return fmt.Errorf("%v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(trimmed), list); err != nil {
+		return fmt.Errorf("failed to parse output: %w", err)
+	}
+	return nil
+}
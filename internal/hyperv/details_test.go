@@ -0,0 +1,41 @@
+package hyperv
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetVMDisks(t *testing.T) {
+	manager, mock := newMockManager(`[{"path":"D:\\VMs\\Web01.vhdx","controllerType":"SCSI","controllerSlot":"0:0","vhdType":"Differencing","fileSize":1073741824,"size":68719476736,"parentPath":"D:\\Templates\\base.vhdx"}]`, nil)
+
+	disks, err := manager.GetVMDisks(context.Background(), `Web"01`)
+	if err != nil {
+		t.Fatalf("GetVMDisks failed: %v", err)
+	}
+	if len(disks) != 1 || disks[0].VhdType != "Differencing" || disks[0].Size != 64<<30 || disks[0].ParentPath == "" {
+		t.Errorf("Unexpected disks %+v", disks)
+	}
+	if !strings.Contains(mock.LastScript, "Web`\"01") || !strings.Contains(mock.LastScript, "-InputObject @($disks)") {
+		t.Errorf("Unexpected script:\n%s", mock.LastScript)
+	}
+}
+
+func TestGetVMNetworkAdapters(t *testing.T) {
+	manager, _ := newMockManager("[]\r\n", nil)
+	adapters, err := manager.GetVMNetworkAdapters(context.Background(), "Web01")
+	if err != nil || len(adapters) != 0 {
+		t.Errorf("Expected no adapters, got %v, %v", adapters, err)
+	}
+
+	manager, _ = newMockManager(`[{"name":"Network Adapter","switchName":"LabNet","macAddress":"00155D010203","status":"Ok","ipAddresses":["10.0.0.5","fe80::1"]}]`, nil)
+	adapters, err = manager.GetVMNetworkAdapters(context.Background(), "Web01")
+	if err != nil || len(adapters) != 1 || adapters[0].SwitchName != "LabNet" || len(adapters[0].IPAddresses) != 2 {
+		t.Errorf("Unexpected adapters %+v, %v", adapters, err)
+	}
+
+	manager, _ = newMockManager("not json", nil)
+	if _, err := manager.GetVMNetworkAdapters(context.Background(), "Web01"); err == nil {
+		t.Error("Expected invalid output to be reported")
+	}
+}
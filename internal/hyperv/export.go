@@ -2,7 +2,6 @@ package hyperv
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -17,9 +16,11 @@ type ExportVMOptions struct {
 // ImportVMOptions contains options for importing a VM
 type ImportVMOptions struct {
 	Path          string
-	Copy          bool   // Copy the VM files instead of registering in place
-	GenerateNewID bool   // Generate new VM ID
-	VHDPath       string // Optional: custom path for VHD files
+	Copy          bool         // Copy the VM files instead of registering in place
+	GenerateNewID bool         // Generate new VM ID
+	VHDPath       string       // Optional: custom path for VHD files
+	NewName       string       // Optional: rename the imported VM
+	Fixes         *ImportFixes // Optional: fix incompatibilities before the import is committed
 }
 
 // ExportVM exports a VM by index to the specified path
@@ -37,14 +38,11 @@ func (m *Manager) ExportVM(ctx context.Context, vmIndex int, path string) error
 	return m.ExportVMByName(ctx, vm.Name, path)
 }
 
-// ExportVMByName exports a VM by name to the specified path
+// ExportVMByName exports a VM by name to the specified path and writes an
+// export manifest (file list with SHA-256 checksums) into the export directory.
+// A partially written export is removed if the export fails or ctx is cancelled.
 func (m *Manager) ExportVMByName(ctx context.Context, vmName, path string) error {
-	output, err := m.Exec.RunCmdlet(ctx, "Export-VM", "-Name", vmName, "-Path", path)
-	if err != nil {
-		return fmt.Errorf("failed to export VM '%s': %v\nOutput: %s", vmName, err, string(output))
-	}
-
-	return nil
+	return m.ExportVMWithProgress(ctx, vmName, path, nil)
 }
 
 // ImportVM imports a VM from the specified path
@@ -55,6 +53,10 @@ func (m *Manager) ImportVM(ctx context.Context, opts ImportVMOptions) (string, e
 		return "", err
 	}
 
+	if opts.Fixes != nil {
+		return m.importWithFixes(ctx, vmcxPath, opts)
+	}
+
 	// Build arguments for RunCmdlet safely
 	args := []string{"-Path", vmcxPath}
 
@@ -91,6 +93,12 @@ func (m *Manager) ImportVM(ctx context.Context, opts ImportVMOptions) (string, e
 	// As established in GetVMStatus, passing "|" as a separate arg works if the shell concatenates them.
 	// Let's rely on that behavior of "powershell -Command ... arg1 arg2 ..." -> it effectively joins them.
 
+	// Rename through the pipeline so the imported VM is targeted even when
+	// another VM with the same name already exists
+	if opts.NewName != "" {
+		args = append(args, "|", "Rename-VM", "-NewName", opts.NewName, "-Passthru")
+	}
+
 	args = append(args, "|", "Select-Object", "-ExpandProperty", "Name")
 
 	output, err := m.Exec.RunCmdlet(ctx, "Import-VM", args...)
@@ -110,6 +118,17 @@ func (m *Manager) findVMCXFile(basePath string) (string, error) {
 		return basePath, nil
 	}
 
+	// Exports written by quickvm record which configuration file belongs to the VM
+	if HasExportManifest(basePath) {
+		manifest, err := ReadExportManifest(basePath)
+		if err != nil {
+			return "", err
+		}
+		if manifest.ConfigFile != "" {
+			return filepath.Join(basePath, filepath.FromSlash(manifest.ConfigFile)), nil
+		}
+	}
+
 	// Search in "Virtual Machines" subdirectory (standard Hyper-V export structure)
 	vmDir := filepath.Join(basePath, "Virtual Machines")
 	pattern := filepath.Join(vmDir, "*.vmcx")
@@ -119,7 +138,10 @@ func (m *Manager) findVMCXFile(basePath string) (string, error) {
 		return "", fmt.Errorf("error searching for .vmcx file: %v", err)
 	}
 
-	if len(matches) > 0 {
+	if len(matches) > 1 {
+		return "", fmt.Errorf("multiple .vmcx files found in '%s'; pass the path of the one to import", vmDir)
+	}
+	if len(matches) == 1 {
 		return matches[0], nil
 	}
 
@@ -130,54 +152,33 @@ func (m *Manager) findVMCXFile(basePath string) (string, error) {
 		return "", fmt.Errorf("error searching for .vmcx file: %v", err)
 	}
 
-	if len(matches) > 0 {
+	if len(matches) > 1 {
+		return "", fmt.Errorf("multiple .vmcx files found in '%s'; pass the path of the one to import", basePath)
+	}
+	if len(matches) == 1 {
 		return matches[0], nil
 	}
 
 	return "", fmt.Errorf("no .vmcx file found in '%s' or '%s'", basePath, vmDir)
 }
 
-// GetExportedVMInfo gets information about an exported VM
+// GetExportedVMInfo gets information about an exported VM.
+// See CheckImportCompatibility for a structured report of incompatibilities.
 func (m *Manager) GetExportedVMInfo(ctx context.Context, path string) (map[string]string, error) {
-	vmcxPath, err := m.findVMCXFile(path)
+	report, err := m.CheckImportCompatibility(ctx, ImportVMOptions{Path: path})
 	if err != nil {
 		return nil, err
 	}
 
-	psScript := fmt.Sprintf(`
-		$report = Compare-VM -Path "%s"
-		@{
-			VMName = $report.VM.Name
-			State = $report.VM.State.ToString()
-			MemoryMB = [int]($report.VM.MemoryStartup/1MB)
-			ProcessorCount = $report.VM.ProcessorCount
-			Incompatibilities = ($report.Incompatibilities | ForEach-Object { $_.Message }) -join "; "
-		} | ConvertTo-Json
-	`, vmcxPath)
-	// Note: vmcxPath is from filepath.Glob which is local. Still potential risk if malicious filenames, but low.
-	// Ideally pass path as arg.
-
-	output, err := m.Exec.RunScript(ctx, psScript)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get VM info from '%s': %v\nOutput: %s", path, err, string(output))
-	}
-
-	// Parse JSON output
-	outputStr := strings.TrimSpace(string(output))
-	if outputStr == "" {
-		return nil, fmt.Errorf("empty output from PowerShell")
-	}
-
-	var info map[string]interface{}
-	if err := json.Unmarshal([]byte(outputStr), &info); err != nil {
-		return nil, fmt.Errorf("failed to parse VM info: %v", err)
-	}
-
-	// Convert to string map
-	result := make(map[string]string)
-	for k, v := range info {
-		result[k] = fmt.Sprintf("%v", v)
+	messages := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Message)
 	}
 
-	return result, nil
+	return map[string]string{
+		"VMName":            report.VMName,
+		"MemoryMB":          fmt.Sprintf("%d", report.MemoryStartupMB),
+		"ProcessorCount":    fmt.Sprintf("%d", report.ProcessorCount),
+		"Incompatibilities": strings.Join(messages, "; "),
+	}, nil
 }
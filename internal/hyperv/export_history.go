@@ -0,0 +1,127 @@
+package hyperv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxExportRecords is how many exports the history keeps; the totals are not limited
+const maxExportRecords = 500
+
+// ExportRecord is a finished export in the export history
+type ExportRecord struct {
+	VMName          string    `json:"vmName"`
+	Path            string    `json:"path"`
+	Started         time.Time `json:"started"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Success         bool      `json:"success"`
+}
+
+// ExportTotals counts the exports of one VM by result
+type ExportTotals struct {
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+}
+
+// ExportHistory holds the recent exports and the number of exports per VM ever recorded
+type ExportHistory struct {
+	Totals  map[string]ExportTotals `json:"totals"`  // By VM name; never truncated, so they only grow
+	Records []ExportRecord          `json:"records"` // Recent exports, oldest first
+}
+
+// add records an export, dropping the oldest records beyond maxExportRecords
+func (h *ExportHistory) add(record ExportRecord) {
+	if h.Totals == nil {
+		h.Totals = make(map[string]ExportTotals)
+	}
+	totals := h.Totals[record.VMName]
+	if record.Success {
+		totals.Success++
+	} else {
+		totals.Failure++
+	}
+	h.Totals[record.VMName] = totals
+
+	h.Records = append(h.Records, record)
+	if len(h.Records) > maxExportRecords {
+		h.Records = h.Records[len(h.Records)-maxExportRecords:]
+	}
+}
+
+// exportHistoryMu serialises history updates of concurrent exports in one process
+var exportHistoryMu sync.Mutex
+
+// GetExportHistoryPath returns the path of the export history, ~/.quickvm/export-history.json
+func GetExportHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".quickvm", "export-history.json"), nil
+}
+
+// LoadExportHistory returns the export history; it is empty when nothing was recorded
+func LoadExportHistory() (*ExportHistory, error) {
+	path, err := GetExportHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the user's home directory
+	if errors.Is(err, os.ErrNotExist) {
+		return &ExportHistory{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export history: %w", err)
+	}
+
+	history := &ExportHistory{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		// Older histories are a plain list of records; the totals start from them
+		var records []ExportRecord
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse export history: %w", err)
+		}
+		for _, record := range records {
+			history.add(record)
+		}
+		return history, nil
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("failed to parse export history: %w", err)
+	}
+	return history, nil
+}
+
+// recordExport adds an export to the history
+func recordExport(record ExportRecord) error {
+	exportHistoryMu.Lock()
+	defer exportHistoryMu.Unlock()
+
+	history, err := LoadExportHistory()
+	if err != nil {
+		return err
+	}
+	history.add(record)
+
+	path, err := GetExportHistoryPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save export history: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -285,3 +286,20 @@ func TestExportVMOptions_Struct(t *testing.T) {
 		t.Errorf("Expected Path=C:\\Backups\\VMs, got %s", opts.Path)
 	}
 }
+
+func TestImportVM_NewName(t *testing.T) {
+	manager, mock := newMockManager("Renamed", nil)
+
+	name, err := manager.ImportVM(context.TODO(), ImportVMOptions{Path: "C:\\Export\\VM.vmcx", NewName: "Renamed"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if name != "Renamed" {
+		t.Errorf("Expected imported name 'Renamed', got %q", name)
+	}
+
+	args := strings.Join(mock.LastArgs, " ")
+	if !strings.Contains(args, "-Passthru | Rename-VM -NewName Renamed -Passthru | Select-Object") {
+		t.Errorf("Expected rename in the import pipeline, got %q", args)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // VM represents a Hyper-V virtual machine
@@ -77,13 +79,16 @@ func (p *PowerShellRunner) RunCmdlet(ctx context.Context, cmdlet string, args ..
 
 // Manager handles Hyper-V operations
 type Manager struct {
-	Exec ShellExecutor
+	Exec          ShellExecutor
+	Launcher      Launcher // Starts console and RDP windows; nil launches them with Exec
+	RecordExports bool     // Append finished exports to the export history
 }
 
 // NewManager creates a new Hyper-V manager with default PowerShell runner
 func NewManager() *Manager {
 	return &Manager{
-		Exec: &PowerShellRunner{},
+		Exec:          &PowerShellRunner{},
+		RecordExports: true,
 	}
 }
 
@@ -301,3 +306,27 @@ func (m *Manager) GetVMStatus(ctx context.Context, name string) (string, error)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// ParseUptime parses a .NET TimeSpan string ([d.]hh:mm:ss[.fffffff]); unparsable values are 0
+func ParseUptime(s string) time.Duration {
+	var days int
+	if dot, colon := strings.Index(s, "."), strings.Index(s, ":"); dot >= 0 && dot < colon {
+		days, _ = strconv.Atoi(s[:dot])
+		s = s[dot+1:]
+	}
+	s, _, _ = strings.Cut(s, ".")
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	var total time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0
+		}
+		total += time.Duration(n) * unit
+	}
+	return total + time.Duration(days)*24*time.Hour
+}
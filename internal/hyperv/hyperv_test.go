@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 // skipIfNoHyperVMain skips test in CI/CD environment or if not admin
@@ -260,3 +261,18 @@ func BenchmarkGetVMs(b *testing.B) {
 		_, _ = manager.GetVMs(context.TODO())
 	}
 }
+
+func TestParseUptime(t *testing.T) {
+	tests := map[string]time.Duration{
+		"00:05:00":         5 * time.Minute,
+		"1.02:00:00.1234":  26 * time.Hour,
+		"03:00:01.5000000": 3*time.Hour + time.Second,
+		"":                 0,
+		"not a time span":  0,
+	}
+	for s, want := range tests {
+		if got := ParseUptime(s); got != want {
+			t.Errorf("ParseUptime(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
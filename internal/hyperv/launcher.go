@@ -0,0 +1,67 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Launcher starts and stops the desktop programs quickvm opens, such as vmconnect and mstsc
+type Launcher interface {
+	// Start starts a program detached from quickvm and returns its process ID
+	Start(program string, args ...string) (int, error)
+	// ProcessName returns the name of a running process, or "" if it has exited
+	ProcessName(ctx context.Context, pid int) (string, error)
+	// Stop ends a process
+	Stop(ctx context.Context, pid int) error
+}
+
+// ProcessLauncher starts programs with os/exec and looks them up through PowerShell
+type ProcessLauncher struct {
+	Exec ShellExecutor
+}
+
+// Start starts program without a context: the window stays open after quickvm exits
+func (l *ProcessLauncher) Start(program string, args ...string) (int, error) {
+	cmd := exec.Command(program, args...) //nolint:gosec // G204: program is vmconnect or mstsc
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s: %w", program, err)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// ProcessName returns the name of a running process, or "" if it has exited
+func (l *ProcessLauncher) ProcessName(ctx context.Context, pid int) (string, error) {
+	output, err := l.Exec.RunCmdlet(ctx, "Get-Process", "-Id", strconv.Itoa(pid), "-ErrorAction", "SilentlyContinue",
+		"|", "Select-Object", "-ExpandProperty", "ProcessName")
+	name := strings.TrimSpace(string(output))
+	if err != nil && name == "" {
+		// Get-Process fails without output for an exited process, which makes PowerShell exit 1
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up process %d: %v\nOutput: %s", pid, err, string(output))
+	}
+	return name, nil
+}
+
+// Stop ends a process
+func (l *ProcessLauncher) Stop(ctx context.Context, pid int) error {
+	output, err := l.Exec.RunCmdlet(ctx, "Stop-Process", "-Id", strconv.Itoa(pid), "-Force")
+	if err != nil {
+		return fmt.Errorf("failed to stop process %d: %v\nOutput: %s", pid, err, string(output))
+	}
+	return nil
+}
+
+// launcher returns the manager's launcher, defaulting to one that uses its executor
+func (m *Manager) launcher() Launcher {
+	if m.Launcher != nil {
+		return m.Launcher
+	}
+	return &ProcessLauncher{Exec: m.Exec}
+}
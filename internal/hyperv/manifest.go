@@ -0,0 +1,323 @@
+package hyperv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ExportManifestFile is the name of the manifest written into every VM export directory
+	ExportManifestFile = "quickvm-manifest.json"
+
+	exportManifestFormatVersion = 1
+)
+
+// FileChecksum records the size and SHA-256 hash of a file, relative to a root directory
+type FileChecksum struct {
+	Path   string `json:"path"` // Slash-separated path relative to the manifest root
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// VMSpec holds the hardware configuration of a VM at export time
+type VMSpec struct {
+	ID              string `json:"id"`
+	Generation      int    `json:"generation"`
+	ProcessorCount  int    `json:"processorCount"`
+	MemoryStartupMB int64  `json:"memoryStartupMB"`
+}
+
+// ExportManifest describes a single VM export and the files it contains
+type ExportManifest struct {
+	FormatVersion int            `json:"formatVersion"`
+	VMName        string         `json:"vmName"`
+	VMID          string         `json:"vmId"`
+	ConfigFile    string         `json:"configFile"` // .vmcx path relative to the export directory
+	CreatedAt     time.Time      `json:"createdAt"`
+	SourceHost    string         `json:"sourceHost"`
+	Spec          *VMSpec        `json:"spec,omitempty"`
+	Files         []FileChecksum `json:"files"`
+}
+
+// ExportVerification is the result of recomputing the checksums of an export or bundle
+type ExportVerification struct {
+	Path       string   `json:"path"`
+	Kind       string   `json:"kind"` // "export" or "bundle"
+	Name       string   `json:"name"` // VM name or workspace name
+	Valid      bool     `json:"valid"`
+	Checked    int      `json:"checked"`
+	Missing    []string `json:"missing,omitempty"`
+	Mismatched []string `json:"mismatched,omitempty"`
+}
+
+// Problems describes every missing or mismatched file
+func (v *ExportVerification) Problems() []string {
+	problems := make([]string, 0, len(v.Missing)+len(v.Mismatched))
+	for _, path := range v.Missing {
+		problems = append(problems, fmt.Sprintf("%s: missing", path))
+	}
+	for _, path := range v.Mismatched {
+		problems = append(problems, fmt.Sprintf("%s: checksum mismatch", path))
+	}
+	return problems
+}
+
+// check recomputes the checksums of files under root and records any differences
+func (v *ExportVerification) check(root string, files []FileChecksum) error {
+	for _, expected := range files {
+		actual, err := checksumFile(root, expected.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			v.Missing = append(v.Missing, expected.Path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		v.Checked++
+		if actual.Size != expected.Size || actual.SHA256 != expected.SHA256 {
+			v.Mismatched = append(v.Mismatched, expected.Path)
+		}
+	}
+	return nil
+}
+
+// HasExportManifest reports whether dir contains a quickvm export manifest
+func HasExportManifest(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ExportManifestFile))
+	return err == nil
+}
+
+// ReadExportManifest reads the manifest of the VM export in dir
+func ReadExportManifest(dir string) (*ExportManifest, error) {
+	//nolint:gosec // G304: Reading the manifest of a user-provided export is intended
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no %s found in '%s'", ExportManifestFile, dir)
+		}
+		return nil, fmt.Errorf("failed to read export manifest: %w", err)
+	}
+
+	var manifest ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse export manifest: %w", err)
+	}
+	if manifest.FormatVersion > exportManifestFormatVersion {
+		return nil, fmt.Errorf("export manifest version %d is newer than supported version %d", manifest.FormatVersion, exportManifestFormatVersion)
+	}
+
+	if manifest.ConfigFile != "" && !isLocalPath(manifest.ConfigFile) {
+		return nil, fmt.Errorf("export manifest has invalid config file '%s'", manifest.ConfigFile)
+	}
+	for _, file := range manifest.Files {
+		if !isLocalPath(file.Path) {
+			return nil, fmt.Errorf("export manifest file '%s' is outside the export", file.Path)
+		}
+	}
+	return &manifest, nil
+}
+
+// VerifyExport recomputes checksums for a VM export or a workspace bundle at path
+func VerifyExport(path string) (*ExportVerification, error) {
+	if _, err := os.Stat(filepath.Join(path, BundleManifestFile)); err == nil {
+		manifest, err := ReadBundleManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		return VerifyBundle(path, manifest)
+	}
+
+	manifest, err := ReadExportManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &ExportVerification{Path: path, Kind: "export", Name: manifest.VMName}
+	if err := verification.check(path, manifest.Files); err != nil {
+		return nil, err
+	}
+	verification.Valid = len(verification.Missing) == 0 && len(verification.Mismatched) == 0
+	return verification, nil
+}
+
+// exportVM runs Export-VM and, if withManifest is set, writes a manifest into the export directory
+func (m *Manager) exportVM(ctx context.Context, vmName, path string, withManifest bool) (*ExportManifest, error) {
+	var spec *VMSpec
+	if withManifest {
+		var err error
+		if spec, err = m.getVMSpec(ctx, vmName); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.runExportVM(ctx, vmName, path); err != nil {
+		return nil, err
+	}
+
+	if !withManifest {
+		return nil, nil
+	}
+	return writeExportManifest(filepath.Join(path, vmName), vmName, spec)
+}
+
+// runExportVM runs Export-VM, which writes the export to path/vmName
+func (m *Manager) runExportVM(ctx context.Context, vmName, path string) error {
+	output, err := m.Exec.RunCmdlet(ctx, "Export-VM", "-Name", vmName, "-Path", path)
+	if err != nil {
+		return fmt.Errorf("failed to export VM '%s': %v\nOutput: %s", vmName, err, string(output))
+	}
+	return nil
+}
+
+// writeExportManifest hashes every file in exportDir and writes the export manifest
+func writeExportManifest(exportDir, vmName string, spec *VMSpec) (*ExportManifest, error) {
+	files, err := checksumTree(exportDir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum export of '%s': %w", vmName, err)
+	}
+
+	configFile, err := selectConfigFile(files, spec.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export of '%s': %w", vmName, err)
+	}
+
+	hostname, _ := os.Hostname()
+	manifest := &ExportManifest{
+		FormatVersion: exportManifestFormatVersion,
+		VMName:        vmName,
+		VMID:          spec.ID,
+		ConfigFile:    configFile,
+		CreatedAt:     time.Now().UTC(),
+		SourceHost:    hostname,
+		Spec:          spec,
+		Files:         files,
+	}
+
+	if err := writeJSONFile(filepath.Join(exportDir, ExportManifestFile), manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// selectConfigFile picks the .vmcx file belonging to the VM with the given ID.
+// Hyper-V names configuration files after the VM ID, so this stays correct when
+// an export also contains configuration files of checkpoints.
+func selectConfigFile(files []FileChecksum, vmID string) (string, error) {
+	var configs []string
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Path), ".vmcx") {
+			configs = append(configs, file.Path)
+		}
+	}
+
+	for _, config := range configs {
+		base := strings.TrimSuffix(filepath.Base(config), filepath.Ext(config))
+		if vmID != "" && strings.EqualFold(base, vmID) {
+			return config, nil
+		}
+	}
+
+	switch len(configs) {
+	case 0:
+		return "", fmt.Errorf("no .vmcx file found")
+	case 1:
+		return configs[0], nil
+	default:
+		return "", fmt.Errorf("multiple .vmcx files found and none matches VM ID '%s'", vmID)
+	}
+}
+
+// getVMSpec reads the hardware configuration of a VM
+func (m *Manager) getVMSpec(ctx context.Context, vmName string) (*VMSpec, error) {
+	output, err := m.Exec.RunCmdlet(ctx, "Get-VM", "-Name", vmName, "|",
+		"Select-Object", "@{N='ID';E={$_.Id.ToString()}},Generation,ProcessorCount,@{N='MemoryStartupMB';E={[int64]($_.MemoryStartup/1MB)}}",
+		"|", "ConvertTo-Json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration of VM '%s': %v\nOutput: %s", vmName, err, string(output))
+	}
+
+	var spec VMSpec
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration of VM '%s': %v", vmName, err)
+	}
+	return &spec, nil
+}
+
+// checksumTree returns checksums for every regular file under root/rel, sorted by path.
+// Paths are relative to root; manifests written by quickvm are skipped.
+func checksumTree(root, rel string) ([]FileChecksum, error) {
+	var files []FileChecksum
+	err := filepath.WalkDir(filepath.Join(root, rel), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		if name := d.Name(); name == ExportManifestFile || name == BundleManifestFile {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve '%s': %w", path, err)
+		}
+		sum, err := checksumFile(root, filepath.ToSlash(relPath))
+		if err != nil {
+			return err
+		}
+		files = append(files, *sum)
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, err
+}
+
+// checksumFile computes the size and SHA-256 of root/rel
+func checksumFile(root, rel string) (*FileChecksum, error) {
+	//nolint:gosec // G304: Hashing files inside a user-provided export is intended
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open '%s': %w", rel, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", rel, err)
+	}
+	return &FileChecksum{Path: rel, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// isLocalPath reports whether a slash-separated manifest path stays inside its root
+func isLocalPath(path string) bool {
+	return path != "" && filepath.IsLocal(filepath.FromSlash(path))
+}
+
+// writeJSONFile writes v as indented JSON. It writes a temporary file next to path and
+// renames it, so a failed write never leaves a truncated manifest behind.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	tmp := path + ".tmp"
+	// gosec G306: Expect WriteFile permissions to be 0600 or less
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
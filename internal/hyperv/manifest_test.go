@@ -0,0 +1,139 @@
+package hyperv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"quickvm/internal/archive"
+)
+
+func TestExportVMByName_WritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	manager := &Manager{Exec: &bundleRunner{}}
+
+	if err := manager.ExportVMByName(context.Background(), "DC01", dir); err != nil {
+		t.Fatalf("ExportVMByName failed: %v", err)
+	}
+
+	exportDir := filepath.Join(dir, "DC01")
+	manifest, err := ReadExportManifest(exportDir)
+	if err != nil {
+		t.Fatalf("ReadExportManifest failed: %v", err)
+	}
+	if manifest.VMName != "DC01" || manifest.VMID != "1234" || manifest.ConfigFile != "Virtual Machines/1234.vmcx" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].SHA256 == "" {
+		t.Errorf("Expected one checksummed file, got %+v", manifest.Files)
+	}
+
+	vmcx, err := manager.findVMCXFile(exportDir)
+	if err != nil || vmcx != filepath.Join(exportDir, "Virtual Machines", "1234.vmcx") {
+		t.Errorf("Expected config file from manifest, got %q (%v)", vmcx, err)
+	}
+}
+
+func TestVerifyExport(t *testing.T) {
+	dir := t.TempDir()
+	manager := &Manager{Exec: &bundleRunner{}}
+	if err := manager.ExportVMByName(context.Background(), "DC01", dir); err != nil {
+		t.Fatalf("ExportVMByName failed: %v", err)
+	}
+	exportDir := filepath.Join(dir, "DC01")
+
+	verification, err := VerifyExport(exportDir)
+	if err != nil || !verification.Valid || verification.Checked != 1 || verification.Kind != "export" {
+		t.Fatalf("Expected intact export, got %+v (%v)", verification, err)
+	}
+
+	vmcx := filepath.Join(exportDir, "Virtual Machines", "1234.vmcx")
+	if err := os.WriteFile(vmcx, []byte("tampered"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	verification, _ = VerifyExport(exportDir)
+	if verification.Valid || len(verification.Mismatched) != 1 {
+		t.Errorf("Expected checksum mismatch, got %+v", verification)
+	}
+
+	_ = os.Remove(vmcx)
+	verification, _ = VerifyExport(exportDir)
+	if verification.Valid || len(verification.Missing) != 1 {
+		t.Errorf("Expected missing file, got %+v", verification)
+	}
+
+	if _, err := VerifyExport(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without a manifest")
+	}
+}
+
+func TestSelectConfigFile(t *testing.T) {
+	files := []FileChecksum{
+		{Path: "Snapshots/AAAA.vmcx"},
+		{Path: "Virtual Machines/BBBB.vmcx"},
+		{Path: "Virtual Hard Disks/disk.vhdx"},
+	}
+
+	config, err := selectConfigFile(files, "bbbb")
+	if err != nil || config != "Virtual Machines/BBBB.vmcx" {
+		t.Errorf("Expected config matching VM ID, got %q (%v)", config, err)
+	}
+	if _, err := selectConfigFile(files, "CCCC"); err == nil {
+		t.Error("Expected error when several configs exist and none matches the ID")
+	}
+	if _, err := selectConfigFile(files[2:], "BBBB"); err == nil {
+		t.Error("Expected error when no config exists")
+	}
+}
+
+func TestFindVMCXFile_Ambiguous(t *testing.T) {
+	dir := t.TempDir()
+	vmDir := filepath.Join(dir, "Virtual Machines")
+	if err := os.MkdirAll(vmDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.vmcx", "b.vmcx"} {
+		if err := os.WriteFile(filepath.Join(vmDir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewManager().findVMCXFile(dir); err == nil {
+		t.Error("Expected error when multiple .vmcx files are found without a manifest")
+	}
+}
+
+func TestExportVMArchive_RoundTrip(t *testing.T) {
+	dir, stagingDir := t.TempDir(), t.TempDir()
+	manager := &Manager{Exec: &bundleRunner{}}
+
+	files, err := manager.ExportVMArchive(context.Background(), "DC01", dir,
+		ExportArchiveOptions{Format: archive.FormatTarZst, StagingDir: stagingDir})
+	if err != nil {
+		t.Fatalf("ExportVMArchive failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dir, "DC01.tar.zst") {
+		t.Errorf("Unexpected archive files %v", files)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the archive in the destination, found %d entries", len(entries))
+	}
+	if entries, _ := os.ReadDir(stagingDir); len(entries) != 0 {
+		t.Errorf("Expected staging directory to be removed, found %d entries", len(entries))
+	}
+
+	exportDir, cleanup, err := ExtractExportArchive(context.Background(), files[0], t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("ExtractExportArchive failed: %v", err)
+	}
+	defer cleanup()
+
+	if filepath.Base(exportDir) != "DC01" {
+		t.Errorf("Expected export directory DC01, got %s", exportDir)
+	}
+	verification, err := VerifyExport(exportDir)
+	if err != nil || !verification.Valid {
+		t.Errorf("Expected extracted export to verify, got %+v (%v)", verification, err)
+	}
+}
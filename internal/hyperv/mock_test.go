@@ -261,7 +261,8 @@ func TestCreateSnapshot_Mock_Success(t *testing.T) {
 func TestGetCPUInfo_Mock_Success(t *testing.T) {
 	mockJSON := `{
 		"Name": "Intel Core i9",
-		"Cores": 16
+		"Cores": 16,
+		"LoadPercent": 37
 	}`
 	manager, _ := newMockManager(mockJSON, nil)
 
@@ -276,6 +277,9 @@ func TestGetCPUInfo_Mock_Success(t *testing.T) {
 	if cpu.Name != "Intel Core i9" {
 		t.Errorf("Expected Intel Core i9, got %s", cpu.Name)
 	}
+	if cpu.LoadPercent != 37 {
+		t.Errorf("Expected 37%% load, got %d", cpu.LoadPercent)
+	}
 }
 
 func TestGetMemoryInfo_Mock_Success(t *testing.T) {
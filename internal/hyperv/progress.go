@@ -0,0 +1,381 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"quickvm/internal/archive"
+)
+
+// ProgressPhase identifies the stage of a long-running export, import or clone
+type ProgressPhase string
+
+// Progress phases
+const (
+	ProgressPreparing   ProgressPhase = "preparing"
+	ProgressExporting   ProgressPhase = "exporting"
+	ProgressChecksums   ProgressPhase = "checksums"
+	ProgressImporting   ProgressPhase = "importing"
+	ProgressCustomizing ProgressPhase = "customizing"
+	ProgressArchiving   ProgressPhase = "archiving"  // Packing an export into an archive
+	ProgressExtracting  ProgressPhase = "extracting" // Unpacking an archive before an import
+	ProgressRollingBack ProgressPhase = "rolling-back"
+	ProgressCompleted   ProgressPhase = "completed"
+	ProgressFailed      ProgressPhase = "failed"
+	ProgressCancelled   ProgressPhase = "cancelled"
+)
+
+// progressPollInterval is how often the destination directory is measured
+var progressPollInterval = time.Second
+
+// rollbackTimeout bounds cleanup after an operation was cancelled
+const rollbackTimeout = 2 * time.Minute
+
+// ProgressEvent reports the state of a long-running operation.
+// Byte counts are estimates derived from the size of the destination directory
+// compared to the size of the source virtual hard disks.
+type ProgressEvent struct {
+	Operation  string        `json:"operation"` // export, import or clone
+	VMName     string        `json:"vmName"`
+	Phase      ProgressPhase `json:"phase"`
+	BytesDone  int64         `json:"bytesDone"`
+	BytesTotal int64         `json:"bytesTotal"` // 0 if unknown
+	Percent    float64       `json:"percent"`
+	ETASeconds int64         `json:"etaSeconds,omitempty"` // Estimated time remaining in the current phase
+	Message    string        `json:"message,omitempty"`
+	Time       time.Time     `json:"time"`
+}
+
+// Finished reports whether this is the last event of an operation
+func (e ProgressEvent) Finished() bool {
+	return e.Phase == ProgressCompleted || e.Phase == ProgressFailed || e.Phase == ProgressCancelled
+}
+
+// progressReporter sends progress events for one operation; a nil channel discards them
+type progressReporter struct {
+	events     chan<- ProgressEvent
+	operation  string
+	vmName     string
+	phaseStart time.Time
+}
+
+func newProgressReporter(events chan<- ProgressEvent, operation, vmName string) *progressReporter {
+	return &progressReporter{events: events, operation: operation, vmName: vmName, phaseStart: time.Now()}
+}
+
+// phase reports the start of a new phase
+func (r *progressReporter) phase(phase ProgressPhase, message string) {
+	r.phaseStart = time.Now()
+	r.emit(phase, 0, 0, message)
+}
+
+// emit sends an event, computing the percentage and ETA from the bytes copied so far
+func (r *progressReporter) emit(phase ProgressPhase, done, total int64, message string) {
+	if r.events == nil {
+		return
+	}
+
+	event := ProgressEvent{
+		Operation:  r.operation,
+		VMName:     r.vmName,
+		Phase:      phase,
+		BytesDone:  done,
+		BytesTotal: total,
+		Message:    message,
+		Time:       time.Now(),
+	}
+	if total > 0 {
+		if done > total {
+			done, event.BytesDone = total, total
+		}
+		event.Percent = float64(done) / float64(total) * 100
+		if elapsed := time.Since(r.phaseStart); done > 0 && done < total {
+			rate := float64(done) / elapsed.Seconds()
+			event.ETASeconds = int64(float64(total-done)/rate + 0.5)
+		}
+	}
+	r.events <- event
+}
+
+// finish reports the outcome of the operation and returns err unchanged
+func (r *progressReporter) finish(ctx context.Context, err error) error {
+	switch {
+	case err == nil:
+		r.emit(ProgressCompleted, 0, 0, "")
+	case ctx.Err() != nil:
+		r.emit(ProgressCancelled, 0, 0, err.Error())
+	default:
+		r.emit(ProgressFailed, 0, 0, err.Error())
+	}
+	return err
+}
+
+// watch polls the size of dir until the returned stop function is called, reporting
+// growth beyond baseline as progress of phase towards total
+func (r *progressReporter) watch(ctx context.Context, phase ProgressPhase, dir string, total int64) func() {
+	if r.events == nil {
+		return func() {}
+	}
+
+	baseline := dirSize(dir)
+	r.phaseStart = time.Now()
+	r.emit(phase, 0, total, "")
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(progressPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+				r.emit(phase, dirSize(dir)-baseline, total, "")
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// dirSize returns the total size of the files under dir, or 0 if it cannot be read
+func dirSize(dir string) int64 {
+	size, err := archive.EstimateSize(dir)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// ExportVMWithProgress exports a VM like ExportVMByName while sending progress events to events.
+// events is closed when the export finishes. If the export fails or ctx is cancelled,
+// the partially written export directory is removed.
+func (m *Manager) ExportVMWithProgress(ctx context.Context, vmName, path string, events chan<- ProgressEvent) error {
+	if events != nil {
+		defer close(events)
+	}
+	r := newProgressReporter(events, "export", vmName)
+	started := time.Now()
+	err := r.finish(ctx, m.exportWithProgress(ctx, vmName, path, r))
+	if m.RecordExports {
+		// The history only feeds metrics; it must not fail the export
+		_ = recordExport(ExportRecord{VMName: vmName, Path: path, Started: started,
+			DurationSeconds: time.Since(started).Seconds(), Success: err == nil})
+	}
+	return err
+}
+
+func (m *Manager) exportWithProgress(ctx context.Context, vmName, path string, r *progressReporter) error {
+	r.phase(ProgressPreparing, "reading VM configuration")
+	spec, err := m.getVMSpec(ctx, vmName)
+	if err != nil {
+		return err
+	}
+	total, _ := m.getVMDiskSize(ctx, vmName) // Progress is indeterminate if the size is unknown
+
+	exportDir := filepath.Join(path, vmName)
+	if _, err := os.Stat(exportDir); err == nil {
+		return fmt.Errorf("export directory '%s' already exists", exportDir)
+	}
+
+	stop := r.watch(ctx, ProgressExporting, exportDir, total)
+	err = m.runExportVM(ctx, vmName, path)
+	stop()
+	if err == nil {
+		r.phase(ProgressChecksums, "writing export manifest")
+		_, err = writeExportManifest(exportDir, vmName, spec)
+	}
+
+	if err != nil {
+		r.phase(ProgressRollingBack, fmt.Sprintf("removing partial export '%s'", exportDir))
+		if removeErr := os.RemoveAll(exportDir); removeErr != nil {
+			return fmt.Errorf("%w (failed to remove partial export: %v)", err, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// ImportVMWithProgress imports a VM like ImportVM while sending progress events to events.
+// events is closed when the import finishes. If the import fails or ctx is cancelled after
+// Hyper-V registered the VM, the VM is removed again (with its disks for copy imports).
+func (m *Manager) ImportVMWithProgress(ctx context.Context, opts ImportVMOptions, events chan<- ProgressEvent) (string, error) {
+	if events != nil {
+		defer close(events)
+	}
+	r := newProgressReporter(events, "import", filepath.Base(opts.Path))
+	name, err := m.importWithProgress(ctx, opts, r)
+	return name, r.finish(ctx, err)
+}
+
+func (m *Manager) importWithProgress(ctx context.Context, opts ImportVMOptions, r *progressReporter) (string, error) {
+	r.phase(ProgressPreparing, "reading export")
+
+	expectedName := opts.NewName
+	if expectedName == "" && HasExportManifest(opts.Path) {
+		if manifest, err := ReadExportManifest(opts.Path); err == nil {
+			expectedName = manifest.VMName
+		}
+	}
+	if expectedName != "" {
+		r.vmName = expectedName
+	}
+
+	before, err := m.listVMIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	stop := func() {}
+	if opts.Copy {
+		var total int64
+		if info, statErr := os.Stat(opts.Path); statErr == nil && info.IsDir() {
+			total = dirSize(opts.Path)
+		}
+		stop = r.watch(ctx, ProgressImporting, m.importDestination(ctx, opts), total)
+	} else {
+		r.phase(ProgressImporting, "registering VM in place")
+	}
+
+	name, err := m.ImportVM(ctx, opts)
+	stop()
+	if err == nil {
+		return name, nil
+	}
+
+	r.phase(ProgressRollingBack, "removing partially imported VM")
+	if rollbackErr := m.rollbackImport(before, expectedName, opts.Copy); rollbackErr != nil {
+		return "", fmt.Errorf("%w (rollback: %v)", err, rollbackErr)
+	}
+	return "", err
+}
+
+// CloneVMWithProgress clones a VM like CloneVMByName, applies custom if it is not nil and
+// sends progress events to events. events is closed when the clone finishes. The temporary
+// export is always removed and a partially imported or customized clone is removed on
+// failure or cancellation.
+func (m *Manager) CloneVMWithProgress(ctx context.Context, sourceName, newName string, custom *CloneCustomization, events chan<- ProgressEvent) error {
+	if events != nil {
+		defer close(events)
+	}
+	r := newProgressReporter(events, "clone", sourceName)
+	return r.finish(ctx, m.cloneVM(ctx, sourceName, newName, custom, r))
+}
+
+// importDestination returns the directory that receives copied virtual hard disks
+func (m *Manager) importDestination(ctx context.Context, opts ImportVMOptions) string {
+	if opts.VHDPath != "" {
+		return opts.VHDPath
+	}
+	output, err := m.Exec.RunCmdlet(ctx, "Get-VMHost", "|", "Select-Object", "-ExpandProperty", "VirtualHardDiskPath")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getVMDiskSize returns the size of a VM's virtual hard disks, including differencing disk parents
+func (m *Manager) getVMDiskSize(ctx context.Context, vmName string) (int64, error) {
+	script := fmt.Sprintf(`
+		$total = [int64]0
+		foreach ($drive in Get-VMHardDiskDrive -VMName "%s") {
+			$path = $drive.Path
+			while ($path) {
+				$total += (Get-Item -LiteralPath $path).Length
+				$path = (Get-VHD -Path $path).ParentPath
+			}
+		}
+		$total
+	`, escapePSString(vmName))
+
+	output, err := m.Exec.RunScript(ctx, script)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get disk size of VM '%s': %v", vmName, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse disk size of VM '%s': %v", vmName, err)
+	}
+	return size, nil
+}
+
+// vmIdentity is a VM's ID and name as reported by Get-VM
+type vmIdentity struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+// listVMIDs returns the IDs and names of all registered VMs
+func (m *Manager) listVMIDs(ctx context.Context) ([]vmIdentity, error) {
+	output, err := m.Exec.RunScript(ctx, `ConvertTo-Json -InputObject @(Get-VM | Select-Object @{N='Id';E={$_.Id.ToString()}},Name)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VMs: %v\nOutput: %s", err, string(output))
+	}
+
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil, nil
+	}
+	var vms []vmIdentity
+	if err := json.Unmarshal([]byte(trimmed), &vms); err != nil {
+		return nil, fmt.Errorf("failed to parse VM list: %v", err)
+	}
+	return vms, nil
+}
+
+// rollbackImport removes VMs named expectedName that were registered after before was taken.
+// Copied disks are deleted too; disks of in-place imports belong to the export and are kept.
+func (m *Manager) rollbackImport(before []vmIdentity, expectedName string, deleteDisks bool) error {
+	if expectedName == "" {
+		return fmt.Errorf("imported VM name unknown, check Hyper-V Manager for a partially imported VM")
+	}
+
+	// The operation context may already be cancelled; cleanup gets its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	after, err := m.listVMIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(before))
+	for _, vm := range before {
+		existing[vm.ID] = true
+	}
+
+	for _, vm := range after {
+		if existing[vm.ID] || vm.Name != expectedName {
+			continue
+		}
+
+		removeDisks := ""
+		if deleteDisks {
+			removeDisks = `$disks | Where-Object { $_ } | Remove-Item -Force -ErrorAction SilentlyContinue`
+		}
+		script := fmt.Sprintf(`
+			$ErrorActionPreference = "Stop"
+			$vm = Get-VM -Id "%s"
+			$disks = @($vm | Get-VMHardDiskDrive | Select-Object -ExpandProperty Path)
+			if ($vm.State -ne "Off") { Stop-VM -VM $vm -TurnOff -Force }
+			Remove-VM -VM $vm -Force
+			%s
+		`, escapePSString(vm.ID), removeDisks)
+
+		if output, err := m.Exec.RunScript(ctx, script); err != nil {
+			return fmt.Errorf("failed to remove VM '%s': %v\nOutput: %s", vm.Name, err, string(output))
+		}
+	}
+	return nil
+}
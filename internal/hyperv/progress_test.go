@@ -0,0 +1,322 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// progressRunner simulates a Hyper-V host whose exports and imports can fail midway
+type progressRunner struct {
+	vms         []vmIdentity
+	exportErr   error
+	importErr   error
+	importedID  string
+	vhdPath     string
+	removedIDs  []string
+	exportBytes int
+}
+
+func (r *progressRunner) RunScript(_ context.Context, script string) ([]byte, error) {
+	switch {
+	case strings.Contains(script, "ConvertTo-Json -InputObject @(Get-VM"):
+		return json.Marshal(r.vms)
+	case strings.Contains(script, "Get-VMHardDiskDrive -VMName"):
+		return []byte("2048"), nil
+	case strings.Contains(script, "Remove-VM -VM"):
+		for _, vm := range r.vms {
+			if strings.Contains(script, `"`+vm.ID+`"`) {
+				r.removedIDs = append(r.removedIDs, vm.ID)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *progressRunner) RunCmdlet(ctx context.Context, cmdlet string, args ...string) ([]byte, error) {
+	switch cmdlet {
+	case "Get-VM":
+		if len(args) > 2 && args[2] == "-ErrorAction" {
+			return nil, nil
+		}
+		return []byte(`{"ID": "1234", "Generation": 2, "ProcessorCount": 2, "MemoryStartupMB": 2048}`), nil
+	case "Get-VMHost":
+		return []byte(r.vhdPath), nil
+	case "Export-VM":
+		vmDir := filepath.Join(args[3], args[1], "Virtual Machines")
+		if err := os.MkdirAll(vmDir, 0750); err != nil {
+			return nil, err
+		}
+		data := make([]byte, r.exportBytes)
+		if err := os.WriteFile(filepath.Join(vmDir, "1234.vmcx"), data, 0600); err != nil {
+			return nil, err
+		}
+		if r.exportErr != nil {
+			if errors.Is(r.exportErr, context.Canceled) {
+				return nil, ctx.Err()
+			}
+			return nil, r.exportErr
+		}
+	case "Import-VM":
+		// Hyper-V registers the VM before copying finishes, so a failed import can leave it behind
+		name := ""
+		for i, arg := range args {
+			if arg == "-NewName" {
+				name = args[i+1]
+			}
+		}
+		r.vms = append(r.vms, vmIdentity{ID: r.importedID, Name: name})
+		if r.importErr != nil {
+			return nil, r.importErr
+		}
+		return []byte(name), nil
+	}
+	return nil, nil
+}
+
+func collectEvents(events <-chan ProgressEvent) <-chan []ProgressEvent {
+	result := make(chan []ProgressEvent, 1)
+	go func() {
+		var collected []ProgressEvent
+		for event := range events {
+			collected = append(collected, event)
+		}
+		result <- collected
+	}()
+	return result
+}
+
+func TestExportVMWithProgress_Completed(t *testing.T) {
+	dir := t.TempDir()
+	manager := &Manager{Exec: &progressRunner{exportBytes: 1024}}
+
+	events := make(chan ProgressEvent)
+	collected := collectEvents(events)
+	if err := manager.ExportVMWithProgress(context.Background(), "Web01", dir, events); err != nil {
+		t.Fatalf("ExportVMWithProgress failed: %v", err)
+	}
+
+	got := <-collected
+	if len(got) == 0 || got[len(got)-1].Phase != ProgressCompleted {
+		t.Fatalf("Expected events to end with %q, got %+v", ProgressCompleted, got)
+	}
+	sawExporting := false
+	for _, event := range got {
+		if event.Phase == ProgressExporting {
+			sawExporting = true
+			if event.BytesTotal != 2048 {
+				t.Errorf("Expected total of 2048 bytes, got %d", event.BytesTotal)
+			}
+		}
+	}
+	if !sawExporting {
+		t.Error("Expected an exporting event")
+	}
+	if !HasExportManifest(filepath.Join(dir, "Web01")) {
+		t.Error("Expected the export manifest to be written")
+	}
+}
+
+func TestExportVMWithProgress_RemovesPartialExport(t *testing.T) {
+	tests := []struct {
+		name      string
+		exportErr error
+		cancel    bool
+		wantPhase ProgressPhase
+	}{
+		{"failure", errors.New("disk full"), false, ProgressFailed},
+		{"cancelled", context.Canceled, true, ProgressCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			manager := &Manager{Exec: &progressRunner{exportErr: tt.exportErr, exportBytes: 512}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			events := make(chan ProgressEvent)
+			collected := collectEvents(events)
+			if err := manager.ExportVMWithProgress(ctx, "Web01", dir, events); err == nil {
+				t.Fatal("Expected export to fail")
+			}
+
+			got := <-collected
+			if last := got[len(got)-1]; last.Phase != tt.wantPhase || !last.Finished() {
+				t.Errorf("Expected last event %q, got %+v", tt.wantPhase, last)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "Web01")); !os.IsNotExist(err) {
+				t.Errorf("Expected partial export to be removed, stat error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExportVMWithProgress_ExistingDirectoryKept(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "Web01")
+	if err := os.MkdirAll(existing, 0750); err != nil {
+		t.Fatal(err)
+	}
+	manager := &Manager{Exec: &progressRunner{}}
+
+	if err := manager.ExportVMWithProgress(context.Background(), "Web01", dir, nil); err == nil {
+		t.Fatal("Expected export into an existing directory to fail")
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("Expected existing directory to be kept: %v", err)
+	}
+}
+
+func TestImportVMWithProgress_RollbackRemovesOnlyNewVM(t *testing.T) {
+	exportDir := filepath.Join(t.TempDir(), "Web01")
+	if err := os.MkdirAll(filepath.Join(exportDir, "Virtual Machines"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(exportDir, "Virtual Machines", "1234.vmcx"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &progressRunner{
+		vms:        []vmIdentity{{ID: "old-id", Name: "Web01-Copy"}, {ID: "other-id", Name: "DB01"}},
+		importErr:  errors.New("copy failed"),
+		importedID: "new-id",
+		vhdPath:    t.TempDir(),
+	}
+	manager := &Manager{Exec: runner}
+
+	events := make(chan ProgressEvent)
+	collected := collectEvents(events)
+	_, err := manager.ImportVMWithProgress(context.Background(), ImportVMOptions{
+		Path:    exportDir,
+		Copy:    true,
+		NewName: "Web01-Copy",
+	}, events)
+	if err == nil {
+		t.Fatal("Expected import to fail")
+	}
+
+	if len(runner.removedIDs) != 1 || runner.removedIDs[0] != "new-id" {
+		t.Errorf("Expected only the new VM to be removed, got %v", runner.removedIDs)
+	}
+
+	got := <-collected
+	sawRollback := false
+	for _, event := range got {
+		if event.Phase == ProgressRollingBack {
+			sawRollback = true
+		}
+	}
+	if !sawRollback || got[len(got)-1].Phase != ProgressFailed {
+		t.Errorf("Expected rollback followed by failure, got %+v", got)
+	}
+}
+
+func TestCloneVMWithProgress_RollsBackFailedImport(t *testing.T) {
+	runner := &progressRunner{
+		importErr:   errors.New("copy failed"),
+		importedID:  "clone-id",
+		exportBytes: 64,
+	}
+	manager := &Manager{Exec: runner}
+
+	if err := manager.CloneVMWithProgress(context.Background(), "Web01", "Web02", nil, nil); err == nil {
+		t.Fatal("Expected clone to fail")
+	}
+	if len(runner.removedIDs) != 1 || runner.removedIDs[0] != "clone-id" {
+		t.Errorf("Expected partial clone to be removed, got %v", runner.removedIDs)
+	}
+}
+
+func TestProgressReporter_Emit(t *testing.T) {
+	events := make(chan ProgressEvent, 1)
+	r := newProgressReporter(events, "export", "Web01")
+	r.phaseStart = time.Now().Add(-10 * time.Second)
+
+	r.emit(ProgressExporting, 250, 1000, "")
+	event := <-events
+	if event.Percent != 25 {
+		t.Errorf("Expected 25%%, got %v", event.Percent)
+	}
+	if event.ETASeconds < 29 || event.ETASeconds > 31 {
+		t.Errorf("Expected ETA of about 30s, got %ds", event.ETASeconds)
+	}
+
+	// Estimates never exceed 100%
+	r.emit(ProgressExporting, 1500, 1000, "")
+	if event := <-events; event.Percent != 100 || event.BytesDone != 1000 {
+		t.Errorf("Expected progress to be capped, got %+v", event)
+	}
+}
+
+func TestExportVMWithProgress_RecordsHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	dir := t.TempDir()
+	manager := &Manager{Exec: &progressRunner{}, RecordExports: true}
+
+	if err := manager.ExportVMWithProgress(context.Background(), "Web01", dir, nil); err != nil {
+		t.Fatalf("ExportVMWithProgress failed: %v", err)
+	}
+	// The directory exists now, so the second export fails
+	if err := manager.ExportVMWithProgress(context.Background(), "Web01", dir, nil); err == nil {
+		t.Fatal("Expected the second export to fail")
+	}
+
+	history, err := LoadExportHistory()
+	if err != nil {
+		t.Fatalf("LoadExportHistory failed: %v", err)
+	}
+	records := history.Records
+	if len(records) != 2 || !records[0].Success || records[1].Success || records[0].VMName != "Web01" {
+		t.Errorf("Unexpected history: %+v", records)
+	}
+	if totals := history.Totals["Web01"]; totals.Success != 1 || totals.Failure != 1 {
+		t.Errorf("Unexpected totals: %+v", totals)
+	}
+}
+
+func TestExportHistory_TotalsOutliveRecords(t *testing.T) {
+	history := &ExportHistory{}
+	for i := 0; i < maxExportRecords+10; i++ {
+		history.add(ExportRecord{VMName: "Web01", Success: i%2 == 0})
+	}
+	if len(history.Records) != maxExportRecords {
+		t.Errorf("Expected %d records, got %d", maxExportRecords, len(history.Records))
+	}
+	if totals := history.Totals["Web01"]; totals.Success+totals.Failure != maxExportRecords+10 {
+		t.Errorf("Expected the totals to count every export, got %+v", totals)
+	}
+}
+
+func TestLoadExportHistory_PlainList(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	path, _ := GetExportHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	data := `[{"vmName":"Web01","success":true},{"vmName":"Web01","success":false}]`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := LoadExportHistory()
+	if err != nil {
+		t.Fatalf("LoadExportHistory failed: %v", err)
+	}
+	if len(history.Records) != 2 || history.Totals["Web01"] != (ExportTotals{Success: 1, Failure: 1}) {
+		t.Errorf("Unexpected history from a plain list: %+v", history)
+	}
+}
@@ -0,0 +1,126 @@
+package hyperv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"quickvm/internal/iso"
+)
+
+// ProvisionISOName is the file name of the provisioning ISO in the VM's configuration directory
+const ProvisionISOName = "quickvm-provision.iso"
+
+// ProvisionOptions controls what happens after the provisioning ISO is attached
+type ProvisionOptions struct {
+	NoStart bool            // Only attach the ISO; the first start is left to the user
+	Ready   *ReadinessCheck // When the guest has consumed the ISO (default: ip for linux, heartbeat for windows)
+}
+
+// ProvisionVM writes img next to the VM's configuration, attaches it to the VM's DVD drive
+// and, unless opts.NoStart is set, starts the VM, waits until it is ready and detaches the
+// ISO again. It returns the path of the ISO.
+func (m *Manager) ProvisionVM(ctx context.Context, vmName string, img *iso.Image, opts ProvisionOptions) (string, error) {
+	if opts.Ready == nil {
+		opts.Ready = &ReadinessCheck{Type: ReadinessIP}
+	}
+	if err := opts.Ready.Validate(); err != nil {
+		return "", err
+	}
+
+	isoPath, err := m.AttachProvisioningISO(ctx, vmName, img)
+	if err != nil || opts.NoStart {
+		return isoPath, err
+	}
+
+	if err := m.StartVMByName(ctx, vmName); err != nil {
+		return isoPath, err
+	}
+	if err := m.WaitReady(ctx, vmName, opts.Ready); err != nil {
+		return isoPath, fmt.Errorf("%w (the provisioning ISO is still attached)", err)
+	}
+	if _, err := m.DetachProvisioningISO(ctx, vmName); err != nil {
+		return isoPath, err
+	}
+	return isoPath, nil
+}
+
+// AttachProvisioningISO writes img into the VM's configuration directory and inserts it into
+// an empty DVD drive, adding a drive if the VM has none free. The VM must be off so the guest
+// finds the ISO on its first boot.
+func (m *Manager) AttachProvisioningISO(ctx context.Context, vmName string, img *iso.Image) (string, error) {
+	output, err := m.Exec.RunScript(ctx, fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$vm = Get-VM -Name "%s"
+		if ($vm.State -ne "Off") { throw "VM '$($vm.Name)' must be off to attach a provisioning ISO (state: $($vm.State))" }
+		$vm.Path
+	`, escapePSString(vmName)))
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare VM '%s' for provisioning: %v\nOutput: %s", vmName, err, strings.TrimSpace(string(output)))
+	}
+	vmDir := strings.TrimSpace(string(output))
+	if vmDir == "" {
+		return "", fmt.Errorf("VM '%s' has no configuration path", vmName)
+	}
+
+	isoPath := filepath.Join(vmDir, ProvisionISOName)
+	if err := iso.WriteFile(isoPath, img); err != nil {
+		return "", err
+	}
+
+	if output, err := m.Exec.RunScript(ctx, buildAttachISOScript(vmName, isoPath)); err != nil {
+		_ = os.Remove(isoPath)
+		return "", fmt.Errorf("failed to attach provisioning ISO to VM '%s': %v\nOutput: %s", vmName, err, strings.TrimSpace(string(output)))
+	}
+	return isoPath, nil
+}
+
+// DetachProvisioningISO ejects the provisioning ISO from the VM's DVD drives and deletes it.
+// It reports whether an ISO was attached.
+func (m *Manager) DetachProvisioningISO(ctx context.Context, vmName string) (bool, error) {
+	output, err := m.Exec.RunScript(ctx, buildDetachISOScript(vmName))
+	if err != nil {
+		return false, fmt.Errorf("failed to detach provisioning ISO from VM '%s': %v\nOutput: %s", vmName, err, strings.TrimSpace(string(output)))
+	}
+
+	detached := false
+	for _, line := range strings.Split(string(output), "\n") {
+		isoPath := strings.TrimSpace(line)
+		if isoPath == "" {
+			continue
+		}
+		detached = true
+		if err := os.Remove(isoPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return true, fmt.Errorf("ISO detached but could not be deleted: %w", err)
+		}
+	}
+	return detached, nil
+}
+
+func buildAttachISOScript(vmName, isoPath string) string {
+	return fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$vm = Get-VM -Name "%s"
+		$drive = Get-VMDvdDrive -VM $vm | Where-Object { -not $_.Path } | Select-Object -First 1
+		if ($drive) {
+			Set-VMDvdDrive -VMDvdDrive $drive -Path "%[2]s"
+		} else {
+			Add-VMDvdDrive -VM $vm -Path "%[2]s"
+		}
+	`, escapePSString(vmName), escapePSString(isoPath))
+}
+
+// buildDetachISOScript ejects every drive holding a provisioning ISO and prints the ISO paths
+func buildDetachISOScript(vmName string) string {
+	return fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$vm = Get-VM -Name "%s"
+		foreach ($drive in Get-VMDvdDrive -VM $vm | Where-Object { $_.Path -and (Split-Path $_.Path -Leaf) -eq "%s" }) {
+			$drive.Path
+			Set-VMDvdDrive -VMDvdDrive $drive -Path $null
+		}
+	`, escapePSString(vmName), ProvisionISOName)
+}
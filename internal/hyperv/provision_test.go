@@ -0,0 +1,127 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quickvm/internal/iso"
+)
+
+// provisionRunner simulates a VM whose configuration lives in a temporary directory
+type provisionRunner struct {
+	vmDir    string
+	state    string
+	ip       string
+	attached string
+	scripts  []string
+	cmdlets  []string
+}
+
+func (r *provisionRunner) RunScript(_ context.Context, script string) ([]byte, error) {
+	r.scripts = append(r.scripts, script)
+	switch {
+	case strings.Contains(script, "$vm.Path"):
+		if r.state != "Off" {
+			return nil, fmt.Errorf("VM must be off")
+		}
+		return []byte(r.vmDir + "\r\n"), nil
+	case strings.Contains(script, "Add-VMDvdDrive"):
+		r.attached = filepath.Join(r.vmDir, ProvisionISOName)
+		return nil, nil
+	case strings.Contains(script, "Set-VMDvdDrive -VMDvdDrive $drive -Path $null"):
+		attached := r.attached
+		r.attached = ""
+		return []byte(attached), nil
+	}
+	return nil, nil
+}
+
+func (r *provisionRunner) RunCmdlet(_ context.Context, cmdlet string, args ...string) ([]byte, error) {
+	r.cmdlets = append(r.cmdlets, cmdlet)
+	switch cmdlet {
+	case "Start-VM":
+		r.state = "Running"
+	case "Get-VMNetworkAdapter":
+		return []byte(r.ip), nil
+	}
+	return nil, nil
+}
+
+func testSeedImage() *iso.Image {
+	return &iso.Image{VolumeID: "cidata", Files: []iso.File{{Name: "user-data", Data: []byte("#cloud-config\n")}}}
+}
+
+func TestProvisionVM_AttachStartWaitDetach(t *testing.T) {
+	runner := &provisionRunner{vmDir: t.TempDir(), state: "Off", ip: "10.0.0.7"}
+	manager := &Manager{Exec: runner}
+
+	isoPath, err := manager.ProvisionVM(context.Background(), "Web01", testSeedImage(), ProvisionOptions{})
+	if err != nil {
+		t.Fatalf("ProvisionVM failed: %v", err)
+	}
+	if isoPath != filepath.Join(runner.vmDir, ProvisionISOName) {
+		t.Errorf("Unexpected ISO path %s", isoPath)
+	}
+	if !strings.Contains(runner.scripts[1], escapePSString(isoPath)) {
+		t.Errorf("Expected the attach script to reference the ISO:\n%s", runner.scripts[1])
+	}
+	if strings.Join(runner.cmdlets, ",") != "Start-VM,Get-VMNetworkAdapter" {
+		t.Errorf("Expected start and an IP readiness check, got %v", runner.cmdlets)
+	}
+	if runner.attached != "" {
+		t.Error("Expected the ISO to be detached after the guest became ready")
+	}
+	if _, err := os.Stat(isoPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the ISO to be deleted, got %v", err)
+	}
+}
+
+func TestProvisionVM_NoStartLeavesISOAttached(t *testing.T) {
+	runner := &provisionRunner{vmDir: t.TempDir(), state: "Off"}
+	manager := &Manager{Exec: runner}
+
+	isoPath, err := manager.ProvisionVM(context.Background(), "Web01", testSeedImage(), ProvisionOptions{NoStart: true})
+	if err != nil {
+		t.Fatalf("ProvisionVM failed: %v", err)
+	}
+	if len(runner.cmdlets) != 0 || runner.attached == "" {
+		t.Errorf("Expected the ISO to be attached without starting the VM, got %v", runner.cmdlets)
+	}
+	if _, err := os.Stat(isoPath); err != nil {
+		t.Errorf("Expected the ISO to exist: %v", err)
+	}
+
+	detached, err := manager.DetachProvisioningISO(context.Background(), "Web01")
+	if err != nil || !detached {
+		t.Fatalf("DetachProvisioningISO = %v, %v", detached, err)
+	}
+	if _, err := os.Stat(isoPath); !os.IsNotExist(err) {
+		t.Error("Expected detach to delete the ISO")
+	}
+	if detached, _ := manager.DetachProvisioningISO(context.Background(), "Web01"); detached {
+		t.Error("Expected nothing to detach the second time")
+	}
+}
+
+func TestProvisionVM_Failures(t *testing.T) {
+	runner := &provisionRunner{vmDir: t.TempDir(), state: "Running"}
+	manager := &Manager{Exec: runner}
+	if _, err := manager.ProvisionVM(context.Background(), "Web01", testSeedImage(), ProvisionOptions{}); err == nil {
+		t.Error("Expected a running VM to be refused")
+	}
+
+	runner.state = "Off"
+	ready := &ReadinessCheck{Type: ReadinessIP, Timeout: Duration(20 * time.Millisecond), Interval: Duration(5 * time.Millisecond)}
+	_, err := manager.ProvisionVM(context.Background(), "Web01", testSeedImage(), ProvisionOptions{Ready: ready})
+	if err == nil || !strings.Contains(err.Error(), "still attached") {
+		t.Errorf("Expected a readiness timeout that leaves the ISO attached, got %v", err)
+	}
+	if runner.attached == "" {
+		t.Error("Expected the ISO to stay attached for a retry")
+	}
+}
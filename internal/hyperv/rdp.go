@@ -89,12 +89,12 @@ func (m *Manager) GetVMIPAddressByName(ctx context.Context, vmName string) (stri
 
 // ConnectRDP opens an RDP connection to a VM by index
 func (m *Manager) ConnectRDP(ctx context.Context, vmIndex int, credentials string) error {
-	ip, err := m.GetVMIPAddress(ctx, vmIndex)
+	vmName, err := m.GetVMNameByIndex(ctx, vmIndex)
 	if err != nil {
 		return err
 	}
 
-	return m.ConnectRDPByIP(ctx, ip, credentials)
+	return m.ConnectRDPByName(ctx, vmName, credentials)
 }
 
 // ConnectRDPByName opens an RDP connection to a VM by name
@@ -104,39 +104,34 @@ func (m *Manager) ConnectRDPByName(ctx context.Context, vmName, credentials stri
 		return err
 	}
 
-	return m.ConnectRDPByIP(ctx, ip, credentials)
+	_, err = m.OpenRDP(ctx, vmName, ip, credentials)
+	return err
 }
 
 // ConnectRDPByIP opens an RDP connection to a specific IP address
 // credentials can be "username" or "username@password"
 func (m *Manager) ConnectRDPByIP(ctx context.Context, ip, credentials string) error {
+	_, err := m.OpenRDP(ctx, "", ip, credentials)
+	return err
+}
+
+// OpenRDP opens mstsc to the address of a VM and records the session; vmName may be empty
+func (m *Manager) OpenRDP(ctx context.Context, vmName, ip, credentials string) (*Session, error) {
 	creds := ParseCredentials(credentials)
 
 	// If password is provided, save to Windows Credential Manager first
 	if creds.Password != "" {
 		if err := m.SaveRDPCredentials(ctx, ip, creds.Username, creds.Password); err != nil {
-			return fmt.Errorf("failed to save RDP credentials: %v", err)
+			return nil, fmt.Errorf("failed to save RDP credentials: %v", err)
 		}
 	}
 
-	// Build mstsc command
-	// mstsc is an external interactive GUI program.
-	// We use Command (not CommandContext) because we usually want it to detach or run independently if possible,
-	// but CommandContext is safer if we want to kill it when CLI exits.
-	// However, RDP usually stays open.
-	// For now, let's use standard exec.Command but we can't use ctx easily without it being killed on context cancel.
-	// If context is short-lived (CLI command), we might want it to survive?
-	// But idiomatic Go says respect context. Use CommandContext.
-	// If user hits Ctrl-C, we kill RDP window? Probably acceptable.
-
-	cmd := exec.CommandContext(ctx, "mstsc", "/v:"+ip)
-
-	// Start mstsc without waiting for it to finish
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start RDP client: %v", err)
+	// mstsc is detached so the window outlives the command; 'quickvm sessions close' ends it
+	pid, err := m.launcher().Start(rdpProgram, "/v:"+ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start RDP client: %v", err)
 	}
-
-	return nil
+	return recordSession(Session{Kind: SessionRDP, VMName: vmName, Target: ip, PID: pid, Program: rdpProgram})
 }
 
 // SaveRDPCredentials saves RDP credentials to Windows Credential Manager
@@ -0,0 +1,158 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ipv4Pattern = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
+
+// WaitReady polls a VM until the readiness check passes, the check's timeout expires,
+// or ctx is cancelled
+func (m *Manager) WaitReady(ctx context.Context, vmName string, check *ReadinessCheck) error {
+	if err := check.Validate(); err != nil {
+		return err
+	}
+
+	timeout := time.Duration(check.Timeout)
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	interval := time.Duration(check.Interval)
+	if interval <= 0 {
+		interval = defaultReadinessInterval
+	}
+
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		ready, err := m.probeReadiness(ctx, vmName, check)
+		if ready {
+			return nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("waiting for VM '%s' cancelled: %w", vmName, ctx.Err())
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if lastErr != nil {
+				return fmt.Errorf("VM '%s' not ready after %s (%s): %w", vmName, timeout, check, lastErr)
+			}
+			return fmt.Errorf("VM '%s' not ready after %s (%s)", vmName, timeout, check)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for VM '%s' cancelled: %w", vmName, ctx.Err())
+		}
+	}
+}
+
+// probeReadiness runs a single readiness probe. A non-nil error explains why the VM is not ready yet.
+func (m *Manager) probeReadiness(ctx context.Context, vmName string, check *ReadinessCheck) (bool, error) {
+	switch check.Type {
+	case ReadinessHeartbeat:
+		return m.probeHeartbeat(ctx, vmName)
+	case ReadinessIP:
+		ip, err := m.getVMIPv4(ctx, vmName)
+		return ip != "", err
+	case ReadinessTCP:
+		return m.probeTCP(ctx, vmName, check)
+	case ReadinessCommand:
+		return m.probeGuestCommand(ctx, vmName, check)
+	default:
+		return false, fmt.Errorf("unknown readiness check type '%s'", check.Type)
+	}
+}
+
+// probeHeartbeat reports whether the heartbeat integration service is in an OK state
+func (m *Manager) probeHeartbeat(ctx context.Context, vmName string) (bool, error) {
+	output, err := m.Exec.RunCmdlet(ctx, "Get-VM", "-Name", vmName, "|", "Select-Object", "-ExpandProperty", "Heartbeat")
+	if err != nil {
+		return false, fmt.Errorf("failed to get heartbeat: %v", err)
+	}
+
+	// Heartbeat values are e.g. OkApplicationsHealthy, OkApplicationsUnknown, NoContact, LostCommunication
+	heartbeat := strings.TrimSpace(string(output))
+	if strings.HasPrefix(heartbeat, "Ok") {
+		return true, nil
+	}
+	return false, fmt.Errorf("heartbeat is '%s'", heartbeat)
+}
+
+// getVMIPv4 returns the first IPv4 address reported by the VM's network adapters, or "" if none
+func (m *Manager) getVMIPv4(ctx context.Context, vmName string) (string, error) {
+	output, err := m.Exec.RunCmdlet(ctx, "Get-VMNetworkAdapter", "-VMName", vmName, "|", "Select-Object", "-ExpandProperty", "IPAddresses")
+	if err != nil {
+		return "", fmt.Errorf("failed to get IP addresses: %v", err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if ip := strings.TrimSpace(line); ipv4Pattern.MatchString(ip) {
+			return ip, nil
+		}
+	}
+	return "", fmt.Errorf("no IPv4 address assigned")
+}
+
+// probeTCP reports whether the configured port accepts TCP connections
+func (m *Manager) probeTCP(ctx context.Context, vmName string, check *ReadinessCheck) (bool, error) {
+	host := check.Host
+	if host == "" {
+		ip, err := m.getVMIPv4(ctx, vmName)
+		if ip == "" {
+			return false, err
+		}
+		host = ip
+	}
+
+	dialer := net.Dialer{Timeout: 3 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(check.Port)))
+	if err != nil {
+		return false, fmt.Errorf("port %d not open: %w", check.Port, err)
+	}
+	_ = conn.Close()
+	return true, nil
+}
+
+// probeGuestCommand runs the configured command inside the guest via PowerShell Direct
+// and reports whether it completed without error
+func (m *Manager) probeGuestCommand(ctx context.Context, vmName string, check *ReadinessCheck) (bool, error) {
+	output, err := m.Exec.RunScript(ctx, buildGuestCommandScript(vmName, check.Command, check.User, check.PasswordEnv))
+	if err != nil {
+		return false, fmt.Errorf("guest command failed: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	return true, nil
+}
+
+// buildGuestCommandScript builds a PowerShell Direct invocation of command inside the guest.
+// The password is read from the environment variable passwordEnv so it never appears in the script.
+func buildGuestCommandScript(vmName, command, user, passwordEnv string) string {
+	credential := ""
+	if user != "" {
+		password := "(New-Object System.Security.SecureString)"
+		if passwordEnv != "" {
+			password = fmt.Sprintf("(ConvertTo-SecureString $env:%s -AsPlainText -Force)", passwordEnv)
+		}
+		credential = fmt.Sprintf(`$credential = New-Object System.Management.Automation.PSCredential("%s", %s)
+		`, escapePSString(user), password)
+	}
+
+	credentialArg := ""
+	if credential != "" {
+		credentialArg = " -Credential $credential"
+	}
+
+	return fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		%s$block = [scriptblock]::Create("%s")
+		Invoke-Command -VMName "%s"%s -ScriptBlock $block
+	`, credential, escapePSString(command), escapePSString(vmName), credentialArg)
+}
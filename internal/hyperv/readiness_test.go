@@ -0,0 +1,107 @@
+package hyperv
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitReady_Heartbeat(t *testing.T) {
+	manager, mock := newMockManager("OkApplicationsHealthy\r\n", nil)
+
+	err := manager.WaitReady(context.Background(), "DC01", &ReadinessCheck{Type: ReadinessHeartbeat})
+	if err != nil {
+		t.Fatalf("Expected heartbeat to be ready, got %v", err)
+	}
+	if mock.LastCmdlet != "Get-VM" {
+		t.Errorf("Expected Get-VM, got %s", mock.LastCmdlet)
+	}
+}
+
+func TestWaitReady_TimesOut(t *testing.T) {
+	manager, _ := newMockManager("NoContact", nil)
+
+	check := &ReadinessCheck{
+		Type:     ReadinessHeartbeat,
+		Timeout:  Duration(30 * time.Millisecond),
+		Interval: Duration(10 * time.Millisecond),
+	}
+	err := manager.WaitReady(context.Background(), "DC01", check)
+	if err == nil {
+		t.Fatal("Expected timeout error, got nil")
+	}
+	if !strings.Contains(err.Error(), "not ready") || !strings.Contains(err.Error(), "NoContact") {
+		t.Errorf("Expected timeout error mentioning heartbeat state, got %v", err)
+	}
+}
+
+func TestWaitReady_IP(t *testing.T) {
+	manager, _ := newMockManager("fe80::1\r\n10.0.0.5\r\n", nil)
+
+	if err := manager.WaitReady(context.Background(), "SQL01", &ReadinessCheck{Type: ReadinessIP}); err != nil {
+		t.Errorf("Expected IP check to pass, got %v", err)
+	}
+}
+
+func TestWaitReady_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	// The VM's IP comes from Get-VMNetworkAdapter; point it at the local listener
+	manager, _ := newMockManager("127.0.0.1", nil)
+
+	check := &ReadinessCheck{Type: ReadinessTCP, Port: port}
+	if err := manager.WaitReady(context.Background(), "SQL01", check); err != nil {
+		t.Errorf("Expected TCP check to pass, got %v", err)
+	}
+
+	closedPort := port
+	_ = listener.Close()
+	check = &ReadinessCheck{
+		Type:     ReadinessTCP,
+		Port:     closedPort,
+		Timeout:  Duration(20 * time.Millisecond),
+		Interval: Duration(10 * time.Millisecond),
+	}
+	if err := manager.WaitReady(context.Background(), "SQL01", check); err == nil {
+		t.Errorf("Expected TCP check on closed port %s to fail", strconv.Itoa(closedPort))
+	}
+}
+
+func TestWaitReady_Cancelled(t *testing.T) {
+	manager, _ := newMockManager("NoContact", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := manager.WaitReady(ctx, "DC01", &ReadinessCheck{Type: ReadinessHeartbeat, Interval: Duration(time.Hour)})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+}
+
+func TestBuildGuestCommandScript(t *testing.T) {
+	script := buildGuestCommandScript(`DC"01`, `Get-Service "NTDS"`, "CORP\\admin", "DC_PASSWORD")
+
+	if !strings.Contains(script, "Invoke-Command -VMName \"DC`\"01\" -Credential $credential") {
+		t.Errorf("Expected escaped VM name and credential, got:\n%s", script)
+	}
+	if !strings.Contains(script, "$env:DC_PASSWORD") {
+		t.Errorf("Expected password to be read from environment, got:\n%s", script)
+	}
+	if !strings.Contains(script, "Get-Service `\"NTDS`\"") {
+		t.Errorf("Expected escaped command, got:\n%s", script)
+	}
+
+	noCred := buildGuestCommandScript("DC01", "hostname", "", "")
+	if strings.Contains(noCred, "-Credential") {
+		t.Errorf("Expected no credential without user, got:\n%s", noCred)
+	}
+}
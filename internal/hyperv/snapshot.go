@@ -76,6 +76,27 @@ func (m *Manager) GetSnapshotsByVMName(ctx context.Context, vmName string) ([]Sn
 	return snapshots, nil
 }
 
+// CountSnapshots returns the number of snapshots of every VM that has any, by VM name
+func (m *Manager) CountSnapshots(ctx context.Context) (map[string]int, error) {
+	psScript := `
+		$counts = @{}
+		Get-VM | Get-VMSnapshot -ErrorAction SilentlyContinue | Group-Object VMName | ForEach-Object { $counts[$_.Name] = $_.Count }
+		$counts | ConvertTo-Json -Compress
+	`
+	output, err := m.Exec.RunScript(ctx, psScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count snapshots: %v\nOutput: %s", err, string(output))
+	}
+
+	counts := make(map[string]int)
+	if outputStr := strings.TrimSpace(string(output)); outputStr != "" {
+		if err := json.Unmarshal([]byte(outputStr), &counts); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot counts: %v", err)
+		}
+	}
+	return counts, nil
+}
+
 // CreateSnapshot creates a new snapshot for a VM by index
 func (m *Manager) CreateSnapshot(ctx context.Context, vmIndex int, snapshotName string) error {
 	vms, err := m.GetVMs(ctx)
@@ -237,3 +237,19 @@ func TestGetSnapshotsByVMName_NonExistent(t *testing.T) {
 		t.Error("Expected error for non-existent VM, got nil")
 	}
 }
+
+func TestCountSnapshots(t *testing.T) {
+	manager, _ := newMockManager(`{"Web01":3,"Db01":1}`, nil)
+	counts, err := manager.CountSnapshots(context.Background())
+	if err != nil {
+		t.Fatalf("CountSnapshots failed: %v", err)
+	}
+	if counts["Web01"] != 3 || counts["Db01"] != 1 || len(counts) != 2 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	manager, _ = newMockManager("", nil)
+	if counts, err := manager.CountSnapshots(context.Background()); err != nil || len(counts) != 0 {
+		t.Errorf("Expected no counts without snapshots, got %v, %v", counts, err)
+	}
+}
@@ -0,0 +1,418 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Readiness check types supported in workspace startup policies
+const (
+	ReadinessHeartbeat = "heartbeat" // Hyper-V heartbeat integration service reports OK
+	ReadinessIP        = "ip"        // VM has an IPv4 address assigned
+	ReadinessTCP       = "tcp"       // A TCP port on the VM accepts connections
+	ReadinessCommand   = "command"   // A guest command succeeds via PowerShell Direct
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+const (
+	defaultReadinessTimeout  = 5 * time.Minute
+	defaultReadinessInterval = 5 * time.Second
+)
+
+// Duration is a time.Duration that is written as a human-readable string ("30s", "5m")
+// in workspace YAML and JSON output instead of raw nanoseconds
+type Duration time.Duration
+
+// MarshalYAML writes the duration as a string such as "1m30s"
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML accepts Go duration strings ("30s") or a plain integer number of seconds
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := parseDuration(value.Value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string such as "1m30s"
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// parseDuration parses "30s"-style durations, treating a bare number as seconds
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if parsed, err := time.ParseDuration(s); err == nil {
+		return parsed, nil
+	}
+	var seconds int
+	if _, err := fmt.Sscanf(s, "%d", &seconds); err == nil && fmt.Sprintf("%d", seconds) == s {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("invalid duration '%s' (use values like 30s, 2m, 1h)", s)
+}
+
+// ReadinessCheck describes how to decide that a started VM is ready for its dependents
+type ReadinessCheck struct {
+	Type        string   `yaml:"type" json:"type"`                                   // heartbeat, ip, tcp or command
+	Port        int      `yaml:"port,omitempty" json:"port,omitempty"`               // tcp: port to probe
+	Host        string   `yaml:"host,omitempty" json:"host,omitempty"`               // tcp: optional host override (defaults to the VM's IPv4)
+	Command     string   `yaml:"command,omitempty" json:"command,omitempty"`         // command: PowerShell run inside the guest
+	User        string   `yaml:"user,omitempty" json:"user,omitempty"`               // command: guest account for PowerShell Direct
+	PasswordEnv string   `yaml:"passwordEnv,omitempty" json:"passwordEnv,omitempty"` // command: environment variable holding the guest password
+	Timeout     Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`         // Give up after this long (default 5m)
+	Interval    Duration `yaml:"interval,omitempty" json:"interval,omitempty"`       // Delay between probes (default 5s)
+}
+
+// Validate checks that the readiness check has the fields its type requires
+func (c *ReadinessCheck) Validate() error {
+	switch c.Type {
+	case ReadinessHeartbeat, ReadinessIP:
+		return nil
+	case ReadinessTCP:
+		if c.Port < 1 || c.Port > 65535 {
+			return fmt.Errorf("tcp readiness check requires a port between 1 and 65535")
+		}
+		return nil
+	case ReadinessCommand:
+		if strings.TrimSpace(c.Command) == "" {
+			return fmt.Errorf("command readiness check requires a command")
+		}
+		if c.PasswordEnv != "" && !envNamePattern.MatchString(c.PasswordEnv) {
+			return fmt.Errorf("invalid passwordEnv '%s': must be an environment variable name", c.PasswordEnv)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown readiness check type '%s' (valid: heartbeat, ip, tcp, command)", c.Type)
+	}
+}
+
+// String returns a short description used in progress output
+func (c *ReadinessCheck) String() string {
+	switch c.Type {
+	case ReadinessTCP:
+		return fmt.Sprintf("tcp:%d", c.Port)
+	default:
+		return c.Type
+	}
+}
+
+// StartupPolicy controls when a workspace member is started relative to the others
+type StartupPolicy struct {
+	DependsOn  []string        `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`   // Members that must be ready first
+	StartDelay Duration        `yaml:"startDelay,omitempty" json:"startDelay,omitempty"` // Wait this long after dependencies are ready
+	Readiness  *ReadinessCheck `yaml:"readiness,omitempty" json:"readiness,omitempty"`   // How to decide this VM is ready
+}
+
+// Policy returns the startup policy for a member, or an empty policy if none is configured
+func (ws *Workspace) Policy(vmName string) StartupPolicy {
+	if ws.Startup == nil {
+		return StartupPolicy{}
+	}
+	return ws.Startup[vmName]
+}
+
+// StartupOrder resolves the dependency graph into start layers.
+// VMs in the same layer do not depend on each other and can start in parallel;
+// within a layer VMs keep their order from the workspace file.
+func (ws *Workspace) StartupOrder() ([][]string, error) {
+	if err := ws.validateStartup(); err != nil {
+		return nil, err
+	}
+	if cycle := ws.findDependencyCycle(); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	placed := make(map[string]bool, len(ws.VMs))
+	var layers [][]string
+	for len(placed) < len(ws.VMs) {
+		var layer []string
+		for _, vm := range ws.VMs {
+			if placed[vm] {
+				continue
+			}
+			ready := true
+			for _, dep := range ws.Policy(vm).DependsOn {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				layer = append(layer, vm)
+			}
+		}
+		for _, vm := range layer {
+			placed[vm] = true
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
+}
+
+// validateStartup checks that startup policies only reference workspace members
+func (ws *Workspace) validateStartup() error {
+	members := make(map[string]bool, len(ws.VMs))
+	for _, vm := range ws.VMs {
+		if members[vm] {
+			return fmt.Errorf("VM '%s' is listed more than once", vm)
+		}
+		members[vm] = true
+	}
+
+	for vm, policy := range ws.Startup {
+		if !members[vm] {
+			return fmt.Errorf("startup policy for '%s' but it is not a workspace member", vm)
+		}
+		for _, dep := range policy.DependsOn {
+			if dep == vm {
+				return fmt.Errorf("VM '%s' cannot depend on itself", vm)
+			}
+			if !members[dep] {
+				return fmt.Errorf("VM '%s' depends on '%s', which is not a workspace member", vm, dep)
+			}
+		}
+		if policy.Readiness != nil {
+			if err := policy.Readiness.Validate(); err != nil {
+				return fmt.Errorf("invalid readiness check for '%s': %w", vm, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findDependencyCycle returns the VMs forming a dependency cycle (first VM repeated at the end), or nil
+func (ws *Workspace) findDependencyCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(ws.VMs))
+	var stack []string
+
+	var visit func(vm string) []string
+	visit = func(vm string) []string {
+		state[vm] = visiting
+		stack = append(stack, vm)
+		for _, dep := range ws.Policy(vm).DependsOn {
+			switch state[dep] {
+			case visiting:
+				for i, v := range stack {
+					if v == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[vm] = done
+		return nil
+	}
+
+	for _, vm := range ws.VMs {
+		if state[vm] == unvisited {
+			if cycle := visit(vm); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// ReadinessProber waits until a VM satisfies a readiness check
+type ReadinessProber interface {
+	WaitReady(ctx context.Context, vmName string, check *ReadinessCheck) error
+}
+
+// Workspace orchestration phases reported through WorkspaceOrchestrator.OnEvent
+const (
+	PhaseStarting = "starting"
+	PhaseDelaying = "delaying"
+	PhaseWaiting  = "waiting"
+	PhaseReady    = "ready"
+	PhaseStopping = "stopping"
+	PhaseStopped  = "stopped"
+	PhaseFailed   = "failed"
+	PhaseSkipped  = "skipped"
+)
+
+// WorkspaceEvent reports progress for one workspace member
+type WorkspaceEvent struct {
+	VMName  string `json:"vmName"`
+	Phase   string `json:"phase"`
+	Message string `json:"message,omitempty"`
+}
+
+// WorkspaceVMResult is the outcome of starting or stopping one workspace member
+type WorkspaceVMResult struct {
+	Name     string   `json:"name"`
+	Success  bool     `json:"success"`
+	Skipped  bool     `json:"skipped,omitempty"`
+	Duration Duration `json:"duration"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// WorkspaceOrchestrator starts and stops workspace members in dependency order.
+// Independent VMs are handled in parallel; a failed VM causes its dependents to be skipped.
+type WorkspaceOrchestrator struct {
+	Manager VMManager
+	Prober  ReadinessProber
+	OnEvent func(WorkspaceEvent) // Optional progress callback, never called concurrently
+
+	eventMu sync.Mutex
+}
+
+// NewWorkspaceOrchestrator creates an orchestrator backed by a Hyper-V manager
+func NewWorkspaceOrchestrator(m *Manager) *WorkspaceOrchestrator {
+	return &WorkspaceOrchestrator{Manager: m, Prober: m}
+}
+
+func (o *WorkspaceOrchestrator) emit(vmName, phase, message string) {
+	if o.OnEvent == nil {
+		return
+	}
+	o.eventMu.Lock()
+	defer o.eventMu.Unlock()
+	o.OnEvent(WorkspaceEvent{VMName: vmName, Phase: phase, Message: message})
+}
+
+// Start boots every member once the VMs it depends on are ready.
+// Results are returned in workspace file order.
+func (o *WorkspaceOrchestrator) Start(ctx context.Context, ws *Workspace) ([]WorkspaceVMResult, error) {
+	if _, err := ws.StartupOrder(); err != nil {
+		return nil, err
+	}
+
+	prerequisites := make(map[string][]string, len(ws.VMs))
+	for _, vm := range ws.VMs {
+		prerequisites[vm] = ws.Policy(vm).DependsOn
+	}
+
+	return o.run(ctx, ws.VMs, prerequisites, func(ctx context.Context, vm string) error {
+		return o.startOne(ctx, vm, ws.Policy(vm))
+	}), nil
+}
+
+// Stop shuts members down in reverse dependency order: a VM is stopped only after
+// every member that depends on it has stopped. Results are returned in workspace file order.
+func (o *WorkspaceOrchestrator) Stop(ctx context.Context, ws *Workspace) ([]WorkspaceVMResult, error) {
+	if _, err := ws.StartupOrder(); err != nil {
+		return nil, err
+	}
+
+	prerequisites := make(map[string][]string, len(ws.VMs))
+	for _, vm := range ws.VMs {
+		for _, dep := range ws.Policy(vm).DependsOn {
+			prerequisites[dep] = append(prerequisites[dep], vm)
+		}
+	}
+
+	return o.run(ctx, ws.VMs, prerequisites, func(ctx context.Context, vm string) error {
+		o.emit(vm, PhaseStopping, "")
+		if err := o.Manager.StopVMByName(ctx, vm); err != nil {
+			return err
+		}
+		o.emit(vm, PhaseStopped, "")
+		return nil
+	}), nil
+}
+
+func (o *WorkspaceOrchestrator) startOne(ctx context.Context, vm string, policy StartupPolicy) error {
+	if delay := time.Duration(policy.StartDelay); delay > 0 {
+		o.emit(vm, PhaseDelaying, delay.String())
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("start of '%s' cancelled: %w", vm, ctx.Err())
+		}
+	}
+
+	o.emit(vm, PhaseStarting, "")
+	if err := o.Manager.StartVMByName(ctx, vm); err != nil {
+		return err
+	}
+
+	if policy.Readiness != nil {
+		o.emit(vm, PhaseWaiting, policy.Readiness.String())
+		if err := o.Prober.WaitReady(ctx, vm, policy.Readiness); err != nil {
+			return err
+		}
+	}
+
+	o.emit(vm, PhaseReady, "")
+	return nil
+}
+
+// run executes action for every VM once all of its prerequisites succeeded.
+// VMs whose prerequisites failed are skipped.
+func (o *WorkspaceOrchestrator) run(
+	ctx context.Context,
+	vms []string,
+	prerequisites map[string][]string,
+	action func(ctx context.Context, vm string) error,
+) []WorkspaceVMResult {
+	done := make(map[string]chan struct{}, len(vms))
+	for _, vm := range vms {
+		done[vm] = make(chan struct{})
+	}
+
+	results := make([]WorkspaceVMResult, len(vms))
+	var wg sync.WaitGroup
+	for i, vm := range vms {
+		wg.Add(1)
+		go func(i int, vm string) {
+			defer wg.Done()
+			defer close(done[vm])
+			results[i] = WorkspaceVMResult{Name: vm}
+
+			for _, prereq := range prerequisites[vm] {
+				<-done[prereq]
+			}
+			// Results of prerequisites are final once their channel is closed.
+			for _, prereq := range prerequisites[vm] {
+				for j := range vms {
+					if vms[j] == prereq && !results[j].Success {
+						results[i].Skipped = true
+						results[i].Error = fmt.Sprintf("skipped because '%s' did not succeed", prereq)
+						o.emit(vm, PhaseSkipped, results[i].Error)
+						return
+					}
+				}
+			}
+
+			began := time.Now()
+			err := action(ctx, vm)
+			results[i].Duration = Duration(time.Since(began).Round(time.Millisecond))
+			if err != nil {
+				results[i].Error = err.Error()
+				o.emit(vm, PhaseFailed, err.Error())
+				return
+			}
+			results[i].Success = true
+		}(i, vm)
+	}
+	wg.Wait()
+
+	return results
+}
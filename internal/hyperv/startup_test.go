@@ -0,0 +1,350 @@
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fakeVMManager is an in-memory VMManager that records start/stop calls
+type fakeVMManager struct {
+	mu        sync.Mutex
+	calls     []string
+	failStart map[string]bool
+}
+
+func (f *fakeVMManager) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeVMManager) indexOf(call string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.calls {
+		if c == call {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeVMManager) GetVMs(_ context.Context) ([]VM, error)            { return nil, nil }
+func (f *fakeVMManager) StartVM(_ context.Context, _ int) error            { return nil }
+func (f *fakeVMManager) StopVM(_ context.Context, _ int) error             { return nil }
+func (f *fakeVMManager) RestartVM(_ context.Context, _ int) error          { return nil }
+func (f *fakeVMManager) RestartVMByName(_ context.Context, _ string) error { return nil }
+func (f *fakeVMManager) GetVMStatus(_ context.Context, _ string) (string, error) {
+	return "Running", nil
+}
+
+func (f *fakeVMManager) StartVMByName(_ context.Context, name string) error {
+	f.record("start:" + name)
+	if f.failStart[name] {
+		return fmt.Errorf("failed to start VM '%s'", name)
+	}
+	return nil
+}
+
+func (f *fakeVMManager) StopVMByName(_ context.Context, name string) error {
+	f.record("stop:" + name)
+	return nil
+}
+
+// fakeProber records readiness waits and always succeeds
+type fakeProber struct {
+	mgr *fakeVMManager
+}
+
+func (p *fakeProber) WaitReady(_ context.Context, vmName string, _ *ReadinessCheck) error {
+	p.mgr.record("ready:" + vmName)
+	return nil
+}
+
+func labWorkspace() *Workspace {
+	return &Workspace{
+		Name: "lab",
+		VMs:  []string{"APP01", "DC01", "SQL01", "WEB01"},
+		Startup: map[string]StartupPolicy{
+			"SQL01": {DependsOn: []string{"DC01"}, Readiness: &ReadinessCheck{Type: ReadinessHeartbeat}},
+			"APP01": {DependsOn: []string{"SQL01"}},
+			"DC01":  {Readiness: &ReadinessCheck{Type: ReadinessTCP, Port: 389}},
+		},
+	}
+}
+
+func TestStartupOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		ws      *Workspace
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name: "No startup policies",
+			ws:   &Workspace{VMs: []string{"A", "B"}},
+			want: [][]string{{"A", "B"}},
+		},
+		{
+			name: "Dependency chain keeps file order within layers",
+			ws:   labWorkspace(),
+			want: [][]string{{"DC01", "WEB01"}, {"SQL01"}, {"APP01"}},
+		},
+		{
+			name: "Cycle",
+			ws: &Workspace{
+				VMs: []string{"A", "B", "C"},
+				Startup: map[string]StartupPolicy{
+					"A": {DependsOn: []string{"C"}},
+					"B": {DependsOn: []string{"A"}},
+					"C": {DependsOn: []string{"B"}},
+				},
+			},
+			wantErr: "dependency cycle detected: A -> C -> B -> A",
+		},
+		{
+			name:    "Self dependency",
+			ws:      &Workspace{VMs: []string{"A"}, Startup: map[string]StartupPolicy{"A": {DependsOn: []string{"A"}}}},
+			wantErr: "cannot depend on itself",
+		},
+		{
+			name:    "Unknown dependency",
+			ws:      &Workspace{VMs: []string{"A"}, Startup: map[string]StartupPolicy{"A": {DependsOn: []string{"X"}}}},
+			wantErr: "not a workspace member",
+		},
+		{
+			name:    "Policy for non-member",
+			ws:      &Workspace{VMs: []string{"A"}, Startup: map[string]StartupPolicy{"B": {}}},
+			wantErr: "not a workspace member",
+		},
+		{
+			name:    "Duplicate member",
+			ws:      &Workspace{VMs: []string{"A", "A"}},
+			wantErr: "listed more than once",
+		},
+		{
+			name: "Invalid readiness check",
+			ws: &Workspace{VMs: []string{"A"}, Startup: map[string]StartupPolicy{
+				"A": {Readiness: &ReadinessCheck{Type: ReadinessTCP}},
+			}},
+			wantErr: "requires a port",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ws.StartupOrder()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StartupOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadinessCheck_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   ReadinessCheck
+		wantErr bool
+	}{
+		{"Heartbeat", ReadinessCheck{Type: ReadinessHeartbeat}, false},
+		{"IP", ReadinessCheck{Type: ReadinessIP}, false},
+		{"TCP with port", ReadinessCheck{Type: ReadinessTCP, Port: 22}, false},
+		{"TCP without port", ReadinessCheck{Type: ReadinessTCP}, true},
+		{"Command", ReadinessCheck{Type: ReadinessCommand, Command: "Get-Service NTDS"}, false},
+		{"Command empty", ReadinessCheck{Type: ReadinessCommand}, true},
+		{"Command bad env name", ReadinessCheck{Type: ReadinessCommand, Command: "x", PasswordEnv: "A; rm"}, true},
+		{"Unknown type", ReadinessCheck{Type: "ping"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.check.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStartupYAML(t *testing.T) {
+	input := `
+name: lab
+vms: [DC01, SQL01]
+startup:
+  SQL01:
+    dependsOn: [DC01]
+    startDelay: 30
+    readiness:
+      type: tcp
+      port: 1433
+      timeout: 2m
+`
+	var ws Workspace
+	if err := yaml.Unmarshal([]byte(input), &ws); err != nil {
+		t.Fatalf("Failed to unmarshal workspace: %v", err)
+	}
+
+	policy := ws.Policy("SQL01")
+	if time.Duration(policy.StartDelay) != 30*time.Second {
+		t.Errorf("Expected startDelay 30s, got %v", time.Duration(policy.StartDelay))
+	}
+	if policy.Readiness == nil || time.Duration(policy.Readiness.Timeout) != 2*time.Minute {
+		t.Errorf("Expected readiness timeout 2m, got %+v", policy.Readiness)
+	}
+
+	data, err := yaml.Marshal(&ws)
+	if err != nil {
+		t.Fatalf("Failed to marshal workspace: %v", err)
+	}
+	if !strings.Contains(string(data), "startDelay: 30s") || !strings.Contains(string(data), "timeout: 2m0s") {
+		t.Errorf("Expected human-readable durations in YAML, got:\n%s", data)
+	}
+
+	var bad Workspace
+	if err := yaml.Unmarshal([]byte("name: x\nstartup:\n  A:\n    startDelay: soon\n"), &bad); err == nil {
+		t.Error("Expected error for invalid duration, got nil")
+	}
+}
+
+func TestWorkspaceOrchestrator_Start(t *testing.T) {
+	mgr := &fakeVMManager{}
+	orchestrator := &WorkspaceOrchestrator{Manager: mgr, Prober: &fakeProber{mgr: mgr}}
+
+	var events []WorkspaceEvent
+	orchestrator.OnEvent = func(e WorkspaceEvent) { events = append(events, e) }
+
+	results, err := orchestrator.Start(context.Background(), labWorkspace())
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Expected %s to succeed, got %+v", result.Name, result)
+		}
+	}
+	if results[0].Name != "APP01" {
+		t.Errorf("Expected results in file order, got %s first", results[0].Name)
+	}
+
+	// Each dependent must start only after its dependency passed its readiness check
+	if mgr.indexOf("ready:DC01") > mgr.indexOf("start:SQL01") {
+		t.Errorf("SQL01 started before DC01 was ready: %v", mgr.calls)
+	}
+	if mgr.indexOf("ready:SQL01") > mgr.indexOf("start:APP01") {
+		t.Errorf("APP01 started before SQL01 was ready: %v", mgr.calls)
+	}
+	if mgr.indexOf("ready:APP01") != -1 {
+		t.Errorf("APP01 has no readiness check but one was run: %v", mgr.calls)
+	}
+
+	if len(events) == 0 {
+		t.Error("Expected progress events")
+	}
+}
+
+func TestWorkspaceOrchestrator_StartSkipsDependentsOfFailedVM(t *testing.T) {
+	mgr := &fakeVMManager{failStart: map[string]bool{"DC01": true}}
+	orchestrator := &WorkspaceOrchestrator{Manager: mgr, Prober: &fakeProber{mgr: mgr}}
+
+	results, err := orchestrator.Start(context.Background(), labWorkspace())
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	byName := make(map[string]WorkspaceVMResult)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	if byName["DC01"].Success || byName["DC01"].Skipped {
+		t.Errorf("Expected DC01 to fail, got %+v", byName["DC01"])
+	}
+	if !byName["SQL01"].Skipped || !byName["APP01"].Skipped {
+		t.Errorf("Expected SQL01 and APP01 to be skipped, got %+v / %+v", byName["SQL01"], byName["APP01"])
+	}
+	if !byName["WEB01"].Success {
+		t.Errorf("Expected independent WEB01 to start, got %+v", byName["WEB01"])
+	}
+	if mgr.indexOf("start:SQL01") != -1 {
+		t.Errorf("SQL01 should not have been started: %v", mgr.calls)
+	}
+}
+
+func TestWorkspaceOrchestrator_StartDelayHonorsCancellation(t *testing.T) {
+	mgr := &fakeVMManager{}
+	orchestrator := &WorkspaceOrchestrator{Manager: mgr, Prober: &fakeProber{mgr: mgr}}
+	ws := &Workspace{
+		VMs:     []string{"A"},
+		Startup: map[string]StartupPolicy{"A": {StartDelay: Duration(time.Hour)}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := orchestrator.Start(ctx, ws)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if results[0].Success || !strings.Contains(results[0].Error, "cancelled") {
+		t.Errorf("Expected cancelled start, got %+v", results[0])
+	}
+	if mgr.indexOf("start:A") != -1 {
+		t.Error("VM should not be started after cancellation")
+	}
+}
+
+func TestWorkspaceOrchestrator_StopReverseOrder(t *testing.T) {
+	mgr := &fakeVMManager{}
+	orchestrator := &WorkspaceOrchestrator{Manager: mgr, Prober: &fakeProber{mgr: mgr}}
+
+	results, err := orchestrator.Stop(context.Background(), labWorkspace())
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	if mgr.indexOf("stop:APP01") > mgr.indexOf("stop:SQL01") {
+		t.Errorf("SQL01 stopped before its dependent APP01: %v", mgr.calls)
+	}
+	if mgr.indexOf("stop:SQL01") > mgr.indexOf("stop:DC01") {
+		t.Errorf("DC01 stopped before its dependent SQL01: %v", mgr.calls)
+	}
+}
+
+func TestWorkspaceOrchestrator_RejectsCycle(t *testing.T) {
+	mgr := &fakeVMManager{}
+	orchestrator := &WorkspaceOrchestrator{Manager: mgr, Prober: &fakeProber{mgr: mgr}}
+	ws := &Workspace{
+		VMs: []string{"A", "B"},
+		Startup: map[string]StartupPolicy{
+			"A": {DependsOn: []string{"B"}},
+			"B": {DependsOn: []string{"A"}},
+		},
+	}
+
+	if _, err := orchestrator.Start(context.Background(), ws); err == nil {
+		t.Error("Expected cycle error, got nil")
+	}
+	if len(mgr.calls) != 0 {
+		t.Errorf("No VM should be started for an invalid workspace: %v", mgr.calls)
+	}
+}
@@ -0,0 +1,77 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Virtual switch types
+const (
+	SwitchPrivate  = "private"  // VMs on the host only
+	SwitchInternal = "internal" // VMs and the host
+	SwitchExternal = "external" // Bridged to a physical network adapter
+)
+
+// EnsureSwitch creates a virtual switch unless one with the same name exists.
+// netAdapter names the physical adapter for external switches. It reports whether
+// the switch was created.
+func (m *Manager) EnsureSwitch(ctx context.Context, name, switchType, netAdapter string) (bool, error) {
+	var create string
+	switch switchType {
+	case SwitchPrivate, SwitchInternal:
+		create = fmt.Sprintf(`New-VMSwitch -Name "%s" -SwitchType %s | Out-Null`, escapePSString(name), switchType)
+	case SwitchExternal:
+		if netAdapter == "" {
+			return false, fmt.Errorf("external switch '%s' needs a network adapter", name)
+		}
+		create = fmt.Sprintf(`New-VMSwitch -Name "%s" -NetAdapterName "%s" -AllowManagementOS $true | Out-Null`,
+			escapePSString(name), escapePSString(netAdapter))
+	default:
+		return false, fmt.Errorf("unknown switch type '%s' (valid: private, internal, external)", switchType)
+	}
+
+	output, err := m.Exec.RunScript(ctx, fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		if (Get-VMSwitch -Name "%s" -ErrorAction SilentlyContinue) {
+			"EXISTS"
+		} else {
+			%s
+			"CREATED"
+		}
+	`, escapePSString(name), create))
+	if err != nil {
+		return false, fmt.Errorf("failed to create switch '%s': %v\nOutput: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return strings.Contains(string(output), "CREATED"), nil
+}
+
+// SwitchVMs returns the names of the VMs with a network adapter connected to the switch
+func (m *Manager) SwitchVMs(ctx context.Context, name string) ([]string, error) {
+	output, err := m.Exec.RunScript(ctx, fmt.Sprintf(
+		`ConvertTo-Json -InputObject @(Get-VMNetworkAdapter -VMName * | Where-Object SwitchName -eq "%s" | Select-Object -ExpandProperty VMName -Unique)`,
+		escapePSString(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the VMs on switch '%s': %v\nOutput: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	var vms []string
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &vms); err != nil {
+			return nil, fmt.Errorf("failed to parse the VMs on switch '%s': %v", name, err)
+		}
+	}
+	return vms, nil
+}
+
+// RemoveSwitch removes a virtual switch if it exists
+func (m *Manager) RemoveSwitch(ctx context.Context, name string) error {
+	output, err := m.Exec.RunScript(ctx, fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		Get-VMSwitch -Name "%s" -ErrorAction SilentlyContinue | Remove-VMSwitch -Force
+	`, escapePSString(name)))
+	if err != nil {
+		return fmt.Errorf("failed to remove switch '%s': %v\nOutput: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package hyperv
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEnsureSwitch(t *testing.T) {
+	manager, mock := newMockManager("CREATED", nil)
+
+	created, err := manager.EnsureSwitch(context.Background(), `Lab"Net`, SwitchInternal, "")
+	if err != nil || !created {
+		t.Fatalf("EnsureSwitch = %v, %v", created, err)
+	}
+	if !strings.Contains(mock.LastScript, "-SwitchType internal") || !strings.Contains(mock.LastScript, "Lab`\"Net") {
+		t.Errorf("Unexpected script:\n%s", mock.LastScript)
+	}
+
+	mock.MockOutput = "EXISTS"
+	if created, _ := manager.EnsureSwitch(context.Background(), "LabNet", SwitchExternal, "Ethernet"); created {
+		t.Error("Expected an existing switch not to be reported as created")
+	}
+	if !strings.Contains(mock.LastScript, `-NetAdapterName "Ethernet"`) {
+		t.Errorf("Expected an external switch on the adapter:\n%s", mock.LastScript)
+	}
+
+	for _, switchType := range []string{"bridge", SwitchExternal} {
+		if _, err := manager.EnsureSwitch(context.Background(), "LabNet", switchType, ""); err == nil {
+			t.Errorf("Expected switch type %q without adapter to be rejected", switchType)
+		}
+	}
+}
+
+func TestSwitchVMs(t *testing.T) {
+	manager, mock := newMockManager(`["DC01","Backup"]`, nil)
+
+	vms, err := manager.SwitchVMs(context.Background(), `Lab"Net`)
+	if err != nil || len(vms) != 2 || vms[1] != "Backup" {
+		t.Fatalf("SwitchVMs = %v, %v", vms, err)
+	}
+	if !strings.Contains(mock.LastScript, "SwitchName -eq \"Lab`\"Net\"") {
+		t.Errorf("Expected the switch name to be escaped:\n%s", mock.LastScript)
+	}
+}
+
+func TestDeleteVMAndDisks(t *testing.T) {
+	manager, mock := newMockManager("", nil)
+
+	if err := manager.DeleteVMAndDisks(context.Background(), "WS01"); err != nil {
+		t.Fatalf("DeleteVMAndDisks failed: %v", err)
+	}
+	for _, want := range []string{"-TurnOff", "Remove-VM -VM $vm -Force", "Remove-Item"} {
+		if !strings.Contains(mock.LastScript, want) {
+			t.Errorf("Expected script to contain %q:\n%s", want, mock.LastScript)
+		}
+	}
+}
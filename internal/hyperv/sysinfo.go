@@ -18,8 +18,9 @@ type SystemInfo struct {
 
 // CPUInfo contains CPU information
 type CPUInfo struct {
-	Name  string `json:"name"`
-	Cores int    `json:"cores"`
+	Name        string `json:"name"`
+	Cores       int    `json:"cores"`
+	LoadPercent int    `json:"loadPercent"` // Average load across processors
 }
 
 // MemoryInfo contains memory information
@@ -180,6 +181,7 @@ func (m *Manager) getCPUInfo(ctx context.Context) (*CPUInfo, error) {
 		@{
 			Name = $cpu.Name
 			Cores = $cpu.NumberOfCores
+			LoadPercent = [int](($cpu | Measure-Object -Property LoadPercentage -Average).Average)
 		} | ConvertTo-Json
 	`
 
@@ -189,16 +191,18 @@ func (m *Manager) getCPUInfo(ctx context.Context) (*CPUInfo, error) {
 	}
 
 	var result struct {
-		Name  string `json:"Name"`
-		Cores int    `json:"Cores"`
+		Name        string `json:"Name"`
+		Cores       int    `json:"Cores"`
+		LoadPercent int    `json:"LoadPercent"`
 	}
 	if err := json.Unmarshal(output, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse CPU info: %v", err)
 	}
 
 	return &CPUInfo{
-		Name:  strings.TrimSpace(result.Name),
-		Cores: result.Cores,
+		Name:        strings.TrimSpace(result.Name),
+		Cores:       result.Cores,
+		LoadPercent: result.LoadPercent,
 	}, nil
 }
 
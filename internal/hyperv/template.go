@@ -0,0 +1,387 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// TemplateMetadataFile is the name of the metadata file in every template directory
+	TemplateMetadataFile = "template.json"
+
+	templateFormatVersion = 1
+)
+
+// Template is a VM export in the template library, described by generalized metadata:
+// the source VM's ID is not recorded and every VM created from it gets a new one
+type Template struct {
+	FormatVersion   int       `json:"formatVersion"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description,omitempty"`
+	SourceVM        string    `json:"sourceVm"` // For reference only
+	SourceHost      string    `json:"sourceHost"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Generation      int       `json:"generation"`
+	ProcessorCount  int       `json:"processorCount"`
+	MemoryStartupMB int64     `json:"memoryStartupMB"`
+	Size            int64     `json:"size"`
+	ExportDir       string    `json:"exportDir"`              // Export-VM output directory, relative to the template directory
+	LinkedClones    []string  `json:"linkedClones,omitempty"` // VMs whose differencing disks use this template's disks as parents
+
+	dir string
+}
+
+// ExportPath returns the absolute path of the template's VM export
+func (t *Template) ExportPath() string {
+	return filepath.Join(t.dir, filepath.FromSlash(t.ExportDir))
+}
+
+// TemplateCreateVMOptions contains options for creating a VM from a template
+type TemplateCreateVMOptions struct {
+	Template string // Template name
+	VMName   string // Name of the new VM
+	Linked   bool   // Use differencing disks on the template's disks instead of copying them
+	VHDPath  string // Optional: directory for the new VM's disks (defaults to <host VHD path>\<VM name>)
+}
+
+// validateTemplateName rejects names that cannot be used safely as a directory name
+func validateTemplateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("template name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\:*?"<>|`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid template name '%s': must not start with '.' or contain any of / \\ : * ? \" < > |", name)
+	}
+	return nil
+}
+
+// GetTemplateDir returns the directory of the template library
+func GetTemplateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	dir := filepath.Join(home, ".quickvm", "templates")
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create template directory: %w", err)
+	}
+	return dir, nil
+}
+
+// templatePath returns the directory of a template name
+func templatePath(name string) (string, error) {
+	if err := validateTemplateName(name); err != nil {
+		return "", err
+	}
+	dir, err := GetTemplateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// LoadTemplate loads a template's metadata by name
+func LoadTemplate(name string) (*Template, error) {
+	dir, err := templatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return readTemplate(dir)
+}
+
+func readTemplate(dir string) (*Template, error) {
+	//nolint:gosec // G304: Path is constructed from the template directory and a literal file name.
+	data, err := os.ReadFile(filepath.Join(dir, TemplateMetadataFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("template '%s' does not exist", filepath.Base(dir))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template '%s': %w", filepath.Base(dir), err)
+	}
+
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse template '%s': %w", filepath.Base(dir), err)
+	}
+	if t.FormatVersion > templateFormatVersion {
+		return nil, fmt.Errorf("template '%s' was created by a newer quickvm (format %d)", t.Name, t.FormatVersion)
+	}
+	if !isLocalPath(t.ExportDir) {
+		return nil, fmt.Errorf("template '%s' has an invalid export directory '%s'", t.Name, t.ExportDir)
+	}
+	t.dir = dir
+	return &t, nil
+}
+
+// saveTemplate writes the template's metadata into its directory
+func saveTemplate(t *Template) error {
+	return writeJSONFile(filepath.Join(t.dir, TemplateMetadataFile), t)
+}
+
+// ListTemplates returns every template in the library, sorted by name
+func ListTemplates() ([]*Template, error) {
+	dir, err := GetTemplateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	templates := make([]*Template, 0, len(entries))
+	for _, entry := range entries {
+		// Templates still being created live in hidden directories
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		t, err := readTemplate(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue // Not a template
+		}
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name) })
+	return templates, nil
+}
+
+// CreateTemplate exports vmName into the template library as template name while sending
+// progress events to events, which is closed when it finishes. VMs with checkpoints are
+// refused, as a template should be a single flattened disk state.
+func (m *Manager) CreateTemplate(ctx context.Context, vmName, name, description string, events chan<- ProgressEvent) (*Template, error) {
+	if events != nil {
+		defer close(events)
+	}
+	r := newProgressReporter(events, "template", vmName)
+	t, err := m.createTemplate(ctx, vmName, name, r)
+	if err == nil {
+		t.Description = description
+		err = commitTemplate(t, name)
+	}
+	if err != nil {
+		return nil, r.finish(ctx, err)
+	}
+	return t, r.finish(ctx, nil)
+}
+
+// createTemplate exports vmName into a hidden staging directory of the library and returns
+// the template describing it
+func (m *Manager) createTemplate(ctx context.Context, vmName, name string, r *progressReporter) (*Template, error) {
+	dir, err := templatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("template '%s' already exists", name)
+	}
+	snapshots, err := m.GetSnapshotsByVMName(ctx, vmName)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) > 0 {
+		return nil, fmt.Errorf("VM '%s' has %d checkpoints; delete or apply them before creating a template", vmName, len(snapshots))
+	}
+
+	staging := filepath.Join(filepath.Dir(dir), "."+name+".partial")
+	_ = os.RemoveAll(staging) // Leftover of an interrupted create
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(staging, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create template directory: %w", err)
+	}
+
+	t, err := m.exportTemplate(ctx, vmName, name, staging, r)
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return nil, err
+	}
+	return t, nil
+}
+
+// exportTemplate exports vmName into staging and builds its generalized metadata
+func (m *Manager) exportTemplate(ctx context.Context, vmName, name, staging string, r *progressReporter) (*Template, error) {
+	if err := m.exportWithProgress(ctx, vmName, staging, r); err != nil {
+		return nil, err
+	}
+	manifest, err := ReadExportManifest(filepath.Join(staging, vmName))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{
+		FormatVersion: templateFormatVersion,
+		Name:          name,
+		SourceVM:      vmName,
+		SourceHost:    manifest.SourceHost,
+		CreatedAt:     time.Now().UTC(),
+		Size:          dirSize(staging),
+		ExportDir:     vmName,
+		dir:           staging,
+	}
+	if manifest.Spec != nil {
+		t.Generation = manifest.Spec.Generation
+		t.ProcessorCount = manifest.Spec.ProcessorCount
+		t.MemoryStartupMB = manifest.Spec.MemoryStartupMB
+	}
+	return t, nil
+}
+
+// commitTemplate writes the metadata and moves the staged template into the library
+func commitTemplate(t *Template, name string) error {
+	dir, err := templatePath(name)
+	if err == nil {
+		err = saveTemplate(t)
+	}
+	if err == nil {
+		err = os.Rename(t.dir, dir)
+	}
+	if err != nil {
+		_ = os.RemoveAll(t.dir)
+		return fmt.Errorf("failed to add template to the library: %w", err)
+	}
+	t.dir = dir
+	return nil
+}
+
+// DeleteTemplate removes a template from the library. Templates that are still the parent of
+// existing linked clones are only removed with force, as those VMs stop working without it.
+func (m *Manager) DeleteTemplate(ctx context.Context, name string, force bool) error {
+	t, err := LoadTemplate(name)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		var inUse []string
+		for _, vmName := range t.LinkedClones {
+			if exists, _ := m.VMExists(ctx, vmName); exists {
+				inUse = append(inUse, vmName)
+			}
+		}
+		if len(inUse) > 0 {
+			return fmt.Errorf("template '%s' holds the parent disks of linked clones: %s", name, strings.Join(inUse, ", "))
+		}
+	}
+
+	if err := os.RemoveAll(t.dir); err != nil {
+		return fmt.Errorf("failed to delete template '%s': %w", name, err)
+	}
+	return nil
+}
+
+// CreateVMFromTemplate creates a VM from a template while sending progress events to events,
+// which is closed when it finishes. A full copy imports the template's export with Copy and
+// GenerateNewId; a linked clone imports only the configuration and attaches differencing disks
+// whose parents are the template's disks. A partially created VM is removed on failure.
+func (m *Manager) CreateVMFromTemplate(ctx context.Context, opts TemplateCreateVMOptions, events chan<- ProgressEvent) (string, error) {
+	if events != nil {
+		defer close(events)
+	}
+	r := newProgressReporter(events, "create", opts.VMName)
+	name, err := m.createVMFromTemplate(ctx, opts, r)
+	return name, r.finish(ctx, err)
+}
+
+func (m *Manager) createVMFromTemplate(ctx context.Context, opts TemplateCreateVMOptions, r *progressReporter) (string, error) {
+	r.phase(ProgressPreparing, "reading template")
+	t, err := LoadTemplate(opts.Template)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(opts.VMName) == "" {
+		return "", fmt.Errorf("VM name cannot be empty")
+	}
+	exists, err := m.VMExists(ctx, opts.VMName)
+	if err != nil {
+		return "", fmt.Errorf("failed to check if VM name exists: %v", err)
+	}
+	if exists {
+		return "", fmt.Errorf("a VM with name '%s' already exists", opts.VMName)
+	}
+
+	// Every VM gets its own disk directory, as VMs from one template share disk file names
+	vhdPath := opts.VHDPath
+	if vhdPath == "" {
+		if hostPath := m.importDestination(ctx, ImportVMOptions{}); hostPath != "" {
+			vhdPath = filepath.Join(hostPath, opts.VMName)
+		}
+	}
+
+	importOpts := ImportVMOptions{
+		Path:          t.ExportPath(),
+		Copy:          true,
+		GenerateNewID: true,
+		NewName:       opts.VMName,
+		VHDPath:       vhdPath,
+	}
+	if !opts.Linked {
+		return m.importWithProgress(ctx, importOpts, r)
+	}
+	if vhdPath == "" {
+		return "", fmt.Errorf("cannot determine the host's virtual hard disk path; pass a disk directory")
+	}
+
+	name, err := m.importLinkedClone(ctx, importOpts, r)
+	if err != nil {
+		return "", err
+	}
+
+	t.LinkedClones = append(t.LinkedClones, name)
+	if err := saveTemplate(t); err != nil {
+		return "", fmt.Errorf("VM '%s' created but not recorded in the template: %w", name, err)
+	}
+	return name, nil
+}
+
+// importLinkedClone imports the template's configuration without its disks and attaches
+// differencing disks in opts.VHDPath at the same controller locations
+func (m *Manager) importLinkedClone(ctx context.Context, opts ImportVMOptions, r *progressReporter) (string, error) {
+	vmcxPath, err := m.findVMCXFile(opts.Path)
+	if err != nil {
+		return "", err
+	}
+	before, err := m.listVMIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r.phase(ProgressImporting, "creating differencing disks")
+	script := fmt.Sprintf(`
+		$ErrorActionPreference = "Stop"
+		$report = Compare-VM %s
+		$drives = @($report.VM.HardDrives | Select-Object ControllerType, ControllerNumber, ControllerLocation, Path)
+		foreach ($drive in @($report.VM.HardDrives)) { Remove-VMHardDiskDrive -VMHardDiskDrive $drive }
+		$vm = Import-VM -CompatibilityReport $report
+		$vm = $vm | Rename-VM -NewName "%s" -Passthru
+		New-Item -ItemType Directory -Force -Path "%s" | Out-Null
+		foreach ($drive in $drives) {
+			$leaf = Split-Path $drive.Path -Leaf
+			$parent = Join-Path "%s" $leaf
+			$diff = Join-Path "%s" $leaf
+			New-VHD -Path $diff -ParentPath $parent -Differencing | Out-Null
+			Add-VMHardDiskDrive -VM $vm -ControllerType $drive.ControllerType -ControllerNumber $drive.ControllerNumber -ControllerLocation $drive.ControllerLocation -Path $diff
+		}
+		$vm.Name
+	`, compareVMArgs(vmcxPath, opts), escapePSString(opts.NewName), escapePSString(opts.VHDPath),
+		escapePSString(filepath.Join(opts.Path, "Virtual Hard Disks")), escapePSString(opts.VHDPath))
+
+	output, err := m.Exec.RunScript(ctx, script)
+	if err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	err = fmt.Errorf("failed to create linked clone: %v\nOutput: %s", err, string(output))
+	r.phase(ProgressRollingBack, "removing partially created VM")
+	if rollbackErr := m.rollbackImport(before, opts.NewName, true); rollbackErr != nil {
+		return "", fmt.Errorf("%w (rollback: %v)", err, rollbackErr)
+	}
+	return "", err
+}
@@ -0,0 +1,159 @@
+package hyperv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// templateRunner simulates a host for template tests on top of progressRunner
+type templateRunner struct {
+	progressRunner
+	snapshots  string
+	existing   map[string]bool
+	scripts    []string
+	importArgs []string
+}
+
+func (r *templateRunner) RunScript(ctx context.Context, script string) ([]byte, error) {
+	r.scripts = append(r.scripts, script)
+	if strings.Contains(script, "Get-VMSnapshot") {
+		return []byte(r.snapshots), nil
+	}
+	if strings.Contains(script, "New-VHD") {
+		return []byte("Dev02"), nil
+	}
+	return r.progressRunner.RunScript(ctx, script)
+}
+
+func (r *templateRunner) RunCmdlet(ctx context.Context, cmdlet string, args ...string) ([]byte, error) {
+	if cmdlet == "Get-VM" && len(args) > 2 && args[2] == "-ErrorAction" && r.existing[args[1]] {
+		return []byte(args[1]), nil
+	}
+	if cmdlet == "Import-VM" {
+		r.importArgs = args
+	}
+	return r.progressRunner.RunCmdlet(ctx, cmdlet, args...)
+}
+
+func newTemplateTestManager(t *testing.T) (*Manager, *templateRunner) {
+	t.Helper()
+	setTestHome(t)
+	runner := &templateRunner{
+		progressRunner: progressRunner{exportBytes: 64, importedID: "new-id", vhdPath: `D:\Hyper-V\Disks`},
+		existing:       map[string]bool{},
+	}
+	return &Manager{Exec: runner}, runner
+}
+
+func TestCreateTemplate_AddsGeneralizedTemplateToLibrary(t *testing.T) {
+	manager, _ := newTemplateTestManager(t)
+
+	tmpl, err := manager.CreateTemplate(context.Background(), "Web01", "web-base", "IIS base", nil)
+	if err != nil {
+		t.Fatalf("CreateTemplate failed: %v", err)
+	}
+	if tmpl.SourceVM != "Web01" || tmpl.ProcessorCount != 2 || tmpl.MemoryStartupMB != 2048 || tmpl.Size == 0 {
+		t.Errorf("Unexpected template %+v", tmpl)
+	}
+	if _, err := os.Stat(filepath.Join(tmpl.ExportPath(), "Virtual Machines", "1234.vmcx")); err != nil {
+		t.Errorf("Expected the export inside the template directory: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(tmpl.ExportPath()), TemplateMetadataFile))
+	if err != nil {
+		t.Fatalf("Expected template metadata: %v", err)
+	}
+	if strings.Contains(string(data), "1234") {
+		t.Error("Expected the source VM ID to be left out of the template metadata")
+	}
+
+	templates, err := ListTemplates()
+	if err != nil || len(templates) != 1 || templates[0].Description != "IIS base" {
+		t.Errorf("ListTemplates = %v, %v", templates, err)
+	}
+
+	if _, err := manager.CreateTemplate(context.Background(), "Web01", "web-base", "", nil); err == nil {
+		t.Error("Expected an existing template name to be rejected")
+	}
+	dir, _ := GetTemplateDir()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no staging directories to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestCreateTemplate_RefusesCheckpointsAndBadNames(t *testing.T) {
+	manager, runner := newTemplateTestManager(t)
+	runner.snapshots = `{"Name": "Before Update", "VMName": "Web01"}`
+
+	if _, err := manager.CreateTemplate(context.Background(), "Web01", "web-base", "", nil); err == nil || !strings.Contains(err.Error(), "checkpoints") {
+		t.Errorf("Expected VM with checkpoints to be refused, got %v", err)
+	}
+	for _, name := range []string{"", "../evil", ".hidden", `a\b`} {
+		if _, err := manager.CreateTemplate(context.Background(), "Web01", name, "", nil); err == nil {
+			t.Errorf("Expected template name %q to be rejected", name)
+		}
+	}
+	if templates, _ := ListTemplates(); len(templates) != 0 {
+		t.Errorf("Expected no templates, got %d", len(templates))
+	}
+}
+
+func TestCreateVMFromTemplate_FullCopy(t *testing.T) {
+	manager, runner := newTemplateTestManager(t)
+	if _, err := manager.CreateTemplate(context.Background(), "Web01", "web-base", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	name, err := manager.CreateVMFromTemplate(context.Background(), TemplateCreateVMOptions{Template: "web-base", VMName: "Web02"}, nil)
+	if err != nil || name != "Web02" {
+		t.Fatalf("CreateVMFromTemplate = %q, %v", name, err)
+	}
+
+	args := strings.Join(runner.importArgs, " ")
+	for _, want := range []string{"-Copy", "-GenerateNewId", "-VhdDestinationPath " + filepath.Join(`D:\Hyper-V\Disks`, "Web02"), "-NewName Web02"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected Import-VM args to contain %q, got %s", want, args)
+		}
+	}
+}
+
+func TestCreateVMFromTemplate_LinkedCloneAndDelete(t *testing.T) {
+	manager, runner := newTemplateTestManager(t)
+	tmpl, err := manager.CreateTemplate(context.Background(), "Web01", "web-base", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := TemplateCreateVMOptions{Template: "web-base", VMName: "Dev02", Linked: true}
+	if _, err := manager.CreateVMFromTemplate(context.Background(), opts, nil); err != nil {
+		t.Fatalf("Linked create failed: %v", err)
+	}
+	script := runner.scripts[len(runner.scripts)-1]
+	for _, want := range []string{"Remove-VMHardDiskDrive", "-Differencing", escapePSString(filepath.Join(tmpl.ExportPath(), "Virtual Hard Disks"))} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected linked clone script to contain %q:\n%s", want, script)
+		}
+	}
+	if len(runner.importArgs) != 0 {
+		t.Error("Expected a linked clone not to copy the template's disks")
+	}
+
+	loaded, err := LoadTemplate("web-base")
+	if err != nil || len(loaded.LinkedClones) != 1 || loaded.LinkedClones[0] != "Dev02" {
+		t.Fatalf("Expected the linked clone to be recorded, got %+v, %v", loaded, err)
+	}
+
+	runner.existing["Dev02"] = true
+	if err := manager.DeleteTemplate(context.Background(), "web-base", false); err == nil {
+		t.Error("Expected delete to be refused while a linked clone exists")
+	}
+	if err := manager.DeleteTemplate(context.Background(), "web-base", true); err != nil {
+		t.Errorf("Forced delete failed: %v", err)
+	}
+	if _, err := LoadTemplate("web-base"); err == nil {
+		t.Error("Expected the template to be gone")
+	}
+}
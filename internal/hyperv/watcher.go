@@ -0,0 +1,301 @@
+package hyperv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventVMAdded         = "VMAdded"
+	EventVMRemoved       = "VMRemoved"
+	EventStateChanged    = "StateChanged"
+	EventIPAssigned      = "IPAssigned"
+	EventHeartbeatLost   = "HeartbeatLost"
+	EventSnapshotCreated = "SnapshotCreated"
+	EventPollFailed      = "PollFailed" // Polling Hyper-V failed; the watcher keeps trying
+)
+
+// DefaultWatchInterval is how often the watcher polls Hyper-V
+const DefaultWatchInterval = 5 * time.Second
+
+// subscriberBuffer is how many events a slow Watcher subscriber may fall behind
+const subscriberBuffer = 64
+
+// Event is a change observed on the host
+type Event struct {
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	VMName        string    `json:"vmName,omitempty"`
+	State         string    `json:"state,omitempty"`         // Current state of the VM
+	PreviousState string    `json:"previousState,omitempty"` // StateChanged only
+	IPAddress     string    `json:"ipAddress,omitempty"`     // IPAssigned only
+	Heartbeat     string    `json:"heartbeat,omitempty"`     // HeartbeatLost only
+	Snapshot      string    `json:"snapshot,omitempty"`      // SnapshotCreated only
+	Error         string    `json:"error,omitempty"`         // PollFailed only
+}
+
+// Describe returns a one-line human-readable description of the event
+func (e Event) Describe() string {
+	switch e.Type {
+	case EventVMAdded:
+		return fmt.Sprintf("%s: added (%s)", e.VMName, e.State)
+	case EventVMRemoved:
+		return e.VMName + ": removed"
+	case EventStateChanged:
+		return fmt.Sprintf("%s: %s → %s", e.VMName, e.PreviousState, e.State)
+	case EventIPAssigned:
+		return fmt.Sprintf("%s: got IP %s", e.VMName, e.IPAddress)
+	case EventHeartbeatLost:
+		return fmt.Sprintf("%s: heartbeat lost (%s)", e.VMName, e.Heartbeat)
+	case EventSnapshotCreated:
+		return fmt.Sprintf("%s: snapshot '%s' created", e.VMName, e.Snapshot)
+	case EventPollFailed:
+		return "polling failed: " + e.Error
+	}
+	return e.Type
+}
+
+// Observation is what one poll sees of the host
+type Observation struct {
+	VMs        []VM
+	Heartbeats map[string]string   // Heartbeat status by VM name; nil when not watched
+	Snapshots  map[string][]string // Snapshot names by VM name; nil when not watched
+}
+
+// WatchOptions configures WatchVMs
+type WatchOptions struct {
+	Interval   time.Duration // DefaultWatchInterval when 0
+	Heartbeats bool          // Poll heartbeats for HeartbeatLost events
+	Snapshots  bool          // Poll snapshots for SnapshotCreated events
+	Initial    bool          // Report the VMs present at the first poll as VMAdded
+}
+
+// DiffVMs returns the VMAdded, VMRemoved, StateChanged and IPAssigned events between two VM lists
+func DiffVMs(prev, next []VM, now time.Time) []Event {
+	return DiffObservations(Observation{VMs: prev}, Observation{VMs: next}, now)
+}
+
+// DiffObservations returns the events that lead from prev to next: changes of the
+// VMs in next order, then removed VMs. Heartbeat and snapshot events need the data
+// in both observations.
+func DiffObservations(prev, next Observation, now time.Time) []Event {
+	before := make(map[string]VM, len(prev.VMs))
+	for _, vm := range prev.VMs {
+		before[vm.Name] = vm
+	}
+
+	var events []Event
+	seen := make(map[string]bool, len(next.VMs))
+	for _, vm := range next.VMs {
+		seen[vm.Name] = true
+		old, ok := before[vm.Name]
+		if !ok {
+			events = append(events, Event{Type: EventVMAdded, Time: now, VMName: vm.Name, State: vm.State})
+			continue
+		}
+		if old.State != vm.State {
+			events = append(events, Event{Type: EventStateChanged, Time: now, VMName: vm.Name,
+				State: vm.State, PreviousState: old.State})
+		}
+		for _, ip := range vm.IPAddresses {
+			if !slices.Contains(old.IPAddresses, ip) {
+				events = append(events, Event{Type: EventIPAssigned, Time: now, VMName: vm.Name, State: vm.State, IPAddress: ip})
+			}
+		}
+		if prev.Heartbeats != nil && next.Heartbeats != nil && vm.State == "Running" {
+			was, is := prev.Heartbeats[vm.Name], next.Heartbeats[vm.Name]
+			if strings.HasPrefix(was, "Ok") && !strings.HasPrefix(is, "Ok") {
+				events = append(events, Event{Type: EventHeartbeatLost, Time: now, VMName: vm.Name, State: vm.State, Heartbeat: is})
+			}
+		}
+		if prev.Snapshots != nil && next.Snapshots != nil {
+			for _, name := range next.Snapshots[vm.Name] {
+				if !slices.Contains(prev.Snapshots[vm.Name], name) {
+					events = append(events, Event{Type: EventSnapshotCreated, Time: now, VMName: vm.Name, State: vm.State, Snapshot: name})
+				}
+			}
+		}
+	}
+	for _, vm := range prev.VMs {
+		if !seen[vm.Name] {
+			events = append(events, Event{Type: EventVMRemoved, Time: now, VMName: vm.Name})
+		}
+	}
+	return events
+}
+
+// Observe polls the VMs and, as requested, their heartbeats and snapshots
+func (m *Manager) Observe(ctx context.Context, heartbeats, snapshots bool) (Observation, error) {
+	var obs Observation
+	var err error
+	if obs.VMs, err = m.GetVMs(ctx); err != nil {
+		return Observation{}, err
+	}
+	if heartbeats {
+		if obs.Heartbeats, err = m.GetHeartbeats(ctx); err != nil {
+			return Observation{}, err
+		}
+	}
+	if snapshots {
+		if obs.Snapshots, err = m.GetSnapshotNames(ctx); err != nil {
+			return Observation{}, err
+		}
+	}
+	return obs, nil
+}
+
+// GetHeartbeats returns the heartbeat status of every VM by name, e.g. OkApplicationsHealthy or LostCommunication
+func (m *Manager) GetHeartbeats(ctx context.Context) (map[string]string, error) {
+	output, err := m.Exec.RunScript(ctx,
+		`ConvertTo-Json -InputObject @(Get-VM | Select-Object Name,@{N='Heartbeat';E={"$($_.Heartbeat)"}})`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heartbeats: %v\nOutput: %s", err, string(output))
+	}
+	var rows []struct{ Name, Heartbeat string }
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse heartbeats: %v", err)
+		}
+	}
+	heartbeats := make(map[string]string, len(rows))
+	for _, row := range rows {
+		heartbeats[row.Name] = row.Heartbeat
+	}
+	return heartbeats, nil
+}
+
+// GetSnapshotNames returns the snapshot names of every VM that has any, by VM name
+func (m *Manager) GetSnapshotNames(ctx context.Context) (map[string][]string, error) {
+	output, err := m.Exec.RunScript(ctx,
+		`ConvertTo-Json -InputObject @(Get-VM | Get-VMSnapshot -ErrorAction SilentlyContinue | Select-Object VMName,Name)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %v\nOutput: %s", err, string(output))
+	}
+	var rows []struct{ VMName, Name string }
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshots: %v", err)
+		}
+	}
+	names := make(map[string][]string)
+	for _, row := range rows {
+		names[row.VMName] = append(names[row.VMName], row.Name)
+	}
+	return names, nil
+}
+
+// WatchVMs polls Hyper-V until ctx is done and sends the events between successive
+// observations to events, which is closed on return. A failed poll is reported as a
+// PollFailed event and the next poll is compared with the last successful one.
+func (m *Manager) WatchVMs(ctx context.Context, opts WatchOptions, events chan<- Event) {
+	defer close(events)
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	var prev *Observation
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		obs, err := m.Observe(ctx, opts.Heartbeats, opts.Snapshots)
+		var batch []Event
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			batch = []Event{{Type: EventPollFailed, Time: time.Now(), Error: err.Error()}}
+		case prev != nil:
+			batch = DiffObservations(*prev, obs, time.Now())
+		case opts.Initial:
+			batch = DiffObservations(Observation{}, obs, time.Now())
+		}
+		if err == nil {
+			prev = &obs
+		}
+		for _, event := range batch {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Watcher shares one WatchVMs poll loop between subscribers, e.g. the clients of
+// an API server. Polling starts with the first subscriber and stops after the last.
+type Watcher struct {
+	manager *Manager
+	opts    WatchOptions
+
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	cancel context.CancelFunc
+}
+
+// NewWatcher returns a watcher polling with opts; opts.Initial is ignored
+func NewWatcher(m *Manager, opts WatchOptions) *Watcher {
+	opts.Initial = false
+	return &Watcher{manager: m, opts: opts, subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel of the events from now on and a function ending the
+// subscription. Events are dropped for a subscriber that falls too far behind.
+func (w *Watcher) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs[ch] = struct{}{}
+	if w.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		w.cancel = cancel
+		go w.run(ctx)
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			delete(w.subs, ch)
+			close(ch)
+			if len(w.subs) == 0 && w.cancel != nil {
+				w.cancel()
+				w.cancel = nil
+			}
+		})
+	}
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	events := make(chan Event)
+	go w.manager.WatchVMs(ctx, w.opts, events)
+	for event := range events {
+		w.mu.Lock()
+		if ctx.Err() != nil {
+			// A later subscriber may have started another loop already
+			w.mu.Unlock()
+			continue
+		}
+		for ch := range w.subs {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+		w.mu.Unlock()
+	}
+}
@@ -0,0 +1,182 @@
+package hyperv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pollRunner answers each poll with the next scripted host state, repeating the last
+type pollRunner struct {
+	mu    sync.Mutex
+	polls []pollState
+	next  int
+}
+
+type pollState struct {
+	vms, heartbeats, snapshots string
+	err                        error
+}
+
+func (r *pollRunner) RunScript(_ context.Context, script string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.polls[min(r.next, len(r.polls)-1)]
+	switch {
+	case strings.Contains(script, "N='Heartbeat'"):
+		return []byte(state.heartbeats), nil
+	case strings.Contains(script, "Get-VMSnapshot"):
+		return []byte(state.snapshots), nil
+	}
+	// Get-VM starts a poll
+	r.next++
+	return []byte(state.vms), state.err
+}
+
+func (r *pollRunner) RunCmdlet(_ context.Context, _ string, _ ...string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestDiffObservations(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	prev := Observation{
+		VMs: []VM{
+			{Name: "Web01", State: "Off"},
+			{Name: "Db01", State: "Running", IPAddresses: []string{"10.0.0.5"}},
+			{Name: "Old", State: "Off"},
+		},
+		Heartbeats: map[string]string{"Db01": "OkApplicationsHealthy"},
+		Snapshots:  map[string][]string{"Db01": {"base"}},
+	}
+	next := Observation{
+		VMs: []VM{
+			{Name: "Web01", State: "Running", IPAddresses: []string{"10.0.0.7"}},
+			{Name: "Db01", State: "Running", IPAddresses: []string{"10.0.0.5"}},
+			{Name: "New", State: "Off"},
+		},
+		Heartbeats: map[string]string{"Db01": "LostCommunication", "Web01": "OkApplicationsUnknown"},
+		Snapshots:  map[string][]string{"Db01": {"base", "before-upgrade"}, "New": {"imported"}},
+	}
+
+	want := []Event{
+		{Type: EventStateChanged, Time: now, VMName: "Web01", State: "Running", PreviousState: "Off"},
+		{Type: EventIPAssigned, Time: now, VMName: "Web01", State: "Running", IPAddress: "10.0.0.7"},
+		{Type: EventHeartbeatLost, Time: now, VMName: "Db01", State: "Running", Heartbeat: "LostCommunication"},
+		{Type: EventSnapshotCreated, Time: now, VMName: "Db01", State: "Running", Snapshot: "before-upgrade"},
+		{Type: EventVMAdded, Time: now, VMName: "New", State: "Off"},
+		{Type: EventVMRemoved, Time: now, VMName: "Old"},
+	}
+	if got := DiffObservations(prev, next, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffObservations() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Without heartbeats and snapshots only VM changes are reported
+	if got := DiffVMs(prev.VMs, next.VMs, now); len(got) != 4 {
+		t.Errorf("DiffVMs() = %+v, want 4 events", got)
+	}
+	if got := DiffVMs(next.VMs, next.VMs, now); len(got) != 0 {
+		t.Errorf("Expected no events for an unchanged host, got %+v", got)
+	}
+}
+
+func TestEventDescribe(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Type: EventStateChanged, VMName: "Web01", PreviousState: "Off", State: "Running"}, "Web01: Off → Running"},
+		{Event{Type: EventIPAssigned, VMName: "Web01", IPAddress: "10.0.0.7"}, "Web01: got IP 10.0.0.7"},
+		{Event{Type: EventSnapshotCreated, VMName: "Db01", Snapshot: "base"}, "Db01: snapshot 'base' created"},
+		{Event{Type: EventPollFailed, Error: "access denied"}, "polling failed: access denied"},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGetHeartbeatsAndSnapshotNames(t *testing.T) {
+	m, _ := newMockManager(`[{"Name":"Web01","Heartbeat":"OkApplicationsHealthy"}]`, nil)
+	heartbeats, err := m.GetHeartbeats(context.Background())
+	if err != nil || heartbeats["Web01"] != "OkApplicationsHealthy" {
+		t.Errorf("GetHeartbeats() = %v, %v", heartbeats, err)
+	}
+
+	m, _ = newMockManager(`[{"VMName":"Web01","Name":"a"},{"VMName":"Web01","Name":"b"}]`, nil)
+	names, err := m.GetSnapshotNames(context.Background())
+	if err != nil || !reflect.DeepEqual(names["Web01"], []string{"a", "b"}) {
+		t.Errorf("GetSnapshotNames() = %v, %v", names, err)
+	}
+
+	m, _ = newMockManager("", nil)
+	if names, err := m.GetSnapshotNames(context.Background()); err != nil || len(names) != 0 {
+		t.Errorf("Expected no snapshots for empty output, got %v, %v", names, err)
+	}
+}
+
+func TestWatchVMs(t *testing.T) {
+	runner := &pollRunner{polls: []pollState{
+		{vms: `{"name":"Web01","state":"Off"}`},
+		{err: errors.New("access denied")},
+		{vms: `{"name":"Web01","state":"Running"}`},
+	}}
+	m := &Manager{Exec: runner}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan Event)
+	go m.WatchVMs(ctx, WatchOptions{Interval: time.Millisecond, Initial: true}, events)
+
+	var types []string
+	for event := range events {
+		types = append(types, event.Type)
+		if event.Type == EventStateChanged {
+			if event.PreviousState != "Off" || event.State != "Running" {
+				t.Errorf("Unexpected state change %+v", event)
+			}
+			cancel()
+		}
+	}
+	want := []string{EventVMAdded, EventPollFailed, EventStateChanged}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("Events = %v, want %v", types, want)
+	}
+}
+
+func TestWatcherSubscribe(t *testing.T) {
+	runner := &pollRunner{polls: []pollState{
+		{vms: `{"name":"Web01","state":"Off"}`},
+		{vms: `{"name":"Web01","state":"Running"}`},
+	}}
+	w := NewWatcher(&Manager{Exec: runner}, WatchOptions{Interval: time.Millisecond, Initial: true})
+
+	first, unsubscribeFirst := w.Subscribe()
+	second, unsubscribeSecond := w.Subscribe()
+	for _, ch := range []<-chan Event{first, second} {
+		select {
+		case event := <-ch:
+			if event.Type != EventStateChanged {
+				t.Errorf("Expected a state change, got %+v", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+
+	unsubscribeFirst()
+	unsubscribeSecond()
+	unsubscribeSecond()
+	if _, ok := <-first; ok {
+		t.Error("Expected the channel to be closed after unsubscribing")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		t.Error("Expected polling to stop after the last subscriber left")
+	}
+}
@@ -1,18 +1,194 @@
 package hyperv
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Workspace represents a group of virtual machines
 type Workspace struct {
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description"`
-	VMs         []string `yaml:"vms"` // List of VM names
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	VMs         []string `yaml:"vms" json:"vms"` // List of VM names
+
+	// Startup holds optional per-VM startup policies keyed by VM name
+	// (dependencies, start delay and readiness check). VMs without an entry start immediately.
+	Startup map[string]StartupPolicy `yaml:"startup,omitempty" json:"startup,omitempty"`
+}
+
+// WorkspaceValidation reports problems found when checking a workspace against the live VM inventory
+type WorkspaceValidation struct {
+	Workspace  string   `json:"workspace"`
+	Valid      bool     `json:"valid"`
+	Missing    []string `json:"missing,omitempty"`    // Members that do not exist in Hyper-V
+	Duplicates []string `json:"duplicates,omitempty"` // Members listed more than once
+	Errors     []string `json:"errors,omitempty"`     // Startup configuration problems
+}
+
+// validateWorkspaceName rejects names that cannot be used safely as a file name
+func validateWorkspaceName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("workspace name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\:*?"<>|`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid workspace name '%s': must not contain any of / \\ : * ? \" < > |", name)
+	}
+	return nil
+}
+
+// workspacePath returns the YAML file path for a workspace name
+func workspacePath(name string) (string, error) {
+	if err := validateWorkspaceName(name); err != nil {
+		return "", err
+	}
+	dir, err := GetWorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// GetWorkspacePath returns the YAML file path of an existing workspace
+func GetWorkspacePath(name string) (string, error) {
+	filename, err := workspacePath(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return "", fmt.Errorf("workspace '%s' does not exist", name)
+	}
+	return filename, nil
+}
+
+// WorkspaceExists reports whether a workspace file with the given name exists
+func WorkspaceExists(name string) bool {
+	_, err := GetWorkspacePath(name)
+	return err == nil
+}
+
+// ParseWorkspace decodes workspace YAML strictly (unknown keys are errors) and validates it
+func ParseWorkspace(data []byte) (*Workspace, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var ws Workspace
+	if err := decoder.Decode(&ws); err != nil {
+		return nil, fmt.Errorf("invalid workspace YAML: %w", err)
+	}
+	if err := ws.Validate(); err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// Validate checks the workspace structure without contacting Hyper-V
+func (ws *Workspace) Validate() error {
+	if err := validateWorkspaceName(ws.Name); err != nil {
+		return err
+	}
+	for _, vm := range ws.VMs {
+		if strings.TrimSpace(vm) == "" {
+			return fmt.Errorf("workspace '%s' contains an empty VM name", ws.Name)
+		}
+	}
+	if _, err := ws.StartupOrder(); err != nil {
+		return fmt.Errorf("workspace '%s': %w", ws.Name, err)
+	}
+	return nil
+}
+
+// HasVM reports whether a VM is a member of the workspace
+func (ws *Workspace) HasVM(name string) bool {
+	for _, vm := range ws.VMs {
+		if vm == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddVMs appends VMs to the workspace, rejecting names that are already members
+func (ws *Workspace) AddVMs(names ...string) error {
+	for _, name := range names {
+		if ws.HasVM(name) {
+			return fmt.Errorf("VM '%s' is already in workspace '%s'", name, ws.Name)
+		}
+		ws.VMs = append(ws.VMs, name)
+	}
+	return nil
+}
+
+// RemoveVMs removes VMs from the workspace along with their startup policies
+// and any dependsOn references to them
+func (ws *Workspace) RemoveVMs(names ...string) error {
+	remove := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !ws.HasVM(name) {
+			return fmt.Errorf("VM '%s' is not in workspace '%s'", name, ws.Name)
+		}
+		remove[name] = true
+	}
+
+	kept := make([]string, 0, len(ws.VMs))
+	for _, vm := range ws.VMs {
+		if !remove[vm] {
+			kept = append(kept, vm)
+		}
+	}
+	ws.VMs = kept
+
+	for vm, policy := range ws.Startup {
+		if remove[vm] {
+			delete(ws.Startup, vm)
+			continue
+		}
+		deps := make([]string, 0, len(policy.DependsOn))
+		for _, dep := range policy.DependsOn {
+			if !remove[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		policy.DependsOn = deps
+		ws.Startup[vm] = policy
+	}
+	return nil
+}
+
+// CheckMembers validates the workspace against the VMs currently registered in Hyper-V
+func (ws *Workspace) CheckMembers(vms []VM) WorkspaceValidation {
+	result := WorkspaceValidation{Workspace: ws.Name}
+
+	existing := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		existing[vm.Name] = true
+	}
+
+	seen := make(map[string]int, len(ws.VMs))
+	for _, name := range ws.VMs {
+		seen[name]++
+		if seen[name] == 2 {
+			result.Duplicates = append(result.Duplicates, name)
+		}
+		if seen[name] == 1 && !existing[name] {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+
+	// Duplicates are already reported above; only surface other startup problems
+	if len(result.Duplicates) == 0 {
+		if _, err := ws.StartupOrder(); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	result.Valid = len(result.Missing) == 0 && len(result.Duplicates) == 0 && len(result.Errors) == 0
+	return result
 }
 
 // GetWorkspaceDir returns the directory where workspace files are stored
@@ -35,12 +211,11 @@ func GetWorkspaceDir() (string, error) {
 
 // SaveWorkspace saves a workspace to a YAML file
 func SaveWorkspace(ws *Workspace) error {
-	dir, err := GetWorkspaceDir()
+	filename, err := workspacePath(ws.Name)
 	if err != nil {
 		return err
 	}
 
-	filename := filepath.Join(dir, ws.Name+".yaml")
 	data, err := yaml.Marshal(ws)
 	if err != nil {
 		return fmt.Errorf("failed to marshal workspace: %w", err)
@@ -55,12 +230,11 @@ func SaveWorkspace(ws *Workspace) error {
 
 // LoadWorkspace loads a workspace by name
 func LoadWorkspace(name string) (*Workspace, error) {
-	dir, err := GetWorkspaceDir()
+	filename, err := workspacePath(name)
 	if err != nil {
 		return nil, err
 	}
 
-	filename := filepath.Join(dir, name+".yaml")
 	//nolint:gosec // G304: Path is constructed from trusted dir and name + literal extension.
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -97,18 +271,141 @@ func ListWorkspaces() ([]string, error) {
 
 // DeleteWorkspace deletes a workspace file
 func DeleteWorkspace(name string) error {
-	dir, err := GetWorkspaceDir()
+	filename, err := GetWorkspacePath(name)
 	if err != nil {
 		return err
 	}
 
-	filename := filepath.Join(dir, name+".yaml")
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return fmt.Errorf("workspace '%s' does not exist", name)
-	}
-
 	if err := os.Remove(filename); err != nil {
 		return fmt.Errorf("failed to delete workspace file: %w", err)
 	}
 	return nil
 }
+
+// RenameWorkspace renames a workspace file and the name stored inside it
+func RenameWorkspace(oldName, newName string) error {
+	if oldName == newName {
+		return fmt.Errorf("new workspace name is the same as the old one")
+	}
+	if WorkspaceExists(newName) {
+		return fmt.Errorf("workspace '%s' already exists", newName)
+	}
+
+	ws, err := LoadWorkspace(oldName)
+	if err != nil {
+		return err
+	}
+	ws.Name = newName
+	if err := SaveWorkspace(ws); err != nil {
+		return err
+	}
+
+	oldFile, err := workspacePath(oldName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(oldFile); err != nil {
+		return fmt.Errorf("failed to remove old workspace file: %w", err)
+	}
+	return nil
+}
+
+// WorkspaceMemberStatus is the live state of one workspace member
+type WorkspaceMemberStatus struct {
+	Name        string   `json:"name"`
+	Index       int      `json:"index,omitempty"`
+	Found       bool     `json:"found"`
+	State       string   `json:"state"`
+	CPUUsage    int      `json:"cpuUsage"`
+	MemoryMB    int64    `json:"memoryMB"`
+	Uptime      string   `json:"uptime"`
+	IPAddresses []string `json:"ipAddresses"`
+}
+
+// WorkspaceStatus aggregates the live state of every workspace member
+type WorkspaceStatus struct {
+	Workspace     string                  `json:"workspace"`
+	Members       []WorkspaceMemberStatus `json:"members"`
+	Total         int                     `json:"total"`
+	Running       int                     `json:"running"`
+	Missing       int                     `json:"missing"`
+	TotalCPUUsage int                     `json:"totalCpuUsage"`       // Sum of member CPU% (relative to host)
+	MemoryMB      int64                   `json:"memoryMB"`            // Memory currently assigned to members
+	HostMemoryMB  int64                   `json:"hostMemoryMB"`        // Total physical memory on the host
+	HostFreeMB    int64                   `json:"hostFreeMB"`          // Free physical memory on the host
+	MemoryPercent float64                 `json:"memoryPercent"`       // Member memory as a percentage of host memory
+	HostError     string                  `json:"hostError,omitempty"` // Why host memory could not be read
+}
+
+// BuildWorkspaceStatus joins workspace members with live VM data and host memory information.
+// host may be nil if host information is unavailable.
+func BuildWorkspaceStatus(ws *Workspace, vms []VM, host *MemoryInfo) *WorkspaceStatus {
+	byName := make(map[string]VM, len(vms))
+	for _, vm := range vms {
+		byName[vm.Name] = vm
+	}
+
+	status := &WorkspaceStatus{
+		Workspace: ws.Name,
+		Members:   make([]WorkspaceMemberStatus, 0, len(ws.VMs)),
+		Total:     len(ws.VMs),
+	}
+
+	for _, name := range ws.VMs {
+		vm, found := byName[name]
+		if !found {
+			status.Missing++
+			status.Members = append(status.Members, WorkspaceMemberStatus{Name: name, State: "Missing"})
+			continue
+		}
+
+		status.Members = append(status.Members, WorkspaceMemberStatus{
+			Name:        name,
+			Index:       vm.Index,
+			Found:       true,
+			State:       vm.State,
+			CPUUsage:    vm.CPUUsage,
+			MemoryMB:    vm.MemoryMB,
+			Uptime:      vm.Uptime,
+			IPAddresses: vm.IPAddresses,
+		})
+		if strings.EqualFold(vm.State, "Running") {
+			status.Running++
+		}
+		status.TotalCPUUsage += vm.CPUUsage
+		status.MemoryMB += vm.MemoryMB
+	}
+
+	if host != nil {
+		status.HostMemoryMB = host.TotalMB
+		status.HostFreeMB = host.FreeMB
+		if host.TotalMB > 0 {
+			status.MemoryPercent = float64(status.MemoryMB) / float64(host.TotalMB) * 100
+		}
+	}
+
+	return status
+}
+
+// GetWorkspaceStatus loads live VM and host memory data and aggregates it for a workspace.
+// A failure to read host information is recorded in the result rather than returned.
+func (m *Manager) GetWorkspaceStatus(ctx context.Context, ws *Workspace) (*WorkspaceStatus, error) {
+	vms, err := m.GetVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostMemory *MemoryInfo
+	var hostErr error
+	if info, err := m.GetSystemInfo(ctx, false); err == nil {
+		hostMemory = &info.Memory
+	} else {
+		hostErr = err
+	}
+
+	status := BuildWorkspaceStatus(ws, vms, hostMemory)
+	if hostErr != nil {
+		status.HostError = hostErr.Error()
+	}
+	return status, nil
+}
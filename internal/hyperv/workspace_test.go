@@ -3,6 +3,7 @@ package hyperv
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -170,3 +171,175 @@ func TestGetWorkspaceDir(t *testing.T) {
 		t.Error("Workspace directory was not created")
 	}
 }
+
+// setTestHome points the user home directory at a fresh temp dir for the duration of a test
+func setTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	return home
+}
+
+func TestWorkspace_AddRemoveVMs(t *testing.T) {
+	ws := &Workspace{
+		Name: "lab",
+		VMs:  []string{"DC01", "SQL01", "APP01"},
+		Startup: map[string]StartupPolicy{
+			"SQL01": {DependsOn: []string{"DC01"}},
+			"APP01": {DependsOn: []string{"SQL01", "DC01"}},
+		},
+	}
+
+	if err := ws.AddVMs("WEB01"); err != nil {
+		t.Fatalf("AddVMs failed: %v", err)
+	}
+	if err := ws.AddVMs("DC01"); err == nil {
+		t.Error("Expected error adding an existing member, got nil")
+	}
+
+	if err := ws.RemoveVMs("SQL01"); err != nil {
+		t.Fatalf("RemoveVMs failed: %v", err)
+	}
+	if ws.HasVM("SQL01") {
+		t.Error("SQL01 should have been removed")
+	}
+	if _, ok := ws.Startup["SQL01"]; ok {
+		t.Error("Startup policy for SQL01 should have been removed")
+	}
+	if deps := ws.Startup["APP01"].DependsOn; len(deps) != 1 || deps[0] != "DC01" {
+		t.Errorf("Expected APP01 to depend only on DC01, got %v", deps)
+	}
+	if err := ws.RemoveVMs("Nope"); err == nil {
+		t.Error("Expected error removing a non-member, got nil")
+	}
+}
+
+func TestWorkspace_CheckMembers(t *testing.T) {
+	live := []VM{{Name: "DC01"}, {Name: "SQL01"}}
+
+	tests := []struct {
+		name           string
+		ws             *Workspace
+		wantValid      bool
+		wantMissing    []string
+		wantDuplicates []string
+		wantErrors     int
+	}{
+		{"Valid", &Workspace{Name: "a", VMs: []string{"DC01", "SQL01"}}, true, nil, nil, 0},
+		{"Missing", &Workspace{Name: "a", VMs: []string{"DC01", "Ghost"}}, false, []string{"Ghost"}, nil, 0},
+		{"Duplicate", &Workspace{Name: "a", VMs: []string{"DC01", "DC01", "DC01"}}, false, nil, []string{"DC01"}, 0},
+		{"Cycle", &Workspace{Name: "a", VMs: []string{"DC01", "SQL01"}, Startup: map[string]StartupPolicy{
+			"DC01":  {DependsOn: []string{"SQL01"}},
+			"SQL01": {DependsOn: []string{"DC01"}},
+		}}, false, nil, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ws.CheckMembers(live)
+			if got.Valid != tt.wantValid {
+				t.Errorf("Valid = %v, want %v (%+v)", got.Valid, tt.wantValid, got)
+			}
+			if !reflect.DeepEqual(got.Missing, tt.wantMissing) {
+				t.Errorf("Missing = %v, want %v", got.Missing, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(got.Duplicates, tt.wantDuplicates) {
+				t.Errorf("Duplicates = %v, want %v", got.Duplicates, tt.wantDuplicates)
+			}
+			if len(got.Errors) != tt.wantErrors {
+				t.Errorf("Errors = %v, want %d", got.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestParseWorkspace(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"Valid", "name: dev\nvms: [A, B]\n", false},
+		{"Unknown key", "name: dev\nvm: [A]\n", true},
+		{"Missing name", "vms: [A]\n", true},
+		{"Path in name", "name: ../evil\nvms: [A]\n", true},
+		{"Bad dependency", "name: dev\nvms: [A]\nstartup:\n  A:\n    dependsOn: [B]\n", true},
+		{"Malformed", "name: [", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWorkspace([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenameWorkspace(t *testing.T) {
+	setTestHome(t)
+
+	if err := SaveWorkspace(&Workspace{Name: "old", VMs: []string{"A"}}); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+	if err := SaveWorkspace(&Workspace{Name: "taken", VMs: []string{"B"}}); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+
+	if err := RenameWorkspace("old", "taken"); err == nil {
+		t.Error("Expected error renaming onto an existing workspace, got nil")
+	}
+	if err := RenameWorkspace("old", "new"); err != nil {
+		t.Fatalf("RenameWorkspace failed: %v", err)
+	}
+
+	if WorkspaceExists("old") {
+		t.Error("Old workspace file should be gone")
+	}
+	ws, err := LoadWorkspace("new")
+	if err != nil {
+		t.Fatalf("LoadWorkspace failed: %v", err)
+	}
+	if ws.Name != "new" || len(ws.VMs) != 1 {
+		t.Errorf("Unexpected renamed workspace: %+v", ws)
+	}
+}
+
+func TestSaveWorkspace_InvalidName(t *testing.T) {
+	setTestHome(t)
+
+	if err := SaveWorkspace(&Workspace{Name: "..\\escape"}); err == nil {
+		t.Error("Expected error for workspace name with path separators, got nil")
+	}
+}
+
+func TestBuildWorkspaceStatus(t *testing.T) {
+	ws := &Workspace{Name: "lab", VMs: []string{"DC01", "SQL01", "Ghost"}}
+	vms := []VM{
+		{Index: 1, Name: "DC01", State: "Running", CPUUsage: 5, MemoryMB: 2048, IPAddresses: []string{"10.0.0.10"}},
+		{Index: 2, Name: "SQL01", State: "Off"},
+		{Index: 3, Name: "Other", State: "Running", MemoryMB: 8192},
+	}
+
+	status := BuildWorkspaceStatus(ws, vms, &MemoryInfo{TotalMB: 16384, FreeMB: 4096})
+
+	if status.Total != 3 || status.Running != 1 || status.Missing != 1 {
+		t.Errorf("Unexpected counts: total=%d running=%d missing=%d", status.Total, status.Running, status.Missing)
+	}
+	if status.MemoryMB != 2048 || status.TotalCPUUsage != 5 {
+		t.Errorf("Non-member VMs should not count towards totals: memory=%d cpu=%d", status.MemoryMB, status.TotalCPUUsage)
+	}
+	if status.MemoryPercent != 12.5 || status.HostFreeMB != 4096 {
+		t.Errorf("Unexpected host memory figures: %+v", status)
+	}
+	if len(status.Members) != 3 || status.Members[0].Index != 1 || status.Members[2].State != "Missing" || status.Members[2].Found {
+		t.Errorf("Unexpected members: %+v", status.Members)
+	}
+
+	noHost := BuildWorkspaceStatus(ws, vms, nil)
+	if noHost.HostMemoryMB != 0 || noHost.MemoryPercent != 0 {
+		t.Errorf("Expected no host figures without host info, got %+v", noHost)
+	}
+}
@@ -0,0 +1,385 @@
+// Package iso writes small ISO 9660 images with Joliet extensions, such as cloud-init
+// NoCloud seeds and Windows answer file media. Images hold a flat root directory only.
+package iso
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// SectorSize is the logical block size of the images written by this package
+const SectorSize = 2048
+
+const (
+	systemAreaSectors = 16
+	maxVolumeIDLength = 32 // Primary descriptor; Joliet allows 16 UCS-2 characters
+	maxJolietName     = 64 // Joliet name limit in characters
+)
+
+// File is a file in the image's root directory
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Image describes an image to write
+type Image struct {
+	VolumeID string    // Volume label, e.g. "cidata"
+	ModTime  time.Time // Recorded for the volume and every file; defaults to now
+	Files    []File
+}
+
+// entry is a file laid out in the image
+type entry struct {
+	file    File
+	primary string // ISO 9660 identifier, e.g. "USER_DAT.A;1"
+	joliet  []byte // UCS-2 big-endian identifier
+	extent  uint32
+}
+
+// layout holds the sector positions of every structure in the image
+type layout struct {
+	primaryPathL, primaryPathM uint32
+	jolietPathL, jolietPathM   uint32
+	primaryRoot, jolietRoot    uint32
+	primaryRootSize            uint32
+	jolietRootSize             uint32
+	totalSectors               uint32
+}
+
+// WriteFile writes img to path
+func WriteFile(path string, img *Image) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, img); err != nil {
+		return err
+	}
+	// gosec G306: Expect WriteFile permissions to be 0600 or less
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write ISO image: %w", err)
+	}
+	return nil
+}
+
+// Write writes img to w as an ISO 9660 image with a Joliet supplementary volume
+// descriptor, so both Windows and Linux see the original file names
+func Write(w io.Writer, img *Image) error {
+	if img.VolumeID == "" || len(img.VolumeID) > maxVolumeIDLength {
+		return fmt.Errorf("volume ID must be 1-%d characters", maxVolumeIDLength)
+	}
+	modTime := img.ModTime
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+
+	entries, err := newEntries(img.Files)
+	if err != nil {
+		return err
+	}
+	l := computeLayout(entries)
+
+	out := &sectorWriter{w: w}
+	out.zeros(systemAreaSectors * SectorSize)
+	out.write(volumeDescriptor(1, img.VolumeID, l, modTime))
+	out.write(volumeDescriptor(2, img.VolumeID, l, modTime))
+	out.write(terminator())
+	out.write(pathTable(l.primaryRoot, binary.LittleEndian))
+	out.write(pathTable(l.primaryRoot, binary.BigEndian))
+	out.write(pathTable(l.jolietRoot, binary.LittleEndian))
+	out.write(pathTable(l.jolietRoot, binary.BigEndian))
+	out.write(directory(entries, l.primaryRoot, l.primaryRootSize, modTime, false))
+	out.write(directory(entries, l.jolietRoot, l.jolietRootSize, modTime, true))
+	for _, e := range entries {
+		out.write(e.file.Data)
+	}
+	if out.err != nil {
+		return fmt.Errorf("failed to write ISO image: %w", out.err)
+	}
+	return nil
+}
+
+// newEntries validates the files and derives their identifiers
+func newEntries(files []File) ([]*entry, error) {
+	entries := make([]*entry, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		if f.Name == "" || strings.ContainsAny(f.Name, `/\;`) || len([]rune(f.Name)) > maxJolietName {
+			return nil, fmt.Errorf("invalid file name '%s'", f.Name)
+		}
+		primary := primaryIdentifier(f.Name)
+		if seen[primary] {
+			return nil, fmt.Errorf("file names '%s' clash in the ISO 9660 directory", f.Name)
+		}
+		seen[primary] = true
+		entries = append(entries, &entry{file: f, primary: primary, joliet: ucs2(f.Name)})
+	}
+	return entries, nil
+}
+
+// primaryIdentifier maps a name to ISO 9660 d-characters: uppercase letters, digits and '_',
+// with at most one '.' separating the extension, followed by the version ";1"
+func primaryIdentifier(name string) string {
+	base, ext := name, ""
+	if i := strings.LastIndex(name, "."); i > 0 {
+		base, ext = name[:i], name[i+1:]
+	}
+	clean := func(s string, max int) string {
+		var b strings.Builder
+		for _, r := range strings.ToUpper(s) {
+			if b.Len() == max {
+				break
+			}
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+		return b.String()
+	}
+	return clean(base, 8) + "." + clean(ext, 3) + ";1"
+}
+
+// ucs2 encodes s as UCS-2 big-endian, as Joliet requires
+func ucs2(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(out[2*i:], u)
+	}
+	return out
+}
+
+// computeLayout assigns sectors: descriptors, path tables, both root directories, then file data
+func computeLayout(entries []*entry) layout {
+	l := layout{
+		primaryPathL: systemAreaSectors + 3,
+		primaryPathM: systemAreaSectors + 4,
+		jolietPathL:  systemAreaSectors + 5,
+		jolietPathM:  systemAreaSectors + 6,
+	}
+	l.primaryRoot = systemAreaSectors + 7
+	l.primaryRootSize = directorySize(entries, false)
+	l.jolietRoot = l.primaryRoot + sectors(int64(l.primaryRootSize))
+	l.jolietRootSize = directorySize(entries, true)
+
+	next := l.jolietRoot + sectors(int64(l.jolietRootSize))
+	for _, e := range entries {
+		e.extent = next
+		next += sectors(int64(len(e.file.Data)))
+	}
+	l.totalSectors = next
+	return l
+}
+
+// sectors returns the number of sectors needed for n bytes
+func sectors(n int64) uint32 {
+	return uint32((n + SectorSize - 1) / SectorSize)
+}
+
+// directorySize returns the size of a root directory in bytes, rounded to whole sectors,
+// as directory records must not cross sector boundaries
+func directorySize(entries []*entry, joliet bool) uint32 {
+	used := recordLength(1) * 2 // "." and ".."
+	total := uint32(0)
+	for _, e := range entries {
+		n := recordLength(len(e.primary))
+		if joliet {
+			n = recordLength(len(e.joliet))
+		}
+		if used+n > SectorSize {
+			total += SectorSize
+			used = 0
+		}
+		used += n
+	}
+	return total + SectorSize
+}
+
+// recordLength returns the length of a directory record with an identifier of n bytes
+func recordLength(n int) int {
+	length := 33 + n
+	if length%2 != 0 {
+		length++
+	}
+	return length
+}
+
+// directory builds a root directory: ".", ".." and the files sorted by identifier
+func directory(entries []*entry, extent, size uint32, modTime time.Time, joliet bool) []byte {
+	sorted := append([]*entry(nil), entries...)
+	ident := func(e *entry) []byte {
+		if joliet {
+			return e.joliet
+		}
+		return []byte(e.primary)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(ident(sorted[i]), ident(sorted[j])) < 0 })
+
+	buf := make([]byte, size)
+	pos := 0
+	put := func(record []byte) {
+		if pos%SectorSize+len(record) > SectorSize {
+			pos += SectorSize - pos%SectorSize
+		}
+		copy(buf[pos:], record)
+		pos += len(record)
+	}
+	put(dirRecord([]byte{0}, extent, size, modTime, true))
+	put(dirRecord([]byte{1}, extent, size, modTime, true))
+	for _, e := range sorted {
+		put(dirRecord(ident(e), e.extent, uint32(len(e.file.Data)), modTime, false))
+	}
+	return buf
+}
+
+// dirRecord encodes a directory record (ECMA-119 9.1)
+func dirRecord(ident []byte, extent, size uint32, modTime time.Time, isDir bool) []byte {
+	record := make([]byte, recordLength(len(ident)))
+	record[0] = byte(len(record))
+	putBoth32(record[2:], extent)
+	putBoth32(record[10:], size)
+	copy(record[18:25], recordingTime(modTime))
+	if isDir {
+		record[25] = 2
+	}
+	putBoth16(record[28:], 1)
+	record[32] = byte(len(ident))
+	copy(record[33:], ident)
+	return record
+}
+
+// volumeDescriptor encodes the primary (type 1) or Joliet supplementary (type 2) descriptor
+func volumeDescriptor(kind byte, volumeID string, l layout, modTime time.Time) []byte {
+	d := make([]byte, SectorSize)
+	d[0] = kind
+	copy(d[1:6], "CD001")
+	d[6] = 1
+
+	root, rootSize, pathL, pathM := l.primaryRoot, l.primaryRootSize, l.primaryPathL, l.primaryPathM
+	text := func(field []byte, s string) { padASCII(field, strings.ToUpper(s)) }
+	if kind == 2 {
+		root, rootSize, pathL, pathM = l.jolietRoot, l.jolietRootSize, l.jolietPathL, l.jolietPathM
+		text = padUCS2
+		copy(d[88:91], "%/E") // UCS-2 level 3
+	}
+
+	text(d[8:40], "")
+	text(d[40:72], volumeID)
+	putBoth32(d[80:], l.totalSectors)
+	putBoth16(d[120:], 1)
+	putBoth16(d[124:], 1)
+	putBoth16(d[128:], SectorSize)
+	putBoth32(d[132:], pathTableSize)
+	binary.LittleEndian.PutUint32(d[140:], pathL)
+	binary.BigEndian.PutUint32(d[148:], pathM)
+	copy(d[156:190], dirRecord([]byte{0}, root, rootSize, modTime, true))
+	text(d[190:318], "")
+	text(d[318:446], "")
+	text(d[446:574], "")
+	text(d[574:702], "QUICKVM")
+	text(d[702:739], "")
+	text(d[739:776], "")
+	text(d[776:813], "")
+	copy(d[813:830], volumeTime(modTime))
+	copy(d[830:847], volumeTime(modTime))
+	copy(d[847:864], volumeTime(time.Time{}))
+	copy(d[864:881], volumeTime(time.Time{}))
+	d[881] = 1
+	return d
+}
+
+func terminator() []byte {
+	d := make([]byte, SectorSize)
+	d[0] = 255
+	copy(d[1:6], "CD001")
+	d[6] = 1
+	return d
+}
+
+// pathTableSize is the size of a path table holding only the root directory
+const pathTableSize = 10
+
+// pathTable encodes a path table with a single root entry
+func pathTable(root uint32, order binary.ByteOrder) []byte {
+	t := make([]byte, pathTableSize)
+	t[0] = 1 // Identifier length; the root's identifier is a single 0 byte
+	order.PutUint32(t[2:], root)
+	order.PutUint16(t[6:], 1)
+	return t
+}
+
+// recordingTime encodes a directory record date (ECMA-119 9.1.5) in UTC
+func recordingTime(t time.Time) []byte {
+	t = t.UTC()
+	return []byte{byte(t.Year() - 1900), byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second()), 0}
+}
+
+// volumeTime encodes a volume descriptor date (ECMA-119 8.4.26.1); the zero time means "not specified"
+func volumeTime(t time.Time) []byte {
+	if t.IsZero() {
+		return append([]byte("0000000000000000"), 0)
+	}
+	t = t.UTC()
+	s := fmt.Sprintf("%04d%02d%02d%02d%02d%02d%02d", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1e7)
+	return append([]byte(s), 0)
+}
+
+func padASCII(field []byte, s string) {
+	for i := range field {
+		field[i] = ' '
+	}
+	copy(field, s)
+}
+
+func padUCS2(field []byte, s string) {
+	for i := 0; i+1 < len(field); i += 2 {
+		field[i], field[i+1] = 0, ' '
+	}
+	encoded := ucs2(s)
+	if len(encoded) > len(field)&^1 {
+		encoded = encoded[:len(field)&^1]
+	}
+	copy(field, encoded)
+}
+
+// putBoth32 writes v in both-endian format (little-endian followed by big-endian)
+func putBoth32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint32(b, v)
+	binary.BigEndian.PutUint32(b[4:], v)
+}
+
+func putBoth16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+	binary.BigEndian.PutUint16(b[2:], v)
+}
+
+// sectorWriter writes data padded to whole sectors and remembers the first error
+type sectorWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (s *sectorWriter) write(data []byte) {
+	if s.err != nil {
+		return
+	}
+	if _, s.err = s.w.Write(data); s.err != nil {
+		return
+	}
+	if pad := len(data) % SectorSize; pad != 0 {
+		s.zeros(SectorSize - pad)
+	}
+}
+
+func (s *sectorWriter) zeros(n int) {
+	if s.err == nil {
+		_, s.err = s.w.Write(make([]byte, n))
+	}
+}
@@ -0,0 +1,149 @@
+package iso
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// readRoot parses the root directory of the primary (kind 1) or Joliet (kind 2) volume
+// descriptor and returns the volume ID and the files by name
+func readRoot(t *testing.T, image []byte, kind byte) (string, map[string][]byte) {
+	t.Helper()
+	for sector := systemAreaSectors; ; sector++ {
+		d := image[sector*SectorSize : (sector+1)*SectorSize]
+		if string(d[1:6]) != "CD001" {
+			t.Fatalf("Sector %d is not a volume descriptor", sector)
+		}
+		if d[0] == 255 {
+			t.Fatalf("Volume descriptor type %d not found", kind)
+		}
+		if d[0] != kind {
+			continue
+		}
+
+		volumeID := strings.TrimRight(string(d[40:72]), " ")
+		if kind == 2 {
+			volumeID = decodeUCS2(bytes.TrimRight(d[40:72], "\x00 "))
+		}
+		root := d[156:190]
+		extent := binary.LittleEndian.Uint32(root[2:])
+		size := binary.LittleEndian.Uint32(root[10:])
+		if binary.BigEndian.Uint32(root[6:]) != extent {
+			t.Error("Both-endian extent fields disagree")
+		}
+
+		files := make(map[string][]byte)
+		dir := image[extent*SectorSize : extent*SectorSize+size]
+		for pos := 0; pos < len(dir); {
+			length := int(dir[pos])
+			if length == 0 { // Rest of the sector is padding
+				pos += SectorSize - pos%SectorSize
+				continue
+			}
+			record := dir[pos : pos+length]
+			pos += length
+			if record[25]&2 != 0 {
+				continue // "." and ".."
+			}
+			name := string(record[33 : 33+int(record[32])])
+			if kind == 2 {
+				name = decodeUCS2(record[33 : 33+int(record[32])])
+			}
+			start := binary.LittleEndian.Uint32(record[2:]) * SectorSize
+			files[name] = image[start : start+binary.LittleEndian.Uint32(record[10:])]
+		}
+		return volumeID, files
+	}
+}
+
+func decodeUCS2(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestWrite_RoundTripsPrimaryAndJoliet(t *testing.T) {
+	bigFile := bytes.Repeat([]byte("x"), 3*SectorSize+17)
+	img := &Image{
+		VolumeID: "cidata",
+		ModTime:  time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Files: []File{
+			{Name: "user-data", Data: []byte("#cloud-config\nhostname: web01\n")},
+			{Name: "meta-data", Data: []byte("instance-id: abc\n")},
+			{Name: "network-config", Data: bigFile},
+			{Name: "empty", Data: nil},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, img); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	image := buf.Bytes()
+	if len(image)%SectorSize != 0 {
+		t.Fatalf("Image size %d is not a whole number of sectors", len(image))
+	}
+	if got := binary.LittleEndian.Uint32(image[16*SectorSize+80:]); int(got) != len(image)/SectorSize {
+		t.Errorf("Volume space size %d does not match image size %d", got, len(image)/SectorSize)
+	}
+
+	label, files := readRoot(t, image, 2)
+	if label != "cidata" {
+		t.Errorf("Joliet volume ID = %q, want cidata", label)
+	}
+	if string(files["user-data"]) != string(img.Files[0].Data) || !bytes.Equal(files["network-config"], bigFile) {
+		t.Errorf("Joliet files do not round-trip: %v", files)
+	}
+	if _, ok := files["empty"]; !ok {
+		t.Error("Expected empty file to be listed")
+	}
+
+	label, files = readRoot(t, image, 1)
+	if label != "CIDATA" {
+		t.Errorf("Primary volume ID = %q, want CIDATA", label)
+	}
+	if string(files["META_DAT.;1"]) != "instance-id: abc\n" {
+		t.Errorf("Primary directory does not hold META_DAT.;1: %v", files)
+	}
+}
+
+func TestWrite_LargeDirectorySpansSectors(t *testing.T) {
+	var files []File
+	for i := 0; i < 80; i++ {
+		files = append(files, File{Name: strings.Repeat("f", 30) + string(rune('A'+i%26)) + string(rune('a'+i/26)), Data: []byte{byte(i)}})
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, &Image{VolumeID: "many", Files: files}); err == nil {
+		// Primary identifiers are truncated to 8.3, so these clash
+		t.Fatal("Expected clashing 8.3 names to be rejected")
+	}
+
+	files = files[:0]
+	for i := 0; i < 80; i++ {
+		files = append(files, File{Name: strings.Repeat("f", 40) + "." + string(rune('A'+i%26)) + string(rune('a'+i/26)), Data: []byte{byte(i)}})
+	}
+	buf.Reset()
+	if err := Write(&buf, &Image{VolumeID: "many", Files: files}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	_, got := readRoot(t, buf.Bytes(), 2)
+	if len(got) != 80 {
+		t.Errorf("Expected 80 Joliet entries across several sectors, got %d", len(got))
+	}
+}
+
+func TestWrite_RejectsInvalidInput(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, &Image{VolumeID: ""}); err == nil {
+		t.Error("Expected empty volume ID to be rejected")
+	}
+	if err := Write(&buf, &Image{VolumeID: "x", Files: []File{{Name: "dir/file"}}}); err == nil {
+		t.Error("Expected subdirectories to be rejected")
+	}
+}
@@ -0,0 +1,324 @@
+package lab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/iso"
+)
+
+// Host is the Hyper-V functionality a lab is built from
+type Host interface {
+	VMExists(ctx context.Context, name string) (bool, error)
+	EnsureSwitch(ctx context.Context, name, switchType, netAdapter string) (bool, error)
+	RemoveSwitch(ctx context.Context, name string) error
+	SwitchVMs(ctx context.Context, name string) ([]string, error)
+	CreateVMFromTemplate(ctx context.Context, opts hyperv.TemplateCreateVMOptions, events chan<- hyperv.ProgressEvent) (string, error)
+	CloneVMWithProgress(ctx context.Context, sourceName, newName string, custom *hyperv.CloneCustomization, events chan<- hyperv.ProgressEvent) error
+	CustomizeVM(ctx context.Context, vmName string, c *hyperv.CloneCustomization) error
+	AttachProvisioningISO(ctx context.Context, vmName string, img *iso.Image) (string, error)
+	DetachProvisioningISO(ctx context.Context, vmName string) (bool, error)
+	AddGPUPartition(ctx context.Context, vmName string, config *hyperv.GPUPartitionConfig) error
+	DeleteVMAndDisks(ctx context.Context, name string) error
+}
+
+// Starter starts a workspace's VMs in dependency order
+type Starter interface {
+	Start(ctx context.Context, ws *hyperv.Workspace) ([]hyperv.WorkspaceVMResult, error)
+}
+
+// Lab build steps reported through Lab.OnEvent
+const (
+	StepNetwork   = "network"
+	StepExists    = "exists"
+	StepCreate    = "create"
+	StepClone     = "clone"
+	StepConfigure = "configure"
+	StepProvision = "provision"
+	StepGPU       = "gpu"
+	StepWorkspace = "workspace"
+	StepDetach    = "detach"
+	StepDelete    = "delete"
+	StepKeep      = "keep"
+)
+
+// Event reports progress for one lab network or VM
+type Event struct {
+	Target  string `json:"target"`
+	Step    string `json:"step"`
+	Message string `json:"message,omitempty"`
+}
+
+// UpResult is the outcome of building and starting a lab
+type UpResult struct {
+	Lab             string                     `json:"lab"`
+	CreatedNetworks []string                   `json:"createdNetworks,omitempty"`
+	CreatedVMs      []string                   `json:"createdVms,omitempty"`
+	ExistingVMs     []string                   `json:"existingVms,omitempty"`
+	Provisioned     []string                   `json:"provisioned,omitempty"`
+	Start           []hyperv.WorkspaceVMResult `json:"start,omitempty"`
+}
+
+// DownPlan lists what tearing a lab down removes
+type DownPlan struct {
+	Lab           string   `json:"lab"`
+	VMs           []string `json:"vms"`                     // Existing VMs created by the lab, deleted with their disks
+	Networks      []string `json:"networks,omitempty"`      // Switches created by the lab
+	Workspace     string   `json:"workspace,omitempty"`     // Lab workspace, if the lab saved it
+	KeptWorkspace string   `json:"keptWorkspace,omitempty"` // Workspace of the lab's name the lab did not save
+	KeptVMs       []string `json:"keptVms,omitempty"`       // Existing manifest VMs the lab did not create
+	KeptNetworks  []string `json:"keptNetworks,omitempty"`  // Manifest switches the lab did not create
+}
+
+// Lab builds and tears down labs on a host
+type Lab struct {
+	Host    Host
+	Starter Starter
+	OnEvent func(Event) // Optional progress callback
+
+	// ReplaceWorkspace lets Up overwrite a workspace of the lab's name that the lab did not save
+	ReplaceWorkspace bool
+}
+
+// New creates a lab builder backed by a Hyper-V manager
+func New(m *hyperv.Manager) *Lab {
+	return &Lab{Host: m, Starter: hyperv.NewWorkspaceOrchestrator(m)}
+}
+
+func (l *Lab) emit(target, step, message string) {
+	if l.OnEvent != nil {
+		l.OnEvent(Event{Target: target, Step: step, Message: message})
+	}
+}
+
+// Up creates the lab's networks and missing VMs, saves its workspace and, if start is set,
+// starts the VMs in dependency order. VMs that already exist are left as they are, so Up
+// can be re-run after a failure. Provisioning media is detached from VMs that became ready.
+// What Up creates is recorded in the lab's State for Down. Up fails before creating anything
+// if a workspace of the lab's name exists that the lab did not save, unless ReplaceWorkspace is set.
+func (l *Lab) Up(ctx context.Context, m *Manifest, start bool) (*UpResult, error) {
+	result := &UpResult{Lab: m.Name}
+	state, err := LoadState(m.Name)
+	if err != nil {
+		return result, err
+	}
+	if !state.Workspace && !l.ReplaceWorkspace && hyperv.WorkspaceExists(m.Name) {
+		return result, fmt.Errorf("workspace '%s' already exists and was not saved by the lab (use --replace-workspace to overwrite)", m.Name)
+	}
+
+	for _, n := range m.Networks {
+		created, err := l.Host.EnsureSwitch(ctx, n.Name, n.Type, n.Adapter)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.CreatedNetworks = append(result.CreatedNetworks, n.Name)
+			l.emit(n.Name, StepNetwork, n.Type)
+			state.Networks = append(state.Networks, n.Name)
+			if err := state.Save(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	for i := range m.VMs {
+		vm := &m.VMs[i]
+		exists, err := l.Host.VMExists(ctx, vm.Name)
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.ExistingVMs = append(result.ExistingVMs, vm.Name)
+			l.emit(vm.Name, StepExists, "")
+			continue
+		}
+		// Recorded before the build, so Down also removes a VM whose build failed halfway
+		if !state.HasVM(vm.Name) {
+			state.VMs = append(state.VMs, vm.Name)
+			if err := state.Save(); err != nil {
+				return result, err
+			}
+		}
+		if err := l.buildVM(ctx, vm); err != nil {
+			return result, fmt.Errorf("failed to build VM '%s': %w", vm.Name, err)
+		}
+		result.CreatedVMs = append(result.CreatedVMs, vm.Name)
+		if vm.provision != nil {
+			result.Provisioned = append(result.Provisioned, vm.Name)
+		}
+	}
+
+	ws := m.Workspace()
+	if err := hyperv.SaveWorkspace(ws); err != nil {
+		return result, err
+	}
+	l.emit(ws.Name, StepWorkspace, "")
+	if !state.Workspace {
+		state.Workspace = true
+		if err := state.Save(); err != nil {
+			return result, err
+		}
+	}
+	if !start {
+		return result, nil
+	}
+
+	results, err := l.Starter.Start(ctx, ws)
+	result.Start = results
+	if err != nil {
+		return result, err
+	}
+	return result, l.detachProvisioning(ctx, result)
+}
+
+// buildVM creates one VM and applies its settings, media and GPU partition
+func (l *Lab) buildVM(ctx context.Context, vm *VM) error {
+	custom := vm.customization()
+	if vm.Template != "" {
+		l.emit(vm.Name, StepCreate, vm.Template)
+		opts := hyperv.TemplateCreateVMOptions{Template: vm.Template, VMName: vm.Name, Linked: vm.Linked, VHDPath: vm.VHDPath}
+		if _, err := l.Host.CreateVMFromTemplate(ctx, opts, nil); err != nil {
+			return err
+		}
+		if !custom.IsEmpty() {
+			l.emit(vm.Name, StepConfigure, "")
+			if err := l.Host.CustomizeVM(ctx, vm.Name, custom); err != nil {
+				return err
+			}
+		}
+	} else {
+		l.emit(vm.Name, StepClone, vm.Clone)
+		if err := l.Host.CloneVMWithProgress(ctx, vm.Clone, vm.Name, custom, nil); err != nil {
+			return err
+		}
+	}
+
+	if vm.provision != nil {
+		img, err := vm.provision.Seed()
+		if err != nil {
+			return err
+		}
+		l.emit(vm.Name, StepProvision, img.VolumeID)
+		if _, err := l.Host.AttachProvisioningISO(ctx, vm.Name, img); err != nil {
+			return err
+		}
+	}
+	if vm.GPU {
+		l.emit(vm.Name, StepGPU, "")
+		if err := l.Host.AddGPUPartition(ctx, vm.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detachProvisioning removes the provisioning media from VMs created in this run that are ready
+func (l *Lab) detachProvisioning(ctx context.Context, result *UpResult) error {
+	ready := make(map[string]bool, len(result.Start))
+	for _, r := range result.Start {
+		ready[r.Name] = r.Success
+	}
+
+	var errs []error
+	for _, name := range result.Provisioned {
+		if !ready[name] {
+			continue
+		}
+		l.emit(name, StepDetach, "")
+		if _, err := l.Host.DetachProvisioningISO(ctx, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PlanDown lists the existing VMs and the networks the lab created, and its workspace.
+// VMs, switches and a workspace that existed before the lab are listed as kept.
+func (l *Lab) PlanDown(ctx context.Context, m *Manifest) (*DownPlan, error) {
+	state, err := LoadState(m.Name)
+	if err != nil {
+		return nil, err
+	}
+	plan := &DownPlan{Lab: m.Name}
+	for _, vm := range m.VMs {
+		exists, err := l.Host.VMExists(ctx, vm.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case exists && state.HasVM(vm.Name):
+			plan.VMs = append(plan.VMs, vm.Name)
+		case exists:
+			plan.KeptVMs = append(plan.KeptVMs, vm.Name)
+		}
+	}
+	for _, n := range m.Networks {
+		if state.HasNetwork(n.Name) {
+			plan.Networks = append(plan.Networks, n.Name)
+		} else {
+			plan.KeptNetworks = append(plan.KeptNetworks, n.Name)
+		}
+	}
+	if hyperv.WorkspaceExists(m.Name) {
+		if state.Workspace {
+			plan.Workspace = m.Name
+		} else {
+			plan.KeptWorkspace = m.Name
+		}
+	}
+	return plan, nil
+}
+
+// Down deletes the planned VMs with their disks in reverse manifest order, then the lab's
+// networks and workspace. A switch that VMs outside the lab are still connected to is kept.
+// It continues past failures and reports them together; what was removed is dropped from
+// the lab's State, so Down can be re-run.
+func (l *Lab) Down(ctx context.Context, plan *DownPlan) error {
+	state, err := LoadState(plan.Lab)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := len(plan.VMs) - 1; i >= 0; i-- {
+		l.emit(plan.VMs[i], StepDelete, "")
+		if err := l.Host.DeleteVMAndDisks(ctx, plan.VMs[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		state.VMs = slices.DeleteFunc(state.VMs, func(name string) bool { return name == plan.VMs[i] })
+	}
+	for _, name := range plan.Networks {
+		users, err := l.Host.SwitchVMs(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(users) > 0 {
+			l.emit(name, StepKeep, strings.Join(users, ", "))
+			continue
+		}
+		l.emit(name, StepDelete, "")
+		if err := l.Host.RemoveSwitch(ctx, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		state.Networks = slices.DeleteFunc(state.Networks, func(n string) bool { return n == name })
+	}
+	if plan.Workspace != "" {
+		l.emit(plan.Workspace, StepDelete, "")
+		if err := hyperv.DeleteWorkspace(plan.Workspace); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.Workspace = false
+		}
+	}
+	if err := state.Save(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
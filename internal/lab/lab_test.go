@@ -0,0 +1,348 @@
+package lab
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/iso"
+)
+
+const trainingLab = `
+name: training
+networks:
+  - name: LabNet
+    type: internal
+vms:
+  - name: DC01
+    template: win2022
+    linked: true
+    switch: LabNet
+    memoryMB: 4096
+    provision: dc01.yaml
+  - name: WS01
+    clone: Win11-Gold
+    switch: LabNet
+    gpu: true
+    dependsOn: [DC01]
+    readiness: {type: heartbeat}
+`
+
+// fakeHost records the calls a lab makes
+type fakeHost struct {
+	existing  map[string]bool
+	calls     []string
+	failOn    string
+	switchVMs map[string][]string // VMs connected to each switch
+}
+
+func (h *fakeHost) record(call string) error {
+	h.calls = append(h.calls, call)
+	if h.failOn != "" && strings.HasPrefix(call, h.failOn) {
+		return fmt.Errorf("%s failed", call)
+	}
+	return nil
+}
+
+func (h *fakeHost) VMExists(_ context.Context, name string) (bool, error) {
+	return h.existing[name], nil
+}
+
+func (h *fakeHost) EnsureSwitch(_ context.Context, name, switchType, _ string) (bool, error) {
+	return !h.existing[name], h.record("switch " + name + " " + switchType)
+}
+
+func (h *fakeHost) RemoveSwitch(_ context.Context, name string) error {
+	return h.record("remove-switch " + name)
+}
+
+func (h *fakeHost) SwitchVMs(_ context.Context, name string) ([]string, error) {
+	return h.switchVMs[name], nil
+}
+
+func (h *fakeHost) CreateVMFromTemplate(_ context.Context, opts hyperv.TemplateCreateVMOptions, _ chan<- hyperv.ProgressEvent) (string, error) {
+	return opts.VMName, h.record(fmt.Sprintf("create %s %s linked=%v", opts.VMName, opts.Template, opts.Linked))
+}
+
+func (h *fakeHost) CloneVMWithProgress(_ context.Context, source, name string, custom *hyperv.CloneCustomization, _ chan<- hyperv.ProgressEvent) error {
+	return h.record(fmt.Sprintf("clone %s %s mac=%v switch=%s", name, source, custom.RegenerateMAC, custom.SwitchName))
+}
+
+func (h *fakeHost) CustomizeVM(_ context.Context, name string, c *hyperv.CloneCustomization) error {
+	return h.record(fmt.Sprintf("customize %s switch=%s memory=%d", name, c.SwitchName, c.MemoryStartupMB))
+}
+
+func (h *fakeHost) AttachProvisioningISO(_ context.Context, name string, img *iso.Image) (string, error) {
+	return "", h.record("attach " + name + " " + img.VolumeID)
+}
+
+func (h *fakeHost) DetachProvisioningISO(_ context.Context, name string) (bool, error) {
+	return true, h.record("detach " + name)
+}
+
+func (h *fakeHost) AddGPUPartition(_ context.Context, name string, _ *hyperv.GPUPartitionConfig) error {
+	return h.record("gpu " + name)
+}
+
+func (h *fakeHost) DeleteVMAndDisks(_ context.Context, name string) error {
+	return h.record("delete " + name)
+}
+
+// fakeStarter starts every VM except those listed in fail
+type fakeStarter struct {
+	fail map[string]bool
+	ws   *hyperv.Workspace
+}
+
+func (s *fakeStarter) Start(_ context.Context, ws *hyperv.Workspace) ([]hyperv.WorkspaceVMResult, error) {
+	s.ws = ws
+	var results []hyperv.WorkspaceVMResult
+	for _, vm := range ws.VMs {
+		results = append(results, hyperv.WorkspaceVMResult{Name: vm, Success: !s.fail[vm]})
+	}
+	return results, nil
+}
+
+func writeLab(t *testing.T, manifest string) *Manifest {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "dc01.yaml"), []byte("os: windows\nhostname: DC01\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "lab.yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return m
+}
+
+func TestParse_RejectsInvalidManifests(t *testing.T) {
+	tests := map[string]string{
+		"no vms":             "name: x\n",
+		"template and clone": "name: x\nvms: [{name: a, template: t, clone: b}]\n",
+		"neither":            "name: x\nvms: [{name: a}]\n",
+		"linked clone":       "name: x\nvms: [{name: a, clone: b, linked: true}]\n",
+		"unknown network":    "name: x\nnetworks: [{name: n, type: bridge}]\nvms: [{name: a, clone: b}]\n",
+		"external adapter":   "name: x\nnetworks: [{name: n, type: external}]\nvms: [{name: a, clone: b}]\n",
+		"duplicate vm":       "name: x\nvms: [{name: a, clone: b}, {name: a, clone: c}]\n",
+		"unknown dependency": "name: x\nvms: [{name: a, clone: b, dependsOn: [z]}]\n",
+		"cycle":              "name: x\nvms: [{name: a, clone: b, dependsOn: [c]}, {name: c, clone: b, dependsOn: [a]}]\n",
+		"missing provision":  "name: x\nvms: [{name: a, clone: b, provision: nope.yaml}]\n",
+		"unknown key":        "name: x\nvms: [{name: a, clone: b, cpus: 2}]\n",
+		"bad workspace name": "name: a/b\nvms: [{name: a, clone: b}]\n",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data), t.TempDir()); err == nil {
+			t.Errorf("%s: expected manifest to be rejected", name)
+		}
+	}
+}
+
+func TestUp_BuildsStartsAndDetaches(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	host := &fakeHost{existing: map[string]bool{}}
+	starter := &fakeStarter{}
+	l := &Lab{Host: host, Starter: starter}
+
+	result, err := l.Up(context.Background(), m, true)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	want := []string{
+		"switch LabNet internal",
+		"create DC01 win2022 linked=true",
+		"customize DC01 switch=LabNet memory=4096",
+		"attach DC01 UNATTEND",
+		"clone WS01 Win11-Gold mac=true switch=LabNet",
+		"gpu WS01",
+		"detach DC01",
+	}
+	if strings.Join(host.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls:\n%s\nwant:\n%s", strings.Join(host.calls, "\n"), strings.Join(want, "\n"))
+	}
+	if len(result.CreatedVMs) != 2 || len(result.CreatedNetworks) != 1 || len(result.Start) != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	ws, err := hyperv.LoadWorkspace("training")
+	if err != nil {
+		t.Fatalf("Expected the lab workspace to be saved: %v", err)
+	}
+	if ws.Policy("DC01").Readiness == nil || ws.Policy("DC01").Readiness.Type != hyperv.ReadinessHeartbeat {
+		t.Errorf("Expected provisioned Windows VM to wait for its heartbeat, got %+v", ws.Policy("DC01"))
+	}
+	if deps := ws.Policy("WS01").DependsOn; len(deps) != 1 || deps[0] != "DC01" {
+		t.Errorf("Expected WS01 to depend on DC01, got %v", deps)
+	}
+}
+
+func TestUp_SkipsExistingVMsAndKeepsMediaOnFailedStart(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	host := &fakeHost{existing: map[string]bool{"WS01": true, "LabNet": true}}
+	l := &Lab{Host: host, Starter: &fakeStarter{fail: map[string]bool{"DC01": true}}}
+
+	result, err := l.Up(context.Background(), m, true)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if len(result.ExistingVMs) != 1 || len(result.CreatedNetworks) != 0 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	for _, call := range host.calls {
+		if strings.Contains(call, "WS01") || strings.HasPrefix(call, "detach") {
+			t.Errorf("Unexpected call %q", call)
+		}
+	}
+}
+
+func TestUp_StopsAtFirstFailure(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	host := &fakeHost{existing: map[string]bool{}, failOn: "attach DC01"}
+	starter := &fakeStarter{}
+	l := &Lab{Host: host, Starter: starter}
+
+	if _, err := l.Up(context.Background(), m, true); err == nil || !strings.Contains(err.Error(), "DC01") {
+		t.Fatalf("Expected a failure naming DC01, got %v", err)
+	}
+	if starter.ws != nil || hyperv.WorkspaceExists("training") {
+		t.Error("Expected nothing to be started or saved after a build failure")
+	}
+}
+
+func TestDown_DeletesInReverseOrder(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	host := &fakeHost{existing: map[string]bool{}}
+	l := &Lab{Host: host, Starter: &fakeStarter{}}
+	if _, err := l.Up(context.Background(), m, false); err != nil {
+		t.Fatal(err)
+	}
+
+	host.existing = map[string]bool{"DC01": true, "WS01": true}
+	host.calls = nil
+	plan, err := l.PlanDown(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.VMs) != 2 || plan.Workspace != "training" {
+		t.Errorf("Unexpected plan %+v", plan)
+	}
+
+	host.failOn = "delete WS01"
+	if err := l.Down(context.Background(), plan); err == nil {
+		t.Error("Expected the failed delete to be reported")
+	}
+	want := "delete WS01\ndelete DC01\nremove-switch LabNet"
+	if strings.Join(host.calls, "\n") != want {
+		t.Errorf("Unexpected calls:\n%s", strings.Join(host.calls, "\n"))
+	}
+	if hyperv.WorkspaceExists("training") {
+		t.Error("Expected the lab workspace to be deleted")
+	}
+	if state, _ := LoadState("training"); len(state.VMs) != 1 || state.VMs[0] != "WS01" || len(state.Networks) != 0 {
+		t.Errorf("Expected only the VM that failed to delete to stay recorded, got %+v", state)
+	}
+}
+
+func TestDown_KeepsWhatTheLabDidNotCreate(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	// LabNet and WS01 existed before the lab
+	host := &fakeHost{existing: map[string]bool{"LabNet": true, "WS01": true}}
+	l := &Lab{Host: host, Starter: &fakeStarter{}}
+	if _, err := l.Up(context.Background(), m, false); err != nil {
+		t.Fatal(err)
+	}
+
+	host.existing = map[string]bool{"DC01": true, "WS01": true}
+	plan, err := l.PlanDown(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(plan.VMs, ",") != "DC01" || len(plan.Networks) != 0 ||
+		strings.Join(plan.KeptVMs, ",") != "WS01" || strings.Join(plan.KeptNetworks, ",") != "LabNet" {
+		t.Errorf("Unexpected plan %+v", plan)
+	}
+}
+
+func TestUp_KeepsWorkspaceTheLabDidNotSave(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	if err := hyperv.SaveWorkspace(&hyperv.Workspace{Name: "training", VMs: []string{"Mine"}}); err != nil {
+		t.Fatal(err)
+	}
+	host := &fakeHost{existing: map[string]bool{}}
+	l := &Lab{Host: host, Starter: &fakeStarter{}}
+
+	if _, err := l.Up(context.Background(), m, false); err == nil || !strings.Contains(err.Error(), "--replace-workspace") {
+		t.Fatalf("Expected Up to refuse the existing workspace, got %v", err)
+	}
+	if len(host.calls) != 0 {
+		t.Errorf("Expected nothing to be created, got %v", host.calls)
+	}
+
+	host.existing = map[string]bool{"DC01": true}
+	plan, err := l.PlanDown(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Workspace != "" || plan.KeptWorkspace != "training" {
+		t.Errorf("Expected the workspace to be kept, got %+v", plan)
+	}
+
+	// Once replaced, the workspace is the lab's and goes with it
+	host.existing = map[string]bool{}
+	l.ReplaceWorkspace = true
+	if _, err := l.Up(context.Background(), m, false); err != nil {
+		t.Fatal(err)
+	}
+	if plan, err = l.PlanDown(context.Background(), m); err != nil || plan.Workspace != "training" {
+		t.Errorf("Expected the replaced workspace to be planned for deletion, got %+v, %v", plan, err)
+	}
+}
+
+func TestDown_KeepsSwitchInUse(t *testing.T) {
+	m := writeLab(t, trainingLab)
+	host := &fakeHost{existing: map[string]bool{}}
+	l := &Lab{Host: host, Starter: &fakeStarter{}}
+	if _, err := l.Up(context.Background(), m, false); err != nil {
+		t.Fatal(err)
+	}
+
+	host.existing = map[string]bool{"DC01": true, "WS01": true}
+	host.switchVMs = map[string][]string{"LabNet": {"Backup"}}
+	host.calls = nil
+	var kept []string
+	l.OnEvent = func(e Event) {
+		if e.Step == StepKeep {
+			kept = append(kept, e.Target+": "+e.Message)
+		}
+	}
+	plan, err := l.PlanDown(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Down(context.Background(), plan); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range host.calls {
+		if strings.HasPrefix(call, "remove-switch") {
+			t.Errorf("Expected the switch in use to be kept, got %q", call)
+		}
+	}
+	if strings.Join(kept, ",") != "LabNet: Backup" {
+		t.Errorf("Unexpected keep events %v", kept)
+	}
+	if state, _ := LoadState("training"); !state.HasNetwork("LabNet") || len(state.VMs) != 0 {
+		t.Errorf("Expected the kept switch to stay recorded, got %+v", state)
+	}
+}
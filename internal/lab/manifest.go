@@ -0,0 +1,179 @@
+// Package lab builds and tears down a set of VMs described by one manifest file:
+// networks, VMs created from templates or cloned from existing VMs, provisioning media,
+// GPU partitions, and a workspace that starts the set in dependency order.
+package lab
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/provision"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes a lab
+type Manifest struct {
+	Name        string    `yaml:"name"` // Also the name of the lab's workspace
+	Description string    `yaml:"description,omitempty"`
+	Networks    []Network `yaml:"networks,omitempty"`
+	VMs         []VM      `yaml:"vms"`
+
+	dir string // Directory of the manifest; provisioning paths are relative to it
+}
+
+// Network is a virtual switch owned by the lab
+type Network struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type,omitempty"`    // private (default), internal or external
+	Adapter string `yaml:"adapter,omitempty"` // external: physical network adapter
+}
+
+// VM is one lab member. Exactly one of Template and Clone must be set.
+type VM struct {
+	Name     string `yaml:"name"`
+	Template string `yaml:"template,omitempty"` // Create from this template
+	Linked   bool   `yaml:"linked,omitempty"`   // template: use differencing disks
+	Clone    string `yaml:"clone,omitempty"`    // Clone this existing VM
+	VHDPath  string `yaml:"vhdPath,omitempty"`  // template: directory for the VM's disks
+
+	Switch    string `yaml:"switch,omitempty"`    // Connect every adapter to this switch
+	CPU       int    `yaml:"cpu,omitempty"`       // Virtual processor count
+	MemoryMB  int64  `yaml:"memoryMB,omitempty"`  // Startup memory
+	Provision string `yaml:"provision,omitempty"` // Provisioning file attached as first-boot media
+	GPU       bool   `yaml:"gpu,omitempty"`       // Add a GPU partition with the default settings
+
+	DependsOn  []string               `yaml:"dependsOn,omitempty"`
+	StartDelay hyperv.Duration        `yaml:"startDelay,omitempty"`
+	Readiness  *hyperv.ReadinessCheck `yaml:"readiness,omitempty"`
+
+	provision *provision.Config
+}
+
+// Load reads and validates a lab manifest
+func Load(path string) (*Manifest, error) {
+	//nolint:gosec // G304: Manifest path is provided by the user.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lab manifest: %w", err)
+	}
+	return Parse(data, filepath.Dir(path))
+}
+
+// Parse decodes manifest YAML strictly (unknown keys are errors), loads the provisioning
+// files it references relative to dir and validates the result
+func Parse(data []byte, dir string) (*Manifest, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var m Manifest
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid lab manifest: %w", err)
+	}
+	m.dir = dir
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest and loads its provisioning files
+func (m *Manifest) Validate() error {
+	if len(m.VMs) == 0 {
+		return fmt.Errorf("lab '%s' has no VMs", m.Name)
+	}
+
+	networks := make(map[string]bool, len(m.Networks))
+	for i := range m.Networks {
+		n := &m.Networks[i]
+		if n.Name == "" {
+			return fmt.Errorf("lab '%s' has a network without a name", m.Name)
+		}
+		if networks[n.Name] {
+			return fmt.Errorf("network '%s' is listed more than once", n.Name)
+		}
+		networks[n.Name] = true
+		if n.Type == "" {
+			n.Type = hyperv.SwitchPrivate
+		}
+		if n.Type != hyperv.SwitchPrivate && n.Type != hyperv.SwitchInternal && n.Type != hyperv.SwitchExternal {
+			return fmt.Errorf("network '%s': unknown type '%s' (valid: private, internal, external)", n.Name, n.Type)
+		}
+		if n.Type == hyperv.SwitchExternal && n.Adapter == "" {
+			return fmt.Errorf("network '%s': external networks need an adapter", n.Name)
+		}
+	}
+
+	for i := range m.VMs {
+		if err := m.validateVM(&m.VMs[i]); err != nil {
+			return fmt.Errorf("VM '%s': %w", m.VMs[i].Name, err)
+		}
+	}
+
+	// Names, duplicates, dependencies and readiness checks are workspace rules
+	return m.Workspace().Validate()
+}
+
+func (m *Manifest) validateVM(vm *VM) error {
+	if (vm.Template == "") == (vm.Clone == "") {
+		return fmt.Errorf("set exactly one of template and clone")
+	}
+	if vm.Clone != "" && (vm.Linked || vm.VHDPath != "") {
+		return fmt.Errorf("linked and vhdPath only apply to VMs created from a template")
+	}
+	if err := vm.customization().Validate(); err != nil {
+		return err
+	}
+
+	if vm.Provision == "" {
+		return nil
+	}
+	path := vm.Provision
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+	cfg, err := provision.Load(path)
+	if err != nil {
+		return err
+	}
+	vm.provision = cfg
+	return nil
+}
+
+// customization returns the hardware changes applied after the VM is created
+func (vm *VM) customization() *hyperv.CloneCustomization {
+	return &hyperv.CloneCustomization{
+		SwitchName:      vm.Switch,
+		ProcessorCount:  vm.CPU,
+		MemoryStartupMB: vm.MemoryMB,
+		RegenerateMAC:   vm.Clone != "", // A clone must not share its source's MAC addresses
+	}
+}
+
+// Workspace returns the workspace that starts the lab's VMs in dependency order.
+// Provisioned VMs without a readiness check wait for their guest, so the provisioning
+// media can be detached once they are ready.
+func (m *Manifest) Workspace() *hyperv.Workspace {
+	ws := &hyperv.Workspace{Name: m.Name, Description: m.Description}
+	for _, vm := range m.VMs {
+		ws.VMs = append(ws.VMs, vm.Name)
+
+		policy := hyperv.StartupPolicy{DependsOn: vm.DependsOn, StartDelay: vm.StartDelay, Readiness: vm.Readiness}
+		if policy.Readiness == nil && vm.provision != nil {
+			policy.Readiness = &hyperv.ReadinessCheck{Type: hyperv.ReadinessIP}
+			if vm.provision.GuestOS() == provision.OSWindows {
+				policy.Readiness.Type = hyperv.ReadinessHeartbeat
+			}
+		}
+		if len(policy.DependsOn) > 0 || policy.StartDelay > 0 || policy.Readiness != nil {
+			if ws.Startup == nil {
+				ws.Startup = make(map[string]hyperv.StartupPolicy)
+			}
+			ws.Startup[vm.Name] = policy
+		}
+	}
+	return ws
+}
@@ -0,0 +1,88 @@
+package lab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// State records what 'lab up' created, so 'lab down' removes only that and leaves
+// switches, VMs and a workspace that existed before the lab alone
+type State struct {
+	Lab       string   `json:"lab"`
+	Networks  []string `json:"networks,omitempty"`  // Switches created by the lab
+	VMs       []string `json:"vms,omitempty"`       // VMs created by the lab
+	Workspace bool     `json:"workspace,omitempty"` // Whether the lab saved the workspace named after it
+}
+
+// GetStatePath returns the path of a lab's state file, ~/.quickvm/labs/<name>.json
+func GetStatePath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".quickvm", "labs", name+".json"), nil
+}
+
+// LoadState loads the state of a lab; a lab that was never brought up has an empty state
+func LoadState(name string) (*State, error) {
+	path, err := GetStatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	//nolint:gosec // G304: Path is constructed from the home directory and the validated lab name.
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{Lab: name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lab state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse lab state: %w", err)
+	}
+	state.Lab = name
+	return &state, nil
+}
+
+// Save writes the state, or removes the state file once nothing is recorded
+func (s *State) Save() error {
+	path, err := GetStatePath(s.Lab)
+	if err != nil {
+		return err
+	}
+	if len(s.Networks) == 0 && len(s.VMs) == 0 && !s.Workspace {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove lab state: %w", err)
+		}
+		return nil
+	}
+
+	// gosec G301: Expect directory permissions to be 0750 or less
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create lab state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lab state: %w", err)
+	}
+	// gosec G306: Expect WriteFile permissions to be 0600 or less
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write lab state: %w", err)
+	}
+	return nil
+}
+
+// HasNetwork reports whether the lab created the switch
+func (s *State) HasNetwork(name string) bool {
+	return slices.Contains(s.Networks, name)
+}
+
+// HasVM reports whether the lab created the VM
+func (s *State) HasVM(name string) bool {
+	return slices.Contains(s.VMs, name)
+}
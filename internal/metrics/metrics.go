@@ -0,0 +1,236 @@
+// Package metrics collects host and VM statistics and renders them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+// DefaultCacheTTL is how long a collection is reused for later scrapes
+const DefaultCacheTTL = 15 * time.Second
+
+// collectTimeout bounds a collection independently of the scrape that triggered it
+const collectTimeout = 60 * time.Second
+
+const bytesPerMB = 1024 * 1024
+
+// Source provides the statistics; *hyperv.Manager implements it
+type Source interface {
+	GetVMs(ctx context.Context) ([]hyperv.VM, error)
+	GetSystemInfo(ctx context.Context, includeDisk bool) (*hyperv.SystemInfo, error)
+	CountSnapshots(ctx context.Context) (map[string]int, error)
+}
+
+// Collector gathers metric families from a Source. Collections are cached for CacheTTL
+// and concurrent scrapes share the collection in progress, so PowerShell runs at
+// most once per TTL however often Prometheus scrapes.
+type Collector struct {
+	Source        Source
+	CacheTTL      time.Duration
+	IncludeDisk   bool                                  // Collect per-disk host gauges (slower)
+	ExportHistory func() (*hyperv.ExportHistory, error) // nil skips export metrics
+
+	mu        sync.Mutex
+	cached    []Family
+	collected time.Time
+	now       func() time.Time
+}
+
+// NewCollector returns a collector of the manager's statistics and the export history
+func NewCollector(source Source, cacheTTL time.Duration) *Collector {
+	return &Collector{Source: source, CacheTTL: cacheTTL, ExportHistory: hyperv.LoadExportHistory}
+}
+
+// Family is a metric with its samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string // "gauge" or "counter"
+	Samples []Sample
+}
+
+// Sample is one value of a metric
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Label is a label name and value
+type Label struct {
+	Name, Value string
+}
+
+// Collect returns the metric families, from the cache when it is fresh
+func (c *Collector) Collect() []Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	if c.cached != nil && now().Sub(c.collected) < c.CacheTTL {
+		return c.cached
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	started := now()
+	families := c.collect(ctx)
+	families = append(families, Family{
+		Name: "quickvm_scrape_duration_seconds", Help: "Time taken to collect the metrics.", Type: "gauge",
+		Samples: []Sample{{Value: now().Sub(started).Seconds()}},
+	})
+	c.cached, c.collected = families, now()
+	return families
+}
+
+// collect queries the source; a failing part is reported by quickvm_scrape_error
+// and leaves the other parts intact
+func (c *Collector) collect(ctx context.Context) []Family {
+	var families []Family
+	scrapeErrors := Family{Name: "quickvm_scrape_error", Help: "Whether collecting a part of the metrics failed.", Type: "gauge"}
+	report := func(part string, err error) {
+		value := 0.0
+		if err != nil {
+			value = 1
+		}
+		scrapeErrors.Samples = append(scrapeErrors.Samples, Sample{Labels: []Label{{"collector", part}}, Value: value})
+	}
+
+	vms, err := c.Source.GetVMs(ctx)
+	report("vms", err)
+	if err == nil {
+		families = append(families, vmFamilies(vms)...)
+	}
+
+	if err == nil {
+		counts, countErr := c.Source.CountSnapshots(ctx)
+		report("snapshots", countErr)
+		if countErr == nil {
+			families = append(families, snapshotFamily(vms, counts))
+		}
+	}
+
+	info, err := c.Source.GetSystemInfo(ctx, c.IncludeDisk)
+	report("host", err)
+	if err == nil {
+		families = append(families, hostFamilies(info)...)
+	}
+
+	if c.ExportHistory != nil {
+		history, err := c.ExportHistory()
+		report("exports", err)
+		if err == nil {
+			families = append(families, exportFamilies(history)...)
+		}
+	}
+	return append(families, scrapeErrors)
+}
+
+func vmFamilies(vms []hyperv.VM) []Family {
+	state := Family{Name: "quickvm_vm_state", Help: "Current state of the VM; the sample with the state label is 1.", Type: "gauge"}
+	running := Family{Name: "quickvm_vm_running", Help: "Whether the VM is running.", Type: "gauge"}
+	cpu := Family{Name: "quickvm_vm_cpu_usage_percent", Help: "CPU usage of the VM relative to the host.", Type: "gauge"}
+	memory := Family{Name: "quickvm_vm_memory_assigned_bytes", Help: "Memory assigned to the VM.", Type: "gauge"}
+	uptime := Family{Name: "quickvm_vm_uptime_seconds", Help: "Time since the VM was started.", Type: "gauge"}
+	for _, vm := range vms {
+		labels := []Label{{"vm", vm.Name}}
+		state.Samples = append(state.Samples, Sample{Labels: []Label{{"vm", vm.Name}, {"state", vm.State}}, Value: 1})
+		running.Samples = append(running.Samples, Sample{Labels: labels, Value: boolValue(vm.State == "Running")})
+		cpu.Samples = append(cpu.Samples, Sample{Labels: labels, Value: float64(vm.CPUUsage)})
+		memory.Samples = append(memory.Samples, Sample{Labels: labels, Value: float64(vm.MemoryMB * bytesPerMB)})
+		uptime.Samples = append(uptime.Samples, Sample{Labels: labels, Value: hyperv.ParseUptime(vm.Uptime).Seconds()})
+	}
+	count := Family{Name: "quickvm_vms", Help: "Number of VMs on the host.", Type: "gauge",
+		Samples: []Sample{{Value: float64(len(vms))}}}
+	return []Family{count, state, running, cpu, memory, uptime}
+}
+
+func snapshotFamily(vms []hyperv.VM, counts map[string]int) Family {
+	family := Family{Name: "quickvm_vm_snapshots", Help: "Number of snapshots of the VM.", Type: "gauge"}
+	for _, vm := range vms {
+		family.Samples = append(family.Samples, Sample{Labels: []Label{{"vm", vm.Name}}, Value: float64(counts[vm.Name])})
+	}
+	return family
+}
+
+func hostFamilies(info *hyperv.SystemInfo) []Family {
+	families := []Family{
+		{Name: "quickvm_host_cpu_cores", Help: "Number of CPU cores of the host.", Type: "gauge",
+			Samples: []Sample{{Value: float64(info.CPU.Cores)}}},
+		{Name: "quickvm_host_cpu_load_percent", Help: "Average CPU load of the host.", Type: "gauge",
+			Samples: []Sample{{Value: float64(info.CPU.LoadPercent)}}},
+		{Name: "quickvm_host_memory_total_bytes", Help: "Physical memory of the host.", Type: "gauge",
+			Samples: []Sample{{Value: float64(info.Memory.TotalMB * bytesPerMB)}}},
+		{Name: "quickvm_host_memory_free_bytes", Help: "Free physical memory of the host.", Type: "gauge",
+			Samples: []Sample{{Value: float64(info.Memory.FreeMB * bytesPerMB)}}},
+		{Name: "quickvm_hyperv_enabled", Help: "Whether Hyper-V is enabled on the host.", Type: "gauge",
+			Samples: []Sample{{Value: boolValue(info.HyperV.Enabled)}}},
+	}
+	if len(info.Disks) == 0 {
+		return families
+	}
+	total := Family{Name: "quickvm_host_disk_total_bytes", Help: "Size of the host disk.", Type: "gauge"}
+	free := Family{Name: "quickvm_host_disk_free_bytes", Help: "Free space on the host disk.", Type: "gauge"}
+	for _, disk := range info.Disks {
+		labels := []Label{{"disk", disk.Name}}
+		total.Samples = append(total.Samples, Sample{Labels: labels, Value: float64(disk.TotalMB * bytesPerMB)})
+		free.Samples = append(free.Samples, Sample{Labels: labels, Value: float64(disk.FreeMB * bytesPerMB)})
+	}
+	return append(families, total, free)
+}
+
+// exportFamilies reports the export totals per VM and the last successful export of each VM
+func exportFamilies(history *hyperv.ExportHistory) []Family {
+	// The totals are kept apart from the truncated records, so the counter never goes down
+	count := Family{Name: "quickvm_exports_total", Help: "Number of exports recorded by result.", Type: "counter"}
+	vms := make([]string, 0, len(history.Totals))
+	for name := range history.Totals {
+		vms = append(vms, name)
+	}
+	sort.Strings(vms)
+	for _, name := range vms {
+		totals := history.Totals[name]
+		for _, outcome := range []struct {
+			result string
+			n      int
+		}{{"failure", totals.Failure}, {"success", totals.Success}} {
+			if outcome.n > 0 {
+				count.Samples = append(count.Samples, Sample{Labels: []Label{{"vm", name}, {"result", outcome.result}}, Value: float64(outcome.n)})
+			}
+		}
+	}
+
+	last := make(map[string]hyperv.ExportRecord)
+	for _, r := range history.Records {
+		// Failed exports stop early, so only successful ones say how long an export takes
+		if r.Success {
+			last[r.VMName] = r
+		}
+	}
+	duration := Family{Name: "quickvm_export_last_duration_seconds", Help: "Duration of the last successful export of the VM.", Type: "gauge"}
+	timestamp := Family{Name: "quickvm_export_last_success_timestamp_seconds", Help: "Start time of the last successful export of the VM.", Type: "gauge"}
+	names := make([]string, 0, len(last))
+	for name := range last {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		labels := []Label{{"vm", name}}
+		duration.Samples = append(duration.Samples, Sample{Labels: labels, Value: last[name].DurationSeconds})
+		timestamp.Samples = append(timestamp.Samples, Sample{Labels: labels, Value: float64(last[name].Started.Unix())})
+	}
+	return []Family{count, duration, timestamp}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+type fakeSource struct {
+	mu      sync.Mutex
+	calls   int
+	vmErr   error
+	started chan struct{} // Closed by the first GetVMs call when set
+	release chan struct{} // GetVMs waits on it when set
+}
+
+func (f *fakeSource) GetVMs(_ context.Context) ([]hyperv.VM, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.started != nil {
+		close(f.started)
+		f.started = nil
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return []hyperv.VM{
+		{Name: "Web01", State: "Running", CPUUsage: 12, MemoryMB: 2048, Uptime: "1.02:00:00.5"},
+		{Name: `Lab "A"`, State: "Off"},
+	}, f.vmErr
+}
+
+func (f *fakeSource) GetSystemInfo(_ context.Context, _ bool) (*hyperv.SystemInfo, error) {
+	return &hyperv.SystemInfo{
+		CPU:    hyperv.CPUInfo{Cores: 8, LoadPercent: 30},
+		Memory: hyperv.MemoryInfo{TotalMB: 32768, FreeMB: 16384},
+		HyperV: hyperv.Status{Enabled: true},
+	}, nil
+}
+
+func (f *fakeSource) CountSnapshots(_ context.Context) (map[string]int, error) {
+	return map[string]int{"Web01": 3}, nil
+}
+
+func render(t *testing.T, c *Collector) string {
+	t.Helper()
+	var b strings.Builder
+	if err := WriteText(&b, c.Collect()); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestCollector_Text(t *testing.T) {
+	started := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	c := NewCollector(&fakeSource{}, 0)
+	c.ExportHistory = func() (*hyperv.ExportHistory, error) {
+		// The totals include exports whose records were dropped from the history
+		return &hyperv.ExportHistory{
+			Totals: map[string]hyperv.ExportTotals{"Web01": {Success: 7, Failure: 1}},
+			Records: []hyperv.ExportRecord{
+				{VMName: "Web01", Started: started.Add(-time.Hour), DurationSeconds: 90, Success: true},
+				{VMName: "Web01", Started: started, DurationSeconds: 120, Success: true},
+				{VMName: "Web01", Started: started, DurationSeconds: 5},
+			},
+		}, nil
+	}
+	text := render(t, c)
+
+	for _, want := range []string{
+		"# TYPE quickvm_vm_running gauge\n",
+		`quickvm_vm_state{vm="Web01",state="Running"} 1`,
+		`quickvm_vm_running{vm="Lab \"A\""} 0`,
+		`quickvm_vm_cpu_usage_percent{vm="Web01"} 12`,
+		`quickvm_vm_memory_assigned_bytes{vm="Web01"} 2147483648`,
+		`quickvm_vm_uptime_seconds{vm="Web01"} 93600`,
+		`quickvm_vm_snapshots{vm="Web01"} 3`,
+		`quickvm_vm_snapshots{vm="Lab \"A\""} 0`,
+		"quickvm_host_cpu_cores 8\n",
+		"quickvm_host_memory_free_bytes 17179869184\n",
+		"quickvm_hyperv_enabled 1\n",
+		`quickvm_exports_total{vm="Web01",result="failure"} 1`,
+		`quickvm_exports_total{vm="Web01",result="success"} 7`,
+		`quickvm_export_last_duration_seconds{vm="Web01"} 120`,
+		`quickvm_export_last_success_timestamp_seconds{vm="Web01"} 1792324800`,
+		`quickvm_scrape_error{collector="vms"} 0`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "quickvm_host_disk_total_bytes") {
+		t.Error("Expected no disk metrics without disks")
+	}
+}
+
+func TestCollector_PartialFailure(t *testing.T) {
+	c := NewCollector(&fakeSource{vmErr: errors.New("access denied")}, 0)
+	c.ExportHistory = nil
+	text := render(t, c)
+	if !strings.Contains(text, `quickvm_scrape_error{collector="vms"} 1`) || strings.Contains(text, "quickvm_vm_running") {
+		t.Errorf("Expected only the VM metrics to be missing:\n%s", text)
+	}
+	if !strings.Contains(text, "quickvm_host_cpu_cores 8") {
+		t.Errorf("Expected host metrics despite the VM failure:\n%s", text)
+	}
+}
+
+func TestCollector_Cache(t *testing.T) {
+	source := &fakeSource{}
+	now := time.Now()
+	c := NewCollector(source, 10*time.Second)
+	c.ExportHistory = nil
+	c.now = func() time.Time { return now }
+
+	c.Collect()
+	now = now.Add(5 * time.Second)
+	c.Collect()
+	if source.calls != 1 {
+		t.Errorf("Expected a cached collection within the TTL, got %d calls", source.calls)
+	}
+	now = now.Add(10 * time.Second)
+	c.Collect()
+	if source.calls != 2 {
+		t.Errorf("Expected a new collection after the TTL, got %d calls", source.calls)
+	}
+}
+
+func TestCollector_ConcurrentScrapesShareCollection(t *testing.T) {
+	source := &fakeSource{started: make(chan struct{}), release: make(chan struct{})}
+	c := NewCollector(source, time.Minute)
+	c.ExportHistory = nil
+
+	started := source.started
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Collect()
+		}()
+	}
+	<-started
+	close(source.release)
+	wg.Wait()
+	if source.calls != 1 {
+		t.Errorf("Expected concurrent scrapes to share one collection, got %d", source.calls)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{0: "0", 1.5: "1.5", 2147483648: "2147483648", 0.000125: "0.000125"}
+	for v, want := range tests {
+		if got := formatValue(v); got != want {
+			t.Errorf("formatValue(%v) = %s, want %s", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes the families in the Prometheus text exposition format
+func WriteText(w io.Writer, families []Family) error {
+	var b strings.Builder
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		b.WriteString("# HELP " + f.Name + " " + escapeHelp(f.Help) + "\n")
+		b.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, s := range f.Samples {
+			b.WriteString(f.Name)
+			if len(s.Labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(l.Name + `="` + escapeLabel(l.Value) + `"`)
+				}
+				b.WriteByte('}')
+			}
+			b.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the collector's metrics over HTTP
+func Handler(c *Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = WriteText(w, c.Collect())
+	})
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -105,10 +105,23 @@ func PrintData(data interface{}) {
 	// For non-JSON, caller handles the formatting
 }
 
+// PrintEvent prints v as a single line of JSON for streaming output (NDJSON).
+// Once an event has been printed, later JSON responses are compact as well so the
+// whole stream stays one JSON document per line.
+func PrintEvent(v interface{}) {
+	streaming = true
+	_ = json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// streaming is set once PrintEvent has been used
+var streaming bool
+
 // printJSON marshals and prints JSON to stdout
 func printJSON(v interface{}) {
 	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
+	if !streaming {
+		encoder.SetIndent("", "  ")
+	}
 	_ = encoder.Encode(v)
 }
 
@@ -0,0 +1,148 @@
+package provision
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sudoAll grants admin users passwordless sudo
+const sudoAll = "ALL=(ALL) NOPASSWD:ALL"
+
+// cloudConfig is the subset of #cloud-config user-data written for a provisioning file
+type cloudConfig struct {
+	Hostname         string        `yaml:"hostname,omitempty"`
+	PreserveHostname *bool         `yaml:"preserve_hostname,omitempty"`
+	Timezone         string        `yaml:"timezone,omitempty"`
+	Users            []interface{} `yaml:"users,omitempty"`
+	SSHPasswordAuth  *bool         `yaml:"ssh_pwauth,omitempty"`
+	PackageUpdate    bool          `yaml:"package_update,omitempty"`
+	Packages         []string      `yaml:"packages,omitempty"`
+	RunCmd           []string      `yaml:"runcmd,omitempty"`
+}
+
+type cloudUser struct {
+	Name              string   `yaml:"name"`
+	Shell             string   `yaml:"shell"`
+	Sudo              string   `yaml:"sudo,omitempty"`
+	Groups            string   `yaml:"groups,omitempty"`
+	LockPassword      bool     `yaml:"lock_passwd"`
+	PlainTextPassword string   `yaml:"plain_text_passwd,omitempty"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+// networkConfig is cloud-init network config version 2
+type networkConfig struct {
+	Version   int                         `yaml:"version"`
+	Ethernets map[string]networkEthernets `yaml:"ethernets"`
+}
+
+type networkEthernets struct {
+	Match       map[string]string  `yaml:"match"`
+	DHCP4       bool               `yaml:"dhcp4"`
+	Addresses   []string           `yaml:"addresses"`
+	Routes      []networkRoute     `yaml:"routes,omitempty"`
+	Nameservers *networkNameserver `yaml:"nameservers,omitempty"`
+}
+
+type networkRoute struct {
+	To  string `yaml:"to"`
+	Via string `yaml:"via"`
+}
+
+type networkNameserver struct {
+	Addresses []string `yaml:"addresses"`
+}
+
+// UserData renders the #cloud-config user-data document
+func (c *Config) UserData() ([]byte, error) {
+	doc := cloudConfig{
+		Hostname:      c.Hostname,
+		Timezone:      c.Timezone,
+		PackageUpdate: len(c.Packages) > 0,
+		Packages:      c.Packages,
+		RunCmd:        c.RunCommands,
+	}
+	if c.Hostname != "" {
+		preserve := false
+		doc.PreserveHostname = &preserve
+	}
+
+	if len(c.Users) > 0 {
+		doc.Users = append(doc.Users, "default") // Keep the image's default user
+	}
+	for _, u := range c.Users {
+		password, err := u.password()
+		if err != nil {
+			return nil, err
+		}
+		user := cloudUser{
+			Name:              u.Name,
+			Shell:             "/bin/bash",
+			LockPassword:      password == "",
+			PlainTextPassword: password,
+			SSHAuthorizedKeys: u.SSHKeys,
+		}
+		if u.Admin {
+			user.Sudo = sudoAll
+			user.Groups = "sudo"
+		}
+		if password != "" {
+			enabled := true
+			doc.SSHPasswordAuth = &enabled
+		}
+		doc.Users = append(doc.Users, user)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render user-data: %w", err)
+	}
+	return append([]byte("#cloud-config\n"), data...), nil
+}
+
+// MetaData renders the NoCloud meta-data document. The instance ID is new on every call,
+// so cloud-init treats each seed as a fresh instance.
+func (c *Config) MetaData() ([]byte, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate instance ID: %w", err)
+	}
+	meta := map[string]string{"instance-id": "quickvm-" + hex.EncodeToString(id)}
+	if c.Hostname != "" {
+		meta["local-hostname"] = c.Hostname
+	}
+
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render meta-data: %w", err)
+	}
+	return data, nil
+}
+
+// NetworkConfig renders the network-config document, or nil when the guest should use DHCP
+func (c *Config) NetworkConfig() ([]byte, error) {
+	if c.Network == nil {
+		return nil, nil
+	}
+
+	eth := networkEthernets{
+		// Match the Hyper-V synthetic adapter, whatever the guest names it
+		Match:     map[string]string{"driver": "hv_netvsc"},
+		Addresses: []string{c.Network.Address},
+	}
+	if c.Network.Gateway != "" {
+		eth.Routes = []networkRoute{{To: "0.0.0.0/0", Via: c.Network.Gateway}}
+	}
+	if len(c.Network.DNS) > 0 {
+		eth.Nameservers = &networkNameserver{Addresses: c.Network.DNS}
+	}
+
+	data, err := yaml.Marshal(networkConfig{Version: 2, Ethernets: map[string]networkEthernets{"primary": eth}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render network-config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,162 @@
+// Package provision turns a provisioning YAML file into first-boot configuration media:
+// a cloud-init NoCloud seed for Linux guests or an autounattend.xml for Windows guests.
+package provision
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Guest operating systems
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
+var (
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	userPattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	envNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Config is a provisioning file
+type Config struct {
+	OS          string   `yaml:"os"`                    // linux (default) or windows
+	Hostname    string   `yaml:"hostname,omitempty"`    // Guest computer name
+	Users       []User   `yaml:"users,omitempty"`       // Accounts to create
+	Packages    []string `yaml:"packages,omitempty"`    // linux: packages to install
+	Network     *Network `yaml:"network,omitempty"`     // Static IPv4 configuration; DHCP if omitted
+	RunCommands []string `yaml:"runCommands,omitempty"` // Commands run once at the end of first boot
+	Timezone    string   `yaml:"timezone,omitempty"`    // linux: tz database name; windows: Windows time zone ID
+}
+
+// User is an account created on first boot
+type User struct {
+	Name        string   `yaml:"name"`
+	PasswordEnv string   `yaml:"passwordEnv,omitempty"` // Environment variable holding the password
+	SSHKeys     []string `yaml:"sshKeys,omitempty"`     // linux: authorized public keys
+	Admin       bool     `yaml:"admin,omitempty"`       // sudo on linux, Administrators on windows
+}
+
+// Network is a static IPv4 configuration for the first network adapter
+type Network struct {
+	Address   string   `yaml:"address"`             // CIDR, e.g. 192.168.10.20/24
+	Gateway   string   `yaml:"gateway,omitempty"`   // Default gateway
+	DNS       []string `yaml:"dns,omitempty"`       // DNS servers
+	Interface string   `yaml:"interface,omitempty"` // windows: adapter name (default "Ethernet")
+}
+
+// Load reads and validates a provisioning file
+func Load(path string) (*Config, error) {
+	//nolint:gosec // G304: Provisioning file path is provided by the user.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provisioning file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes provisioning YAML strictly (unknown keys are errors) and validates it
+func Parse(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var cfg Config
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid provisioning file: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GuestOS returns the configured guest OS, defaulting to linux
+func (c *Config) GuestOS() string {
+	if c.OS == "" {
+		return OSLinux
+	}
+	return strings.ToLower(c.OS)
+}
+
+// Validate checks the configuration for the target guest OS
+func (c *Config) Validate() error {
+	guestOS := c.GuestOS()
+	if guestOS != OSLinux && guestOS != OSWindows {
+		return fmt.Errorf("unknown os '%s' (valid: linux, windows)", c.OS)
+	}
+
+	if c.Hostname != "" {
+		maxLen := 63
+		if guestOS == OSWindows {
+			maxLen = 15 // NetBIOS limit
+		}
+		if len(c.Hostname) > maxLen || !hostnamePattern.MatchString(c.Hostname) {
+			return fmt.Errorf("invalid hostname '%s': use letters, digits and hyphens, at most %d characters", c.Hostname, maxLen)
+		}
+	}
+
+	for _, u := range c.Users {
+		if err := u.validate(guestOS); err != nil {
+			return err
+		}
+	}
+	if guestOS == OSWindows && len(c.Packages) > 0 {
+		return fmt.Errorf("packages are only supported for linux guests")
+	}
+	if c.Network != nil {
+		if err := c.Network.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *User) validate(guestOS string) error {
+	if !userPattern.MatchString(u.Name) {
+		return fmt.Errorf("invalid user name '%s'", u.Name)
+	}
+	if u.PasswordEnv != "" && !envNamePattern.MatchString(u.PasswordEnv) {
+		return fmt.Errorf("invalid passwordEnv '%s' for user '%s': must be an environment variable name", u.PasswordEnv, u.Name)
+	}
+	if guestOS == OSWindows {
+		if len(u.SSHKeys) > 0 {
+			return fmt.Errorf("sshKeys are only supported for linux guests (user '%s')", u.Name)
+		}
+		if u.PasswordEnv == "" {
+			return fmt.Errorf("windows user '%s' needs a passwordEnv", u.Name)
+		}
+	}
+	return nil
+}
+
+func (n *Network) validate() error {
+	ip, _, err := net.ParseCIDR(n.Address)
+	if err != nil || ip.To4() == nil {
+		return fmt.Errorf("invalid network address '%s': use IPv4 CIDR notation such as 192.168.10.20/24", n.Address)
+	}
+	for _, addr := range append([]string{n.Gateway}, n.DNS...) {
+		if addr != "" && net.ParseIP(addr).To4() == nil {
+			return fmt.Errorf("invalid IPv4 address '%s'", addr)
+		}
+	}
+	return nil
+}
+
+// password returns the user's password from the environment, or "" if none is configured
+func (u *User) password() (string, error) {
+	if u.PasswordEnv == "" {
+		return "", nil
+	}
+	password, ok := os.LookupEnv(u.PasswordEnv)
+	if !ok || password == "" {
+		return "", fmt.Errorf("environment variable %s for the password of '%s' is not set", u.PasswordEnv, u.Name)
+	}
+	return password, nil
+}
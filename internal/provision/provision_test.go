@@ -0,0 +1,173 @@
+package provision
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const linuxConfig = `
+hostname: web01
+users:
+  - name: alice
+    admin: true
+    passwordEnv: QUICKVM_TEST_PASSWORD
+    sshKeys: ["ssh-ed25519 AAAA alice@laptop"]
+packages: [nginx, git]
+network:
+  address: 192.168.10.20/24
+  gateway: 192.168.10.1
+  dns: [1.1.1.1]
+runCommands: ["systemctl enable --now nginx"]
+`
+
+func TestParse_RejectsInvalidConfigs(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":          "hostname: a\nhostnme: b\n",
+		"unknown os":           "os: bsd\n",
+		"bad hostname":         "hostname: web_01\n",
+		"long windows name":    "os: windows\nhostname: averyveryverylongname\n",
+		"windows packages":     "os: windows\npackages: [git]\n",
+		"windows ssh key":      "os: windows\nusers: [{name: a, passwordEnv: P, sshKeys: [k]}]\n",
+		"windows no password":  "os: windows\nusers: [{name: a}]\n",
+		"bad address":          "network: {address: 192.168.10.20}\n",
+		"ipv6 address":         "network: {address: 'fd00::1/64'}\n",
+		"bad dns":              "network: {address: 10.0.0.2/24, dns: [nope]}\n",
+		"bad password env var": "users: [{name: a, passwordEnv: 'A B'}]\n",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected config to be rejected", name)
+		}
+	}
+}
+
+func TestSeed_Linux(t *testing.T) {
+	t.Setenv("QUICKVM_TEST_PASSWORD", `s3cret"`)
+	cfg, err := Parse([]byte(linuxConfig))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	img, err := cfg.Seed()
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if img.VolumeID != CloudInitLabel || len(img.Files) != 3 {
+		t.Fatalf("Unexpected seed image %q with %d files", img.VolumeID, len(img.Files))
+	}
+
+	userData := string(img.Files[0].Data)
+	if !strings.HasPrefix(userData, "#cloud-config\n") {
+		t.Errorf("user-data must start with #cloud-config:\n%s", userData)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(img.Files[0].Data, &doc); err != nil {
+		t.Fatalf("user-data is not valid YAML: %v", err)
+	}
+	users := doc["users"].([]interface{})
+	if users[0] != "default" {
+		t.Errorf("Expected the default user to be kept, got %v", users[0])
+	}
+	alice := users[1].(map[string]interface{})
+	if alice["plain_text_passwd"] != `s3cret"` || alice["sudo"] != sudoAll || alice["lock_passwd"] != false {
+		t.Errorf("Unexpected user entry %v", alice)
+	}
+	if doc["package_update"] != true || len(doc["packages"].([]interface{})) != 2 || doc["hostname"] != "web01" {
+		t.Errorf("Unexpected user-data %v", doc)
+	}
+
+	if meta := string(img.Files[1].Data); !strings.Contains(meta, "instance-id: quickvm-") || !strings.Contains(meta, "local-hostname: web01") {
+		t.Errorf("Unexpected meta-data:\n%s", meta)
+	}
+	other, _ := cfg.MetaData()
+	if string(other) == string(img.Files[1].Data) {
+		t.Error("Expected a new instance ID for every seed")
+	}
+
+	network := string(img.Files[2].Data)
+	for _, want := range []string{"version: 2", "driver: hv_netvsc", "dhcp4: false", "192.168.10.20/24", "via: 192.168.10.1", "1.1.1.1"} {
+		if !strings.Contains(network, want) {
+			t.Errorf("Expected network-config to contain %q:\n%s", want, network)
+		}
+	}
+}
+
+func TestSeed_LinuxDHCPWithoutUsers(t *testing.T) {
+	cfg, err := Parse([]byte("hostname: db01\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := cfg.Seed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img.Files) != 2 {
+		t.Errorf("Expected no network-config for DHCP, got %d files", len(img.Files))
+	}
+	if strings.Contains(string(img.Files[0].Data), "users") {
+		t.Errorf("Expected no users section:\n%s", img.Files[0].Data)
+	}
+}
+
+func TestSeed_MissingPasswordEnv(t *testing.T) {
+	cfg, err := Parse([]byte("users: [{name: bob, passwordEnv: QUICKVM_TEST_UNSET_PASSWORD}]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Seed(); err == nil || !strings.Contains(err.Error(), "QUICKVM_TEST_UNSET_PASSWORD") {
+		t.Errorf("Expected an error naming the unset variable, got %v", err)
+	}
+}
+
+func TestSeed_Windows(t *testing.T) {
+	t.Setenv("QUICKVM_TEST_PASSWORD", "P@ss<word>&")
+	cfg, err := Parse([]byte(`
+os: windows
+hostname: WEB01
+timezone: W. Europe Standard Time
+users: [{name: admin, admin: true, passwordEnv: QUICKVM_TEST_PASSWORD}]
+network: {address: 10.0.0.5/24, gateway: 10.0.0.1, dns: [10.0.0.2, 10.0.0.3]}
+runCommands: ["powershell -Command Install-WindowsFeature Web-Server"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	img, err := cfg.Seed()
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if img.VolumeID != UnattendLabel || img.Files[0].Name != "autounattend.xml" {
+		t.Fatalf("Unexpected seed image %q %v", img.VolumeID, img.Files[0].Name)
+	}
+
+	answer := img.Files[0].Data
+	var parsed struct {
+		Settings []struct {
+			Pass string `xml:"pass,attr"`
+		} `xml:"settings"`
+	}
+	if err := xml.Unmarshal(answer, &parsed); err != nil {
+		t.Fatalf("Answer file is not well-formed XML: %v\n%s", err, answer)
+	}
+	if len(parsed.Settings) != 2 || parsed.Settings[0].Pass != "specialize" || parsed.Settings[1].Pass != "oobeSystem" {
+		t.Errorf("Unexpected passes %+v", parsed.Settings)
+	}
+
+	for _, want := range []string{
+		"<ComputerName>WEB01</ComputerName>",
+		"<Identifier>Ethernet</Identifier>",
+		"<NextHopAddress>10.0.0.1</NextHopAddress>",
+		`wcm:keyValue="2">10.0.0.3</IpAddress>`,
+		"<Value>P@ss&lt;word&gt;&amp;</Value>",
+		"<Group>Administrators</Group>",
+		"<Path>powershell -Command Install-WindowsFeature Web-Server</Path>",
+	} {
+		if !strings.Contains(string(answer), want) {
+			t.Errorf("Expected answer file to contain %q:\n%s", want, answer)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package provision
+
+import (
+	"time"
+
+	"quickvm/internal/iso"
+)
+
+// Volume labels the guests look for
+const (
+	CloudInitLabel = "cidata" // cloud-init NoCloud data source
+	UnattendLabel  = "UNATTEND"
+)
+
+// Seed builds the ISO image holding the first-boot configuration for the guest
+func (c *Config) Seed() (*iso.Image, error) {
+	img := &iso.Image{ModTime: time.Now()}
+
+	if c.GuestOS() == OSWindows {
+		answer, err := c.Unattend()
+		if err != nil {
+			return nil, err
+		}
+		// Setup reads autounattend.xml during installation; a generalized image reads
+		// unattend.xml from removable media on its first boot
+		img.VolumeID = UnattendLabel
+		img.Files = []iso.File{{Name: "autounattend.xml", Data: answer}, {Name: "unattend.xml", Data: answer}}
+		return img, nil
+	}
+
+	userData, err := c.UserData()
+	if err != nil {
+		return nil, err
+	}
+	metaData, err := c.MetaData()
+	if err != nil {
+		return nil, err
+	}
+	img.VolumeID = CloudInitLabel
+	img.Files = []iso.File{{Name: "user-data", Data: userData}, {Name: "meta-data", Data: metaData}}
+
+	networkData, err := c.NetworkConfig()
+	if err != nil {
+		return nil, err
+	}
+	if networkData != nil {
+		img.Files = append(img.Files, iso.File{Name: "network-config", Data: networkData})
+	}
+	return img, nil
+}
@@ -0,0 +1,157 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// defaultWindowsInterface is the name Windows gives the first network adapter
+const defaultWindowsInterface = "Ethernet"
+
+// unattendTemplate covers the specialize and oobeSystem passes, so the answer file works
+// both for a fresh installation and for a generalized (sysprepped) image
+var unattendTemplate = template.Must(template.New("unattend").Funcs(template.FuncMap{
+	"x":   xmlEscape,
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<?xml version="1.0" encoding="utf-8"?>
+<unattend xmlns="urn:schemas-microsoft-com:unattend" xmlns:wcm="http://schemas.microsoft.com/WMIConfig/2002/State">
+  <settings pass="specialize">
+    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+{{- if .Hostname}}
+      <ComputerName>{{x .Hostname}}</ComputerName>
+{{- end}}
+{{- if .Timezone}}
+      <TimeZone>{{x .Timezone}}</TimeZone>
+{{- end}}
+    </component>
+{{- with .Network}}
+    <component name="Microsoft-Windows-TCPIP" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <Interfaces>
+        <Interface wcm:action="add">
+          <Identifier>{{x .Interface}}</Identifier>
+          <Ipv4Settings>
+            <DhcpEnabled>false</DhcpEnabled>
+          </Ipv4Settings>
+          <UnicastIpAddresses>
+            <IpAddress wcm:action="add" wcm:keyValue="1">{{x .Address}}</IpAddress>
+          </UnicastIpAddresses>
+{{- if .Gateway}}
+          <Routes>
+            <Route wcm:action="add">
+              <Identifier>0</Identifier>
+              <Prefix>0.0.0.0/0</Prefix>
+              <NextHopAddress>{{x .Gateway}}</NextHopAddress>
+            </Route>
+          </Routes>
+{{- end}}
+        </Interface>
+      </Interfaces>
+    </component>
+{{- if .DNS}}
+    <component name="Microsoft-Windows-DNS-Client" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <Interfaces>
+        <Interface wcm:action="add">
+          <Identifier>{{x .Interface}}</Identifier>
+          <DNSServerSearchOrder>
+{{- range $i, $dns := .DNS}}
+            <IpAddress wcm:action="add" wcm:keyValue="{{inc $i}}">{{x $dns}}</IpAddress>
+{{- end}}
+          </DNSServerSearchOrder>
+        </Interface>
+      </Interfaces>
+    </component>
+{{- end}}
+{{- end}}
+{{- if .RunCommands}}
+    <component name="Microsoft-Windows-Deployment" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <RunSynchronous>
+{{- range $i, $cmd := .RunCommands}}
+        <RunSynchronousCommand wcm:action="add">
+          <Order>{{inc $i}}</Order>
+          <Path>{{x $cmd}}</Path>
+        </RunSynchronousCommand>
+{{- end}}
+      </RunSynchronous>
+    </component>
+{{- end}}
+  </settings>
+  <settings pass="oobeSystem">
+    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">
+      <OOBE>
+        <HideEULAPage>true</HideEULAPage>
+        <HideOnlineAccountScreens>true</HideOnlineAccountScreens>
+        <HideWirelessSetupInOOBE>true</HideWirelessSetupInOOBE>
+        <ProtectYourPC>3</ProtectYourPC>
+      </OOBE>
+{{- if .Users}}
+      <UserAccounts>
+        <LocalAccounts>
+{{- range .Users}}
+          <LocalAccount wcm:action="add">
+            <Name>{{x .Name}}</Name>
+            <Group>{{x .Group}}</Group>
+            <Password>
+              <Value>{{x .Password}}</Value>
+              <PlainText>true</PlainText>
+            </Password>
+          </LocalAccount>
+{{- end}}
+        </LocalAccounts>
+      </UserAccounts>
+{{- end}}
+    </component>
+  </settings>
+</unattend>
+`))
+
+type unattendUser struct {
+	Name     string
+	Group    string
+	Password string
+}
+
+type unattendData struct {
+	Hostname    string
+	Timezone    string
+	Network     *Network
+	RunCommands []string
+	Users       []unattendUser
+}
+
+// Unattend renders the Windows answer file
+func (c *Config) Unattend() ([]byte, error) {
+	data := unattendData{Hostname: c.Hostname, Timezone: c.Timezone, RunCommands: c.RunCommands}
+	if c.Network != nil {
+		network := *c.Network
+		if network.Interface == "" {
+			network.Interface = defaultWindowsInterface
+		}
+		data.Network = &network
+	}
+	for _, u := range c.Users {
+		password, err := u.password()
+		if err != nil {
+			return nil, err
+		}
+		group := "Users"
+		if u.Admin {
+			group = "Administrators"
+		}
+		data.Users = append(data.Users, unattendUser{Name: u.Name, Group: group, Password: password})
+	}
+
+	var buf bytes.Buffer
+	if err := unattendTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render answer file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package theme
+
+import "strings"
+
+// StateSymbol returns the symbol of a VM state, so states differ by shape and not only by colour
+func StateSymbol(state string) string {
+	switch strings.ToLower(state) {
+	case "running":
+		return "●"
+	case "off":
+		return "○"
+	case "paused":
+		return "◐"
+	case "saved":
+		return "◑"
+	case "missing":
+		return "✗"
+	}
+	return "◌" // Starting, stopping, saving and other transitional states
+}
+
+// Status is how healthy a VM state is, which decides its colour
+type Status int
+
+const (
+	StatusOK      Status = iota // Running
+	StatusWarning               // Paused, saved and transitional states
+	StatusError                 // Off or missing
+)
+
+// StateStatus returns the status of a VM state
+func StateStatus(state string) Status {
+	switch strings.ToLower(state) {
+	case "running":
+		return StatusOK
+	case "off", "missing":
+		return StatusError
+	}
+	return StatusWarning
+}
+
+// StateColor returns the colour of a VM state
+func (t Theme) StateColor(state string) string {
+	switch StateStatus(state) {
+	case StatusOK:
+		return t.Success
+	case StatusError:
+		return t.Error
+	}
+	return t.Warning
+}
+
+// State renders a VM state as its symbol and name, padded to width, in its colour for CLI output
+func (t Theme) State(state string, width int) string {
+	return Color(t.StateColor(state)).Sprintf("%s %-*s", StateSymbol(state), width, state)
+}
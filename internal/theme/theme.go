@@ -0,0 +1,212 @@
+// Package theme provides the colour themes of the TUI and the human-readable CLI output.
+// A theme is chosen with --theme or $QUICKVM_THEME; NO_COLOR always selects monochrome.
+package theme
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is the environment variable naming the theme when --theme is not given
+const EnvVar = "QUICKVM_THEME"
+
+// DefaultName is the theme used when none is chosen
+const DefaultName = "dark"
+
+// Monochrome is the theme without colours, forced by NO_COLOR
+const Monochrome = "monochrome"
+
+// Theme is a set of colours. A colour is an ANSI 256 colour number ("205") or a
+// hex colour ("#ff5f87"); an empty colour leaves the terminal default.
+type Theme struct {
+	Name                string `yaml:"name"`
+	Base                string `yaml:"base,omitempty"`      // Built-in theme providing the colours a user theme leaves empty
+	Accent              string `yaml:"accent"`              // Titles, headers and focused borders
+	Surface             string `yaml:"surface"`             // Background of titles and headers
+	Highlight           string `yaml:"highlight"`           // Text of the selected row and active tab
+	HighlightBackground string `yaml:"highlightBackground"` // Empty highlights with reverse video
+	Border              string `yaml:"border"`
+	Muted               string `yaml:"muted"`   // Help lines and secondary details
+	Success             string `yaml:"success"` // Running VMs, completed operations, low usage
+	Warning             string `yaml:"warning"` // Transitional states, dialogs, high usage
+	Error               string `yaml:"error"`   // Stopped VMs, failures, critical usage
+	Info                string `yaml:"info"`    // Sparklines, spinners and progress messages
+}
+
+// builtins are the themes shipped with quickvm
+var builtins = map[string]Theme{
+	"dark": {
+		Name: "dark", Accent: "205", Surface: "235", Highlight: "229", HighlightBackground: "57",
+		Border: "240", Muted: "241", Success: "46", Warning: "226", Error: "196", Info: "39",
+	},
+	"light": {
+		Name: "light", Accent: "125", Surface: "254", Highlight: "231", HighlightBackground: "25",
+		Border: "246", Muted: "243", Success: "28", Warning: "130", Error: "160", Info: "25",
+	},
+	// Okabe-Ito colours stay distinguishable with the common forms of colour blindness
+	"high-contrast": {
+		Name: "high-contrast", Accent: "#FFFFFF", Surface: "#000000", Highlight: "#000000", HighlightBackground: "#F0E442",
+		Border: "#FFFFFF", Muted: "250", Success: "#56B4E9", Warning: "#F0E442", Error: "#E69F00", Info: "#FFFFFF",
+	},
+	Monochrome: {Name: Monochrome},
+}
+
+var active = builtins[DefaultName]
+
+// Active returns the theme in use
+func Active() Theme {
+	return active
+}
+
+// Names returns the names of the built-in themes
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select activates the named theme; see Resolve
+func Select(name string) error {
+	t, err := Resolve(name)
+	if err != nil {
+		return err
+	}
+	active = t
+	return nil
+}
+
+// Resolve returns the named built-in or user theme. An empty name uses $QUICKVM_THEME,
+// then the dark theme; a non-empty NO_COLOR always gives the monochrome theme.
+func Resolve(name string) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return builtins[Monochrome], nil
+	}
+	if name == "" {
+		name = os.Getenv(EnvVar)
+	}
+	if name == "" {
+		name = DefaultName
+	}
+	if t, ok := builtins[name]; ok {
+		return t, nil
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return Theme{}, fmt.Errorf("invalid theme name: %s", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return Theme{}, err
+	}
+	return Load(filepath.Join(dir, name+".yaml"))
+}
+
+// Dir returns the directory of user themes, ~/.quickvm/themes
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".quickvm", "themes"), nil
+}
+
+// Load reads a user theme file
+func Load(path string) (Theme, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the user's home directory
+	if errors.Is(err, os.ErrNotExist) {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		return Theme{}, fmt.Errorf("unknown theme '%s' (built-in: %s; user themes go in %s)",
+			name, strings.Join(Names(), ", "), filepath.Dir(path))
+	}
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to read theme: %w", err)
+	}
+	t, err := Parse(data)
+	if err != nil {
+		return Theme{}, err
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), ".yaml")
+	}
+	return t, nil
+}
+
+// Parse parses a user theme; colours it leaves empty come from its base theme, dark by default
+func Parse(data []byte) (Theme, error) {
+	var t Theme
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&t); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+	if t.Base == "" {
+		t.Base = DefaultName
+	}
+	base, ok := builtins[t.Base]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown base theme '%s' (built-in: %s)", t.Base, strings.Join(Names(), ", "))
+	}
+
+	baseColors := base.colors()
+	for i, c := range t.colors() {
+		if *c == "" {
+			*c = *baseColors[i]
+			continue
+		}
+		if !validColor(*c) {
+			return Theme{}, fmt.Errorf("invalid colour '%s' in theme: use 0-255 or #rrggbb", *c)
+		}
+	}
+	return t, nil
+}
+
+// colors returns pointers to every colour of the theme, in declaration order
+func (t *Theme) colors() []*string {
+	return []*string{
+		&t.Accent, &t.Surface, &t.Highlight, &t.HighlightBackground, &t.Border,
+		&t.Muted, &t.Success, &t.Warning, &t.Error, &t.Info,
+	}
+}
+
+func validColor(c string) bool {
+	if n, err := strconv.Atoi(c); err == nil {
+		return n >= 0 && n <= 255
+	}
+	_, _, _, ok := parseHex(c)
+	return ok
+}
+
+func parseHex(c string) (r, g, b int, ok bool) {
+	if len(c) != 7 || c[0] != '#' {
+		return 0, 0, 0, false
+	}
+	v, err := strconv.ParseUint(c[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), true
+}
+
+// Color returns a fatih/color printer for a theme colour; an empty colour prints plain text
+func Color(c string) *color.Color {
+	if n, err := strconv.Atoi(c); err == nil {
+		return color.New(38, 5, color.Attribute(n))
+	}
+	if r, g, b, ok := parseHex(c); ok {
+		return color.RGB(r, g, b)
+	}
+	plain := color.New()
+	plain.DisableColor()
+	return plain
+}
@@ -0,0 +1,105 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve_BuiltinsAndEnvironment(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv(EnvVar, "light")
+
+	for _, name := range Names() {
+		theme, err := Resolve(name)
+		if err != nil || theme.Name != name {
+			t.Errorf("Resolve(%q) = %q, %v", name, theme.Name, err)
+		}
+	}
+	if theme, _ := Resolve(""); theme.Name != "light" {
+		t.Errorf("Expected $%s to choose the theme, got %q", EnvVar, theme.Name)
+	}
+	t.Setenv(EnvVar, "")
+	if theme, _ := Resolve(""); theme.Name != DefaultName {
+		t.Errorf("Expected the default theme, got %q", theme.Name)
+	}
+	if _, err := Resolve("../secrets"); err == nil {
+		t.Error("Expected a theme name with a path to be rejected")
+	}
+}
+
+func TestResolve_NoColorForcesMonochrome(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	theme, err := Resolve("high-contrast")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if theme.Name != Monochrome || theme.Success != "" || theme.Accent != "" {
+		t.Errorf("Expected the monochrome theme, got %+v", theme)
+	}
+}
+
+func TestResolve_UserTheme(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("NO_COLOR", "")
+	dir := filepath.Join(home, ".quickvm", "themes")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	data := "base: light\nsuccess: \"#00ff00\"\nerror: \"9\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "mine.yaml"), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := Resolve("mine")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	light := builtins["light"]
+	if theme.Name != "mine" || theme.Success != "#00ff00" || theme.Error != "9" || theme.Accent != light.Accent {
+		t.Errorf("Expected the user colours over the light theme, got %+v", theme)
+	}
+
+	_, err = Resolve("missing")
+	if err == nil || !strings.Contains(err.Error(), "unknown theme 'missing'") {
+		t.Errorf("Expected an unknown theme error, got %v", err)
+	}
+}
+
+func TestParse_RejectsInvalidThemes(t *testing.T) {
+	tests := map[string]string{
+		"colour":       "accent: pink\n",
+		"number":       "accent: \"300\"\n",
+		"base":         "base: solarized\n",
+		"unknown key":  "accnet: \"205\"\n",
+		"hex too long": "accent: \"#ff00ff00\"\n",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error for %q", name, data)
+		}
+	}
+}
+
+func TestStateSymbols_DifferByShape(t *testing.T) {
+	seen := make(map[string]string)
+	for _, state := range []string{"Running", "Off", "Paused", "Saved", "Missing", "Starting"} {
+		symbol := StateSymbol(state)
+		if other, ok := seen[symbol]; ok {
+			t.Errorf("%s and %s share the symbol %s", state, other, symbol)
+		}
+		seen[symbol] = state
+	}
+	if StateSymbol("running") != StateSymbol("Running") {
+		t.Error("Expected state symbols to ignore case")
+	}
+
+	dark := builtins["dark"]
+	if dark.StateColor("Running") != dark.Success || dark.StateColor("Off") != dark.Error || dark.StateColor("Stopping") != dark.Warning {
+		t.Error("Expected states to use the success, error and warning colours")
+	}
+}
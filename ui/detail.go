@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailTab is a tab of the detail pane
+type detailTab int
+
+const (
+	tabOverview detailTab = iota
+	tabSnapshots
+	tabDisks
+	tabNetwork
+	tabGPU
+	detailTabCount
+)
+
+var detailTabNames = [detailTabCount]string{"Overview", "Snapshots", "Disks", "Network", "GPU"}
+
+// detailPaneWidth is the width of the detail pane next to the table
+const detailPaneWidth = 60
+
+// detailLoadDelay is how long the cursor must rest on a VM before its details are loaded,
+// so scrolling through the list does not start PowerShell for every VM passed
+const detailLoadDelay = 150 * time.Millisecond
+
+// detailPane holds the details of the selected VM, loaded asynchronously per tab
+type detailPane struct {
+	vmName    string
+	tab       detailTab
+	cursor    int // Selected snapshot
+	snapshots []hyperv.Snapshot
+	disks     []hyperv.VMDisk
+	adapters  []hyperv.VMNetworkAdapter
+	gpu       *hyperv.VMGPUPartition
+	loaded    map[detailTab]bool
+	errs      map[detailTab]error
+
+	ctx    context.Context    // Scope of this VM's detail fetches
+	cancel context.CancelFunc // Cancels them once another VM is selected
+}
+
+// detailLoadMsg loads the details of a VM once the cursor rested on it for detailLoadDelay
+type detailLoadMsg struct{ vmName string }
+
+// detailMsg delivers one tab's data for a VM
+type detailMsg struct {
+	vmName string
+	tab    detailTab
+	data   interface{}
+	err    error
+}
+
+func newDetailPane(vmName string, tab detailTab) detailPane {
+	ctx, cancel := context.WithCancel(context.Background())
+	return detailPane{
+		vmName: vmName,
+		tab:    tab,
+		loaded: map[detailTab]bool{tabOverview: true},
+		errs:   make(map[detailTab]error),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// stop cancels the pane's fetches still in flight
+func (d *detailPane) stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
+
+// selectedVM returns the VM under the table cursor
+func (m Model) selectedVM() (hyperv.VM, bool) {
+	if len(m.vms) == 0 || m.table.Cursor() >= len(m.vms) {
+		return hyperv.VM{}, false
+	}
+	return m.vms[m.table.Cursor()], true
+}
+
+// syncDetail schedules loading the details when the selected VM changed and cancels
+// the fetches for the VM selected before
+func (m *Model) syncDetail() tea.Cmd {
+	vm, ok := m.selectedVM()
+	if !ok {
+		m.detail.stop()
+		m.detail = newDetailPane("", m.detail.tab)
+		return nil
+	}
+	if vm.Name == m.detail.vmName {
+		return nil
+	}
+	m.detail.stop()
+	m.detail = newDetailPane(vm.Name, m.detail.tab)
+	return tea.Tick(detailLoadDelay, func(time.Time) tea.Msg { return detailLoadMsg{vmName: vm.Name} })
+}
+
+// loadDetails loads every tab of the detail pane concurrently, within the pane's context
+func (m Model) loadDetails(vmName string) tea.Cmd {
+	ctx := m.detail.ctx
+	load := func(tab detailTab, fetch func(ctx context.Context) (interface{}, error)) tea.Cmd {
+		return func() tea.Msg {
+			data, err := fetch(ctx)
+			return detailMsg{vmName: vmName, tab: tab, data: data, err: err}
+		}
+	}
+	return tea.Batch(
+		load(tabSnapshots, func(ctx context.Context) (interface{}, error) {
+			return m.manager.GetSnapshotsByVMName(ctx, vmName)
+		}),
+		load(tabDisks, func(ctx context.Context) (interface{}, error) {
+			return m.manager.GetVMDisks(ctx, vmName)
+		}),
+		load(tabNetwork, func(ctx context.Context) (interface{}, error) {
+			return m.manager.GetVMNetworkAdapters(ctx, vmName)
+		}),
+		load(tabGPU, func(ctx context.Context) (interface{}, error) {
+			return m.manager.GetVMGPUPartition(ctx, vmName)
+		}),
+	)
+}
+
+// applyDetail stores loaded data if it is still for the selected VM
+func (d *detailPane) apply(msg detailMsg) {
+	if msg.vmName != d.vmName {
+		return
+	}
+	d.loaded[msg.tab] = true
+	d.errs[msg.tab] = msg.err
+	if msg.err != nil {
+		return
+	}
+	switch data := msg.data.(type) {
+	case []hyperv.Snapshot:
+		d.snapshots = data
+		if d.cursor >= len(data) {
+			d.cursor = max(len(data)-1, 0)
+		}
+	case []hyperv.VMDisk:
+		d.disks = data
+	case []hyperv.VMNetworkAdapter:
+		d.adapters = data
+	case *hyperv.VMGPUPartition:
+		d.gpu = data
+	}
+}
+
+// handleDetailKey handles keys while the detail pane has focus
+func (m Model) handleDetailKey(key string) (Model, tea.Cmd) {
+	switch key {
+	case "esc", "left", "h":
+		m.detailFocus = false
+	case "up", "k":
+		if m.detail.cursor > 0 {
+			m.detail.cursor--
+		}
+	case "down", "j":
+		if m.detail.cursor < len(m.detail.snapshots)-1 {
+			m.detail.cursor++
+		}
+	default:
+		return m.handleDetailAction(key)
+	}
+	return m, nil
+}
+
+// handleDetailAction opens the dialog for an action on the active tab
+func (m Model) handleDetailAction(key string) (Model, tea.Cmd) {
+	vmName := m.detail.vmName
+	if vmName == "" {
+		return m, nil
+	}
+
+	switch m.detail.tab {
+	case tabSnapshots:
+		switch key {
+		case "c":
+			m.dialog = newInputDialog("Name of the new snapshot of "+vmName, func(name string) tea.Cmd {
+				return m.detailAction(vmName, "Creating snapshot", fmt.Sprintf("Snapshot '%s' created", name), func(ctx context.Context) error {
+					return m.manager.CreateSnapshotByVMName(ctx, vmName, name)
+				})
+			})
+		case "enter":
+			if snapshot, ok := m.selectedSnapshot(); ok {
+				m.dialog = newConfirmDialog(fmt.Sprintf("Restore snapshot '%s' of %s? Its current state is lost.", snapshot, vmName),
+					m.detailAction(vmName, "Restoring snapshot", fmt.Sprintf("Snapshot '%s' restored", snapshot), func(ctx context.Context) error {
+						return m.manager.RestoreSnapshotByVMName(ctx, vmName, snapshot)
+					}))
+			}
+		case "x", "delete":
+			if snapshot, ok := m.selectedSnapshot(); ok {
+				m.dialog = newConfirmDialog(fmt.Sprintf("Delete snapshot '%s' of %s?", snapshot, vmName),
+					m.detailAction(vmName, "Deleting snapshot", fmt.Sprintf("Snapshot '%s' deleted", snapshot), func(ctx context.Context) error {
+						return m.manager.DeleteSnapshotByVMName(ctx, vmName, snapshot)
+					}))
+			}
+		}
+	case tabGPU:
+		switch key {
+		case "a":
+			m.dialog = newConfirmDialog(fmt.Sprintf("Add a GPU partition to %s? The VM must be off.", vmName),
+				m.detailAction(vmName, "Adding GPU", "GPU partition added", func(ctx context.Context) error {
+					return m.manager.AddGPUPartition(ctx, vmName, nil)
+				}))
+		case "x", "delete":
+			m.dialog = newConfirmDialog(fmt.Sprintf("Remove the GPU partition from %s? The VM must be off.", vmName),
+				m.detailAction(vmName, "Removing GPU", "GPU partition removed", func(ctx context.Context) error {
+					return m.manager.RemoveGPUPartition(ctx, vmName)
+				}))
+		}
+	}
+	return m, nil
+}
+
+func (m Model) selectedSnapshot() (string, bool) {
+	if m.detail.cursor >= len(m.detail.snapshots) {
+		return "", false
+	}
+	return m.detail.snapshots[m.detail.cursor].Name, true
+}
+
+// detailAction asks the model to run an action on a VM as a tracked operation
+func (m Model) detailAction(vmName, verb, success string, action func(ctx context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		return startOpMsg{target: vmName, verb: verb, success: success, run: action}
+	}
+}
+
+// viewDetail renders the detail pane for the selected VM
+func (m Model) viewDetail() string {
+	var tabs []string
+	for tab := detailTab(0); tab < detailTabCount; tab++ {
+		style := inactiveTabStyle
+		if tab == m.detail.tab {
+			style = activeTabStyle
+		}
+		tabs = append(tabs, style.Render(detailTabNames[tab]))
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+	b.WriteString("\n\n")
+
+	vm, ok := m.selectedVM()
+	switch {
+	case !ok:
+		b.WriteString(dimStyle.Render("No VM selected"))
+	case !m.detail.loaded[m.detail.tab]:
+		b.WriteString(dimStyle.Render("Loading..."))
+	case m.detail.errs[m.detail.tab] != nil:
+		b.WriteString(statusStoppedStyle.Render(fmt.Sprintf("Error: %v", m.detail.errs[m.detail.tab])))
+	default:
+		b.WriteString(m.viewDetailTab(vm))
+	}
+
+	if m.detailFocus {
+		b.WriteString("\n\n")
+		b.WriteString(dimStyle.Render(detailHelp(m.detail.tab)))
+	}
+
+	style := paneStyle
+	if m.detailFocus {
+		style = focusedPaneStyle
+	}
+	return style.Render(b.String())
+}
+
+func (m Model) viewDetailTab(vm hyperv.VM) string {
+	var lines []string
+	switch m.detail.tab {
+	case tabOverview:
+		lines = append(lines,
+			fmt.Sprintf("Name:     %s", vm.Name),
+			fmt.Sprintf("State:    %s", vm.State),
+			fmt.Sprintf("Status:   %s", vm.Status),
+			fmt.Sprintf("Uptime:   %s", vm.Uptime),
+			fmt.Sprintf("CPU:      %d%%", vm.CPUUsage),
+			fmt.Sprintf("Memory:   %d MB", vm.MemoryMB),
+			fmt.Sprintf("Version:  %s", vm.Version),
+			fmt.Sprintf("IPs:      %s", strings.Join(vm.IPAddresses, ", ")))
+	case tabSnapshots:
+		if len(m.detail.snapshots) == 0 {
+			lines = append(lines, dimStyle.Render("No snapshots"))
+		}
+		for i, s := range m.detail.snapshots {
+			line := fmt.Sprintf("%s  %s", s.CreationTime, s.Name)
+			if m.detailFocus && i == m.detail.cursor {
+				line = selectedStyle.Render(line)
+			}
+			lines = append(lines, line)
+		}
+	case tabDisks:
+		if len(m.detail.disks) == 0 {
+			lines = append(lines, dimStyle.Render("No virtual hard disks"))
+		}
+		for _, d := range m.detail.disks {
+			lines = append(lines, fmt.Sprintf("%s %s  %s  %s / %s",
+				d.ControllerType, d.ControllerSlot, d.VhdType, formatBytes(d.FileSize), formatBytes(d.Size)))
+			lines = append(lines, dimStyle.Render("  "+d.Path))
+			if d.ParentPath != "" {
+				lines = append(lines, dimStyle.Render("  parent: "+d.ParentPath))
+			}
+		}
+	case tabNetwork:
+		if len(m.detail.adapters) == 0 {
+			lines = append(lines, dimStyle.Render("No network adapters"))
+		}
+		for _, a := range m.detail.adapters {
+			lines = append(lines, fmt.Sprintf("%s  switch: %s  MAC: %s", a.Name, a.SwitchName, a.MacAddress))
+			if len(a.IPAddresses) > 0 {
+				lines = append(lines, dimStyle.Render("  "+strings.Join(a.IPAddresses, ", ")))
+			}
+		}
+	case tabGPU:
+		if m.detail.gpu != nil && m.detail.gpu.HasGPU {
+			lines = append(lines, fmt.Sprintf("GPU partitions: %d", m.detail.gpu.PartitionCount))
+		} else {
+			lines = append(lines, dimStyle.Render("No GPU partition"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detailHelp returns the key help for the active tab while the pane has focus
+func detailHelp(tab detailTab) string {
+	switch tab {
+	case tabSnapshots:
+		return "↑/↓: Select • c: Create • Enter: Restore • x: Delete • Esc: Back"
+	case tabGPU:
+		return "a: Add partition • x: Remove partition • Esc: Back"
+	default:
+		return "Tab: Next tab • Esc: Back"
+	}
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
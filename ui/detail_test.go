@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeExec records scripts and answers every call with the same output
+type fakeExec struct {
+	output  string
+	scripts []string
+}
+
+func (f *fakeExec) RunScript(_ context.Context, script string) ([]byte, error) {
+	f.scripts = append(f.scripts, script)
+	return []byte(f.output), nil
+}
+
+func (f *fakeExec) RunCmdlet(_ context.Context, cmdlet string, args ...string) ([]byte, error) {
+	f.scripts = append(f.scripts, cmdlet+" "+strings.Join(args, " "))
+	return []byte(f.output), nil
+}
+
+func newTestModel(exec *fakeExec, vms ...hyperv.VM) Model {
+	m := NewModel()
+	m.manager = &hyperv.Manager{Exec: exec}
+	m.prefs, m.prefsPath = preferences{}, ""
+	m.keys, _ = newKeymap(nil)
+	updated, _ := m.Update(vmListMsg(vms))
+	return updated.(Model)
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestDetail_LoadsSelectedVMAndIgnoresStaleResults(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Name: "Web01"}, hyperv.VM{Name: "Db01"})
+	if m.detail.vmName != "Web01" {
+		t.Fatalf("Expected details of the selected VM, got %q", m.detail.vmName)
+	}
+
+	updated, _ := m.Update(detailMsg{vmName: "Db01", tab: tabSnapshots, data: []hyperv.Snapshot{{Name: "old"}}})
+	m = updated.(Model)
+	if m.detail.loaded[tabSnapshots] {
+		t.Error("Expected results for another VM to be ignored")
+	}
+
+	updated, _ = m.Update(detailMsg{vmName: "Web01", tab: tabSnapshots, data: []hyperv.Snapshot{{Name: "clean"}}})
+	m = updated.(Model)
+	if !m.detail.loaded[tabSnapshots] || len(m.detail.snapshots) != 1 {
+		t.Fatalf("Expected snapshots to be loaded, got %+v", m.detail)
+	}
+
+	m.detail.tab = tabSnapshots
+	if view := m.View(); !strings.Contains(view, "clean") {
+		t.Errorf("Expected the snapshot in the view, got:\n%s", view)
+	}
+}
+
+func TestDetail_ScrollingCancelsAndDebouncesLoads(t *testing.T) {
+	exec := &fakeExec{}
+	m := newTestModel(exec, hyperv.VM{Name: "Web01"}, hyperv.VM{Name: "Db01"}, hyperv.VM{Name: "App01"})
+	first := m.detail.ctx
+
+	for range 2 {
+		updated, _ := m.Update(key("down"))
+		m = updated.(Model)
+	}
+	if m.detail.vmName != "App01" {
+		t.Fatalf("Expected App01 to be selected, got %q", m.detail.vmName)
+	}
+	if first.Err() == nil {
+		t.Error("Expected the fetches for the VM scrolled past to be cancelled")
+	}
+
+	// Loads scheduled for VMs the cursor passed start nothing
+	if _, cmd := m.Update(detailLoadMsg{vmName: "Web01"}); cmd != nil {
+		t.Error("Expected no load for a VM that is no longer selected")
+	}
+	_, cmd := m.Update(detailLoadMsg{vmName: "App01"})
+	if cmd == nil {
+		t.Fatal("Expected the selected VM's details to load")
+	}
+	for _, load := range cmd().(tea.BatchMsg) {
+		load()
+	}
+	if len(exec.scripts) != int(detailTabCount)-1 {
+		t.Errorf("Expected one fetch per tab, got %d: %v", len(exec.scripts), exec.scripts)
+	}
+}
+
+func TestDetail_TabCycles(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Name: "Web01"})
+	for i := 0; i < int(detailTabCount); i++ {
+		updated, _ := m.Update(key("tab"))
+		m = updated.(Model)
+	}
+	if m.detail.tab != tabOverview {
+		t.Errorf("Expected tabs to wrap around, got %d", m.detail.tab)
+	}
+}
+
+func TestDetail_DeleteSnapshotAsksForConfirmation(t *testing.T) {
+	exec := &fakeExec{}
+	m := newTestModel(exec, hyperv.VM{Name: "Web01"})
+	m.detail.tab = tabSnapshots
+	m.detail.apply(detailMsg{vmName: "Web01", tab: tabSnapshots, data: []hyperv.Snapshot{{Name: "clean"}}})
+
+	updated, _ := m.Update(key("right"))
+	updated, _ = updated.Update(key("x"))
+	m = updated.(Model)
+	if m.dialog == nil {
+		t.Fatal("Expected a confirmation dialog")
+	}
+
+	// Cancelling runs nothing
+	updated, cmd := m.Update(key("n"))
+	if updated.(Model).dialog != nil || cmd != nil {
+		t.Fatal("Expected the dialog to close without an action")
+	}
+
+	updated, cmd = m.Update(key("y"))
+	if updated.(Model).dialog != nil || cmd == nil {
+		t.Fatal("Expected confirming to close the dialog and run the action")
+	}
+	if m = runOperation(t, m, cmd); m.message != "Snapshot 'clean' deleted" {
+		t.Fatalf("Expected a successful delete, got %q", m.message)
+	}
+	if len(exec.scripts) == 0 || !strings.Contains(exec.scripts[len(exec.scripts)-1], "clean") {
+		t.Errorf("Expected the snapshot to be deleted, got %v", exec.scripts)
+	}
+}
+
+func TestDetail_CreateSnapshotPromptsForName(t *testing.T) {
+	exec := &fakeExec{}
+	m := newTestModel(exec, hyperv.VM{Name: "Web01"})
+	m.detail.tab = tabSnapshots
+	m.detailFocus = true
+
+	updated, _ := m.Update(key("c"))
+	for _, r := range []string{"b", "a", "s", "e", "x"} {
+		updated, _ = updated.Update(key(r))
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	updated, cmd := updated.Update(key("enter"))
+	if updated.(Model).dialog != nil || cmd == nil {
+		t.Fatal("Expected enter to submit the prompt")
+	}
+	if m = runOperation(t, updated.(Model), cmd); m.message != "Snapshot 'base' created" {
+		t.Errorf("Unexpected result: %q", m.message)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{512: "512 B", 2048: "2.0 KiB", 10 * 1024 * 1024 * 1024: "10.0 GiB"}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
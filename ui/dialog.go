@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dialog asks for a confirmation or a line of text before an action runs
+type dialog struct {
+	prompt string
+	input  bool    // Ask for text instead of yes/no
+	value  []rune  // Text entered so far
+	onYes  tea.Cmd // Confirmation: command run on yes
+	submit func(string) tea.Cmd
+}
+
+func newConfirmDialog(prompt string, onYes tea.Cmd) *dialog {
+	return &dialog{prompt: prompt, onYes: onYes}
+}
+
+func newInputDialog(prompt string, submit func(string) tea.Cmd) *dialog {
+	return &dialog{prompt: prompt, input: true, submit: submit}
+}
+
+// handleKey processes a key; done reports whether the dialog is closed and cmd is the
+// action to run, if any
+func (d *dialog) handleKey(msg tea.KeyMsg) (done bool, cmd tea.Cmd) {
+	if d.input {
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyCtrlC:
+			return true, nil
+		case tea.KeyEnter:
+			value := strings.TrimSpace(string(d.value))
+			if value == "" {
+				return false, nil
+			}
+			return true, d.submit(value)
+		case tea.KeyBackspace:
+			if len(d.value) > 0 {
+				d.value = d.value[:len(d.value)-1]
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			d.value = append(d.value, msg.Runes...)
+		}
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "y", "Y", "enter":
+		return true, d.onYes
+	case "n", "N", "esc", "ctrl+c":
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *dialog) View() string {
+	if d.input {
+		return dialogStyle.Render(d.prompt + "\n> " + string(d.value) + "█\n" + dimStyle.Render("Enter: OK • Esc: Cancel"))
+	}
+	return dialogStyle.Render(d.prompt + "\n" + dimStyle.Render("y: Yes • n: No"))
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sortColumns are the column titles the table can be sorted by, in the order 'o' cycles through them
+var sortColumns = []string{"Index", "Name", "State", "IP Address", "CPU%", "Memory(MB)", "Uptime", "Status"}
+
+// fuzzyMatch reports whether the characters of pattern appear in order in s, ignoring case and spaces
+func fuzzyMatch(pattern, s string) bool {
+	target := []rune(strings.ToLower(s))
+	i := 0
+	for _, r := range strings.ToLower(pattern) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		for i < len(target) && target[i] != r {
+			i++
+		}
+		if i == len(target) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// filterVMs keeps the VMs whose name, state or one of their IPs fuzzy-matches pattern
+func filterVMs(vms []hyperv.VM, pattern string) []hyperv.VM {
+	if strings.TrimSpace(pattern) == "" {
+		return vms
+	}
+	filtered := make([]hyperv.VM, 0, len(vms))
+	for _, vm := range vms {
+		fields := append([]string{vm.Name, vm.State}, vm.IPAddresses...)
+		if slices.ContainsFunc(fields, func(field string) bool { return fuzzyMatch(pattern, field) }) {
+			filtered = append(filtered, vm)
+		}
+	}
+	return filtered
+}
+
+// sortVMs returns a copy of vms sorted by a column title; an unknown column keeps Hyper-V order
+func sortVMs(vms []hyperv.VM, column string, desc bool) []hyperv.VM {
+	sorted := slices.Clone(vms)
+	compare := vmComparer(column)
+	if compare == nil {
+		return sorted
+	}
+	slices.SortStableFunc(sorted, func(a, b hyperv.VM) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return sorted
+}
+
+func vmComparer(column string) func(a, b hyperv.VM) int {
+	switch column {
+	case "Index":
+		return func(a, b hyperv.VM) int { return cmp.Compare(a.Index, b.Index) }
+	case "Name":
+		return func(a, b hyperv.VM) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) }
+	case "State":
+		return func(a, b hyperv.VM) int { return strings.Compare(a.State, b.State) }
+	case "IP Address":
+		return func(a, b hyperv.VM) int { return strings.Compare(firstIP(a), firstIP(b)) }
+	case "CPU%":
+		return func(a, b hyperv.VM) int { return cmp.Compare(a.CPUUsage, b.CPUUsage) }
+	case "Memory(MB)":
+		return func(a, b hyperv.VM) int { return cmp.Compare(a.MemoryMB, b.MemoryMB) }
+	case "Uptime":
+		return func(a, b hyperv.VM) int {
+			return cmp.Compare(hyperv.ParseUptime(a.Uptime), hyperv.ParseUptime(b.Uptime))
+		}
+	case "Status":
+		return func(a, b hyperv.VM) int { return strings.Compare(a.Status, b.Status) }
+	}
+	return nil
+}
+
+// nextSortColumn returns the column after current, or "" (Hyper-V order) after the last one
+func nextSortColumn(current string) string {
+	i := slices.Index(sortColumns, current)
+	if i == len(sortColumns)-1 {
+		return ""
+	}
+	return sortColumns[i+1]
+}
+
+func firstIP(vm hyperv.VM) string {
+	if len(vm.IPAddresses) == 0 {
+		return ""
+	}
+	return vm.IPAddresses[0]
+}
+
+// applyView filters and sorts the loaded VMs into the table, keeping the cursor on the same VM
+func (m *Model) applyView() {
+	current := m.detail.vmName
+	m.vms = sortVMs(filterVMs(m.all, m.prefs.Filter), m.prefs.SortBy, m.prefs.SortDesc)
+
+	loaded := make(map[string]bool, len(m.all))
+	for _, vm := range m.all {
+		loaded[vm.Name] = true
+	}
+	for name := range m.selected {
+		if !loaded[name] {
+			delete(m.selected, name)
+		}
+	}
+
+	m.updateColumns()
+	m.updateTable()
+
+	cursor := slices.IndexFunc(m.vms, func(vm hyperv.VM) bool { return vm.Name == current })
+	if cursor < 0 {
+		cursor = min(m.table.Cursor(), max(len(m.vms)-1, 0))
+	}
+	m.table.SetCursor(cursor)
+}
+
+// updateColumns marks the sort column and direction in the table header
+func (m *Model) updateColumns() {
+	columns := tableColumns()
+	for i, column := range columns {
+		if column.Title != m.prefs.SortBy {
+			continue
+		}
+		if m.prefs.SortDesc {
+			columns[i].Title += " ▼"
+		} else {
+			columns[i].Title += " ▲"
+		}
+	}
+	m.table.SetColumns(columns)
+}
+
+// savePreferences applies the sort and filter to the table and saves them for the next launch
+func (m Model) savePreferences() Model {
+	m.applyView()
+	if err := m.prefs.save(m.prefsPath); err != nil {
+		m.err = err
+		m.message = fmt.Sprintf("Error: %v", err)
+	}
+	return m
+}
+
+// toggleSelected marks or unmarks the VM under the cursor and moves to the next row
+func (m *Model) toggleSelected() {
+	vm, ok := m.selectedVM()
+	if !ok {
+		return
+	}
+	if m.selected[vm.Name] {
+		delete(m.selected, vm.Name)
+	} else {
+		m.selected[vm.Name] = true
+	}
+	m.updateTable()
+	m.table.MoveDown(1)
+}
+
+// handleFilterKey edits the filter; the table updates as you type
+func (m Model) handleFilterKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+		m = m.savePreferences()
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+	case tea.KeyEsc:
+		m.filtering = false
+		m.prefs.Filter = ""
+		m = m.savePreferences()
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+	case tea.KeyBackspace:
+		if filter := []rune(m.prefs.Filter); len(filter) > 0 {
+			m.prefs.Filter = string(filter[:len(filter)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.prefs.Filter += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.applyView()
+	detailCmd := m.syncDetail()
+	return m, detailCmd
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"web", "Web01", true},
+		{"wb1", "Web01", true},
+		{"10.0 5", "10.0.0.5", true},
+		{"bew", "Web01", false},
+		{"", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFilterVMs_MatchesNameStateAndIP(t *testing.T) {
+	vms := []hyperv.VM{
+		{Name: "Web01", State: "Running", IPAddresses: []string{"10.0.0.5"}},
+		{Name: "Db01", State: "Off"},
+	}
+	for pattern, want := range map[string]string{"web": "Web01", "off": "Db01", "10.0.0.5": "Web01"} {
+		got := filterVMs(vms, pattern)
+		if len(got) != 1 || got[0].Name != want {
+			t.Errorf("filterVMs(%q) = %v, want %s", pattern, got, want)
+		}
+	}
+}
+
+func TestSortVMs(t *testing.T) {
+	vms := []hyperv.VM{
+		{Name: "b", CPUUsage: 10, Uptime: "1.00:00:00"},
+		{Name: "a", CPUUsage: 50, Uptime: "02:00:00"},
+		{Name: "c", CPUUsage: 30, Uptime: "00:00:00"},
+	}
+	names := func(vms []hyperv.VM) string {
+		var s []string
+		for _, vm := range vms {
+			s = append(s, vm.Name)
+		}
+		return strings.Join(s, ",")
+	}
+
+	if got := names(sortVMs(vms, "CPU%", true)); got != "a,c,b" {
+		t.Errorf("CPU descending = %s", got)
+	}
+	if got := names(sortVMs(vms, "Uptime", false)); got != "c,a,b" {
+		t.Errorf("Uptime ascending = %s", got)
+	}
+	if got := names(sortVMs(vms, "", false)); got != "b,a,c" {
+		t.Errorf("Expected Hyper-V order without a sort column, got %s", got)
+	}
+}
+
+func TestModel_FilterAsYouTypeAndPersist(t *testing.T) {
+	m := newTestModel(&fakeExec{},
+		hyperv.VM{Name: "Web01", State: "Running"}, hyperv.VM{Name: "Db01", State: "Off"})
+	m.prefsPath = filepath.Join(t.TempDir(), "tui.yaml")
+
+	updated, _ := m.Update(key("/"))
+	for _, r := range "db" {
+		updated, _ = updated.Update(key(string(r)))
+	}
+	m = updated.(Model)
+	if len(m.vms) != 1 || m.vms[0].Name != "Db01" || m.detail.vmName != "Db01" {
+		t.Fatalf("Expected only Db01 to be shown and selected, got %v", m.vms)
+	}
+
+	updated, _ = m.Update(key("enter"))
+	updated, _ = updated.Update(key("o"))
+	m = updated.(Model)
+	if m.filtering {
+		t.Error("Expected enter to finish editing the filter")
+	}
+	if prefs := loadPreferences(m.prefsPath); prefs.Filter != "db" || prefs.SortBy != "Index" {
+		t.Errorf("Expected filter and sort to be saved, got %+v", prefs)
+	}
+
+	// Esc clears the filter before quitting
+	updated, _ = m.Update(key("esc"))
+	if m = updated.(Model); len(m.vms) != 2 || m.prefs.Filter != "" {
+		t.Errorf("Expected esc to clear the filter, got %v", m.vms)
+	}
+}
+
+func TestModel_BatchActionOnSelectedVMs(t *testing.T) {
+	exec := &fakeExec{output: "[]"}
+	m := newTestModel(exec,
+		hyperv.VM{Index: 1, Name: "Web01"}, hyperv.VM{Index: 2, Name: "Web02"}, hyperv.VM{Index: 3, Name: "Db01"})
+
+	updated, _ := m.Update(key(" "))
+	updated, _ = updated.Update(key(" "))
+	m = updated.(Model)
+	if len(m.selected) != 2 || !m.selected["Web01"] || !m.selected["Web02"] {
+		t.Fatalf("Expected the first two VMs to be selected, got %v", m.selected)
+	}
+
+	updated, cmd := m.Update(key("x"))
+	m = updated.(Model)
+	if len(m.selected) != 0 || m.message != "Stopping 2 VMs..." {
+		t.Errorf("Expected the selection to be cleared, got %v (%s)", m.selected, m.message)
+	}
+	for _, c := range cmd().(tea.BatchMsg) {
+		findMsg[opDoneMsg](c)
+	}
+	if len(exec.scripts) != 2 {
+		t.Errorf("Expected one stop per selected VM, got %v", exec.scripts)
+	}
+}
+
+func TestPreferences_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "tui.yaml")
+	want := preferences{SortBy: "CPU%", SortDesc: true, Filter: "web"}
+	if err := want.save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if got := loadPreferences(path); got != want {
+		t.Errorf("loadPreferences = %+v, want %+v", got, want)
+	}
+	if got := loadPreferences(filepath.Join(t.TempDir(), "missing.yaml")); got != (preferences{}) {
+		t.Errorf("Expected defaults for a missing file, got %+v", got)
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+const (
+	// historySize is the number of refreshes kept per VM for sparklines
+	historySize = 20
+	// flashDuration is how long a state change stays highlighted
+	flashDuration = 4 * time.Second
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// vmHistory holds recent samples for one VM
+type vmHistory struct {
+	cpu       []int
+	memoryMB  []int64
+	state     string
+	changedAt time.Time // Last state transition; zero until the state changes
+}
+
+// history tracks per-VM samples across refreshes, keyed by VM name
+type history map[string]*vmHistory
+
+// record adds a sample for every VM, notes state transitions and forgets VMs that are gone
+func (h history) record(vms []hyperv.VM, now time.Time) {
+	seen := make(map[string]bool, len(vms))
+	for _, vm := range vms {
+		seen[vm.Name] = true
+		entry, ok := h[vm.Name]
+		if !ok {
+			entry = &vmHistory{state: vm.State}
+			h[vm.Name] = entry
+		}
+		if entry.state != vm.State {
+			entry.state = vm.State
+			entry.changedAt = now
+		}
+		entry.cpu = appendLimited(entry.cpu, vm.CPUUsage)
+		entry.memoryMB = appendLimited(entry.memoryMB, vm.MemoryMB)
+	}
+	for name := range h {
+		if !seen[name] {
+			delete(h, name)
+		}
+	}
+}
+
+// flashing reports whether the VM's state changed within flashDuration of now
+func (h history) flashing(name string, now time.Time) bool {
+	entry, ok := h[name]
+	return ok && !entry.changedAt.IsZero() && now.Sub(entry.changedAt) < flashDuration
+}
+
+func appendLimited[T int | int64](values []T, v T) []T {
+	values = append(values, v)
+	if len(values) > historySize {
+		values = values[len(values)-historySize:]
+	}
+	return values
+}
+
+// sparkline renders values as block characters scaled to ceiling; a ceiling of 0 scales to the
+// largest value. The result is padded to width so table columns line up.
+func sparkline[T int | int64](values []T, ceiling T, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if ceiling <= 0 {
+		for _, v := range values {
+			if v > ceiling {
+				ceiling = v
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := len(values); i < width; i++ {
+		b.WriteRune(' ')
+	}
+	for _, v := range values {
+		level := 0
+		if ceiling > 0 && v > 0 {
+			level = int(int64(v) * int64(len(sparkRunes)-1) / int64(ceiling))
+			if level >= len(sparkRunes) {
+				level = len(sparkRunes) - 1
+			}
+		}
+		b.WriteRune(sparkRunes[level])
+	}
+	return b.String()
+}
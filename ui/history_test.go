@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 50, 100}, 100, 5); got != "  ▁▄█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline([]int64{1024, 2048}, 0, 2); got != "▄█" {
+		t.Errorf("Expected scaling to the largest value, got %q", got)
+	}
+	long := make([]int, 30)
+	if got := []rune(sparkline(long, 100, 10)); len(got) != 10 {
+		t.Errorf("Expected the sparkline to be cut to its width, got %d runes", len(got))
+	}
+}
+
+func TestHistory_RecordsSamplesAndStateChanges(t *testing.T) {
+	h := make(history)
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < historySize+5; i++ {
+		h.record([]hyperv.VM{{Name: "Web01", State: "Off", CPUUsage: i}, {Name: "Gone", State: "Off"}}, start)
+	}
+	if len(h["Web01"].cpu) != historySize || h["Web01"].cpu[historySize-1] != historySize+4 {
+		t.Errorf("Expected the last %d samples, got %v", historySize, h["Web01"].cpu)
+	}
+	if h.flashing("Web01", start) {
+		t.Error("Expected no flash without a state change")
+	}
+
+	changedAt := start.Add(time.Minute)
+	h.record([]hyperv.VM{{Name: "Web01", State: "Running"}}, changedAt)
+	if !h.flashing("Web01", changedAt.Add(time.Second)) || h.flashing("Web01", changedAt.Add(flashDuration)) {
+		t.Error("Expected the state change to flash for flashDuration")
+	}
+	if _, ok := h["Gone"]; ok {
+		t.Error("Expected VMs that disappeared to be forgotten")
+	}
+}
+
+func TestModel_TickRefreshesWithoutOverlap(t *testing.T) {
+	m := NewModel().WithRefreshInterval(time.Second)
+	updated, cmd := m.Update(tickMsg(time.Now()))
+	if cmd == nil || !updated.(Model).loading {
+		t.Fatal("Expected a tick to start a reload")
+	}
+
+	_, cmd = updated.Update(tickMsg(time.Now()))
+	if cmd == nil {
+		t.Fatal("Expected the next tick to be scheduled while a reload is in flight")
+	}
+
+	updated, _ = updated.Update(vmListMsg{{Name: "Web01", State: "Running", CPUUsage: 40}})
+	model := updated.(Model)
+	if model.loading || len(model.history["Web01"].cpu) != 1 {
+		t.Errorf("Expected the reload to finish and record a sample, got %+v", model.history["Web01"])
+	}
+	if !strings.Contains(model.View(), "auto every 1s") {
+		t.Error("Expected the help line to show the refresh interval")
+	}
+
+	if NewModel().WithRefreshInterval(0).tick() != nil {
+		t.Error("Expected no ticks when automatic refresh is disabled")
+	}
+}
+
+func TestModel_AnnouncesVMEvents(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Name: "Web01", State: "Off"}, hyperv.VM{Name: "Db01", State: "Off"})
+	if m.message != "" {
+		t.Errorf("Expected the first load not to announce events, got %q", m.message)
+	}
+
+	updated, _ := m.Update(vmListMsg{{Name: "Web01", State: "Running"}, {Name: "Db01", State: "Starting"}})
+	model := updated.(Model)
+	if model.message != "⚡ Db01: Off → Starting (+1 more)" {
+		t.Errorf("Unexpected status %q", model.message)
+	}
+
+	model.message = "Stopping VM: Web01..."
+	updated, _ = model.Update(vmListMsg{{Name: "Web01", State: "Stopping"}, {Name: "Db01", State: "Running"}})
+	if got := updated.(Model).message; got != "Stopping VM: Web01..." {
+		t.Errorf("Expected an operation message to be kept, got %q", got)
+	}
+}
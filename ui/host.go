@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gaugeWidth is the width of the bar of a host gauge
+const gaugeWidth = 30
+
+// hostMsg delivers host information
+type hostMsg struct {
+	info *hyperv.SystemInfo
+	err  error
+}
+
+func (m Model) loadHost() tea.Msg {
+	info, err := m.manager.GetSystemInfo(context.TODO(), true)
+	return hostMsg{info: info, err: err}
+}
+
+// handleHostKey handles keys on the host screen
+func (m Model) handleHostKey(msg tea.KeyMsg, act action) (Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.screen = screenVMs
+		return m, nil
+	}
+	switch act {
+	case actionQuit:
+		return m, tea.Quit
+	case actionRefresh:
+		if !m.hostLoading {
+			m.hostLoading = true
+			return m, tea.Batch(m.loadHost, m.loadVMs)
+		}
+	case actionPalette:
+		return m.openPalette(), nil
+	case actionOperations:
+		m.showOps = !m.showOps
+	}
+	return m, nil
+}
+
+// gauge renders a usage bar coloured by how full it is
+func gauge(percent float64) string {
+	percent = math.Max(0, math.Min(100, percent))
+	filled := int(math.Round(percent / 100 * gaugeWidth))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", gaugeWidth-filled)
+
+	style := statusRunningStyle
+	switch {
+	case percent >= 90:
+		style = statusStoppedStyle
+	case percent >= 70:
+		style = statusOtherStyle
+	}
+	return style.Render(bar) + fmt.Sprintf(" %3.0f%%", percent)
+}
+
+func percentOf(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// viewHost renders CPU, memory and disk gauges, memory committed to running VMs and Hyper-V status
+func (m Model) viewHost() string {
+	if m.host == nil {
+		if m.hostErr != nil {
+			return baseStyle.Render(statusStoppedStyle.Render(fmt.Sprintf("Error: %v", m.hostErr)))
+		}
+		return baseStyle.Render(dimStyle.Render("Loading host information..."))
+	}
+	info := m.host
+	label := lipgloss.NewStyle().Width(10).Bold(true)
+
+	var committed int64
+	running := 0
+	for _, vm := range m.all {
+		if strings.EqualFold(vm.State, "running") {
+			committed += vm.MemoryMB
+			running++
+		}
+	}
+
+	lines := []string{
+		label.Render("CPU") + gauge(float64(info.CPU.LoadPercent)) + dimStyle.Render(fmt.Sprintf("  %s (%d cores)", info.CPU.Name, info.CPU.Cores)),
+		label.Render("Memory") + gauge(percentOf(info.Memory.UsedMB, info.Memory.TotalMB)) +
+			dimStyle.Render(fmt.Sprintf("  %.1f / %.1f GB used", info.Memory.UsedGB, info.Memory.TotalGB)),
+		label.Render("VMs") + gauge(percentOf(committed, info.Memory.TotalMB)) +
+			dimStyle.Render(fmt.Sprintf("  %d MB committed to %d running VMs", committed, running)),
+	}
+	for _, disk := range info.Disks {
+		lines = append(lines, label.Render("Disk "+disk.Name)+gauge(percentOf(disk.UsedMB, disk.TotalMB))+
+			dimStyle.Render(fmt.Sprintf("  %.1f / %.1f GB used", disk.UsedGB, disk.TotalGB)))
+	}
+
+	hyperV := statusStoppedStyle.Render("✗ Disabled")
+	if info.HyperV.Enabled {
+		hyperV = statusRunningStyle.Render("✓ Enabled")
+	}
+	if info.HyperV.Status != "" {
+		hyperV += dimStyle.Render("  " + info.HyperV.Status)
+	}
+	lines = append(lines, "", label.Render("Hyper-V")+hyperV)
+
+	if m.hostErr != nil {
+		lines = append(lines, statusStoppedStyle.Render(fmt.Sprintf("Last refresh failed: %v", m.hostErr)))
+	}
+	return baseStyle.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// action is something a key can be bound to in the VM table
+type action string
+
+const (
+	actionStart       action = "start"
+	actionStop        action = "stop"
+	actionRestart     action = "restart"
+	actionConsole     action = "console"
+	actionRefresh     action = "refresh"
+	actionSelect      action = "select"
+	actionFilter      action = "filter"
+	actionSort        action = "sort"
+	actionReverseSort action = "reverse-sort"
+	actionCancel      action = "cancel"
+	actionOperations  action = "operations"
+	actionDetails     action = "details"
+	actionNextTab     action = "next-tab"
+	actionPrevTab     action = "prev-tab"
+	actionPalette     action = "palette"
+	actionVMsScreen   action = "vms-screen"
+	actionWsScreen    action = "workspaces-screen"
+	actionHostScreen  action = "host-screen"
+	actionQuit        action = "quit"
+)
+
+// actionLabels lists every action in help line order with its label
+var actionLabels = []struct {
+	action action
+	label  string
+}{
+	{actionStart, "Start"},
+	{actionStop, "Stop"},
+	{actionRestart, "Restart"},
+	{actionConsole, "Console"},
+	{actionRefresh, "Refresh"},
+	{actionSelect, "Select"},
+	{actionFilter, "Filter"},
+	{actionSort, "Sort"},
+	{actionReverseSort, "Reverse sort"},
+	{actionCancel, "Cancel"},
+	{actionOperations, "Operations"},
+	{actionDetails, "Details"},
+	{actionNextTab, "Next tab"},
+	{actionPrevTab, "Previous tab"},
+	{actionPalette, "Commands"},
+	{actionVMsScreen, "VMs"},
+	{actionWsScreen, "Workspaces"},
+	{actionHostScreen, "Host"},
+	{actionQuit, "Quit"},
+}
+
+// defaultBindings are the keys of each action unless ~/.quickvm/keys.yaml remaps them
+var defaultBindings = map[action][]string{
+	actionStart:       {"s"},
+	actionStop:        {"x"},
+	actionRestart:     {"t"},
+	actionConsole:     {"v"},
+	actionRefresh:     {"r"},
+	actionSelect:      {" "},
+	actionFilter:      {"/"},
+	actionSort:        {"o"},
+	actionReverseSort: {"O"},
+	actionCancel:      {"c"},
+	actionOperations:  {"a"},
+	actionDetails:     {"enter", "right", "l"},
+	actionNextTab:     {"tab"},
+	actionPrevTab:     {"shift+tab"},
+	actionPalette:     {":"},
+	actionVMsScreen:   {"1"},
+	actionWsScreen:    {"2"},
+	actionHostScreen:  {"3"},
+	actionQuit:        {"q"},
+}
+
+// keyNames maps key names accepted in keys.yaml to the names Bubble Tea reports
+var keyNames = map[string]string{"space": " ", "colon": ":"}
+
+// keymap binds keys to actions
+type keymap struct {
+	keys    map[action][]string
+	actions map[string]action
+}
+
+// keyBindings is the keys.yaml format: an action name with one key or a list of keys
+type keyBindings map[string]keyList
+
+type keyList []string
+
+func (l *keyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = keyList{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*l = keys
+	return nil
+}
+
+// keymapPath returns the path of the user keymap file, or "" if there is no home directory
+func keymapPath() string {
+	path, err := configPath("keys.yaml")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// loadKeymap reads the keymap at path over the defaults; a missing file gives the defaults
+func loadKeymap(path string) (keymap, error) {
+	if path == "" {
+		return newKeymap(nil)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the user's home directory
+	if errors.Is(err, os.ErrNotExist) {
+		return newKeymap(nil)
+	}
+	if err != nil {
+		return keymap{}, fmt.Errorf("failed to read keymap: %w", err)
+	}
+	return parseKeymap(data)
+}
+
+// parseKeymap parses keys.yaml content; actions it does not mention keep their default keys
+func parseKeymap(data []byte) (keymap, error) {
+	var bindings keyBindings
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&bindings); err != nil && !errors.Is(err, io.EOF) {
+		return keymap{}, fmt.Errorf("failed to parse keymap: %w", err)
+	}
+	return newKeymap(bindings)
+}
+
+// newKeymap applies bindings over the defaults and rejects unknown actions and keys bound twice
+func newKeymap(bindings keyBindings) (keymap, error) {
+	k := keymap{keys: make(map[action][]string), actions: make(map[string]action)}
+	for a, keys := range defaultBindings {
+		k.keys[a] = keys
+	}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := action(name)
+		if _, ok := defaultBindings[a]; !ok {
+			return keymap{}, fmt.Errorf("unknown action '%s' in keymap", name)
+		}
+		if len(bindings[name]) == 0 {
+			return keymap{}, fmt.Errorf("action '%s' has no keys", name)
+		}
+		keys := make([]string, 0, len(bindings[name]))
+		for _, key := range bindings[name] {
+			if alias, ok := keyNames[key]; ok {
+				key = alias
+			}
+			keys = append(keys, key)
+		}
+		k.keys[a] = keys
+	}
+
+	for _, entry := range actionLabels {
+		for _, key := range k.keys[entry.action] {
+			if other, ok := k.actions[key]; ok {
+				return keymap{}, fmt.Errorf("key '%s' is bound to both '%s' and '%s'", displayKey(key), other, entry.action)
+			}
+			k.actions[key] = entry.action
+		}
+	}
+	return k, nil
+}
+
+// action returns the action bound to a key
+func (k keymap) action(key string) (action, bool) {
+	a, ok := k.actions[key]
+	return a, ok
+}
+
+// help renders the help line for actions from the active bindings, in help line order;
+// labels overrides the label of some actions
+func (k keymap) help(actions []action, labels map[action]string) string {
+	parts := []string{"↑/↓: Navigate"}
+	for _, entry := range actionLabels {
+		if !slices.Contains(actions, entry.action) {
+			continue
+		}
+		label := entry.label
+		if override, ok := labels[entry.action]; ok {
+			label = override
+		}
+		keys := make([]string, 0, len(k.keys[entry.action]))
+		for _, key := range k.keys[entry.action] {
+			keys = append(keys, displayKey(key))
+		}
+		parts = append(parts, strings.Join(slices.Compact(keys), "/")+": "+label)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// displayKey returns a key as it is shown in the help line
+func displayKey(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case ":":
+		return "Colon"
+	case "enter":
+		return "Enter"
+	case "tab":
+		return "Tab"
+	case "shift+tab":
+		return "Shift+Tab"
+	case "right":
+		return "→"
+	case "left":
+		return "←"
+	case "esc":
+		return "Esc"
+	}
+	return key
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKeymap_RemapsActionsOverDefaults(t *testing.T) {
+	k, err := parseKeymap([]byte("start: enter\nstop: [S, ctrl+x]\nselect: space\ndetails: [right, l]\n"))
+	if err != nil {
+		t.Fatalf("parseKeymap failed: %v", err)
+	}
+
+	tests := map[string]action{"enter": actionStart, "S": actionStop, "ctrl+x": actionStop, " ": actionSelect, "t": actionRestart}
+	for key, want := range tests {
+		if got, ok := k.action(key); !ok || got != want {
+			t.Errorf("action(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := k.action("x"); ok {
+		t.Error("Expected the default stop key to be unbound")
+	}
+
+	help := k.help(screenActions[screenVMs], nil)
+	if !strings.Contains(help, "Enter: Start") || !strings.Contains(help, "S/ctrl+x: Stop") || strings.Contains(help, "• x: Stop") {
+		t.Errorf("Expected the help line to follow the keymap, got %q", help)
+	}
+}
+
+func TestParseKeymap_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown action": "launch: s\n",
+		"no keys":        "start: []\n",
+		"conflict":       "start: x\n",
+		"invalid yaml":   "start: [\n",
+	}
+	for name, data := range tests {
+		if _, err := parseKeymap([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadKeymap_MissingFileGivesDefaults(t *testing.T) {
+	k, err := loadKeymap(filepath.Join(t.TempDir(), "keys.yaml"))
+	if err != nil {
+		t.Fatalf("loadKeymap failed: %v", err)
+	}
+	if got, _ := k.action("s"); got != actionStart {
+		t.Errorf("Expected the default keymap, got %q for s", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte("quit: Q\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if k, err = loadKeymap(path); err != nil {
+		t.Fatalf("loadKeymap failed: %v", err)
+	}
+	if got, _ := k.action("Q"); got != actionQuit {
+		t.Errorf("Expected Q to quit, got %q", got)
+	}
+}
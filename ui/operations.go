@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxFinishedOperations is how many completed operations the queue view keeps
+const maxFinishedOperations = 10
+
+// operation is an action running, or run, against one VM or workspace
+type operation struct {
+	id       int
+	target   string // VM name, or "workspace <name>"
+	verb     string // Shown while running, e.g. "Starting"
+	success  string // Message shown when it succeeds
+	started  time.Time
+	duration time.Duration // Set when done
+	done     bool
+	canceled bool
+	err      error
+	cancel   context.CancelFunc
+}
+
+// operationTracker keeps the in-flight and recently finished operations, oldest first
+type operationTracker struct {
+	nextID int
+	ops    []*operation
+}
+
+// startOpMsg asks the model to run an operation, e.g. once a dialog is confirmed
+type startOpMsg struct {
+	target  string // VM name, "workspace <name>", or empty for operations not tied to either
+	verb    string
+	success string
+	run     func(ctx context.Context) error
+	command func(ctx context.Context) (string, error) // Used instead of run when the output is shown
+}
+
+// opDoneMsg reports that an operation returned
+type opDoneMsg struct {
+	id     int
+	err    error
+	output string
+}
+
+// start registers a new operation and returns it with the context to run it under
+func (t *operationTracker) start(target, verb, success string, now time.Time) (*operation, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.nextID++
+	op := &operation{id: t.nextID, target: target, verb: verb, success: success, started: now, cancel: cancel}
+	t.ops = append(t.ops, op)
+	return op, ctx
+}
+
+// finish marks an operation as done and drops the oldest finished operations beyond the limit
+func (t *operationTracker) finish(id int, err error, now time.Time) *operation {
+	var finished *operation
+	for _, op := range t.ops {
+		if op.id == id && !op.done {
+			op.done, op.err, op.duration = true, err, now.Sub(op.started)
+			op.canceled = op.canceled && err != nil // It completed before the cancellation took effect
+			op.cancel()
+			finished = op
+		}
+	}
+
+	excess := -maxFinishedOperations
+	for _, op := range t.ops {
+		if op.done {
+			excess++
+		}
+	}
+	kept := t.ops[:0]
+	for _, op := range t.ops {
+		if op.done && excess > 0 {
+			excess--
+			continue
+		}
+		kept = append(kept, op)
+	}
+	t.ops = kept
+	return finished
+}
+
+// busy returns the in-flight operation on a target, if any
+func (t *operationTracker) busy(target string) *operation {
+	if target == "" {
+		return nil
+	}
+	for _, op := range t.ops {
+		if op.target == target && !op.done {
+			return op
+		}
+	}
+	return nil
+}
+
+// cancel cancels the in-flight operation on a target and reports whether there was one
+func (t *operationTracker) cancel(target string) bool {
+	op := t.busy(target)
+	if op == nil || op.canceled {
+		return false
+	}
+	op.canceled = true
+	op.cancel()
+	return true
+}
+
+// active returns the number of in-flight operations
+func (t *operationTracker) active() int {
+	n := 0
+	for _, op := range t.ops {
+		if !op.done {
+			n++
+		}
+	}
+	return n
+}
+
+// startOperation runs an operation unless one is already in flight on its target
+func (m Model) startOperation(msg startOpMsg) (Model, tea.Cmd) {
+	if op := m.ops.busy(msg.target); op != nil {
+		m.err = nil
+		m.message = fmt.Sprintf("%s is busy: %s", msg.target, op.verb)
+		return m, nil
+	}
+
+	op, ctx := m.ops.start(msg.target, msg.verb, msg.success, m.now())
+	run := func() tea.Msg {
+		if msg.command != nil {
+			output, err := msg.command(ctx)
+			return opDoneMsg{id: op.id, err: err, output: output}
+		}
+		return opDoneMsg{id: op.id, err: msg.run(ctx)}
+	}
+	m.updateTable()
+
+	if m.spinning {
+		return m, run
+	}
+	m.spinning = true
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+// finishOperation reports a finished operation and reloads what it may have changed
+func (m Model) finishOperation(msg opDoneMsg) (Model, tea.Cmd) {
+	op := m.ops.finish(msg.id, msg.err, m.now())
+	if op == nil {
+		return m, nil
+	}
+
+	switch {
+	case op.canceled:
+		m.err = nil
+		m.message = fmt.Sprintf("%s %s: canceled", op.verb, op.target)
+	case op.err != nil:
+		m.err = op.err
+		m.message = fmt.Sprintf("Error: %v", op.err)
+	default:
+		m.err = nil
+		m.message = op.success
+	}
+	if msg.output != "" {
+		m.output = commandOutput{title: op.verb, text: msg.output}
+	}
+	m.updateTable()
+
+	m.loading = true
+	if op.target != m.detail.vmName {
+		return m, m.loadVMs
+	}
+	m.detail.stop()
+	m.detail = newDetailPane(op.target, m.detail.tab)
+	return m, tea.Batch(m.loadVMs, m.loadDetails(op.target))
+}
+
+// cancelOperations cancels the in-flight operations on targets
+func (m Model) cancelOperations(targets []string) Model {
+	var canceled []string
+	for _, target := range targets {
+		if m.ops.cancel(target) {
+			canceled = append(canceled, target)
+		}
+	}
+	m.err = nil
+	if len(canceled) == 0 {
+		m.message = "No operation to cancel"
+	} else {
+		m.message = "Canceling: " + strings.Join(canceled, ", ")
+	}
+	return m
+}
+
+// viewOperations renders the queue of in-flight and recent operations, newest first
+func (m Model) viewOperations() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Operations"))
+	if len(m.ops.ops) == 0 {
+		b.WriteString("\n" + dimStyle.Render("No operations yet"))
+	}
+	now := m.now()
+	for i := len(m.ops.ops) - 1; i >= 0; i-- {
+		op := m.ops.ops[i]
+		var line string
+		switch {
+		case !op.done:
+			line = fmt.Sprintf("%s %s %s  %s", m.spinner.View(), op.verb, op.target, formatDuration(now.Sub(op.started)))
+		case op.canceled:
+			line = opCanceledStyle.Render(fmt.Sprintf("⊘ %s %s  %s  canceled", op.verb, op.target, formatDuration(op.duration)))
+		case op.err != nil:
+			line = opFailedStyle.Render(fmt.Sprintf("✗ %s %s  %s  %v", op.verb, op.target, formatDuration(op.duration), op.err))
+		default:
+			line = opDoneStyle.Render(fmt.Sprintf("✓ %s %s  %s", op.verb, op.target, formatDuration(op.duration)))
+		}
+		b.WriteString("\n" + line)
+	}
+	return baseStyle.Render(b.String())
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runOperation feeds the startOpMsg produced by cmd to the model, runs the operation
+// and returns the model after it finished
+func runOperation(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	start, ok := cmd().(startOpMsg)
+	if !ok {
+		t.Fatal("Expected the command to start an operation")
+	}
+	updated, cmd := m.Update(start)
+	m = updated.(Model)
+	done, ok := findMsg[opDoneMsg](cmd)
+	if !ok {
+		t.Fatal("Expected the operation to report its result")
+	}
+	updated, _ = m.Update(done)
+	return updated.(Model)
+}
+
+// findMsg runs cmd, descending into batches, and returns the first message of type T
+func findMsg[T tea.Msg](cmd tea.Cmd) (T, bool) {
+	var zero T
+	if cmd == nil {
+		return zero, false
+	}
+	switch msg := cmd().(type) {
+	case T:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if found, ok := findMsg[T](c); ok {
+				return found, true
+			}
+		}
+	}
+	return zero, false
+}
+
+func TestOperationTracker_KeepsRecentFinishedOperations(t *testing.T) {
+	tracker := &operationTracker{}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	running, _ := tracker.start("Web01", "Starting", "", now)
+	for i := 0; i < maxFinishedOperations+3; i++ {
+		op, _ := tracker.start("Db01", "Stopping", "", now)
+		tracker.finish(op.id, nil, now.Add(time.Second))
+	}
+
+	if len(tracker.ops) != maxFinishedOperations+1 || tracker.ops[0] != running {
+		t.Errorf("Expected the running operation and the last %d finished ones, got %d", maxFinishedOperations, len(tracker.ops))
+	}
+	if tracker.busy("Web01") != running || tracker.busy("Db01") != nil || tracker.active() != 1 {
+		t.Error("Expected only Web01 to be busy")
+	}
+}
+
+func TestOperations_GuardAgainstDuplicateActions(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Index: 1, Name: "Web01"})
+
+	updated, cmd := m.Update(key("s"))
+	m = updated.(Model)
+	if cmd == nil || m.ops.busy("Web01") == nil {
+		t.Fatal("Expected Web01 to be busy starting")
+	}
+	if row := m.table.Rows()[0]; !strings.Contains(row[len(row)-1], "Starting") {
+		t.Errorf("Expected a spinner in the row, got %q", row[len(row)-1])
+	}
+
+	updated, cmd = m.Update(key("x"))
+	m = updated.(Model)
+	if _, ok := findMsg[opDoneMsg](cmd); ok || !strings.Contains(m.message, "Busy, skipped: Web01") {
+		t.Errorf("Expected the stop to be skipped, got %q", m.message)
+	}
+}
+
+func TestOperations_TargetVMsByName(t *testing.T) {
+	exec := &fakeExec{}
+	m := newTestModel(exec, hyperv.VM{Index: 1, Name: "Web01"}, hyperv.VM{Index: 2, Name: "Db01"})
+	m.table.SetCursor(1)
+
+	updated, cmd := m.Update(key("s"))
+	done, ok := findMsg[opDoneMsg](cmd)
+	if !ok || done.err != nil {
+		t.Fatalf("Expected the start to finish, got %v", done.err)
+	}
+	if len(exec.scripts) != 1 || exec.scripts[0] != "Start-VM -Name Db01" {
+		t.Errorf("Expected Db01 to be started by name, got %v", exec.scripts)
+	}
+
+	updated, _ = updated.Update(done)
+	if !updated.(Model).loading {
+		t.Error("Expected the reload after the operation to be tracked")
+	}
+}
+
+func TestOperations_Cancel(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Name: "Web01"})
+	blocked := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	updated, cmd := m.Update(startOpMsg{target: "Web01", verb: "Starting", run: blocked})
+	updated, _ = updated.Update(key("c"))
+	m = updated.(Model)
+	if m.message != "Canceling: Web01" {
+		t.Fatalf("Unexpected message %q", m.message)
+	}
+
+	done, ok := findMsg[opDoneMsg](cmd)
+	if !ok || !errors.Is(done.err, context.Canceled) {
+		t.Fatalf("Expected the operation context to be canceled, got %v", done.err)
+	}
+	updated, _ = m.Update(done)
+	m = updated.(Model)
+	if m.message != "Starting Web01: canceled" || m.ops.active() != 0 {
+		t.Errorf("Unexpected state after cancel: %q", m.message)
+	}
+
+	m.showOps = true
+	if view := m.View(); !strings.Contains(view, "⊘ Starting Web01") {
+		t.Errorf("Expected the canceled operation in the queue, got:\n%s", view)
+	}
+}
+
+// consoleLauncher records launched programs
+type consoleLauncher struct{ started []string }
+
+func (l *consoleLauncher) Start(program string, args ...string) (int, error) {
+	l.started = append(l.started, program+" "+strings.Join(args, " "))
+	return 42, nil
+}
+
+func (l *consoleLauncher) ProcessName(context.Context, int) (string, error) { return "", nil }
+
+func (l *consoleLauncher) Stop(context.Context, int) error { return nil }
+
+func TestConsoleKey_OpensConsoleWhileVMIsBusy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	m := newTestModel(&fakeExec{output: "Web01"}, hyperv.VM{Index: 1, Name: "Web01", State: "Running"})
+	launcher := &consoleLauncher{}
+	m.manager.Launcher = launcher
+	m.ops.start("Web01", "Restarting", "", time.Now())
+
+	updated, cmd := m.Update(key("v"))
+	done, ok := findMsg[opDoneMsg](cmd)
+	if !ok || done.err != nil {
+		t.Fatalf("Expected the console to open, got %v", done.err)
+	}
+	updated, _ = updated.Update(done)
+	if got := updated.(Model).message; got != "Console of Web01 opened" {
+		t.Errorf("Unexpected message %q", got)
+	}
+	if len(launcher.started) != 1 || launcher.started[0] != "vmconnect.exe localhost Web01" {
+		t.Errorf("Unexpected launches: %v", launcher.started)
+	}
+}
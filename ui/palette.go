@@ -0,0 +1,303 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteRows is how many matching commands the palette lists
+const paletteRows = 8
+
+// Command is a CLI command offered by the command palette
+type Command struct {
+	Path  []string // Subcommand path, e.g. ["snapshot", "create"]
+	Short string
+	Args  []Arg
+}
+
+// Arg is a value the palette prompts for before running a command
+type Arg struct {
+	Name     string // e.g. "vm-index"
+	Flag     string // Set when the value belongs to a flag, e.g. "--repo"
+	Optional bool
+	Variadic bool // Several space-separated values
+}
+
+// NewCommand builds a palette command from a cobra Use line such as
+// "restore <set> <dir> --repo <dir>"; the first word of use is the command name
+func NewCommand(path []string, short, use string) Command {
+	c := Command{Path: path, Short: short}
+	fields := strings.Fields(use)
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		var arg Arg
+		if strings.HasPrefix(field, "-") {
+			if i+1 >= len(fields) || strings.HasPrefix(fields[i+1], "-") {
+				continue // A flag without a value placeholder
+			}
+			arg.Flag = field
+			i++
+			field = fields[i]
+		}
+		arg.Variadic = strings.HasSuffix(field, "...")
+		field = strings.TrimSuffix(field, "...")
+		arg.Optional = strings.HasPrefix(field, "[")
+		arg.Name = strings.Trim(field, "<>[]")
+		c.Args = append(c.Args, arg)
+	}
+	return c
+}
+
+// Name returns the command as typed on the command line, without arguments
+func (c Command) Name() string {
+	return strings.Join(c.Path, " ")
+}
+
+// isVMArg reports whether an argument names or indexes a VM
+func (a Arg) isVMArg() bool {
+	switch a.Name {
+	case "vm", "vm-name", "vm-index", "vm-indices":
+		return true
+	}
+	return false
+}
+
+// palette is the ':' command palette: pick a command, then answer its argument prompts
+type palette struct {
+	query   []rune
+	cursor  int
+	command *Command // Chosen command, nil while picking
+	values  []string // Answers to the prompts so far
+	input   []rune   // Current prompt answer
+}
+
+// runCLIFunc runs quickvm with arguments and returns its combined output
+type runCLIFunc func(ctx context.Context, args []string) (string, error)
+
+// runSelf runs the quickvm executable itself
+func runSelf(ctx context.Context, args []string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate quickvm executable: %w", err)
+	}
+	out, err := exec.CommandContext(ctx, exe, args...).CombinedOutput() //nolint:gosec // G204: runs quickvm itself
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			return output, fmt.Errorf("%v: %s", err, lastLine(output))
+		}
+		return output, err
+	}
+	return output, nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// matches returns the commands matching the query
+func (p *palette) matches(commands []Command) []Command {
+	var matched []Command
+	for _, c := range commands {
+		if fuzzyMatch(string(p.query), c.Name()) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+// openPalette opens the command palette
+func (m Model) openPalette() Model {
+	if len(m.commands) == 0 {
+		m.err = nil
+		m.message = "No commands available"
+		return m
+	}
+	m.palette = &palette{}
+	return m
+}
+
+// handlePaletteKey handles keys while the command palette is open
+func (m Model) handlePaletteKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := m.palette
+	if msg.Type == tea.KeyEsc {
+		m.palette = nil
+		return m, nil
+	}
+	if p.command != nil {
+		return m.handlePromptKey(msg)
+	}
+
+	matches := p.matches(m.commands)
+	switch msg.Type {
+	case tea.KeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tea.KeyDown:
+		if p.cursor < len(matches)-1 {
+			p.cursor++
+		}
+	case tea.KeyEnter:
+		if p.cursor < len(matches) {
+			chosen := matches[p.cursor]
+			p.command = &chosen
+			return m.nextPrompt()
+		}
+	case tea.KeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.cursor = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.query = append(p.query, msg.Runes...)
+		p.cursor = 0
+	}
+	return m, nil
+}
+
+// handlePromptKey edits the answer to the current argument prompt
+func (m Model) handlePromptKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := m.palette
+	switch msg.Type {
+	case tea.KeyEnter:
+		arg := p.command.Args[len(p.values)]
+		value := strings.TrimSpace(string(p.input))
+		if value == "" && !arg.Optional {
+			return m, nil
+		}
+		p.values = append(p.values, value)
+		return m.nextPrompt()
+	case tea.KeyBackspace:
+		if len(p.input) > 0 {
+			p.input = p.input[:len(p.input)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.input = append(p.input, msg.Runes...)
+	}
+	return m, nil
+}
+
+// nextPrompt prefills the next argument prompt, or runs the command once every argument is answered
+func (m Model) nextPrompt() (Model, tea.Cmd) {
+	p := m.palette
+	if len(p.values) < len(p.command.Args) {
+		p.input = []rune(m.argDefault(p.command.Args[len(p.values)]))
+		return m, nil
+	}
+
+	command, values := *p.command, p.values
+	m.palette = nil
+	args := append([]string{}, command.Path...)
+	vmName := ""
+	for i, arg := range command.Args {
+		value := values[i]
+		if value == "" {
+			continue
+		}
+		if arg.isVMArg() && vmName == "" {
+			vmName = m.vmNameFor(value)
+		}
+		if arg.Flag != "" {
+			args = append(args, arg.Flag)
+		}
+		if arg.Variadic {
+			args = append(args, strings.Fields(value)...)
+		} else {
+			args = append(args, value)
+		}
+	}
+
+	return m.startOperation(startOpMsg{
+		target:  vmName,
+		verb:    command.Name(),
+		success: fmt.Sprintf("quickvm %s: done", strings.Join(args, " ")),
+		command: func(ctx context.Context) (string, error) { return m.runCLI(ctx, args) },
+	})
+}
+
+// argDefault prefills VM arguments from the VM under the cursor
+func (m Model) argDefault(arg Arg) string {
+	vm, ok := m.selectedVM()
+	if !ok || !arg.isVMArg() {
+		return ""
+	}
+	if strings.HasPrefix(arg.Name, "vm-ind") {
+		return strconv.Itoa(vm.Index)
+	}
+	return vm.Name
+}
+
+// vmNameFor returns the name of the VM a VM argument refers to, by name or index
+func (m Model) vmNameFor(value string) string {
+	index, err := strconv.Atoi(strings.Fields(value)[0])
+	if err != nil {
+		return value
+	}
+	for _, vm := range m.all {
+		if vm.Index == index {
+			return vm.Name
+		}
+	}
+	return ""
+}
+
+// viewPalette renders the command palette
+func (m Model) viewPalette() string {
+	p := m.palette
+	var b strings.Builder
+	if p.command != nil {
+		arg := p.command.Args[len(p.values)]
+		name := arg.Name
+		if arg.Flag != "" {
+			name = arg.Flag + " " + name
+		}
+		if arg.Optional {
+			name += " (optional)"
+		}
+		b.WriteString(fmt.Sprintf(":%s › %s: %s█\n", p.command.Name(), name, string(p.input)))
+		b.WriteString(dimStyle.Render("Enter: Next • Esc: Cancel"))
+		return paletteStyle.Render(b.String())
+	}
+
+	b.WriteString(":" + string(p.query) + "█")
+	matches := p.matches(m.commands)
+	start := max(0, p.cursor-paletteRows+1)
+	for i := start; i < len(matches) && i < start+paletteRows; i++ {
+		name := fmt.Sprintf("%-24s", matches[i].Name())
+		if i == p.cursor {
+			name = selectedStyle.Render(name)
+		}
+		line := name + " " + dimStyle.Render(matches[i].Short)
+		b.WriteString("\n" + line)
+	}
+	if len(matches) == 0 {
+		b.WriteString("\n" + dimStyle.Render("No matching command"))
+	}
+	b.WriteString("\n" + dimStyle.Render("↑/↓: Select • Enter: Run • Esc: Close"))
+	return paletteStyle.Render(b.String())
+}
+
+// outputRows is how many lines of command output are shown
+const outputRows = 12
+
+// commandOutput is the output of the last palette command
+type commandOutput struct {
+	title string
+	text  string
+}
+
+func (o commandOutput) View() string {
+	lines := strings.Split(o.text, "\n")
+	if len(lines) > outputRows {
+		lines = append([]string{dimStyle.Render(fmt.Sprintf("... %d more lines", len(lines)-outputRows))}, lines[len(lines)-outputRows:]...)
+	}
+	return baseStyle.Render(headerStyle.Render("quickvm "+o.title) + "\n" + strings.Join(lines, "\n") + "\n" + dimStyle.Render("Esc: Close"))
+}
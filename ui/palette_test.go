@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestNewCommand_ParsesUseLine(t *testing.T) {
+	c := NewCommand([]string{"backup", "restore"}, "Restore a backup", "restore <set> [dir] --repo <dir>")
+	want := []Arg{{Name: "set"}, {Name: "dir", Optional: true}, {Name: "dir", Flag: "--repo"}}
+	if !reflect.DeepEqual(c.Args, want) {
+		t.Errorf("Args = %+v, want %+v", c.Args, want)
+	}
+
+	c = NewCommand([]string{"workspace", "add"}, "", "add <name> <vm-name>...")
+	if len(c.Args) != 2 || !c.Args[1].Variadic || !c.Args[1].isVMArg() {
+		t.Errorf("Expected a variadic VM argument, got %+v", c.Args)
+	}
+}
+
+func TestPalette_PromptsForArgumentsAndRunsCommand(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Index: 3, Name: "Web01"})
+	var ran []string
+	m.runCLI = func(_ context.Context, args []string) (string, error) {
+		ran = args
+		return "✅ Snapshot created", nil
+	}
+	m = m.WithCommands([]Command{
+		NewCommand([]string{"snapshot", "create"}, "Create a snapshot", "create <vm-index> <snapshot-name>"),
+		NewCommand([]string{"rdp"}, "Connect with RDP", "rdp <vm-index>"),
+	})
+
+	updated, _ := m.Update(key(":"))
+	for _, r := range "snapc" {
+		updated, _ = updated.Update(key(string(r)))
+	}
+	updated, _ = updated.Update(key("enter"))
+	m = updated.(Model)
+	if m.palette == nil || string(m.palette.input) != "3" {
+		t.Fatalf("Expected the VM index prompt to be prefilled, got %+v", m.palette)
+	}
+
+	updated, _ = m.Update(key("enter"))
+	updated, _ = updated.Update(key("enter")) // A required argument cannot be empty
+	for _, r := range "base" {
+		updated, _ = updated.Update(key(string(r)))
+	}
+	updated, cmd := updated.Update(key("enter"))
+	m = updated.(Model)
+	if m.palette != nil || m.ops.busy("Web01") == nil {
+		t.Fatal("Expected the palette to close and the command to run on Web01")
+	}
+
+	done, ok := findMsg[opDoneMsg](cmd)
+	if !ok {
+		t.Fatal("Expected the command to finish")
+	}
+	updated, _ = m.Update(done)
+	m = updated.(Model)
+	if !reflect.DeepEqual(ran, []string{"snapshot", "create", "3", "base"}) {
+		t.Errorf("Unexpected arguments %v", ran)
+	}
+	if !strings.Contains(m.View(), "Snapshot created") {
+		t.Error("Expected the command output to be shown")
+	}
+
+	updated, _ = m.Update(key("esc"))
+	if updated.(Model).output.text != "" {
+		t.Error("Expected esc to close the output")
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// preferences are TUI choices kept between launches
+type preferences struct {
+	SortBy   string `yaml:"sortBy,omitempty"` // Column title, empty for Hyper-V order
+	SortDesc bool   `yaml:"sortDesc,omitempty"`
+	Filter   string `yaml:"filter,omitempty"`
+}
+
+// configPath returns the path of a file in ~/.quickvm
+func configPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".quickvm", name), nil
+}
+
+// preferencesPath returns the path of the TUI preferences file, or "" if there is no home directory
+func preferencesPath() string {
+	path, err := configPath("tui.yaml")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// loadPreferences reads preferences from path; a missing or unreadable file gives the defaults
+func loadPreferences(path string) preferences {
+	var prefs preferences
+	if path == "" {
+		return prefs
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is under the user's home directory
+	if err != nil {
+		return prefs
+	}
+	if err := yaml.Unmarshal(data, &prefs); err != nil {
+		return preferences{}
+	}
+	return prefs
+}
+
+// save writes preferences to path; an empty path disables saving
+func (p preferences) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// screen is a top-level view of the TUI
+type screen int
+
+const (
+	screenVMs screen = iota
+	screenWorkspaces
+	screenHost
+	screenCount
+)
+
+var screenNames = [screenCount]string{"VMs", "Workspaces", "Host"}
+
+// screenSwitches maps the screen switching actions to their screen
+var screenSwitches = map[action]screen{
+	actionVMsScreen:  screenVMs,
+	actionWsScreen:   screenWorkspaces,
+	actionHostScreen: screenHost,
+}
+
+// screenActions lists the actions shown in the help line of each screen
+var screenActions = map[screen][]action{
+	screenVMs: {
+		actionStart, actionStop, actionRestart, actionConsole, actionRefresh, actionSelect, actionFilter, actionSort,
+		actionReverseSort, actionCancel, actionOperations, actionDetails, actionNextTab, actionPrevTab,
+		actionPalette, actionVMsScreen, actionWsScreen, actionHostScreen, actionQuit,
+	},
+	screenWorkspaces: {
+		actionStart, actionStop, actionRefresh, actionCancel, actionOperations, actionPalette,
+		actionVMsScreen, actionWsScreen, actionHostScreen, actionQuit,
+	},
+	screenHost: {
+		actionRefresh, actionOperations, actionPalette, actionVMsScreen, actionWsScreen, actionHostScreen, actionQuit,
+	},
+}
+
+// switchScreen shows another screen and loads its data
+func (m Model) switchScreen(s screen) (Model, tea.Cmd) {
+	m.screen = s
+	m.detailFocus = false
+	switch s {
+	case screenWorkspaces:
+		return m, m.loadWorkspaces
+	case screenHost:
+		if m.hostLoading {
+			return m, nil
+		}
+		m.hostLoading = true
+		return m, m.loadHost
+	}
+	return m, nil
+}
+
+// viewScreenTabs renders the screen tab bar with the key of each screen
+func (m Model) viewScreenTabs() string {
+	tabs := make([]string, 0, screenCount)
+	for s, a := range []action{actionVMsScreen, actionWsScreen, actionHostScreen} {
+		label := screenNames[s]
+		if keys := m.keys.keys[a]; len(keys) > 0 {
+			label = displayKey(keys[0]) + " " + label
+		}
+		style := inactiveTabStyle
+		if screen(s) == m.screen {
+			style = activeTabStyle
+		}
+		tabs = append(tabs, style.Render(label))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+)
+
+func TestScreens_SwitchWithKeys(t *testing.T) {
+	m := newTestModel(&fakeExec{}, hyperv.VM{Name: "Web01"})
+
+	updated, cmd := m.Update(key("3"))
+	m = updated.(Model)
+	if m.screen != screenHost || !m.hostLoading || cmd == nil {
+		t.Fatal("Expected the host screen to load host information")
+	}
+	if help := m.View(); strings.Contains(help, "Space: Select") {
+		t.Error("Expected the help line to only show host screen actions")
+	}
+
+	updated, _ = m.Update(key("esc"))
+	if updated.(Model).screen != screenVMs {
+		t.Error("Expected esc to go back to the VM table")
+	}
+}
+
+func TestHostScreen_ShowsGaugesAndCommittedMemory(t *testing.T) {
+	m := newTestModel(&fakeExec{},
+		hyperv.VM{Name: "Web01", State: "Running", MemoryMB: 4096},
+		hyperv.VM{Name: "Db01", State: "Off", MemoryMB: 8192})
+	m.screen = screenHost
+
+	updated, _ := m.Update(hostMsg{info: &hyperv.SystemInfo{
+		CPU:    hyperv.CPUInfo{Name: "Test CPU", Cores: 8, LoadPercent: 50},
+		Memory: hyperv.MemoryInfo{TotalMB: 16384, UsedMB: 8192, TotalGB: 16, UsedGB: 8},
+		Disks:  []hyperv.DiskInfo{{Name: "C:", TotalMB: 1000, UsedMB: 950}},
+		HyperV: hyperv.Status{Enabled: true, Status: "Enabled"},
+	}})
+	view := updated.(Model).View()
+	for _, want := range []string{"Test CPU (8 cores)", "4096 MB committed to 1 running VMs", "Disk C:", " 95%", "✓ Enabled"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the host screen, got:\n%s", want, view)
+		}
+	}
+}
+
+func TestWorkspacesScreen_ListsAndStartsWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	if err := hyperv.SaveWorkspace(&hyperv.Workspace{Name: "lab", VMs: []string{"Web01", "Ghost"}}); err != nil {
+		t.Fatalf("SaveWorkspace failed: %v", err)
+	}
+
+	exec := &fakeExec{}
+	m := newTestModel(exec, hyperv.VM{Name: "Web01", State: "Running", MemoryMB: 2048})
+	updated, cmd := m.Update(key("2"))
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	view := m.View()
+	for _, want := range []string{"lab", "1/2", "Members of lab", "Ghost", "Missing"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the workspaces screen, got:\n%s", want, view)
+		}
+	}
+
+	updated, _ = m.Update(key("s"))
+	m = updated.(Model)
+	if m.ops.busy("workspace lab") == nil {
+		t.Fatal("Expected the workspace to be starting")
+	}
+
+	// Stopping a busy workspace is refused once confirmed
+	updated, _ = m.Update(key("x"))
+	updated, cmd = updated.Update(key("y"))
+	updated, _ = updated.Update(cmd())
+	if msg := updated.(Model).message; !strings.Contains(msg, "busy") {
+		t.Errorf("Expected the stop to be refused, got %q", msg)
+	}
+}
+
+func TestWorkspaceRunError(t *testing.T) {
+	if err := workspaceRunError([]hyperv.WorkspaceVMResult{{Name: "a", Success: true}, {Name: "b", Skipped: true}}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	err := workspaceRunError([]hyperv.WorkspaceVMResult{{Name: "a", Success: true}, {Name: "b", Error: "boom"}})
+	if err == nil || err.Error() != "1 of 2 VMs failed (b: boom)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	if got := gauge(150); !strings.Contains(got, "100%") || strings.Contains(got, "░") {
+		t.Errorf("Expected a full gauge, got %q", got)
+	}
+	if got := gauge(0); strings.Contains(got, "█") {
+		t.Errorf("Expected an empty gauge, got %q", got)
+	}
+}
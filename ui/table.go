@@ -5,84 +5,93 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"quickvm/internal/hyperv"
+	"quickvm/internal/theme"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	// Styles
-	baseStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240"))
-
-	headerStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			Background(lipgloss.Color("235")).
-			Padding(0, 1)
-
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57")).
-			Bold(true)
-
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Background(lipgloss.Color("235")).
-			Bold(true).
-			Padding(0, 1).
-			MarginBottom(1)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			MarginTop(1)
-
-	statusRunningStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("46")).
-				Bold(true)
-
-	statusStoppedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("196")).
-				Bold(true)
-
-	statusOtherStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("226")).
-				Bold(true)
-)
+// DefaultRefreshInterval is how often the TUI reloads the VM list
+const DefaultRefreshInterval = 5 * time.Second
 
 // Model represents the state of the TUI application.
 type Model struct {
-	table   table.Model
-	vms     []hyperv.VM
-	manager *hyperv.Manager
-	message string
-	err     error
+	table     table.Model
+	all       []hyperv.VM // Every VM loaded
+	vms       []hyperv.VM // VMs shown, filtered and sorted as in the table
+	manager   *hyperv.Manager
+	workspace *hyperv.Workspace // When set, only members of this workspace are shown
+	message   string
+	err       error
+
+	interval time.Duration // Automatic refresh interval; 0 disables it
+	loading  bool          // A VM list reload is in flight
+	loaded   bool          // The VM list has loaded once, so reloads can be diffed
+	history  history       // CPU/memory samples and state changes per VM
+	now      func() time.Time
+
+	detail      detailPane // Details of the selected VM
+	detailFocus bool       // Keys go to the detail pane instead of the table
+	dialog      *dialog    // Open confirmation or input dialog
+	width       int        // Terminal width
+
+	selected  map[string]bool // VMs marked for a batch action, by name
+	filtering bool            // Keys edit the filter
+	prefs     preferences     // Sort and filter, saved between launches
+	prefsPath string          // Where prefs are saved; empty disables saving
+
+	ops      *operationTracker // In-flight and recent operations
+	spinner  spinner.Model
+	spinning bool // A spinner tick is scheduled
+	showOps  bool // Show the operations queue
+
+	keys     keymap
+	commands []Command     // Commands offered by the palette
+	palette  *palette      // Open command palette
+	runCLI   runCLIFunc    // Runs palette commands
+	output   commandOutput // Output of the last palette command
+
+	screen      screen
+	workspaces  []*hyperv.Workspace
+	wsCursor    int
+	wsErr       error
+	host        *hyperv.SystemInfo
+	hostErr     error
+	hostLoading bool // A host information reload is in flight
 }
 
 type vmListMsg []hyperv.VM
 type errMsg struct{ err error }
+type tickMsg time.Time
 
 func (e errMsg) Error() string { return e.err.Error() }
 
-// NewModel creates a new TUI model.
-func NewModel() Model {
-	columns := []table.Column{
-		{Title: "Index", Width: 7},
+// tableColumns returns the columns of the VM table
+func tableColumns() []table.Column {
+	return []table.Column{
+		{Title: "Index", Width: 8},
 		{Title: "Name", Width: 25},
-		{Title: "State", Width: 10},
+		{Title: "State", Width: 12},
 		{Title: "IP Address", Width: 15},
 		{Title: "CPU%", Width: 6},
+		{Title: "CPU History", Width: historySize},
 		{Title: "Memory(MB)", Width: 12},
+		{Title: "Mem History", Width: historySize},
 		{Title: "Uptime", Width: 18},
 		{Title: "Status", Width: 15},
 	}
+}
 
+// NewModel creates a new TUI model.
+func NewModel() Model {
+	applyTheme(theme.Active())
 	t := table.New(
-		table.WithColumns(columns),
+		table.WithColumns(tableColumns()),
 		table.WithFocused(true),
 		table.WithHeight(15),
 	)
@@ -92,15 +101,64 @@ func NewModel() Model {
 	s.Selected = selectedStyle
 	t.SetStyles(s)
 
-	return Model{
-		table:   t,
-		manager: hyperv.NewManager(),
+	prefsPath := preferencesPath()
+	keys, keysErr := loadKeymap(keymapPath())
+	if keysErr != nil {
+		keys, _ = newKeymap(nil)
+	}
+	m := Model{
+		table:     t,
+		manager:   hyperv.NewManager(),
+		interval:  DefaultRefreshInterval,
+		history:   make(history),
+		now:       time.Now,
+		selected:  make(map[string]bool),
+		prefs:     loadPreferences(prefsPath),
+		prefsPath: prefsPath,
+		ops:       &operationTracker{},
+		spinner:   spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(sparkStyle)),
+		keys:      keys,
+		runCLI:    runSelf,
+	}
+	if keysErr != nil {
+		m.err = keysErr
+		m.message = fmt.Sprintf("Error in keys.yaml, using default keys: %v", keysErr)
 	}
+	m.updateColumns()
+	return m
+}
+
+// WithRefreshInterval returns the model with a different automatic refresh interval.
+// An interval of 0 disables automatic refresh; 'r' still reloads the list.
+func (m Model) WithRefreshInterval(interval time.Duration) Model {
+	m.interval = interval
+	return m
+}
+
+// WithCommands returns the model with the commands offered by the ':' command palette.
+func (m Model) WithCommands(commands []Command) Model {
+	m.commands = commands
+	return m
+}
+
+// NewWorkspaceModel creates a TUI model that only shows the members of a workspace.
+func NewWorkspaceModel(ws *hyperv.Workspace) Model {
+	m := NewModel()
+	m.workspace = ws
+	return m
 }
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return m.loadVMs
+	return tea.Batch(m.loadVMs, m.tick())
+}
+
+// tick schedules the next automatic refresh
+func (m Model) tick() tea.Cmd {
+	if m.interval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return tickMsg(t) })
 }
 
 func (m Model) loadVMs() tea.Msg {
@@ -108,9 +166,29 @@ func (m Model) loadVMs() tea.Msg {
 	if err != nil {
 		return errMsg{err}
 	}
+	if m.workspace != nil {
+		vms = filterWorkspaceVMs(vms, m.workspace)
+	}
 	return vmListMsg(vms)
 }
 
+// filterWorkspaceVMs keeps only workspace members, in workspace order.
+// VM indices are left untouched so actions still target the right VM.
+func filterWorkspaceVMs(vms []hyperv.VM, ws *hyperv.Workspace) []hyperv.VM {
+	byName := make(map[string]hyperv.VM, len(vms))
+	for _, vm := range vms {
+		byName[vm.Name] = vm
+	}
+
+	filtered := make([]hyperv.VM, 0, len(ws.VMs))
+	for _, name := range ws.VMs {
+		if vm, ok := byName[name]; ok {
+			filtered = append(filtered, vm)
+		}
+	}
+	return filtered
+}
+
 // Update updates the model based on messages.
 //
 //nolint:gocyclo // UI Update loop is naturally complex
@@ -119,150 +197,490 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c", "esc":
-			return m, tea.Quit
-
-		case "r":
-			// Refresh VM list
-			m.message = "Refreshing VM list..."
-			return m, m.loadVMs
-
-		case "enter":
-			// Start selected VM
-			if len(m.vms) > 0 && m.table.Cursor() < len(m.vms) {
-				selectedVM := m.vms[m.table.Cursor()]
-				m.message = fmt.Sprintf("Starting VM: %s...", selectedVM.Name)
-				return m, m.startVM(selectedVM.Index)
-			}
-
-		case "s":
-			// Stop selected VM
-			if len(m.vms) > 0 && m.table.Cursor() < len(m.vms) {
-				selectedVM := m.vms[m.table.Cursor()]
-				m.message = fmt.Sprintf("Stopping VM: %s...", selectedVM.Name)
-				return m, m.stopVM(selectedVM.Index)
-			}
-
-		case "t":
-			// Restart selected VM
-			if len(m.vms) > 0 && m.table.Cursor() < len(m.vms) {
-				selectedVM := m.vms[m.table.Cursor()]
-				m.message = fmt.Sprintf("Restarting VM: %s...", selectedVM.Name)
-				return m, m.restartVM(selectedVM.Index)
-			}
+		return m.handleKey(msg)
+
+	case tickMsg:
+		cmds := []tea.Cmd{m.tick()}
+		if m.screen == screenHost && !m.hostLoading {
+			m.hostLoading = true
+			cmds = append(cmds, m.loadHost)
+		}
+		// Skip this refresh if the previous one has not returned yet
+		if !m.loading {
+			m.loading = true
+			cmds = append(cmds, m.loadVMs)
 		}
+		return m, tea.Batch(cmds...)
+
+	case workspacesMsg:
+		m.workspaces, m.wsErr = msg.workspaces, msg.err
+		m.wsCursor = min(m.wsCursor, max(len(m.workspaces)-1, 0))
+		return m, nil
+
+	case hostMsg:
+		m.hostLoading = false
+		m.hostErr = msg.err
+		if msg.info != nil {
+			m.host = msg.info
+		}
+		return m, nil
 
 	case vmListMsg:
-		m.vms = msg
-		m.updateTable()
+		m.loading = false
+		if m.loaded {
+			m.announceEvents(hyperv.DiffVMs(m.all, msg, m.now()))
+		}
+		m.loaded = true
+		m.all = msg
+		m.history.record(m.all, m.now())
+		m.applyView()
 		if m.message == "Refreshing VM list..." {
 			m.message = "VM list refreshed!"
 		}
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+
+	case detailLoadMsg:
+		// The cursor has moved on if another VM is selected by now
+		if msg.vmName != m.detail.vmName || m.detail.ctx.Err() != nil {
+			return m, nil
+		}
+		return m, m.loadDetails(msg.vmName)
+
+	case detailMsg:
+		m.detail.apply(msg)
+		return m, nil
+
+	case startOpMsg:
+		return m.startOperation(msg)
+
+	case opDoneMsg:
+		return m.finishOperation(msg)
+
+	case spinner.TickMsg:
+		// Stop animating once nothing is in flight
+		if m.ops.active() == 0 {
+			m.spinning = false
+			return m, nil
+		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		m.updateTable()
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
 		return m, nil
 
 	case errMsg:
+		m.loading = false
 		m.err = msg.err
 		m.message = fmt.Sprintf("Error: %v", msg.err)
 		return m, nil
 	}
 
 	m.table, cmd = m.table.Update(msg)
-	return m, cmd
+	detailCmd := m.syncDetail()
+	return m, tea.Batch(cmd, detailCmd)
 }
 
 func (m *Model) updateTable() {
 	rows := make([]table.Row, 0, len(m.vms))
+	now := m.now()
 	for _, vm := range m.vms {
-		var state string
-		switch strings.ToLower(vm.State) {
-		case "running":
-			state = statusRunningStyle.Render(vm.State)
-		case "off":
-			state = statusStoppedStyle.Render(vm.State)
-		default:
-			state = statusOtherStyle.Render(vm.State)
+		state := renderState(vm.State)
+		if m.history.flashing(vm.Name, now) {
+			state = flashStyle.Render(theme.StateSymbol(vm.State) + " " + vm.State)
+		}
+
+		var cpuHistory, memoryHistory string
+		if h, ok := m.history[vm.Name]; ok {
+			cpuHistory = sparkStyle.Render(sparkline(h.cpu, 100, historySize))
+			memoryHistory = sparkStyle.Render(sparkline(h.memoryMB, 0, historySize))
+		}
+
+		ip := firstIP(vm) // Show first IP
+
+		status := vm.Status
+		if op := m.ops.busy(vm.Name); op != nil {
+			status = m.spinner.View() + " " + op.verb
 		}
 
-		ip := ""
-		if len(vm.IPAddresses) > 0 {
-			ip = vm.IPAddresses[0] // Show first IP
+		mark := "  "
+		if m.selected[vm.Name] {
+			mark = "● "
 		}
 
 		rows = append(rows, table.Row{
-			fmt.Sprintf("%d", vm.Index),
+			mark + fmt.Sprintf("%d", vm.Index),
 			vm.Name,
 			state,
 			ip,
 			fmt.Sprintf("%d%%", vm.CPUUsage),
+			cpuHistory,
 			fmt.Sprintf("%d", vm.MemoryMB),
+			memoryHistory,
 			vm.Uptime,
-			vm.Status,
+			status,
 		})
 	}
 	m.table.SetRows(rows)
 }
 
-func (m Model) startVM(index int) tea.Cmd {
-	return func() tea.Msg {
-		if err := m.manager.StartVM(context.TODO(), index); err != nil {
-			return errMsg{err}
+// handleKey routes a key to the open dialog or palette, the filter, or the current screen
+func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.dialog != nil {
+		done, action := m.dialog.handleKey(msg)
+		if done {
+			m.dialog = nil
 		}
-		// Reload VMs after action
-		return m.loadVMs()
+		return m, action
 	}
+	if m.palette != nil {
+		return m.handlePaletteKey(msg)
+	}
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.filtering {
+		return m.handleFilterKey(msg)
+	}
+
+	act, _ := m.keys.action(msg.String())
+	if s, ok := screenSwitches[act]; ok {
+		return m.switchScreen(s)
+	}
+	switch m.screen {
+	case screenWorkspaces:
+		return m.handleWorkspacesKey(msg, act)
+	case screenHost:
+		return m.handleHostKey(msg, act)
+	}
+
+	switch act {
+	case actionNextTab:
+		m.detail.tab = (m.detail.tab + 1) % detailTabCount
+		return m, nil
+	case actionPrevTab:
+		m.detail.tab = (m.detail.tab + detailTabCount - 1) % detailTabCount
+		return m, nil
+	}
+	if m.detailFocus {
+		return m.handleDetailKey(msg.String())
+	}
+	return m.handleTableKey(msg)
 }
 
-func (m Model) stopVM(index int) tea.Cmd {
-	return func() tea.Msg {
-		if err := m.manager.StopVM(context.TODO(), index); err != nil {
-			return errMsg{err}
+// handleTableKey handles keys while the table has focus
+func (m Model) handleTableKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		return m.escape()
+	}
+
+	act, _ := m.keys.action(msg.String())
+	switch act {
+	case actionQuit:
+		return m, tea.Quit
+
+	case actionDetails:
+		if m.detail.vmName != "" {
+			m.detailFocus = true
+		}
+		return m, nil
+
+	case actionRefresh:
+		// Refresh VM list
+		m.message = "Refreshing VM list..."
+		m.loading = true
+		return m, m.loadVMs
+
+	case actionFilter:
+		m.filtering = true
+		return m, nil
+
+	case actionPalette:
+		return m.openPalette(), nil
+
+	case actionCancel:
+		var names []string
+		for _, vm := range m.targets() {
+			names = append(names, vm.Name)
 		}
-		// Reload VMs after action
-		return m.loadVMs()
+		return m.cancelOperations(names), nil
+
+	case actionOperations:
+		m.showOps = !m.showOps
+		return m, nil
+
+	case actionSelect:
+		m.toggleSelected()
+		return m, nil
+
+	case actionSort:
+		m.prefs.SortBy = nextSortColumn(m.prefs.SortBy)
+		m = m.savePreferences()
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+
+	case actionReverseSort:
+		m.prefs.SortDesc = !m.prefs.SortDesc
+		m = m.savePreferences()
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+
+	case actionStart:
+		return m.vmAction("Starting", "started", func(ctx context.Context, name string) error {
+			return m.manager.StartVMByName(ctx, name)
+		})
+
+	case actionStop:
+		return m.vmAction("Stopping", "stopped", func(ctx context.Context, name string) error {
+			return m.manager.StopVMByName(ctx, name)
+		})
+
+	case actionRestart:
+		return m.vmAction("Restarting", "restarted", func(ctx context.Context, name string) error {
+			return m.manager.RestartVMByName(ctx, name)
+		})
+
+	case actionConsole:
+		if vm, ok := m.selectedVM(); ok {
+			return m.startOperation(m.consoleOperation(vm.Name))
+		}
+		return m, nil
 	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	detailCmd := m.syncDetail()
+	return m, tea.Batch(cmd, detailCmd)
 }
 
-func (m Model) restartVM(index int) tea.Cmd {
-	return func() tea.Msg {
-		if err := m.manager.RestartVM(context.TODO(), index); err != nil {
-			return errMsg{err}
+// consoleOperation opens the vmconnect console of a VM; it has its own target so
+// that it does not wait for operations running on the VM
+func (m Model) consoleOperation(vmName string) startOpMsg {
+	return startOpMsg{
+		target:  "console " + vmName,
+		verb:    "Opening console",
+		success: fmt.Sprintf("Console of %s opened", vmName),
+		run: func(ctx context.Context) error {
+			_, err := m.manager.OpenConsole(ctx, vmName, hyperv.ConsoleOptions{})
+			return err
+		},
+	}
+}
+
+// escape closes the command output, then clears the filter, then quits
+func (m Model) escape() (Model, tea.Cmd) {
+	switch {
+	case m.output.text != "":
+		m.output = commandOutput{}
+		return m, nil
+	case m.prefs.Filter != "":
+		m.prefs.Filter = ""
+		m = m.savePreferences()
+		detailCmd := m.syncDetail()
+		return m, detailCmd
+	}
+	return m, tea.Quit
+}
+
+// targets returns the VMs an action applies to: the selected VMs, or the VM under the cursor
+func (m Model) targets() []hyperv.VM {
+	var targets []hyperv.VM
+	for _, vm := range m.all {
+		if m.selected[vm.Name] {
+			targets = append(targets, vm)
 		}
-		// Reload VMs after action
-		return m.loadVMs()
 	}
+	if len(targets) > 0 {
+		return targets
+	}
+	if vm, ok := m.selectedVM(); ok {
+		return []hyperv.VM{vm}
+	}
+	return nil
 }
 
+// vmAction runs an action on every target VM that is not busy and clears the selection.
+// VMs are passed by name: their indexes change when VMs are added or removed meanwhile.
+func (m Model) vmAction(verb, done string, action func(ctx context.Context, name string) error) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var started, busy []string
+	for _, vm := range m.targets() {
+		if m.ops.busy(vm.Name) != nil {
+			busy = append(busy, vm.Name)
+			continue
+		}
+		name := vm.Name
+		var cmd tea.Cmd
+		m, cmd = m.startOperation(startOpMsg{
+			target:  vm.Name,
+			verb:    verb,
+			success: fmt.Sprintf("VM %s %s", vm.Name, done),
+			run:     func(ctx context.Context) error { return action(ctx, name) },
+		})
+		cmds = append(cmds, cmd)
+		started = append(started, vm.Name)
+	}
+	m.selected = make(map[string]bool)
+	m.updateTable()
+
+	m.err = nil
+	switch len(started) {
+	case 0:
+		m.message = ""
+	case 1:
+		m.message = fmt.Sprintf("%s VM: %s...", verb, started[0])
+	default:
+		m.message = fmt.Sprintf("%s %d VMs...", verb, len(started))
+	}
+	if len(busy) > 0 {
+		m.message = strings.TrimSpace(m.message + " Busy, skipped: " + strings.Join(busy, ", "))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// announceEvents shows the latest change between two VM lists in the status line,
+// unless it holds something more important such as an operation in flight
+func (m *Model) announceEvents(events []hyperv.Event) {
+	if len(events) == 0 {
+		return
+	}
+	idle := m.message == "" || m.message == "Refreshing VM list..." || m.message == "VM list refreshed!" ||
+		strings.HasPrefix(m.message, eventMessagePrefix)
+	if !idle || m.err != nil {
+		return
+	}
+	m.message = eventMessagePrefix + events[len(events)-1].Describe()
+	if len(events) > 1 {
+		m.message += fmt.Sprintf(" (+%d more)", len(events)-1)
+	}
+}
+
+// eventMessagePrefix marks status messages set by announceEvents
+const eventMessagePrefix = "⚡ "
+
 // View renders the TUI view
 func (m Model) View() string {
 	var b strings.Builder
 
 	// Title
 	title := titleStyle.Render("🖥️  QuickVM - Hyper-V Manager")
+	if m.workspace != nil {
+		title = titleStyle.Render(fmt.Sprintf("🖥️  QuickVM - Workspace: %s", m.workspace.Name))
+	}
 	b.WriteString(title)
+	b.WriteString("\n")
+	b.WriteString(m.viewScreenTabs())
 	b.WriteString("\n\n")
 
-	// Table
-	b.WriteString(baseStyle.Render(m.table.View()))
-	b.WriteString("\n")
+	switch m.screen {
+	case screenWorkspaces:
+		b.WriteString(m.viewWorkspaces())
+		b.WriteString("\n")
+	case screenHost:
+		b.WriteString(m.viewHost())
+		b.WriteString("\n")
+	default:
+		b.WriteString(m.viewVMs())
+	}
+
+	if m.showOps {
+		b.WriteString(m.viewOperations())
+		b.WriteString("\n")
+	}
+
+	if m.output.text != "" {
+		b.WriteString(m.output.View())
+		b.WriteString("\n")
+	}
+
+	if m.dialog != nil {
+		b.WriteString(m.dialog.View())
+		b.WriteString("\n")
+	}
+
+	if m.palette != nil {
+		b.WriteString(m.viewPalette())
+		b.WriteString("\n")
+	}
 
 	// Message
 	if m.message != "" {
 		b.WriteString("\n")
 		if m.err != nil {
-			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.message))
+			b.WriteString(opFailedStyle.Render(m.message))
 		} else {
-			b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(m.message))
+			b.WriteString(opDoneStyle.Render(m.message))
 		}
 		b.WriteString("\n")
 	}
 
-	// Help
-	help := helpStyle.Render(
-		"↑/↓: Navigate • Enter: Start VM • s: Stop VM • t: Restart VM • r: Refresh • q: Quit",
-	)
+	// Help, generated from the active keymap
+	labels := map[action]string{}
+	if m.interval > 0 {
+		labels[actionRefresh] = fmt.Sprintf("Refresh (auto every %s)", m.interval)
+	}
 	b.WriteString("\n")
-	b.WriteString(help)
+	b.WriteString(helpStyle.Render(m.keys.help(screenActions[m.screen], labels)))
 
 	return b.String()
 }
+
+// viewVMs renders the VM table with the detail pane and the list status
+func (m Model) viewVMs() string {
+	var b strings.Builder
+
+	// Table and detail pane, side by side when the terminal is wide enough
+	tableView := baseStyle.Render(m.table.View())
+	detailView := m.viewDetail()
+	if m.width >= lipgloss.Width(tableView)+lipgloss.Width(detailView) {
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tableView, detailView))
+	} else {
+		b.WriteString(lipgloss.JoinVertical(lipgloss.Left, tableView, detailView))
+	}
+	b.WriteString("\n")
+
+	if m.workspace != nil {
+		b.WriteString(m.workspaceSummary())
+		b.WriteString("\n")
+	}
+
+	if status := m.listStatus(); status != "" {
+		b.WriteString(helpStyle.Render(status))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// listStatus describes the active filter, sort and selection
+func (m Model) listStatus() string {
+	var parts []string
+	switch {
+	case m.filtering:
+		parts = append(parts, fmt.Sprintf("/%s█ (%d/%d)", m.prefs.Filter, len(m.vms), len(m.all)))
+	case m.prefs.Filter != "":
+		parts = append(parts, fmt.Sprintf("Filter: %s (%d/%d, Esc to clear)", m.prefs.Filter, len(m.vms), len(m.all)))
+	}
+	if m.prefs.SortBy != "" {
+		order := "ascending"
+		if m.prefs.SortDesc {
+			order = "descending"
+		}
+		parts = append(parts, fmt.Sprintf("Sort: %s %s", m.prefs.SortBy, order))
+	}
+	if len(m.selected) > 0 {
+		parts = append(parts, fmt.Sprintf("Selected: %d", len(m.selected)))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// workspaceSummary renders the running count and memory committed to workspace members
+func (m Model) workspaceSummary() string {
+	status := hyperv.BuildWorkspaceStatus(m.workspace, m.all, nil)
+	summary := fmt.Sprintf("Running: %d/%d • Memory: %d MB", status.Running, status.Total, status.MemoryMB)
+	if status.Missing > 0 {
+		summary += fmt.Sprintf(" • Missing: %d", status.Missing)
+	}
+	return helpStyle.Render(summary)
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"quickvm/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles, built from the active theme by applyTheme
+var (
+	baseStyle          lipgloss.Style
+	headerStyle        lipgloss.Style
+	selectedStyle      lipgloss.Style
+	titleStyle         lipgloss.Style
+	helpStyle          lipgloss.Style
+	statusRunningStyle lipgloss.Style
+	statusStoppedStyle lipgloss.Style
+	statusOtherStyle   lipgloss.Style
+	flashStyle         lipgloss.Style // Highlights a state that changed during the last few seconds
+	sparkStyle         lipgloss.Style
+
+	paneStyle        lipgloss.Style
+	focusedPaneStyle lipgloss.Style
+	activeTabStyle   lipgloss.Style
+	inactiveTabStyle lipgloss.Style
+	dimStyle         lipgloss.Style
+
+	opDoneStyle     lipgloss.Style
+	opFailedStyle   lipgloss.Style
+	opCanceledStyle lipgloss.Style
+
+	dialogStyle  lipgloss.Style
+	paletteStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(theme.Active())
+}
+
+// fg returns a style with a theme colour as foreground; an empty colour keeps the terminal default
+func fg(c string) lipgloss.Style {
+	if c == "" {
+		return lipgloss.NewStyle()
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// onSurface returns a style with a theme colour as background, or reverse video without one
+func onSurface(style lipgloss.Style, background string) lipgloss.Style {
+	if background == "" {
+		return style.Reverse(true)
+	}
+	return style.Background(lipgloss.Color(background))
+}
+
+// applyTheme rebuilds every style from a theme
+func applyTheme(t theme.Theme) {
+	border := fg(t.Border).GetForeground()
+	accent := fg(t.Accent).GetForeground()
+
+	baseStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(border)
+	headerStyle = fg(t.Accent).Bold(true).Padding(0, 1)
+	if t.Surface != "" {
+		headerStyle = headerStyle.Background(lipgloss.Color(t.Surface))
+	}
+	selectedStyle = onSurface(fg(t.Highlight), t.HighlightBackground).Bold(true)
+	titleStyle = headerStyle.Copy().MarginBottom(1)
+	helpStyle = fg(t.Muted).MarginTop(1)
+	statusRunningStyle = fg(t.Success).Bold(true)
+	statusStoppedStyle = fg(t.Error).Bold(true)
+	statusOtherStyle = fg(t.Warning).Bold(true)
+	flashStyle = onSurface(fg(t.Surface), t.Warning).Bold(true)
+	sparkStyle = fg(t.Info)
+
+	paneStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).BorderForeground(border).
+		Padding(0, 1).Width(detailPaneWidth)
+	focusedPaneStyle = paneStyle.Copy().BorderForeground(accent)
+	if t.Accent == "" {
+		focusedPaneStyle = focusedPaneStyle.BorderStyle(lipgloss.ThickBorder())
+	}
+	activeTabStyle = selectedStyle.Copy().Padding(0, 1)
+	inactiveTabStyle = fg(t.Muted).Padding(0, 1)
+	dimStyle = fg(t.Muted)
+
+	opDoneStyle = fg(t.Success)
+	opFailedStyle = fg(t.Error)
+	opCanceledStyle = fg(t.Muted)
+
+	dialogStyle = lipgloss.NewStyle().BorderStyle(lipgloss.DoubleBorder()).
+		BorderForeground(fg(t.Warning).GetForeground()).Padding(0, 1)
+	paletteStyle = lipgloss.NewStyle().BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(accent).Padding(0, 1)
+}
+
+// renderState renders a VM state as its symbol and name in its colour
+func renderState(state string) string {
+	return stateStyle(state).Render(theme.StateSymbol(state) + " " + state)
+}
+
+// stateStyle returns the style of a VM state
+func stateStyle(state string) lipgloss.Style {
+	switch theme.StateStatus(state) {
+	case theme.StatusOK:
+		return statusRunningStyle
+	case theme.StatusError:
+		return statusStoppedStyle
+	}
+	return statusOtherStyle
+}
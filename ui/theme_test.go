@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"quickvm/internal/hyperv"
+	"quickvm/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestApplyTheme_MonochromeUsesNoColours(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	monochrome, err := theme.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	applyTheme(monochrome)
+	t.Cleanup(func() { applyTheme(theme.Active()) })
+
+	for name, style := range map[string]lipgloss.Style{"selected": selectedStyle, "flash": flashStyle} {
+		if !style.GetReverse() {
+			t.Errorf("Expected the %s style to use reverse video without colours", name)
+		}
+	}
+	for name, style := range map[string]lipgloss.Style{"running": statusRunningStyle, "header": headerStyle} {
+		if style.GetForeground() != (lipgloss.NoColor{}) || style.GetBackground() != (lipgloss.NoColor{}) {
+			t.Errorf("Expected no colours in the %s style", name)
+		}
+	}
+}
+
+func TestVMTable_StatesHaveSymbols(t *testing.T) {
+	m := newTestModel(&fakeExec{},
+		hyperv.VM{Index: 1, Name: "Web01", State: "Running"},
+		hyperv.VM{Index: 2, Name: "Db01", State: "Off"},
+		hyperv.VM{Index: 3, Name: "Cache01", State: "Paused"})
+
+	view := m.View()
+	for _, want := range []string{"● Running", "○ Off", "◐ Paused"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the table to show %q", want)
+		}
+	}
+}
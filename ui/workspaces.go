@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"quickvm/internal/hyperv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// workspacesMsg delivers the saved workspaces
+type workspacesMsg struct {
+	workspaces []*hyperv.Workspace
+	err        error // Workspaces that could not be loaded
+}
+
+// loadWorkspaces loads every saved workspace; broken files are reported but do not hide the others
+func (m Model) loadWorkspaces() tea.Msg {
+	names, err := hyperv.ListWorkspaces()
+	if err != nil {
+		return workspacesMsg{err: err}
+	}
+	var workspaces []*hyperv.Workspace
+	var errs []error
+	for _, name := range names {
+		ws, err := hyperv.LoadWorkspace(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+	return workspacesMsg{workspaces: workspaces, err: errors.Join(errs...)}
+}
+
+// selectedWorkspace returns the workspace under the cursor
+func (m Model) selectedWorkspace() (*hyperv.Workspace, bool) {
+	if m.wsCursor >= len(m.workspaces) {
+		return nil, false
+	}
+	return m.workspaces[m.wsCursor], true
+}
+
+// workspaceTarget is the operation target of a workspace
+func workspaceTarget(name string) string {
+	return "workspace " + name
+}
+
+// handleWorkspacesKey handles keys on the workspaces screen
+func (m Model) handleWorkspacesKey(msg tea.KeyMsg, act action) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.wsCursor > 0 {
+			m.wsCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.wsCursor < len(m.workspaces)-1 {
+			m.wsCursor++
+		}
+		return m, nil
+	case "esc":
+		m.screen = screenVMs
+		return m, nil
+	}
+
+	switch act {
+	case actionQuit:
+		return m, tea.Quit
+	case actionRefresh:
+		return m, tea.Batch(m.loadWorkspaces, m.loadVMs)
+	case actionPalette:
+		return m.openPalette(), nil
+	case actionOperations:
+		m.showOps = !m.showOps
+	case actionCancel:
+		if ws, ok := m.selectedWorkspace(); ok {
+			return m.cancelOperations([]string{workspaceTarget(ws.Name)}), nil
+		}
+	case actionStart:
+		if ws, ok := m.selectedWorkspace(); ok {
+			return m.startOperation(m.workspaceOperation(ws, true))
+		}
+	case actionStop:
+		if ws, ok := m.selectedWorkspace(); ok {
+			op := m.workspaceOperation(ws, false)
+			m.dialog = newConfirmDialog(fmt.Sprintf("Stop the %d VMs of workspace '%s'?", len(ws.VMs), ws.Name),
+				func() tea.Msg { return op })
+		}
+	}
+	return m, nil
+}
+
+// workspaceOperation starts or stops a workspace in dependency order
+func (m Model) workspaceOperation(ws *hyperv.Workspace, start bool) startOpMsg {
+	verb, done := "Stopping", "stopped"
+	if start {
+		verb, done = "Starting", "started"
+	}
+	return startOpMsg{
+		target:  workspaceTarget(ws.Name),
+		verb:    verb,
+		success: fmt.Sprintf("Workspace '%s' %s", ws.Name, done),
+		run: func(ctx context.Context) error {
+			orchestrator := hyperv.NewWorkspaceOrchestrator(m.manager)
+			run := orchestrator.Stop
+			if start {
+				run = orchestrator.Start
+			}
+			results, err := run(ctx, ws)
+			if err != nil {
+				return err
+			}
+			return workspaceRunError(results)
+		},
+	}
+}
+
+// workspaceRunError summarizes the members that failed, or returns nil
+func workspaceRunError(results []hyperv.WorkspaceVMResult) error {
+	var failed []string
+	for _, r := range results {
+		if !r.Success && !r.Skipped {
+			failed = append(failed, fmt.Sprintf("%s: %s", r.Name, r.Error))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d VMs failed (%s)", len(failed), len(results), strings.Join(failed, "; "))
+}
+
+// viewWorkspaces renders the workspace list and the members of the selected workspace
+func (m Model) viewWorkspaces() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%-24s %-10s %-12s %-8s %s", "Workspace", "Running", "Memory(MB)", "Missing", "Description")))
+	if len(m.workspaces) == 0 {
+		b.WriteString("\n" + dimStyle.Render("No workspaces. Create one with 'quickvm workspace create'."))
+	}
+	for i, ws := range m.workspaces {
+		status := hyperv.BuildWorkspaceStatus(ws, m.all, nil)
+		name := ws.Name
+		if op := m.ops.busy(workspaceTarget(ws.Name)); op != nil {
+			name = m.spinner.View() + " " + name
+		}
+		line := fmt.Sprintf("%-24s %-10s %-12d %-8d %s", name,
+			fmt.Sprintf("%d/%d", status.Running, status.Total), status.MemoryMB, status.Missing, ws.Description)
+		if i == m.wsCursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString("\n" + line)
+	}
+	if m.wsErr != nil {
+		b.WriteString("\n" + statusStoppedStyle.Render(fmt.Sprintf("Error: %v", m.wsErr)))
+	}
+
+	if ws, ok := m.selectedWorkspace(); ok {
+		b.WriteString("\n\n" + headerStyle.Render("Members of "+ws.Name))
+		status := hyperv.BuildWorkspaceStatus(ws, m.all, nil)
+		for _, member := range status.Members {
+			b.WriteString(fmt.Sprintf("\n%-30s %s", member.Name, renderState(member.State)))
+		}
+	}
+	return baseStyle.Render(b.String())
+}